@@ -0,0 +1,310 @@
+package arrow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/compute"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+)
+
+// AggregateFunc names an aggregation applied to each group
+type AggregateFunc string
+
+const (
+	// AggregateSum sums the non-null values of a numeric column
+	AggregateSum AggregateFunc = "sum"
+	// AggregateCount counts the non-null values of a column, or the rows of
+	// the group when no column is given
+	AggregateCount AggregateFunc = "count"
+	// AggregateMin takes the smallest value of a column
+	AggregateMin AggregateFunc = "min"
+	// AggregateMax takes the largest value of a column
+	AggregateMax AggregateFunc = "max"
+	// AggregateAvg averages the non-null values of a numeric column
+	AggregateAvg AggregateFunc = "avg"
+)
+
+// Aggregation describes one output column of AggregateRecord
+type Aggregation struct {
+	// Func is the aggregation to apply
+	Func AggregateFunc
+	// Column is the input column. It may be empty for AggregateCount.
+	Column string
+	// Alias is the output column name. Defaults to "<func>_<column>".
+	Alias string
+}
+
+// outputName returns the name of the column produced by the aggregation
+func (a Aggregation) outputName() string {
+	if a.Alias != "" {
+		return a.Alias
+	}
+	if a.Column == "" {
+		return string(a.Func)
+	}
+	return fmt.Sprintf("%s_%s", a.Func, a.Column)
+}
+
+// AggregateRecord groups a record by the given columns and computes the
+// aggregations for each group. The output has one row per group, in order of
+// first appearance, with the group columns followed by one column per
+// aggregation. Nulls in group columns form their own group. With no group
+// columns the whole record is a single group.
+//
+// Sums of integer columns are int64 and sums of floating point columns are
+// float64; averages are always float64; counts are int64; minimum and maximum
+// keep the input type. Aggregates over groups with no non-null values are null,
+// except counts.
+func AggregateRecord(ctx context.Context, record arrow.Record, groupBy []string, aggs []Aggregation, mem memory.Allocator) (arrow.Record, error) {
+	if mem == nil {
+		mem = memory.NewGoAllocator()
+	}
+	schema := record.Schema()
+	numRows := int(record.NumRows())
+
+	// Resolve the group columns
+	groupCols := make([]int, len(groupBy))
+	for i, name := range groupBy {
+		indices := schema.FieldIndices(name)
+		if len(indices) == 0 {
+			return nil, fmt.Errorf("column with name '%s' not found in schema", name)
+		}
+		groupCols[i] = indices[0]
+	}
+
+	// Assign every row to a group
+	rowGroups := make([]int, numRows)
+	var firstRows []int64
+	if len(groupCols) == 0 {
+		firstRows = []int64{0}
+	} else {
+		groupIndex := make(map[string]int)
+		for row := 0; row < numRows; row++ {
+			key := groupKey(record, groupCols, row)
+			group, ok := groupIndex[key]
+			if !ok {
+				group = len(firstRows)
+				groupIndex[key] = group
+				firstRows = append(firstRows, int64(row))
+			}
+			rowGroups[row] = group
+		}
+	}
+	numGroups := len(firstRows)
+
+	fields := make([]arrow.Field, 0, len(groupCols)+len(aggs))
+	columns := make([]arrow.Array, 0, len(groupCols)+len(aggs))
+	defer func() {
+		for _, col := range columns {
+			col.Release()
+		}
+	}()
+
+	// Gather the group columns from the first row of each group
+	if len(groupCols) > 0 {
+		indicesBuilder := array.NewInt64Builder(mem)
+		defer indicesBuilder.Release()
+		indicesBuilder.AppendValues(firstRows, nil)
+		indices := indicesBuilder.NewInt64Array()
+		defer indices.Release()
+
+		takeCtx := compute.WithAllocator(ctx, mem)
+		for _, i := range groupCols {
+			col, err := compute.TakeArray(takeCtx, record.Column(i), indices)
+			if err != nil {
+				return nil, fmt.Errorf("failed to gather group column %s: %w", schema.Field(i).Name, err)
+			}
+			fields = append(fields, schema.Field(i))
+			columns = append(columns, col)
+		}
+	}
+
+	// Compute each aggregation
+	for _, agg := range aggs {
+		var input arrow.Array
+		if agg.Column != "" {
+			indices := schema.FieldIndices(agg.Column)
+			if len(indices) == 0 {
+				return nil, fmt.Errorf("column with name '%s' not found in schema", agg.Column)
+			}
+			input = record.Column(indices[0])
+		} else if agg.Func != AggregateCount {
+			return nil, fmt.Errorf("%s aggregation requires a column", agg.Func)
+		}
+
+		col, err := aggregateColumn(ctx, agg.Func, input, rowGroups, numGroups, mem)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute %s: %w", agg.outputName(), err)
+		}
+		fields = append(fields, arrow.Field{Name: agg.outputName(), Type: col.DataType(), Nullable: true})
+		columns = append(columns, col)
+	}
+
+	return array.NewRecord(arrow.NewSchema(fields, nil), columns, int64(numGroups)), nil
+}
+
+// aggregateColumn computes a single aggregation of input for every group. A nil
+// input counts rows.
+func aggregateColumn(ctx context.Context, fn AggregateFunc, input arrow.Array, rowGroups []int, numGroups int, mem memory.Allocator) (arrow.Array, error) {
+	switch fn {
+	case AggregateCount:
+		counts := make([]int64, numGroups)
+		for row, group := range rowGroups {
+			if input == nil || input.IsValid(row) {
+				counts[group]++
+			}
+		}
+		builder := array.NewInt64Builder(mem)
+		defer builder.Release()
+		builder.AppendValues(counts, nil)
+		return builder.NewArray(), nil
+
+	case AggregateSum, AggregateAvg:
+		return aggregateNumeric(fn, input, rowGroups, numGroups, mem)
+
+	case AggregateMin, AggregateMax:
+		compare, err := valueComparator(input)
+		if err != nil {
+			return nil, err
+		}
+
+		// Track the row holding the extreme value of each group
+		best := make([]int64, numGroups)
+		for i := range best {
+			best[i] = -1
+		}
+		for row, group := range rowGroups {
+			if input.IsNull(row) {
+				continue
+			}
+			if best[group] < 0 {
+				best[group] = int64(row)
+				continue
+			}
+			c := compare(row, int(best[group]))
+			if (fn == AggregateMin && c < 0) || (fn == AggregateMax && c > 0) {
+				best[group] = int64(row)
+			}
+		}
+
+		indicesBuilder := array.NewInt64Builder(mem)
+		defer indicesBuilder.Release()
+		for _, row := range best {
+			if row < 0 {
+				indicesBuilder.AppendNull()
+			} else {
+				indicesBuilder.Append(row)
+			}
+		}
+		indices := indicesBuilder.NewInt64Array()
+		defer indices.Release()
+
+		return compute.TakeArray(compute.WithAllocator(ctx, mem), input, indices)
+
+	default:
+		return nil, fmt.Errorf("unsupported aggregation: %q", fn)
+	}
+}
+
+// aggregateNumeric computes sums or averages of a numeric column per group
+func aggregateNumeric(fn AggregateFunc, input arrow.Array, rowGroups []int, numGroups int, mem memory.Allocator) (arrow.Array, error) {
+	intValue, floatValue, err := numericAccessor(input)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]int64, numGroups)
+	intSums := make([]int64, numGroups)
+	floatSums := make([]float64, numGroups)
+	for row, group := range rowGroups {
+		if input.IsNull(row) {
+			continue
+		}
+		counts[group]++
+		if intValue != nil {
+			intSums[group] += intValue(row)
+		} else {
+			floatSums[group] += floatValue(row)
+		}
+	}
+
+	// Integer sums stay integers
+	if fn == AggregateSum && intValue != nil {
+		builder := array.NewInt64Builder(mem)
+		defer builder.Release()
+		for group, sum := range intSums {
+			if counts[group] == 0 {
+				builder.AppendNull()
+			} else {
+				builder.Append(sum)
+			}
+		}
+		return builder.NewArray(), nil
+	}
+
+	builder := array.NewFloat64Builder(mem)
+	defer builder.Release()
+	for group := range counts {
+		if counts[group] == 0 {
+			builder.AppendNull()
+			continue
+		}
+		sum := floatSums[group]
+		if intValue != nil {
+			sum = float64(intSums[group])
+		}
+		if fn == AggregateAvg {
+			sum /= float64(counts[group])
+		}
+		builder.Append(sum)
+	}
+	return builder.NewArray(), nil
+}
+
+// numericAccessor returns a value accessor for a numeric array: an int64
+// accessor for integer types or a float64 accessor for floating point types
+func numericAccessor(arr arrow.Array) (func(int) int64, func(int) float64, error) {
+	switch a := arr.(type) {
+	case *array.Int8:
+		return func(i int) int64 { return int64(a.Value(i)) }, nil, nil
+	case *array.Int16:
+		return func(i int) int64 { return int64(a.Value(i)) }, nil, nil
+	case *array.Int32:
+		return func(i int) int64 { return int64(a.Value(i)) }, nil, nil
+	case *array.Int64:
+		return a.Value, nil, nil
+	case *array.Uint8:
+		return func(i int) int64 { return int64(a.Value(i)) }, nil, nil
+	case *array.Uint16:
+		return func(i int) int64 { return int64(a.Value(i)) }, nil, nil
+	case *array.Uint32:
+		return func(i int) int64 { return int64(a.Value(i)) }, nil, nil
+	case *array.Uint64:
+		return func(i int) int64 { return int64(a.Value(i)) }, nil, nil
+	case *array.Float32:
+		return nil, func(i int) float64 { return float64(a.Value(i)) }, nil
+	case *array.Float64:
+		return nil, a.Value, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported type for numeric aggregation: %s", arr.DataType())
+	}
+}
+
+// groupKey encodes the group columns of a row as a map key, keeping nulls
+// distinct from every value
+func groupKey(record arrow.Record, keys []int, row int) string {
+	var sb strings.Builder
+	for _, k := range keys {
+		col := record.Column(k)
+		if col.IsNull(row) {
+			sb.WriteByte('-')
+			continue
+		}
+		writeKeyValue(&sb, col.ValueStr(row))
+	}
+	return sb.String()
+}
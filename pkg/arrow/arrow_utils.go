@@ -1069,6 +1069,84 @@ func FilterBatch(batch arrow.Record, threshold float64) (arrow.Record, error) {
 	return filteredBatch, nil
 }
 
+// SelectColumns returns a view of the record containing only the named columns,
+// in the requested order. Column data is shared with the input record, which is
+// left untouched; the caller must release the returned record.
+func SelectColumns(record arrow.Record, columns []string) (arrow.Record, error) {
+	schema := record.Schema()
+	fields := make([]arrow.Field, len(columns))
+	arrays := make([]arrow.Array, len(columns))
+
+	for i, name := range columns {
+		indices := schema.FieldIndices(name)
+		if len(indices) == 0 {
+			return nil, fmt.Errorf("column with name '%s' not found in schema", name)
+		}
+		fields[i] = schema.Field(indices[0])
+		arrays[i] = record.Column(indices[0])
+	}
+
+	metadata := schema.Metadata()
+	projected := arrow.NewSchema(fields, &metadata)
+	return array.NewRecord(projected, arrays, record.NumRows()), nil
+}
+
+// SelectSchema returns the schema SelectColumns gives records of the schema
+func SelectSchema(schema *arrow.Schema, columns []string) (*arrow.Schema, error) {
+	fields := make([]arrow.Field, len(columns))
+	for i, name := range columns {
+		indices := schema.FieldIndices(name)
+		if len(indices) == 0 {
+			return nil, fmt.Errorf("column with name '%s' not found in schema", name)
+		}
+		fields[i] = schema.Field(indices[0])
+	}
+
+	metadata := schema.Metadata()
+	return arrow.NewSchema(fields, &metadata), nil
+}
+
+// ConcatRecords combines records sharing a schema into a single record. With
+// no records the result is an empty record with the schema.
+func ConcatRecords(mem memory.Allocator, schema *arrow.Schema, records []arrow.Record) (arrow.Record, error) {
+	if mem == nil {
+		mem = memory.NewGoAllocator()
+	}
+	if len(records) == 0 {
+		builder := array.NewRecordBuilder(mem, schema)
+		defer builder.Release()
+		return builder.NewRecord(), nil
+	}
+
+	columns := make([]arrow.Array, schema.NumFields())
+	defer func() {
+		for _, col := range columns {
+			if col != nil {
+				col.Release()
+			}
+		}
+	}()
+
+	var numRows int64
+	for _, rec := range records {
+		numRows += rec.NumRows()
+	}
+
+	chunks := make([]arrow.Array, len(records))
+	for i := range columns {
+		for j, rec := range records {
+			chunks[j] = rec.Column(i)
+		}
+		col, err := array.Concatenate(chunks, mem)
+		if err != nil {
+			return nil, fmt.Errorf("failed to combine column %s: %w", schema.Field(i).Name, err)
+		}
+		columns[i] = col
+	}
+
+	return array.NewRecord(schema, columns, numRows), nil
+}
+
 // CombineBatches combines multiple Arrow batches into a single batch
 func CombineBatches(batches []arrow.Record) (arrow.Record, error) {
 	if len(batches) == 0 {
@@ -1089,51 +1167,5 @@ func CombineBatches(batches []arrow.Record) (arrow.Record, error) {
 		}
 	}
 
-	// Calculate the total number of rows
-	var totalRows int64
-	for _, batch := range batches {
-		totalRows += batch.NumRows()
-	}
-
-	// Create builders for each column
-	allocator := memory.NewGoAllocator()
-	builders := make([]array.Builder, schema.NumFields())
-	arrays := make([]arrow.Array, schema.NumFields())
-
-	for i, field := range schema.Fields() {
-		builders[i] = array.NewBuilder(allocator, field.Type)
-		defer builders[i].Release()
-	}
-
-	// Copy data from each batch
-	for _, batch := range batches {
-		for colIdx := 0; colIdx < int(batch.NumCols()); colIdx++ {
-			col := batch.Column(colIdx)
-			builder := builders[colIdx]
-
-			// Copy each value in the column
-			for rowIdx := 0; rowIdx < int(batch.NumRows()); rowIdx++ {
-				switch col.DataType().ID() {
-				case arrow.INT32:
-					builder.(*array.Int32Builder).Append(col.(*array.Int32).Value(rowIdx))
-				case arrow.FLOAT64:
-					builder.(*array.Float64Builder).Append(col.(*array.Float64).Value(rowIdx))
-				case arrow.STRING:
-					builder.(*array.StringBuilder).Append(col.(*array.String).Value(rowIdx))
-				default:
-					return nil, fmt.Errorf("unsupported data type: %s", col.DataType().Name())
-				}
-			}
-		}
-	}
-
-	// Build arrays
-	for i := 0; i < int(schema.NumFields()); i++ {
-		arrays[i] = builders[i].NewArray()
-		defer arrays[i].Release()
-	}
-
-	// Create the combined record
-	combinedBatch := array.NewRecord(schema, arrays, totalRows)
-	return combinedBatch, nil
+	return ConcatRecords(memory.NewGoAllocator(), schema, batches)
 }
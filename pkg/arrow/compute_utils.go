@@ -1,11 +1,15 @@
 package arrow
 
 import (
+	"bytes"
+	"cmp"
 	"context"
 	"fmt"
+	"slices"
 
 	"github.com/apache/arrow-go/v18/arrow"
 	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/compute"
 	"github.com/apache/arrow-go/v18/arrow/memory"
 )
 
@@ -544,9 +548,87 @@ func FilterRecordByColumn(ctx context.Context, record arrow.Record, columnName s
 
 // SortRecordByColumn sorts a record by the specified column
 func SortRecordByColumn(ctx context.Context, record arrow.Record, columnName string, order SortOrder, mem memory.Allocator) (arrow.Record, error) {
-	// This is a placeholder implementation
-	// In a real implementation, you would need to implement sorting logic
-	return record, fmt.Errorf("SortRecordByColumn not implemented")
+	return SortRecord(ctx, record, []SortKey{{Column: columnName, Order: order}}, mem)
+}
+
+// SortKey describes one column of a multi-column sort
+type SortKey struct {
+	// Column is the name of the column to sort by
+	Column string
+	// Order is the sort direction for this column
+	Order SortOrder
+	// NullsLast places nulls after all other values instead of before them
+	NullsLast bool
+}
+
+// SortRecord returns a copy of record sorted by the given keys, compared in the
+// order listed. The sort is stable and runs entirely in memory: the sort
+// indices and the sorted copy are held alongside the input.
+func SortRecord(ctx context.Context, record arrow.Record, keys []SortKey, mem memory.Allocator) (arrow.Record, error) {
+	if mem == nil {
+		mem = memory.NewGoAllocator()
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("at least one sort key is required")
+	}
+
+	schema := record.Schema()
+	comparators := make([]func(i, j int) int, len(keys))
+	for k, key := range keys {
+		indices := schema.FieldIndices(key.Column)
+		if len(indices) == 0 {
+			return nil, fmt.Errorf("column with name '%s' not found in schema", key.Column)
+		}
+		col := record.Column(indices[0])
+		compare, err := valueComparator(col)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", key.Column, err)
+		}
+		if key.Order == Descending {
+			ascending := compare
+			compare = func(i, j int) int { return ascending(j, i) }
+		}
+		comparators[k] = withNullOrder(col, compare, !key.NullsLast)
+	}
+
+	// Sort the row indices rather than the data
+	order := make([]int64, record.NumRows())
+	for i := range order {
+		order[i] = int64(i)
+	}
+	slices.SortStableFunc(order, func(a, b int64) int {
+		for _, compare := range comparators {
+			if c := compare(int(a), int(b)); c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+
+	indicesBuilder := array.NewInt64Builder(mem)
+	defer indicesBuilder.Release()
+	indicesBuilder.AppendValues(order, nil)
+	indices := indicesBuilder.NewInt64Array()
+	defer indices.Release()
+
+	// Gather every column in sorted order
+	columns := make([]arrow.Array, 0, record.NumCols())
+	defer func() {
+		for _, col := range columns {
+			col.Release()
+		}
+	}()
+
+	ctx = compute.WithAllocator(ctx, mem)
+	for i := 0; i < int(record.NumCols()); i++ {
+		col, err := compute.TakeArray(ctx, record.Column(i), indices)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reorder column %s: %w", record.ColumnName(i), err)
+		}
+		columns = append(columns, col)
+	}
+
+	return array.NewRecord(schema, columns, record.NumRows()), nil
 }
 
 // SumRecordColumn calculates the sum of values in the specified column
@@ -613,3 +695,141 @@ func MeanAggregator() func(context.Context, arrow.Array) (interface{}, error) {
 		return 0.0, nil
 	}
 }
+
+// FirstUnsortedRow returns the index of the first row that is not in ascending
+// order by the given columns, comparing lexicographically across the columns in
+// the order listed, or -1 if the record is sorted. Nulls sort before all other
+// values when nullsFirst is set and after them otherwise.
+func FirstUnsortedRow(record arrow.Record, columns []string, nullsFirst bool) (int64, error) {
+	schema := record.Schema()
+	comparators := make([]func(i, j int) int, len(columns))
+
+	for k, name := range columns {
+		indices := schema.FieldIndices(name)
+		if len(indices) == 0 {
+			return -1, fmt.Errorf("column with name '%s' not found in schema", name)
+		}
+		compare, err := rowComparator(record.Column(indices[0]), nullsFirst)
+		if err != nil {
+			return -1, fmt.Errorf("column %s: %w", name, err)
+		}
+		comparators[k] = compare
+	}
+
+	for row := 1; row < int(record.NumRows()); row++ {
+		for _, compare := range comparators {
+			c := compare(row-1, row)
+			if c < 0 {
+				break
+			}
+			if c > 0 {
+				return int64(row), nil
+			}
+		}
+	}
+
+	return -1, nil
+}
+
+// rowComparator returns a function comparing two rows of arr, ordering nulls
+// according to nullsFirst
+func rowComparator(arr arrow.Array, nullsFirst bool) (func(i, j int) int, error) {
+	compare, err := valueComparator(arr)
+	if err != nil {
+		return nil, err
+	}
+	return withNullOrder(arr, compare, nullsFirst), nil
+}
+
+// valueComparator returns a function comparing two non-null values of arr
+func valueComparator(arr arrow.Array) (func(i, j int) int, error) {
+	var compare func(i, j int) int
+
+	switch a := arr.(type) {
+	case *array.Int8:
+		compare = orderedComparator(a.Value)
+	case *array.Int16:
+		compare = orderedComparator(a.Value)
+	case *array.Int32:
+		compare = orderedComparator(a.Value)
+	case *array.Int64:
+		compare = orderedComparator(a.Value)
+	case *array.Uint8:
+		compare = orderedComparator(a.Value)
+	case *array.Uint16:
+		compare = orderedComparator(a.Value)
+	case *array.Uint32:
+		compare = orderedComparator(a.Value)
+	case *array.Uint64:
+		compare = orderedComparator(a.Value)
+	case *array.Float32:
+		compare = orderedComparator(a.Value)
+	case *array.Float64:
+		compare = orderedComparator(a.Value)
+	case *array.Date32:
+		compare = orderedComparator(a.Value)
+	case *array.Date64:
+		compare = orderedComparator(a.Value)
+	case *array.Timestamp:
+		compare = orderedComparator(a.Value)
+	case *array.Time32:
+		compare = orderedComparator(a.Value)
+	case *array.Time64:
+		compare = orderedComparator(a.Value)
+	case *array.Duration:
+		compare = orderedComparator(a.Value)
+	case *array.String:
+		compare = orderedComparator(a.Value)
+	case *array.LargeString:
+		compare = orderedComparator(a.Value)
+	case *array.Binary:
+		compare = func(i, j int) int { return bytes.Compare(a.Value(i), a.Value(j)) }
+	case *array.Boolean:
+		compare = func(i, j int) int {
+			vi, vj := a.Value(i), a.Value(j)
+			switch {
+			case vi == vj:
+				return 0
+			case !vi:
+				return -1
+			default:
+				return 1
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported type for ordering: %s", arr.DataType())
+	}
+
+	return compare, nil
+}
+
+// withNullOrder extends a value comparator to handle the nulls in arr
+func withNullOrder(arr arrow.Array, compare func(i, j int) int, nullsFirst bool) func(i, j int) int {
+	if arr.NullN() == 0 {
+		return compare
+	}
+
+	nullOrder := 1
+	if nullsFirst {
+		nullOrder = -1
+	}
+	return func(i, j int) int {
+		iNull, jNull := arr.IsNull(i), arr.IsNull(j)
+		switch {
+		case iNull && jNull:
+			return 0
+		case iNull:
+			return nullOrder
+		case jNull:
+			return -nullOrder
+		}
+		return compare(i, j)
+	}
+}
+
+// orderedComparator adapts a typed value accessor to a row comparator
+func orderedComparator[T cmp.Ordered](value func(int) T) func(i, j int) int {
+	return func(i, j int) int {
+		return cmp.Compare(value(i), value(j))
+	}
+}
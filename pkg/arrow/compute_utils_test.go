@@ -0,0 +1,106 @@
+package arrow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createKeyedRecord creates a record with a string key and an int64 key with
+// optional nulls
+func createKeyedRecord(t *testing.T, mem memory.Allocator, regions []string, seqs []int64, valid []bool) arrow.Record {
+	t.Helper()
+	schema := arrow.NewSchema(
+		[]arrow.Field{
+			{Name: "region", Type: arrow.BinaryTypes.String},
+			{Name: "seq", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		},
+		nil,
+	)
+
+	builder := array.NewRecordBuilder(mem, schema)
+	defer builder.Release()
+	builder.Field(0).(*array.StringBuilder).AppendValues(regions, nil)
+	builder.Field(1).(*array.Int64Builder).AppendValues(seqs, valid)
+	return builder.NewRecord()
+}
+
+// TestSortRecord tests multi-column sorts with mixed directions and null
+// placement
+func TestSortRecord(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+	ctx := context.Background()
+
+	record := createKeyedRecord(t, mem,
+		[]string{"b", "a", "b", "a", "c"},
+		[]int64{2, 9, 0, 1, 4},
+		[]bool{true, true, false, true, true},
+	)
+	defer record.Release()
+
+	sorted, err := SortRecord(ctx, record, []SortKey{
+		{Column: "region"},
+		{Column: "seq", Order: Descending, NullsLast: true},
+	}, mem)
+	require.NoError(t, err, "Failed to sort record")
+	defer sorted.Release()
+
+	regions := sorted.Column(0).(*array.String)
+	seqs := sorted.Column(1).(*array.Int64)
+	assert.Equal(t, []string{"a", "a", "b", "b", "c"}, []string{
+		regions.Value(0), regions.Value(1), regions.Value(2), regions.Value(3), regions.Value(4),
+	})
+	assert.Equal(t, []int64{9, 1, 2}, []int64{seqs.Value(0), seqs.Value(1), seqs.Value(2)})
+	assert.True(t, seqs.IsNull(3), "Null should sort last within its region")
+
+	row, err := FirstUnsortedRow(sorted, []string{"region"}, true)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-1), row, "Sorted output should pass the sort check")
+
+	// Nulls sort first by default
+	bySeq, err := SortRecordByColumn(ctx, record, "seq", Ascending, mem)
+	require.NoError(t, err, "Failed to sort record")
+	defer bySeq.Release()
+	assert.True(t, bySeq.Column(1).IsNull(0))
+	ordered := bySeq.Column(1).(*array.Int64)
+	assert.Equal(t, []int64{1, 2, 4, 9}, []int64{ordered.Value(1), ordered.Value(2), ordered.Value(3), ordered.Value(4)})
+
+	_, err = SortRecord(ctx, record, nil, mem)
+	assert.Error(t, err)
+	_, err = SortRecord(ctx, record, []SortKey{{Column: "missing"}}, mem)
+	assert.Error(t, err)
+}
+
+// TestFirstUnsortedRow tests that the first out of order row is found across
+// several columns and with either null placement
+func TestFirstUnsortedRow(t *testing.T) {
+	mem := memory.NewGoAllocator()
+
+	record := createKeyedRecord(t, mem, []string{"a", "a", "b", "b"}, []int64{1, 2, 1, 5}, nil)
+	defer record.Release()
+
+	row, err := FirstUnsortedRow(record, []string{"region", "seq"}, true)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-1), row)
+	row, err = FirstUnsortedRow(record, []string{"seq", "region"}, true)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), row)
+
+	nulls := createKeyedRecord(t, mem, []string{"a", "b", "c"}, []int64{0, 1, 2}, []bool{false, true, true})
+	defer nulls.Release()
+	row, err = FirstUnsortedRow(nulls, []string{"seq"}, true)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-1), row)
+	row, err = FirstUnsortedRow(nulls, []string{"seq"}, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), row)
+
+	_, err = FirstUnsortedRow(record, []string{"missing"}, true)
+	assert.Error(t, err)
+}
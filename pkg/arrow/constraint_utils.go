@@ -0,0 +1,233 @@
+package arrow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/compute"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/arrow/scalar"
+)
+
+// ConstraintKind selects what a Constraint checks
+type ConstraintKind int
+
+const (
+	// ConstraintNotNull rejects null values
+	ConstraintNotNull ConstraintKind = iota
+	// ConstraintRange rejects values below Min or above Max
+	ConstraintRange
+	// ConstraintOneOf rejects values not listed in Values
+	ConstraintOneOf
+)
+
+// maxReportedRows bounds the row indices listed in a violation's message
+const maxReportedRows = 10
+
+// Constraint is a rule the values of a column must satisfy. Range and
+// one-of constraints ignore nulls; combine them with ConstraintNotNull to
+// reject nulls as well.
+type Constraint struct {
+	// Column is the name of the constrained column
+	Column string
+	Kind   ConstraintKind
+	// Min and Max are the inclusive ConstraintRange bounds, cast to the
+	// column's type; nil leaves that side unbounded
+	Min, Max any
+	// Values lists the values ConstraintOneOf allows, cast to the column's type
+	Values []any
+}
+
+// String describes the constraint
+func (c Constraint) String() string {
+	switch c.Kind {
+	case ConstraintNotNull:
+		return fmt.Sprintf("column %q must not be null", c.Column)
+	case ConstraintRange:
+		switch {
+		case c.Min == nil:
+			return fmt.Sprintf("column %q must be at most %v", c.Column, c.Max)
+		case c.Max == nil:
+			return fmt.Sprintf("column %q must be at least %v", c.Column, c.Min)
+		default:
+			return fmt.Sprintf("column %q must be between %v and %v", c.Column, c.Min, c.Max)
+		}
+	case ConstraintOneOf:
+		return fmt.Sprintf("column %q must be one of %v", c.Column, c.Values)
+	default:
+		return fmt.Sprintf("column %q has unknown constraint kind %d", c.Column, c.Kind)
+	}
+}
+
+// ConstraintViolation lists the rows breaking a constraint
+type ConstraintViolation struct {
+	Constraint Constraint
+	// Rows are the indices of the offending rows, in ascending order
+	Rows []int64
+}
+
+// ConstraintError is returned by ValidateConstraints when rows break any of
+// the constraints. It lists every broken constraint, in the order given.
+type ConstraintError struct {
+	Violations []ConstraintViolation
+}
+
+// Error implements the error interface, listing up to 10 rows per violation
+func (e *ConstraintError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		rows := fmt.Sprint(v.Rows[:min(len(v.Rows), maxReportedRows)])
+		if len(v.Rows) > maxReportedRows {
+			rows += fmt.Sprintf(" and %d more", len(v.Rows)-maxReportedRows)
+		}
+		messages[i] = fmt.Sprintf("%s: rows %s", v.Constraint, rows)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// ValidateConstraints checks every row of record against the constraints,
+// evaluating range and one-of constraints with Arrow compute kernels. It
+// returns a *ConstraintError listing the offending rows of each broken
+// constraint, or another error if a constraint names a missing column or
+// cannot apply to the column's type.
+func ValidateConstraints(ctx context.Context, record arrow.Record, constraints []Constraint, mem memory.Allocator) error {
+	if mem == nil {
+		mem = memory.NewGoAllocator()
+	}
+	ctx = compute.WithAllocator(ctx, mem)
+
+	var violations []ConstraintViolation
+	for _, constraint := range constraints {
+		indices := record.Schema().FieldIndices(constraint.Column)
+		if len(indices) == 0 {
+			return fmt.Errorf("column %q not found", constraint.Column)
+		}
+		rows, err := violatingRows(ctx, record.Column(indices[0]), constraint)
+		if err != nil {
+			return fmt.Errorf("failed to check %s: %w", constraint, err)
+		}
+		if len(rows) > 0 {
+			violations = append(violations, ConstraintViolation{Constraint: constraint, Rows: rows})
+		}
+	}
+
+	if len(violations) > 0 {
+		return &ConstraintError{Violations: violations}
+	}
+	return nil
+}
+
+// violatingRows returns the indices of the rows of col breaking constraint
+func violatingRows(ctx context.Context, col arrow.Array, constraint Constraint) ([]int64, error) {
+	switch constraint.Kind {
+	case ConstraintNotNull:
+		if col.NullN() == 0 {
+			return nil, nil
+		}
+		var rows []int64
+		for i := 0; i < col.Len(); i++ {
+			if col.IsNull(i) {
+				rows = append(rows, int64(i))
+			}
+		}
+		return rows, nil
+
+	case ConstraintRange:
+		var outside compute.Datum
+		for _, bound := range []struct {
+			value any
+			fn    string
+		}{{constraint.Min, "less"}, {constraint.Max, "greater"}} {
+			if bound.value == nil {
+				continue
+			}
+			cmp, err := compareScalar(ctx, bound.fn, col, bound.value)
+			if err != nil {
+				if outside != nil {
+					outside.Release()
+				}
+				return nil, err
+			}
+			if outside, err = combineMasks(ctx, outside, cmp); err != nil {
+				return nil, err
+			}
+		}
+		if outside == nil {
+			return nil, nil
+		}
+		defer outside.Release()
+		return maskRows(outside, col, true), nil
+
+	case ConstraintOneOf:
+		var allowed compute.Datum
+		for _, value := range constraint.Values {
+			eq, err := compareScalar(ctx, "equal", col, value)
+			if err != nil {
+				if allowed != nil {
+					allowed.Release()
+				}
+				return nil, err
+			}
+			if allowed, err = combineMasks(ctx, allowed, eq); err != nil {
+				return nil, err
+			}
+		}
+		if allowed == nil {
+			// Nothing is allowed, so every value breaks the constraint
+			var rows []int64
+			for i := 0; i < col.Len(); i++ {
+				if col.IsValid(i) {
+					rows = append(rows, int64(i))
+				}
+			}
+			return rows, nil
+		}
+		defer allowed.Release()
+		return maskRows(allowed, col, false), nil
+
+	default:
+		return nil, fmt.Errorf("unknown constraint kind %d", constraint.Kind)
+	}
+}
+
+// compareScalar applies the comparison function fn to col and value, cast to
+// the column's type
+func compareScalar(ctx context.Context, fn string, col arrow.Array, value any) (compute.Datum, error) {
+	s := scalar.MakeScalar(value)
+	if !arrow.TypeEqual(s.DataType(), col.DataType()) {
+		cast, err := s.CastTo(col.DataType())
+		if err != nil {
+			return nil, fmt.Errorf("cannot compare %v with %s values: %w", value, col.DataType(), err)
+		}
+		s = cast
+	}
+	return compute.CallFunction(ctx, fn, nil, compute.NewDatumWithoutOwning(col), compute.NewDatum(s))
+}
+
+// combineMasks ors mask into acc, taking ownership of both; acc may be nil
+func combineMasks(ctx context.Context, acc, mask compute.Datum) (compute.Datum, error) {
+	if acc == nil {
+		return mask, nil
+	}
+	defer acc.Release()
+	defer mask.Release()
+	return compute.CallFunction(ctx, "or", nil, acc, mask)
+}
+
+// maskRows returns the indices of the non-null rows of col whose mask value
+// equals want
+func maskRows(mask compute.Datum, col arrow.Array, want bool) []int64 {
+	values := mask.(*compute.ArrayDatum).MakeArray().(*array.Boolean)
+	defer values.Release()
+
+	var rows []int64
+	for i := 0; i < values.Len(); i++ {
+		if col.IsValid(i) && values.IsValid(i) && values.Value(i) == want {
+			rows = append(rows, int64(i))
+		}
+	}
+	return rows
+}
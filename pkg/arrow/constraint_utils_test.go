@@ -0,0 +1,57 @@
+package arrow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestValidateConstraints tests that each kind of constraint reports the rows
+// breaking it, ignoring nulls where it should
+func TestValidateConstraints(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "age", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		{Name: "status", Type: arrow.BinaryTypes.String, Nullable: true},
+	}, nil)
+	builder := array.NewRecordBuilder(mem, schema)
+	defer builder.Release()
+	builder.Field(0).(*array.Int64Builder).AppendValues([]int64{30, -1, 0, 200, 45}, []bool{true, true, false, true, true})
+	builder.Field(1).(*array.StringBuilder).AppendValues([]string{"active", "deleted", "active", "", "inactive"}, []bool{true, true, true, false, true})
+	rec := builder.NewRecord()
+	defer rec.Release()
+
+	ctx := context.Background()
+	notNull := Constraint{Column: "age", Kind: ConstraintNotNull}
+	inRange := Constraint{Column: "age", Kind: ConstraintRange, Min: 0, Max: 150}
+	oneOf := Constraint{Column: "status", Kind: ConstraintOneOf, Values: []any{"active", "inactive"}}
+
+	err := ValidateConstraints(ctx, rec, []Constraint{notNull, inRange, oneOf}, mem)
+	var violated *ConstraintError
+	require.ErrorAs(t, err, &violated)
+	assert.Equal(t, []ConstraintViolation{
+		{Constraint: notNull, Rows: []int64{2}},
+		{Constraint: inRange, Rows: []int64{1, 3}},
+		{Constraint: oneOf, Rows: []int64{1}},
+	}, violated.Violations)
+	assert.ErrorContains(t, err, `column "age" must be between 0 and 150: rows [1 3]`)
+
+	// Open-ended ranges and satisfied constraints
+	atMost := Constraint{Column: "age", Kind: ConstraintRange, Max: 150}
+	err = ValidateConstraints(ctx, rec, []Constraint{atMost}, mem)
+	require.ErrorAs(t, err, &violated)
+	assert.Equal(t, []int64{3}, violated.Violations[0].Rows)
+	assert.NoError(t, ValidateConstraints(ctx, rec, []Constraint{{Column: "age", Kind: ConstraintRange, Min: -10}}, mem))
+
+	// Constraints that cannot be checked are errors, not violations
+	err = ValidateConstraints(ctx, rec, []Constraint{{Column: "missing", Kind: ConstraintNotNull}}, mem)
+	require.Error(t, err)
+	assert.NotErrorAs(t, err, &violated, "A missing column is not a violation")
+}
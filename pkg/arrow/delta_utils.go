@@ -0,0 +1,169 @@
+package arrow
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/compute"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+)
+
+// ApplyDelta merges a delta into a base record identified by the key columns.
+// Updated rows replace the base rows with the same key in place, deleted keys
+// (a record holding just the key columns) remove rows, and added rows are
+// appended. It is an error to add a key that remains in the base, to update a
+// key the base does not have, or to both update and delete a key; deleting a
+// missing key is ignored. Any of added, updated and deleted may be nil. The
+// result keeps the base schema, including its metadata.
+func ApplyDelta(ctx context.Context, base, added, updated, deleted arrow.Record, keys []string, mem memory.Allocator) (arrow.Record, error) {
+	if mem == nil {
+		mem = memory.NewGoAllocator()
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("at least one key column is required")
+	}
+
+	schema := base.Schema()
+	for name, rec := range map[string]arrow.Record{"added": added, "updated": updated} {
+		if rec != nil && !sameFields(schema, rec.Schema()) {
+			return nil, fmt.Errorf("%s rows do not match the base schema", name)
+		}
+	}
+
+	baseKeys, err := keyColumns(base, keys)
+	if err != nil {
+		return nil, fmt.Errorf("base: %w", err)
+	}
+	baseRows := make(map[string]int, base.NumRows())
+	for row := 0; row < int(base.NumRows()); row++ {
+		baseRows[groupKey(base, baseKeys, row)] = row
+	}
+
+	// Resolve deletions and updates to base rows
+	deletedRows := make(map[int]bool)
+	if deleted != nil {
+		deletedKeys, err := keyColumns(deleted, keys)
+		if err != nil {
+			return nil, fmt.Errorf("deleted keys: %w", err)
+		}
+		for row := 0; row < int(deleted.NumRows()); row++ {
+			if baseRow, ok := baseRows[groupKey(deleted, deletedKeys, row)]; ok {
+				deletedRows[baseRow] = true
+			}
+		}
+	}
+
+	updatedRows := make(map[int]int)
+	if updated != nil {
+		updatedKeys, err := keyColumns(updated, keys)
+		if err != nil {
+			return nil, fmt.Errorf("updated rows: %w", err)
+		}
+		for row := 0; row < int(updated.NumRows()); row++ {
+			baseRow, ok := baseRows[groupKey(updated, updatedKeys, row)]
+			if !ok {
+				return nil, fmt.Errorf("updated row %d has a key not present in the base", row)
+			}
+			if deletedRows[baseRow] {
+				return nil, fmt.Errorf("updated row %d has a key that is also deleted", row)
+			}
+			updatedRows[baseRow] = row
+		}
+	}
+
+	if added != nil {
+		addedKeys, err := keyColumns(added, keys)
+		if err != nil {
+			return nil, fmt.Errorf("added rows: %w", err)
+		}
+		for row := 0; row < int(added.NumRows()); row++ {
+			if baseRow, ok := baseRows[groupKey(added, addedKeys, row)]; ok && !deletedRows[baseRow] {
+				return nil, fmt.Errorf("added row %d has a key already present in the base", row)
+			}
+		}
+	}
+
+	// Gather from base, updated and added rows laid end to end
+	sources := []arrow.Record{base}
+	updatedOffset := base.NumRows()
+	addedOffset := updatedOffset
+	if updated != nil {
+		sources = append(sources, updated)
+		addedOffset += updated.NumRows()
+	}
+	if added != nil {
+		sources = append(sources, added)
+	}
+	combined, err := ConcatRecords(mem, schema, sources)
+	if err != nil {
+		return nil, err
+	}
+	defer combined.Release()
+
+	indicesBuilder := array.NewInt64Builder(mem)
+	defer indicesBuilder.Release()
+	for row := 0; row < int(base.NumRows()); row++ {
+		switch updatedRow, ok := updatedRows[row]; {
+		case deletedRows[row]:
+		case ok:
+			indicesBuilder.Append(updatedOffset + int64(updatedRow))
+		default:
+			indicesBuilder.Append(int64(row))
+		}
+	}
+	if added != nil {
+		for row := int64(0); row < added.NumRows(); row++ {
+			indicesBuilder.Append(addedOffset + row)
+		}
+	}
+	indices := indicesBuilder.NewInt64Array()
+	defer indices.Release()
+
+	columns := make([]arrow.Array, 0, schema.NumFields())
+	defer func() {
+		for _, col := range columns {
+			col.Release()
+		}
+	}()
+
+	ctx = compute.WithAllocator(ctx, mem)
+	for i := 0; i < int(combined.NumCols()); i++ {
+		col, err := compute.TakeArray(ctx, combined.Column(i), indices)
+		if err != nil {
+			return nil, fmt.Errorf("failed to gather column %s: %w", schema.Field(i).Name, err)
+		}
+		columns = append(columns, col)
+	}
+
+	return array.NewRecord(schema, columns, int64(indices.Len())), nil
+}
+
+// keyColumns resolves the indices of the key columns of a record
+func keyColumns(record arrow.Record, keys []string) ([]int, error) {
+	indices := make([]int, len(keys))
+	for i, name := range keys {
+		found := record.Schema().FieldIndices(name)
+		if len(found) == 0 {
+			return nil, fmt.Errorf("column with name '%s' not found in schema", name)
+		}
+		indices[i] = found[0]
+	}
+	return indices, nil
+}
+
+// sameFields reports whether two schemas have the same column names and types,
+// ignoring nullability and metadata
+func sameFields(a, b *arrow.Schema) bool {
+	if a.NumFields() != b.NumFields() {
+		return false
+	}
+	for i := 0; i < a.NumFields(); i++ {
+		fa, fb := a.Field(i), b.Field(i)
+		if fa.Name != fb.Name || !arrow.TypeEqual(fa.Type, fb.Type) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,285 @@
+package arrow
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+)
+
+// defaultTimestampLayouts are the string formats InferSchema recognizes as
+// timestamps when InferOptions.TimestampLayouts is empty
+var defaultTimestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// InferOptions configures InferSchema
+type InferOptions struct {
+	// Strict fails inference when samples disagree on a column's type,
+	// instead of widening it
+	Strict bool
+	// ParseStrings also infers int64, float64 and boolean columns from
+	// strings, as with CSV input where every value is a string. Empty strings
+	// then count as nulls.
+	ParseStrings bool
+	// TimestampLayouts are the time.Parse layouts tried on strings to detect
+	// timestamp columns (default: RFC 3339, "2006-01-02T15:04:05",
+	// "2006-01-02 15:04:05" and "2006-01-02")
+	TimestampLayouts []string
+}
+
+// inferKind is a type InferSchema can infer, with the nested kinds last
+type inferKind int
+
+const (
+	inferNull inferKind = iota
+	inferBool
+	inferInt
+	inferFloat
+	inferTimestamp
+	inferString
+	inferStruct
+	inferList
+)
+
+// inferredType is the type inferred so far for a column or nested value
+type inferredType struct {
+	kind   inferKind
+	fields map[string]*inferredType // Struct fields
+	elem   *inferredType            // List elements
+}
+
+// InferSchema infers a schema from sampled rows of loosely typed input, such
+// as decoded JSON or CSV. Each key of a row is a column. The rules are:
+//
+//   - bool is boolean; Go integers and json.Number values without a fraction
+//     or exponent are int64; other numbers are float64
+//   - time.Time, and strings matching a timestamp layout, are UTC
+//     microsecond timestamps; other strings are utf8
+//   - map[string]any is a struct, and []any a list, inferred recursively
+//   - nil and missing keys are nulls, and a column that only holds nulls has
+//     the null type
+//
+// When samples disagree, int64 and float64 widen to float64, and scalars of
+// any other mix widen to utf8, unless opts.Strict is set. Structs and lists
+// cannot be mixed with scalars or each other. Columns, and the fields of
+// structs, are sorted by name since Go maps do not keep their order, and are
+// all nullable since a sample cannot prove a column never holds nulls.
+//
+// JSON decoded into map[string]any represents every number as float64, so
+// decode it with json.Decoder.UseNumber to tell integer columns apart. An
+// inferred schema only describes the samples; later rows may not fit it.
+func InferSchema(samples []map[string]any, opts InferOptions) (*arrow.Schema, error) {
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("no samples to infer a schema from")
+	}
+	if len(opts.TimestampLayouts) == 0 {
+		opts.TimestampLayouts = defaultTimestampLayouts
+	}
+
+	root := &inferredType{kind: inferStruct, fields: make(map[string]*inferredType)}
+	for i, row := range samples {
+		for name, value := range row {
+			t, err := inferValue(value, opts)
+			if err != nil {
+				return nil, fmt.Errorf("sample %d, column %s: %w", i, name, err)
+			}
+			merged, err := mergeInferred(root.fields[name], t, opts.Strict)
+			if err != nil {
+				return nil, fmt.Errorf("column %s: %w", name, err)
+			}
+			root.fields[name] = merged
+		}
+	}
+	return arrow.NewSchema(inferredFields(root.fields), nil), nil
+}
+
+// inferValue returns the type of one sampled value
+func inferValue(value any, opts InferOptions) (*inferredType, error) {
+	switch v := value.(type) {
+	case nil:
+		return &inferredType{kind: inferNull}, nil
+	case bool:
+		return &inferredType{kind: inferBool}, nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return &inferredType{kind: inferInt}, nil
+	case float32, float64:
+		return &inferredType{kind: inferFloat}, nil
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return &inferredType{kind: inferInt}, nil
+		}
+		return &inferredType{kind: inferFloat}, nil
+	case time.Time:
+		return &inferredType{kind: inferTimestamp}, nil
+	case string:
+		return &inferredType{kind: stringKind(v, opts)}, nil
+	case map[string]any:
+		t := &inferredType{kind: inferStruct, fields: make(map[string]*inferredType, len(v))}
+		for name, fieldValue := range v {
+			field, err := inferValue(fieldValue, opts)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", name, err)
+			}
+			t.fields[name] = field
+		}
+		return t, nil
+	case []any:
+		t := &inferredType{kind: inferList}
+		for _, elemValue := range v {
+			elem, err := inferValue(elemValue, opts)
+			if err != nil {
+				return nil, fmt.Errorf("list element: %w", err)
+			}
+			if t.elem, err = mergeInferred(t.elem, elem, opts.Strict); err != nil {
+				return nil, fmt.Errorf("list element: %w", err)
+			}
+		}
+		return t, nil
+	default:
+		return nil, fmt.Errorf("unsupported value type %T", value)
+	}
+}
+
+// stringKind returns the kind of a string value
+func stringKind(s string, opts InferOptions) inferKind {
+	if opts.ParseStrings {
+		if s == "" {
+			return inferNull
+		}
+		if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return inferInt
+		}
+		if _, err := strconv.ParseFloat(s, 64); err == nil {
+			return inferFloat
+		}
+		if strings.EqualFold(s, "true") || strings.EqualFold(s, "false") {
+			return inferBool
+		}
+	}
+	for _, layout := range opts.TimestampLayouts {
+		if _, err := time.Parse(layout, s); err == nil {
+			return inferTimestamp
+		}
+	}
+	return inferString
+}
+
+// mergeInferred combines the types inferred for two values of the same
+// column. Either may be nil if no value was seen.
+func mergeInferred(a, b *inferredType, strict bool) (*inferredType, error) {
+	switch {
+	case a == nil || a.kind == inferNull:
+		if b == nil {
+			return a, nil
+		}
+		return b, nil
+	case b == nil || b.kind == inferNull:
+		return a, nil
+	}
+
+	if a.kind == b.kind {
+		switch a.kind {
+		case inferStruct:
+			merged := &inferredType{kind: inferStruct, fields: make(map[string]*inferredType, len(a.fields))}
+			for name, field := range a.fields {
+				merged.fields[name] = field
+			}
+			for name, field := range b.fields {
+				mergedField, err := mergeInferred(merged.fields[name], field, strict)
+				if err != nil {
+					return nil, fmt.Errorf("field %s: %w", name, err)
+				}
+				merged.fields[name] = mergedField
+			}
+			return merged, nil
+		case inferList:
+			elem, err := mergeInferred(a.elem, b.elem, strict)
+			if err != nil {
+				return nil, fmt.Errorf("list element: %w", err)
+			}
+			return &inferredType{kind: inferList, elem: elem}, nil
+		default:
+			return a, nil
+		}
+	}
+
+	nested := a.kind >= inferStruct || b.kind >= inferStruct
+	if strict || nested {
+		return nil, fmt.Errorf("conflicting types %s and %s", a.kind, b.kind)
+	}
+	if min(a.kind, b.kind) == inferInt && max(a.kind, b.kind) == inferFloat {
+		return &inferredType{kind: inferFloat}, nil
+	}
+	return &inferredType{kind: inferString}, nil
+}
+
+// String names the kind in error messages
+func (k inferKind) String() string {
+	switch k {
+	case inferNull:
+		return "null"
+	case inferBool:
+		return "bool"
+	case inferInt:
+		return "int64"
+	case inferFloat:
+		return "float64"
+	case inferTimestamp:
+		return "timestamp"
+	case inferString:
+		return "utf8"
+	case inferStruct:
+		return "struct"
+	default:
+		return "list"
+	}
+}
+
+// inferredFields converts inferred struct fields to nullable Arrow fields
+// sorted by name
+func inferredFields(fields map[string]*inferredType) []arrow.Field {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	result := make([]arrow.Field, len(names))
+	for i, name := range names {
+		result[i] = arrow.Field{Name: name, Type: fields[name].dataType(), Nullable: true}
+	}
+	return result
+}
+
+// dataType returns the Arrow type of an inferred type
+func (t *inferredType) dataType() arrow.DataType {
+	if t == nil {
+		return arrow.Null
+	}
+	switch t.kind {
+	case inferBool:
+		return arrow.FixedWidthTypes.Boolean
+	case inferInt:
+		return arrow.PrimitiveTypes.Int64
+	case inferFloat:
+		return arrow.PrimitiveTypes.Float64
+	case inferTimestamp:
+		return &arrow.TimestampType{Unit: arrow.Microsecond, TimeZone: "UTC"}
+	case inferString:
+		return arrow.BinaryTypes.String
+	case inferStruct:
+		return arrow.StructOf(inferredFields(t.fields)...)
+	case inferList:
+		return arrow.ListOf(t.elem.dataType())
+	default:
+		return arrow.Null
+	}
+}
@@ -0,0 +1,100 @@
+package arrow
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeSamples decodes JSON lines into rows, keeping integers apart from floats
+func decodeSamples(t *testing.T, lines ...string) []map[string]any {
+	decoder := json.NewDecoder(strings.NewReader(strings.Join(lines, "\n")))
+	decoder.UseNumber()
+	var rows []map[string]any
+	for decoder.More() {
+		var row map[string]any
+		require.NoError(t, decoder.Decode(&row))
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// TestInferSchema tests type inference, promotion and timestamp detection on sampled JSON rows
+func TestInferSchema(t *testing.T) {
+	samples := decodeSamples(t,
+		`{"id": 1, "score": 3, "ok": true, "seen": "2024-03-01T10:00:00Z", "day": "2024-03-01", "tags": ["a"], "geo": {"lat": 1.5}}`,
+		`{"id": 2, "score": 4.5, "ok": false, "seen": "2024-03-02T11:30:00.123+02:00", "day": "2024-03-02", "tags": [], "geo": {"lat": 2, "lon": 3.25}, "note": null}`,
+		`{"id": 3, "score": null, "mixed": 1, "label": "x"}`,
+		`{"id": 4, "mixed": "two", "label": "2024-01-01"}`,
+	)
+
+	schema, err := InferSchema(samples, InferOptions{})
+	require.NoError(t, err, "Failed to infer schema")
+
+	timestamp := &arrow.TimestampType{Unit: arrow.Microsecond, TimeZone: "UTC"}
+	expected := map[string]arrow.DataType{
+		"day":   timestamp,
+		"geo":   arrow.StructOf(arrow.Field{Name: "lat", Type: arrow.PrimitiveTypes.Float64, Nullable: true}, arrow.Field{Name: "lon", Type: arrow.PrimitiveTypes.Float64, Nullable: true}),
+		"id":    arrow.PrimitiveTypes.Int64,
+		"label": arrow.BinaryTypes.String,
+		"mixed": arrow.BinaryTypes.String,
+		"note":  arrow.Null,
+		"ok":    arrow.FixedWidthTypes.Boolean,
+		"score": arrow.PrimitiveTypes.Float64,
+		"seen":  timestamp,
+		"tags":  arrow.ListOf(arrow.BinaryTypes.String),
+	}
+	var names []string
+	for _, field := range schema.Fields() {
+		names = append(names, field.Name)
+		assert.True(t, arrow.TypeEqual(expected[field.Name], field.Type), "column %s: got %s", field.Name, field.Type)
+		assert.True(t, field.Nullable)
+	}
+	assert.Equal(t, []string{"day", "geo", "id", "label", "mixed", "note", "ok", "score", "seen", "tags"}, names, "Columns should be sorted by name")
+
+	// Strict inference rejects the conflicts widened above
+	_, err = InferSchema(samples, InferOptions{Strict: true})
+	assert.ErrorContains(t, err, "conflicting types")
+
+	// Nested values cannot be widened
+	_, err = InferSchema([]map[string]any{{"a": map[string]any{"b": 1}}, {"a": 2}}, InferOptions{})
+	assert.ErrorContains(t, err, "conflicting types struct and int64")
+
+	_, err = InferSchema(nil, InferOptions{})
+	assert.Error(t, err)
+}
+
+// TestInferSchemaStrings tests inference from CSV-like string values and custom timestamp layouts
+func TestInferSchemaStrings(t *testing.T) {
+	samples := []map[string]any{
+		{"n": "1", "x": "1", "flag": "TRUE", "when": "01/02/2024", "at": time.Now(), "opt": ""},
+		{"n": "2", "x": "2.5", "flag": "false", "when": "12/31/2023", "opt": "7"},
+	}
+
+	schema, err := InferSchema(samples, InferOptions{ParseStrings: true, TimestampLayouts: []string{"01/02/2006"}})
+	require.NoError(t, err, "Failed to infer schema")
+	types := make(map[string]arrow.DataType)
+	for _, field := range schema.Fields() {
+		types[field.Name] = field.Type
+	}
+	assert.Equal(t, arrow.PrimitiveTypes.Int64, types["n"])
+	assert.Equal(t, arrow.PrimitiveTypes.Float64, types["x"], "Integers and floats should widen to floats")
+	assert.Equal(t, arrow.FixedWidthTypes.Boolean, types["flag"])
+	assert.Equal(t, arrow.TIMESTAMP, types["when"].ID())
+	assert.Equal(t, arrow.TIMESTAMP, types["at"].ID())
+	assert.Equal(t, arrow.PrimitiveTypes.Int64, types["opt"], "Empty strings should count as nulls")
+
+	// Without ParseStrings only timestamps are detected in strings
+	schema, err = InferSchema(samples, InferOptions{})
+	require.NoError(t, err, "Failed to infer schema")
+	for _, field := range schema.Fields() {
+		if field.Name == "n" {
+			assert.Equal(t, arrow.BinaryTypes.String, field.Type)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package arrow
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/compute"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+)
+
+// JoinType selects which probe rows a hash join emits
+type JoinType string
+
+const (
+	// InnerJoin emits only probe rows with at least one matching build row
+	InnerJoin JoinType = "inner"
+	// LeftJoin emits every probe row, with nulls for build columns when there is no match
+	LeftJoin JoinType = "left"
+)
+
+// rightSuffix is appended to build-side column names that collide with probe-side names
+const rightSuffix = "_right"
+
+// HashJoin joins probe records against an in-memory hash table built from a
+// single build record. The probe side is supplied one record at a time, so it
+// can be streamed; only the build side is held in memory, which should be the
+// smaller input. The build record is the right input (NewHashJoin) or the left
+// one (NewHashJoinBuildLeft); the output columns are the same either way.
+// Null keys never match.
+type HashJoin struct {
+	build       arrow.Record
+	buildLeft   bool // The build record is the left input and probe records the right one
+	joinType    JoinType
+	probeKeys   []int
+	buildKeys   []int
+	rightOutput []int // Columns of the right input in the output, without the keys
+	table       map[string][]int64
+	matched     []bool // Build rows matched so far, for left joins built on the left
+	schema      *arrow.Schema
+	mem         memory.Allocator
+}
+
+// NewHashJoin builds the hash table for a join of left records with
+// probeSchema against the right record build on the given key columns. The
+// output has every left column followed by the right columns other than the
+// keys.
+func NewHashJoin(probeSchema *arrow.Schema, build arrow.Record, keys []string, joinType JoinType, mem memory.Allocator) (*HashJoin, error) {
+	return newHashJoin(probeSchema, build.Schema(), build, false, keys, joinType, mem)
+}
+
+// NewHashJoinBuildLeft builds the hash table from the left record build, for
+// joins whose left input is the smaller one, and probes it with right records
+// with probeSchema. The output is that of NewHashJoin. A left join emits the
+// left rows no probe row matched from Finish, once every probe record has
+// been joined.
+func NewHashJoinBuildLeft(build arrow.Record, probeSchema *arrow.Schema, keys []string, joinType JoinType, mem memory.Allocator) (*HashJoin, error) {
+	return newHashJoin(build.Schema(), probeSchema, build, true, keys, joinType, mem)
+}
+
+// newHashJoin builds the hash table of a join of the left and right schemas
+// from build, the left input if buildLeft is set and the right one otherwise
+func newHashJoin(leftSchema, rightSchema *arrow.Schema, build arrow.Record, buildLeft bool, keys []string, joinType JoinType, mem memory.Allocator) (*HashJoin, error) {
+	if mem == nil {
+		mem = memory.NewGoAllocator()
+	}
+	if joinType != InnerJoin && joinType != LeftJoin {
+		return nil, fmt.Errorf("unsupported join type: %q", joinType)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("at least one join key is required")
+	}
+
+	j := &HashJoin{
+		build:     build,
+		buildLeft: buildLeft,
+		joinType:  joinType,
+		table:     make(map[string][]int64),
+		mem:       mem,
+	}
+
+	// Resolve the key columns on both sides
+	isKey := make(map[int]bool, len(keys))
+	for _, name := range keys {
+		leftIdx := leftSchema.FieldIndices(name)
+		rightIdx := rightSchema.FieldIndices(name)
+		if len(leftIdx) == 0 || len(rightIdx) == 0 {
+			return nil, fmt.Errorf("join key %s not found in both schemas", name)
+		}
+		leftType := leftSchema.Field(leftIdx[0]).Type
+		rightType := rightSchema.Field(rightIdx[0]).Type
+		if !arrow.TypeEqual(leftType, rightType) {
+			return nil, fmt.Errorf("join key %s has type %s on the left side and %s on the right side", name, leftType, rightType)
+		}
+		if buildLeft {
+			j.buildKeys = append(j.buildKeys, leftIdx[0])
+			j.probeKeys = append(j.probeKeys, rightIdx[0])
+		} else {
+			j.probeKeys = append(j.probeKeys, leftIdx[0])
+			j.buildKeys = append(j.buildKeys, rightIdx[0])
+		}
+		isKey[rightIdx[0]] = true
+	}
+
+	// Build the output schema
+	fields := append([]arrow.Field{}, leftSchema.Fields()...)
+	for i, field := range rightSchema.Fields() {
+		if isKey[i] {
+			continue
+		}
+		if leftSchema.HasField(field.Name) {
+			field.Name += rightSuffix
+		}
+		if joinType == LeftJoin {
+			field.Nullable = true
+		}
+		fields = append(fields, field)
+		j.rightOutput = append(j.rightOutput, i)
+	}
+	j.schema = arrow.NewSchema(fields, nil)
+
+	// Index the build rows by key
+	for row := 0; row < int(build.NumRows()); row++ {
+		key, ok := joinKey(build, j.buildKeys, row)
+		if !ok {
+			continue
+		}
+		j.table[key] = append(j.table[key], int64(row))
+	}
+	if buildLeft && joinType == LeftJoin {
+		j.matched = make([]bool, build.NumRows())
+	}
+
+	build.Retain()
+	return j, nil
+}
+
+// Schema returns the schema of the joined records
+func (j *HashJoin) Schema() *arrow.Schema {
+	return j.schema
+}
+
+// Probe joins a probe record against the hash table. The caller must release
+// the returned record.
+func (j *HashJoin) Probe(ctx context.Context, probe arrow.Record) (arrow.Record, error) {
+	probeIndices := array.NewInt64Builder(j.mem)
+	defer probeIndices.Release()
+	buildIndices := array.NewInt64Builder(j.mem)
+	defer buildIndices.Release()
+
+	for row := 0; row < int(probe.NumRows()); row++ {
+		var matches []int64
+		if key, ok := joinKey(probe, j.probeKeys, row); ok {
+			matches = j.table[key]
+		}
+
+		for _, match := range matches {
+			probeIndices.Append(int64(row))
+			buildIndices.Append(match)
+			if j.matched != nil {
+				j.matched[match] = true
+			}
+		}
+		if len(matches) == 0 && j.joinType == LeftJoin && !j.buildLeft {
+			probeIndices.Append(int64(row))
+			buildIndices.AppendNull()
+		}
+	}
+
+	probeIdx := probeIndices.NewInt64Array()
+	defer probeIdx.Release()
+	buildIdx := buildIndices.NewInt64Array()
+	defer buildIdx.Release()
+
+	if j.buildLeft {
+		return j.gather(ctx, j.build, buildIdx, probe, probeIdx)
+	}
+	return j.gather(ctx, probe, probeIdx, j.build, buildIdx)
+}
+
+// Finish returns the rows the join emits once every probe record has been
+// joined: for a left join built on the left input, the left rows no probe row
+// matched, with nulls for the right columns. For other joins the record is
+// empty. The caller must release the returned record.
+func (j *HashJoin) Finish(ctx context.Context) (arrow.Record, error) {
+	leftIndices := array.NewInt64Builder(j.mem)
+	defer leftIndices.Release()
+	for row, matched := range j.matched {
+		if !matched {
+			leftIndices.Append(int64(row))
+		}
+	}
+	leftIdx := leftIndices.NewInt64Array()
+	defer leftIdx.Release()
+
+	if !j.buildLeft {
+		// No rows are left; gather none from both sides
+		return j.gather(ctx, nil, leftIdx, j.build, leftIdx)
+	}
+	return j.gather(ctx, j.build, leftIdx, nil, leftIdx)
+}
+
+// gather assembles joined rows, taking the left columns of left at leftIdx
+// and the right output columns of right at rightIdx. A nil side yields null
+// columns.
+func (j *HashJoin) gather(ctx context.Context, left arrow.Record, leftIdx *array.Int64, right arrow.Record, rightIdx *array.Int64) (arrow.Record, error) {
+	columns := make([]arrow.Array, 0, j.schema.NumFields())
+	defer func() {
+		for _, col := range columns {
+			col.Release()
+		}
+	}()
+
+	ctx = compute.WithAllocator(ctx, j.mem)
+	numLeft := j.schema.NumFields() - len(j.rightOutput)
+	take := func(rec arrow.Record, col, field int, idx *array.Int64) error {
+		if rec == nil {
+			columns = append(columns, array.MakeArrayOfNull(j.mem, j.schema.Field(field).Type, idx.Len()))
+			return nil
+		}
+		out, err := compute.TakeArray(ctx, rec.Column(col), idx)
+		if err != nil {
+			return fmt.Errorf("failed to gather column %s: %w", rec.ColumnName(col), err)
+		}
+		columns = append(columns, out)
+		return nil
+	}
+	for i := 0; i < numLeft; i++ {
+		if err := take(left, i, i, leftIdx); err != nil {
+			return nil, err
+		}
+	}
+	for k, i := range j.rightOutput {
+		if err := take(right, i, numLeft+k, rightIdx); err != nil {
+			return nil, err
+		}
+	}
+
+	return array.NewRecord(j.schema, columns, int64(leftIdx.Len())), nil
+}
+
+// Release releases the build record held by the join
+func (j *HashJoin) Release() {
+	j.build.Release()
+}
+
+// joinKey encodes the key columns of a row as a map key. It reports false if
+// any key is null.
+func joinKey(record arrow.Record, keys []int, row int) (string, bool) {
+	var sb strings.Builder
+	for _, k := range keys {
+		col := record.Column(k)
+		if col.IsNull(row) {
+			return "", false
+		}
+		writeKeyValue(&sb, col.ValueStr(row))
+	}
+	return sb.String(), true
+}
+
+// writeKeyValue appends a length-prefixed value to a composite key so that
+// different combinations of values cannot collide
+func writeKeyValue(sb *strings.Builder, value string) {
+	sb.WriteString(strconv.Itoa(len(value)))
+	sb.WriteByte(':')
+	sb.WriteString(value)
+}
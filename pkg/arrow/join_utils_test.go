@@ -0,0 +1,182 @@
+package arrow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// joinRecord builds a record with a nullable int32 id column and a string
+// column of the given name; a nil id is null
+func joinRecord(t *testing.T, mem memory.Allocator, column string, ids []*int32, values []string) arrow.Record {
+	t.Helper()
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int32, Nullable: true},
+		{Name: column, Type: arrow.BinaryTypes.String},
+	}, nil)
+
+	builder := array.NewRecordBuilder(mem, schema)
+	defer builder.Release()
+	for i, id := range ids {
+		if id == nil {
+			builder.Field(0).AppendNull()
+		} else {
+			builder.Field(0).(*array.Int32Builder).Append(*id)
+		}
+		builder.Field(1).(*array.StringBuilder).Append(values[i])
+	}
+	return builder.NewRecord()
+}
+
+// joinID returns a pointer to a join key, for joinRecord
+func joinID(v int32) *int32 { return &v }
+
+// joinRows renders the rows of a joined record as strings, with "(null)"
+// for null values
+func joinRows(rec arrow.Record) []string {
+	rows := make([]string, rec.NumRows())
+	for row := range rows {
+		for col := 0; col < int(rec.NumCols()); col++ {
+			if col > 0 {
+				rows[row] += ","
+			}
+			rows[row] += rec.Column(col).ValueStr(row)
+		}
+	}
+	return rows
+}
+
+// runJoin joins left and right, building on the left record if buildLeft is
+// set, and returns the joined rows
+func runJoin(t *testing.T, mem memory.Allocator, left, right arrow.Record, joinType JoinType, buildLeft bool) ([]string, *arrow.Schema) {
+	t.Helper()
+	ctx := context.Background()
+
+	var join *HashJoin
+	var err error
+	probe := left
+	if buildLeft {
+		join, err = NewHashJoinBuildLeft(left, right.Schema(), []string{"id"}, joinType, mem)
+		probe = right
+	} else {
+		join, err = NewHashJoin(left.Schema(), right, []string{"id"}, joinType, mem)
+	}
+	require.NoError(t, err, "Failed to build join")
+	defer join.Release()
+
+	out, err := join.Probe(ctx, probe)
+	require.NoError(t, err, "Failed to probe join")
+	defer out.Release()
+	rest, err := join.Finish(ctx)
+	require.NoError(t, err, "Failed to finish join")
+	defer rest.Release()
+
+	result, err := ConcatRecords(mem, join.Schema(), []arrow.Record{out, rest})
+	require.NoError(t, err, "Failed to combine join output")
+	defer result.Release()
+	return joinRows(result), join.Schema()
+}
+
+// TestHashJoin tests inner and left joins, with the hash table built on
+// either side, including null keys and duplicate matches
+func TestHashJoin(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	left := joinRecord(t, mem, "name", []*int32{joinID(1), joinID(2), nil, joinID(4)}, []string{"a", "b", "c", "d"})
+	defer left.Release()
+	right := joinRecord(t, mem, "name", []*int32{joinID(2), joinID(1), joinID(2), nil}, []string{"x", "y", "z", "w"})
+	defer right.Release()
+
+	tests := []struct {
+		joinType JoinType
+		want     []string
+	}{
+		{InnerJoin, []string{"1,a,y", "2,b,x", "2,b,z"}},
+		{LeftJoin, []string{"1,a,y", "2,b,x", "2,b,z", "(null),c,(null)", "4,d,(null)"}},
+	}
+	for _, tt := range tests {
+		for _, buildLeft := range []bool{false, true} {
+			rows, schema := runJoin(t, mem, left, right, tt.joinType, buildLeft)
+			assert.ElementsMatch(t, tt.want, rows, "join %s, build left %v", tt.joinType, buildLeft)
+
+			names := make([]string, schema.NumFields())
+			for i, field := range schema.Fields() {
+				names[i] = field.Name
+			}
+			assert.Equal(t, []string{"id", "name", "name_right"}, names)
+			assert.Equal(t, tt.joinType == LeftJoin, schema.Field(2).Nullable)
+		}
+	}
+}
+
+// TestHashJoinEmpty tests that joins with an empty input produce the
+// expected, possibly empty, output
+func TestHashJoinEmpty(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	left := joinRecord(t, mem, "name", []*int32{joinID(1), joinID(2)}, []string{"a", "b"})
+	defer left.Release()
+	empty := joinRecord(t, mem, "value", nil, nil)
+	defer empty.Release()
+
+	for _, buildLeft := range []bool{false, true} {
+		rows, _ := runJoin(t, mem, left, empty, InnerJoin, buildLeft)
+		assert.Empty(t, rows)
+		rows, _ = runJoin(t, mem, left, empty, LeftJoin, buildLeft)
+		assert.ElementsMatch(t, []string{"1,a,(null)", "2,b,(null)"}, rows)
+		rows, _ = runJoin(t, mem, empty, left, LeftJoin, buildLeft)
+		assert.Empty(t, rows)
+	}
+}
+
+// TestHashJoinInvalid tests that joins with unknown keys, mismatched key
+// types or unsupported join types are rejected
+func TestHashJoinInvalid(t *testing.T) {
+	mem := memory.NewGoAllocator()
+	left := joinRecord(t, mem, "name", []*int32{joinID(1)}, []string{"a"})
+	defer left.Release()
+
+	_, err := NewHashJoin(left.Schema(), left, []string{"missing"}, InnerJoin, mem)
+	assert.Error(t, err)
+	_, err = NewHashJoin(left.Schema(), left, nil, InnerJoin, mem)
+	assert.Error(t, err)
+	_, err = NewHashJoin(left.Schema(), left, []string{"id"}, JoinType("outer"), mem)
+	assert.Error(t, err)
+
+	other := arrow.NewSchema([]arrow.Field{{Name: "id", Type: arrow.BinaryTypes.String}}, nil)
+	_, err = NewHashJoin(other, left, []string{"id"}, InnerJoin, mem)
+	assert.Error(t, err)
+}
+
+// TestConcatRecords tests that records are combined in order and that no
+// records combine into an empty record with the schema
+func TestConcatRecords(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	first := joinRecord(t, mem, "name", []*int32{joinID(1)}, []string{"a"})
+	defer first.Release()
+	second := joinRecord(t, mem, "name", []*int32{nil, joinID(3)}, []string{"b", "c"})
+	defer second.Release()
+
+	combined, err := ConcatRecords(mem, first.Schema(), []arrow.Record{first, second})
+	require.NoError(t, err, "Failed to combine records")
+	assert.Equal(t, []string{"1,a", "(null),b", "3,c"}, joinRows(combined))
+	combined.Release()
+
+	empty, err := ConcatRecords(mem, first.Schema(), nil)
+	require.NoError(t, err, "Failed to combine no records")
+	assert.Equal(t, int64(0), empty.NumRows())
+	assert.True(t, first.Schema().Equal(empty.Schema()))
+	empty.Release()
+
+	_, err = CombineBatches(nil)
+	assert.Error(t, err)
+}
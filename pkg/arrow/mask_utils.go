@@ -0,0 +1,238 @@
+package arrow
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/compute"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/arrow/scalar"
+)
+
+// MaskKind selects how MaskRecord hides a column's values
+type MaskKind int
+
+const (
+	// MaskNullify replaces every value with null, keeping the column's type
+	MaskNullify MaskKind = iota
+	// MaskHash replaces every non-null value with a salted SHA-256 hash, so
+	// equal values still compare equal. String and binary columns hold the
+	// hex digest and integer columns its leading bytes, keeping their type;
+	// columns of other types become utf8 hex digests of the values' text.
+	MaskHash
+	// MaskRedact replaces every value, null or not, with a constant
+	MaskRedact
+)
+
+// redactedText is the MaskRedact constant of string and binary columns when
+// none is given
+const redactedText = "REDACTED"
+
+// MaskStrategy describes how to mask a column. The zero value nullifies it.
+type MaskStrategy struct {
+	Kind MaskKind
+	// Constant is the MaskRedact value, cast to the column's type (default:
+	// "REDACTED" for string and binary columns, zero for others)
+	Constant any
+	// Salt is prepended to every value hashed by MaskHash, so the hashes of
+	// guessable values cannot be looked up
+	Salt []byte
+}
+
+// MaskSchema returns schema as changed by masking the columns named in
+// masks: nullified columns become nullable, and hashed columns that cannot
+// keep their type become utf8. Every named column must be in the schema.
+func MaskSchema(schema *arrow.Schema, masks map[string]MaskStrategy) (*arrow.Schema, error) {
+	fields := schema.Fields()
+	for name, mask := range masks {
+		indices := schema.FieldIndices(name)
+		if len(indices) == 0 {
+			return nil, fmt.Errorf("column %q not found", name)
+		}
+		for _, i := range indices {
+			switch mask.Kind {
+			case MaskNullify:
+				fields[i].Nullable = true
+			case MaskHash:
+				if !keepsHashType(fields[i].Type) {
+					fields[i].Type = arrow.BinaryTypes.String
+				}
+			case MaskRedact:
+			default:
+				return nil, fmt.Errorf("unknown mask kind %d for column %q", mask.Kind, name)
+			}
+		}
+	}
+
+	md := schema.Metadata()
+	return arrow.NewSchema(fields, &md), nil
+}
+
+// MaskRecord masks the columns named in masks, leaving the other columns
+// unchanged, for callers that must not see their values
+func MaskRecord(ctx context.Context, record arrow.Record, masks map[string]MaskStrategy, mem memory.Allocator) (arrow.Record, error) {
+	schema, err := MaskSchema(record.Schema(), masks)
+	if err != nil {
+		return nil, err
+	}
+	ctx = compute.WithAllocator(ctx, mem)
+
+	cols := make([]arrow.Array, record.NumCols())
+	defer func() {
+		for _, col := range cols {
+			if col != nil {
+				col.Release()
+			}
+		}
+	}()
+
+	for i, col := range record.Columns() {
+		name := schema.Field(i).Name
+		mask, ok := masks[name]
+		if !ok {
+			col.Retain()
+			cols[i] = col
+			continue
+		}
+
+		var masked arrow.Array
+		switch mask.Kind {
+		case MaskNullify:
+			masked = array.MakeArrayOfNull(mem, col.DataType(), col.Len())
+		case MaskHash:
+			masked, err = hashColumn(ctx, col, mask.Salt, mem)
+		case MaskRedact:
+			masked, err = redactColumn(col, mask.Constant, mem)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to mask column %q: %w", name, err)
+		}
+		cols[i] = masked
+	}
+
+	return array.NewRecord(schema, cols, record.NumRows()), nil
+}
+
+// keepsHashType reports whether MaskHash keeps a column of type dt
+func keepsHashType(dt arrow.DataType) bool {
+	switch dt.ID() {
+	case arrow.STRING, arrow.LARGE_STRING, arrow.BINARY, arrow.LARGE_BINARY:
+		return true
+	default:
+		return arrow.IsInteger(dt.ID())
+	}
+}
+
+// maskHash returns the salted SHA-256 hash of a value
+func maskHash(salt, value []byte) [sha256.Size]byte {
+	hash := sha256.New()
+	hash.Write(salt)
+	hash.Write(value)
+	var sum [sha256.Size]byte
+	hash.Sum(sum[:0])
+	return sum
+}
+
+// hashColumn implements MaskHash for one column
+func hashColumn(ctx context.Context, col arrow.Array, salt []byte, mem memory.Allocator) (arrow.Array, error) {
+	dt := col.DataType()
+
+	// Integers are hashed as int64 and truncated back to their own width
+	if arrow.IsInteger(dt.ID()) {
+		wide, err := compute.CastArray(ctx, col, compute.UnsafeCastOptions(arrow.PrimitiveTypes.Int64))
+		if err != nil {
+			return nil, err
+		}
+		defer wide.Release()
+		values := wide.(*array.Int64)
+
+		builder := array.NewInt64Builder(mem)
+		defer builder.Release()
+		var buf [8]byte
+		for i := 0; i < values.Len(); i++ {
+			if values.IsNull(i) {
+				builder.AppendNull()
+				continue
+			}
+			v := uint64(values.Value(i))
+			for b := range buf {
+				buf[b] = byte(v >> (8 * b))
+			}
+			sum := maskHash(salt, buf[:])
+			var h uint64
+			for b := 0; b < 8; b++ {
+				h |= uint64(sum[b]) << (8 * b)
+			}
+			builder.Append(int64(h))
+		}
+		hashed := builder.NewArray()
+		defer hashed.Release()
+		return compute.CastArray(ctx, hashed, compute.UnsafeCastOptions(dt))
+	}
+
+	builder := array.NewBuilder(mem, dt)
+	if !keepsHashType(dt) {
+		builder.Release()
+		builder = array.NewStringBuilder(mem)
+	}
+	defer builder.Release()
+
+	for i := 0; i < col.Len(); i++ {
+		if col.IsNull(i) {
+			builder.AppendNull()
+			continue
+		}
+		var value []byte
+		switch values := col.(type) {
+		case *array.String:
+			value = []byte(values.Value(i))
+		case *array.LargeString:
+			value = []byte(values.Value(i))
+		case *array.Binary:
+			value = values.Value(i)
+		case *array.LargeBinary:
+			value = values.Value(i)
+		default:
+			value = []byte(col.ValueStr(i))
+		}
+		sum := maskHash(salt, value)
+		digest := hex.EncodeToString(sum[:])
+
+		switch b := builder.(type) {
+		case *array.StringBuilder:
+			b.Append(digest)
+		case *array.LargeStringBuilder:
+			b.Append(digest)
+		case *array.BinaryBuilder:
+			b.Append([]byte(digest))
+		}
+	}
+	return builder.NewArray(), nil
+}
+
+// redactColumn implements MaskRedact for one column
+func redactColumn(col arrow.Array, constant any, mem memory.Allocator) (arrow.Array, error) {
+	dt := col.DataType()
+	if constant == nil {
+		switch dt.ID() {
+		case arrow.STRING, arrow.LARGE_STRING, arrow.BINARY, arrow.LARGE_BINARY:
+			constant = redactedText
+		default:
+			constant = int64(0)
+		}
+	}
+
+	value := scalar.MakeScalar(constant)
+	if !arrow.TypeEqual(value.DataType(), dt) {
+		cast, err := value.CastTo(dt)
+		if err != nil {
+			return nil, fmt.Errorf("cannot redact %s values to %v: %w", dt, constant, err)
+		}
+		value = cast
+	}
+	return scalar.MakeArrayFromScalar(value, col.Len(), mem)
+}
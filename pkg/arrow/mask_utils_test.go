@@ -0,0 +1,97 @@
+package arrow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createMaskRecord creates a record with int64, string and float64 columns
+// whose first and last rows repeat and whose name column holds a null
+func createMaskRecord(t *testing.T, mem memory.Allocator) arrow.Record {
+	t.Helper()
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "value", Type: arrow.PrimitiveTypes.Float64},
+	}, nil)
+
+	builder := array.NewRecordBuilder(mem, schema)
+	defer builder.Release()
+	builder.Field(0).(*array.Int64Builder).AppendValues([]int64{7, 8, 7}, nil)
+	builder.Field(1).(*array.StringBuilder).AppendValues([]string{"ann", "", "ann"}, []bool{true, false, true})
+	builder.Field(2).(*array.Float64Builder).AppendValues([]float64{1.5, 2.5, 1.5}, nil)
+	return builder.NewRecord()
+}
+
+// TestMaskRecord tests each masking strategy and the schema changes they make
+func TestMaskRecord(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+	ctx := context.Background()
+
+	record := createMaskRecord(t, mem)
+	defer record.Release()
+
+	mask := func(t *testing.T, masks map[string]MaskStrategy) arrow.Record {
+		masked, err := MaskRecord(ctx, record, masks, mem)
+		require.NoError(t, err, "Failed to mask record")
+		t.Cleanup(masked.Release)
+		require.Equal(t, record.NumRows(), masked.NumRows())
+		return masked
+	}
+
+	t.Run("nullify", func(t *testing.T) {
+		masked := mask(t, map[string]MaskStrategy{"id": {}})
+		assert.True(t, arrow.TypeEqual(arrow.PrimitiveTypes.Int64, masked.Schema().Field(0).Type))
+		assert.True(t, masked.Schema().Field(0).Nullable)
+		assert.Equal(t, 3, masked.Column(0).NullN())
+		assert.True(t, array.Equal(record.Column(1), masked.Column(1)), "Unmasked columns should be unchanged")
+	})
+
+	t.Run("hash", func(t *testing.T) {
+		masked := mask(t, map[string]MaskStrategy{
+			"id":    {Kind: MaskHash},
+			"name":  {Kind: MaskHash},
+			"value": {Kind: MaskHash},
+		})
+		ids := masked.Column(0).(*array.Int64)
+		names := masked.Column(1).(*array.String)
+		values := masked.Column(2).(*array.String)
+		assert.True(t, arrow.TypeEqual(arrow.BinaryTypes.String, masked.Schema().Field(2).Type), "Floats should become hex digests")
+
+		assert.Equal(t, ids.Value(0), ids.Value(2), "Equal values should hash equally")
+		assert.NotEqual(t, ids.Value(0), ids.Value(1))
+		assert.NotEqual(t, int64(7), ids.Value(0))
+		assert.Len(t, names.Value(0), 64)
+		assert.Equal(t, names.Value(0), names.Value(2))
+		assert.True(t, names.IsNull(1), "Nulls should stay null")
+		assert.Equal(t, values.Value(0), values.Value(2))
+
+		salted := mask(t, map[string]MaskStrategy{"name": {Kind: MaskHash, Salt: []byte("pepper")}})
+		assert.NotEqual(t, names.Value(0), salted.Column(1).(*array.String).Value(0), "The salt should change the hash")
+	})
+
+	t.Run("redact", func(t *testing.T) {
+		masked := mask(t, map[string]MaskStrategy{
+			"id":    {Kind: MaskRedact},
+			"name":  {Kind: MaskRedact},
+			"value": {Kind: MaskRedact, Constant: -1.0},
+		})
+		for row := 0; row < int(masked.NumRows()); row++ {
+			assert.Equal(t, int64(0), masked.Column(0).(*array.Int64).Value(row))
+			assert.Equal(t, "REDACTED", masked.Column(1).(*array.String).Value(row), "Nulls should be redacted too")
+			assert.Equal(t, -1.0, masked.Column(2).(*array.Float64).Value(row))
+		}
+	})
+
+	_, err := MaskRecord(ctx, record, map[string]MaskStrategy{"ssn": {}}, mem)
+	assert.ErrorContains(t, err, `column "ssn" not found`)
+	_, err = MaskSchema(record.Schema(), map[string]MaskStrategy{"id": {Kind: MaskKind(42)}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,156 @@
+package arrow
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/compute"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+)
+
+// UnifySchemas returns the union of the given schemas: every column that
+// appears in any of them, in the order first seen. A column missing from some
+// schemas, or nullable in any, is nullable in the result. Columns with the
+// same name must have the same type. Schema metadata is taken from the first
+// schema.
+func UnifySchemas(schemas ...*arrow.Schema) (*arrow.Schema, error) {
+	if len(schemas) == 0 {
+		return nil, fmt.Errorf("no schemas to unify")
+	}
+
+	var fields []arrow.Field
+	positions := make(map[string]int)
+	counts := make(map[string]int)
+	for _, schema := range schemas {
+		for _, field := range schema.Fields() {
+			pos, ok := positions[field.Name]
+			if !ok {
+				positions[field.Name] = len(fields)
+				counts[field.Name] = 1
+				fields = append(fields, arrow.Field{Name: field.Name, Type: field.Type, Nullable: field.Nullable})
+				continue
+			}
+			if !arrow.TypeEqual(fields[pos].Type, field.Type) {
+				return nil, fmt.Errorf("incompatible types for column %q: %s and %s", field.Name, fields[pos].Type, field.Type)
+			}
+			fields[pos].Nullable = fields[pos].Nullable || field.Nullable
+			counts[field.Name]++
+		}
+	}
+
+	// Columns some schemas lack are filled with nulls
+	for i := range fields {
+		if counts[fields[i].Name] < len(schemas) {
+			fields[i].Nullable = true
+		}
+	}
+
+	md := schemas[0].Metadata()
+	return arrow.NewSchema(fields, &md), nil
+}
+
+// AlignRecord returns record rearranged to schema: columns are matched by
+// name and reordered, and columns the record lacks are added as all-null
+// columns, which schema must declare nullable. Columns of record that schema
+// does not contain, or whose type differs, are an error.
+func AlignRecord(record arrow.Record, schema *arrow.Schema, mem memory.Allocator) (arrow.Record, error) {
+	for _, field := range record.Schema().Fields() {
+		if len(schema.FieldIndices(field.Name)) == 0 {
+			return nil, fmt.Errorf("column %q is not in the target schema", field.Name)
+		}
+	}
+
+	cols := make([]arrow.Array, len(schema.Fields()))
+	defer func() {
+		for _, col := range cols {
+			if col != nil {
+				col.Release()
+			}
+		}
+	}()
+
+	for i, field := range schema.Fields() {
+		indices := record.Schema().FieldIndices(field.Name)
+		switch len(indices) {
+		case 0:
+			if !field.Nullable {
+				return nil, fmt.Errorf("column %q is missing and not nullable", field.Name)
+			}
+			cols[i] = array.MakeArrayOfNull(mem, field.Type, int(record.NumRows()))
+		case 1:
+			col := record.Column(indices[0])
+			if !arrow.TypeEqual(col.DataType(), field.Type) {
+				return nil, fmt.Errorf("incompatible types for column %q: %s and %s", field.Name, field.Type, col.DataType())
+			}
+			col.Retain()
+			cols[i] = col
+		default:
+			return nil, fmt.Errorf("duplicate column %q", field.Name)
+		}
+	}
+
+	return array.NewRecord(schema, cols, record.NumRows()), nil
+}
+
+// CoerceSchema returns schema with the columns named in types changed to the
+// given types. Every named column must be in the schema.
+func CoerceSchema(schema *arrow.Schema, types map[string]arrow.DataType) (*arrow.Schema, error) {
+	fields := schema.Fields()
+	for name, typ := range types {
+		indices := schema.FieldIndices(name)
+		if len(indices) == 0 {
+			return nil, fmt.Errorf("column %q not found", name)
+		}
+		for _, i := range indices {
+			fields[i].Type = typ
+		}
+	}
+
+	md := schema.Metadata()
+	return arrow.NewSchema(fields, &md), nil
+}
+
+// CoerceRecord casts the columns named in types to the given types with
+// Arrow compute casts, leaving the other columns unchanged. Casts that could
+// lose information, such as overflowing integers, truncating floats or
+// dropping timestamp precision, fail unless allowLossy is set, in which case
+// values are converted as the cast kernels define.
+func CoerceRecord(ctx context.Context, record arrow.Record, types map[string]arrow.DataType, allowLossy bool, mem memory.Allocator) (arrow.Record, error) {
+	schema, err := CoerceSchema(record.Schema(), types)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := compute.SafeCastOptions
+	if allowLossy {
+		opts = compute.UnsafeCastOptions
+	}
+	ctx = compute.WithAllocator(ctx, mem)
+
+	cols := make([]arrow.Array, record.NumCols())
+	defer func() {
+		for _, col := range cols {
+			if col != nil {
+				col.Release()
+			}
+		}
+	}()
+
+	for i, col := range record.Columns() {
+		target := schema.Field(i).Type
+		if arrow.TypeEqual(col.DataType(), target) {
+			col.Retain()
+			cols[i] = col
+			continue
+		}
+		cast, err := compute.CastArray(ctx, col, opts(target))
+		if err != nil {
+			return nil, fmt.Errorf("failed to cast column %q from %s to %s: %w", schema.Field(i).Name, col.DataType(), target, err)
+		}
+		cols[i] = cast
+	}
+
+	return array.NewRecord(schema, cols, record.NumRows()), nil
+}
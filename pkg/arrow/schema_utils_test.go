@@ -0,0 +1,58 @@
+package arrow
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCoerceRecord tests that listed columns are cast, and that lossy casts
+// fail unless allowed
+func TestCoerceRecord(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+	ctx := context.Background()
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "narrow", Type: arrow.PrimitiveTypes.Int32},
+		{Name: "wide", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "at", Type: &arrow.TimestampType{Unit: arrow.Millisecond, TimeZone: "UTC"}},
+	}, nil)
+	builder := array.NewRecordBuilder(mem, schema)
+	defer builder.Release()
+	builder.Field(0).(*array.Int32Builder).AppendValues([]int32{1, 2}, nil)
+	builder.Field(1).(*array.Int64Builder).AppendValues([]int64{3, math.MaxInt32 + 1}, nil)
+	builder.Field(2).(*array.TimestampBuilder).AppendValues([]arrow.Timestamp{1000, 1500}, nil)
+	record := builder.NewRecord()
+	defer record.Release()
+
+	widened, err := CoerceRecord(ctx, record, map[string]arrow.DataType{"narrow": arrow.PrimitiveTypes.Int64}, false, mem)
+	require.NoError(t, err, "Widening casts should succeed")
+	assert.True(t, arrow.TypeEqual(arrow.PrimitiveTypes.Int64, widened.Schema().Field(0).Type))
+	assert.Equal(t, []int64{1, 2}, widened.Column(0).(*array.Int64).Int64Values())
+	assert.True(t, array.Equal(record.Column(1), widened.Column(1)), "Unlisted columns should be unchanged")
+	widened.Release()
+
+	// Overflowing integers and truncated timestamps are lossy
+	lossy := []map[string]arrow.DataType{
+		{"wide": arrow.PrimitiveTypes.Int32},
+		{"at": &arrow.TimestampType{Unit: arrow.Second, TimeZone: "UTC"}},
+	}
+	for _, types := range lossy {
+		_, err := CoerceRecord(ctx, record, types, false, mem)
+		assert.Error(t, err, "Cast to %v should be rejected", types)
+
+		cast, err := CoerceRecord(ctx, record, types, true, mem)
+		require.NoError(t, err, "Cast to %v should be allowed when lossy", types)
+		cast.Release()
+	}
+
+	_, err = CoerceRecord(ctx, record, map[string]arrow.DataType{"missing": arrow.PrimitiveTypes.Int64}, false, mem)
+	assert.ErrorContains(t, err, `column "missing" not found`)
+}
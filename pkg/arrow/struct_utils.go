@@ -0,0 +1,367 @@
+package arrow
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+)
+
+// timeType is the reflect type of time.Time, mapped to a microsecond timestamp
+var timeType = reflect.TypeOf(time.Time{})
+
+// structColumn maps one exported struct field to a record column
+type structColumn struct {
+	index    []int
+	nullable bool
+	append   func(b array.Builder, v reflect.Value)
+}
+
+// structColumns derives the schema and column mapping of struct type t.
+// Columns are named after the fields, or after their `arrow:"name"` tag;
+// `arrow:"-"` skips a field. Pointer fields are nullable.
+func structColumns(t reflect.Type) (*arrow.Schema, []structColumn, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, nil, fmt.Errorf("type %s is not a struct", t)
+	}
+
+	var fields []arrow.Field
+	var columns []structColumn
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("arrow"); ok {
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+		if len(f.Index) > 1 && embedsPointer(t, f.Index) {
+			return nil, nil, fmt.Errorf("field %s of %s is promoted through an embedded pointer", f.Name, t)
+		}
+
+		typ := f.Type
+		nullable := typ.Kind() == reflect.Pointer
+		if nullable {
+			typ = typ.Elem()
+		}
+		dataType, appendValue, err := structValueType(typ)
+		if err != nil {
+			return nil, nil, fmt.Errorf("field %s of %s: %w", f.Name, t, err)
+		}
+
+		fields = append(fields, arrow.Field{Name: name, Type: dataType, Nullable: nullable})
+		columns = append(columns, structColumn{index: f.Index, nullable: nullable, append: appendValue})
+	}
+	if len(fields) == 0 {
+		return nil, nil, fmt.Errorf("type %s has no exported fields", t)
+	}
+
+	return arrow.NewSchema(fields, nil), columns, nil
+}
+
+// embedsPointer reports whether the path to a promoted field goes through an
+// embedded pointer, which may be nil
+func embedsPointer(t reflect.Type, index []int) bool {
+	for _, i := range index[:len(index)-1] {
+		f := t.Field(i)
+		if f.Type.Kind() == reflect.Pointer {
+			return true
+		}
+		t = f.Type
+	}
+	return false
+}
+
+// structValueType returns the Arrow type of a Go field type and a function
+// appending a value of that type to the matching builder
+func structValueType(t reflect.Type) (arrow.DataType, func(array.Builder, reflect.Value), error) {
+	if t == timeType {
+		return &arrow.TimestampType{Unit: arrow.Microsecond, TimeZone: "UTC"}, func(b array.Builder, v reflect.Value) {
+			b.(*array.TimestampBuilder).Append(arrow.Timestamp(v.Interface().(time.Time).UnixMicro()))
+		}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return arrow.FixedWidthTypes.Boolean, func(b array.Builder, v reflect.Value) {
+			b.(*array.BooleanBuilder).Append(v.Bool())
+		}, nil
+	case reflect.Int8:
+		return arrow.PrimitiveTypes.Int8, func(b array.Builder, v reflect.Value) {
+			b.(*array.Int8Builder).Append(int8(v.Int()))
+		}, nil
+	case reflect.Int16:
+		return arrow.PrimitiveTypes.Int16, func(b array.Builder, v reflect.Value) {
+			b.(*array.Int16Builder).Append(int16(v.Int()))
+		}, nil
+	case reflect.Int32:
+		return arrow.PrimitiveTypes.Int32, func(b array.Builder, v reflect.Value) {
+			b.(*array.Int32Builder).Append(int32(v.Int()))
+		}, nil
+	case reflect.Int, reflect.Int64:
+		return arrow.PrimitiveTypes.Int64, func(b array.Builder, v reflect.Value) {
+			b.(*array.Int64Builder).Append(v.Int())
+		}, nil
+	case reflect.Uint8:
+		return arrow.PrimitiveTypes.Uint8, func(b array.Builder, v reflect.Value) {
+			b.(*array.Uint8Builder).Append(uint8(v.Uint()))
+		}, nil
+	case reflect.Uint16:
+		return arrow.PrimitiveTypes.Uint16, func(b array.Builder, v reflect.Value) {
+			b.(*array.Uint16Builder).Append(uint16(v.Uint()))
+		}, nil
+	case reflect.Uint32:
+		return arrow.PrimitiveTypes.Uint32, func(b array.Builder, v reflect.Value) {
+			b.(*array.Uint32Builder).Append(uint32(v.Uint()))
+		}, nil
+	case reflect.Uint, reflect.Uint64:
+		return arrow.PrimitiveTypes.Uint64, func(b array.Builder, v reflect.Value) {
+			b.(*array.Uint64Builder).Append(v.Uint())
+		}, nil
+	case reflect.Float32:
+		return arrow.PrimitiveTypes.Float32, func(b array.Builder, v reflect.Value) {
+			b.(*array.Float32Builder).Append(float32(v.Float()))
+		}, nil
+	case reflect.Float64:
+		return arrow.PrimitiveTypes.Float64, func(b array.Builder, v reflect.Value) {
+			b.(*array.Float64Builder).Append(v.Float())
+		}, nil
+	case reflect.String:
+		return arrow.BinaryTypes.String, func(b array.Builder, v reflect.Value) {
+			b.(*array.StringBuilder).Append(v.String())
+		}, nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return arrow.BinaryTypes.Binary, func(b array.Builder, v reflect.Value) {
+				b.(*array.BinaryBuilder).Append(v.Bytes())
+			}, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("unsupported type %s", t)
+}
+
+// StructRecordBuilder accumulates values of struct type T into Arrow records,
+// one row per value, using the schema derived from T's exported fields
+type StructRecordBuilder[T any] struct {
+	builder *array.RecordBuilder
+	columns []structColumn
+	rows    int
+}
+
+// NewStructRecordBuilder creates a builder for struct type T. Columns are
+// named after the fields, or after their `arrow:"name"` tag; `arrow:"-"`
+// skips a field. Booleans, integers, floats, strings, []byte and time.Time
+// (as a UTC microsecond timestamp) are supported, and pointers to them become
+// nullable columns.
+func NewStructRecordBuilder[T any](mem memory.Allocator) (*StructRecordBuilder[T], error) {
+	schema, columns, err := structColumns(reflect.TypeFor[T]())
+	if err != nil {
+		return nil, err
+	}
+	return &StructRecordBuilder[T]{
+		builder: array.NewRecordBuilder(mem, schema),
+		columns: columns,
+	}, nil
+}
+
+// Schema returns the schema of the built records
+func (b *StructRecordBuilder[T]) Schema() *arrow.Schema {
+	return b.builder.Schema()
+}
+
+// Append adds a row
+func (b *StructRecordBuilder[T]) Append(row T) {
+	v := reflect.ValueOf(row)
+	for i, column := range b.columns {
+		field := b.builder.Field(i)
+		value := v.FieldByIndex(column.index)
+		if column.nullable {
+			if value.IsNil() {
+				field.AppendNull()
+				continue
+			}
+			value = value.Elem()
+		}
+		column.append(field, value)
+	}
+	b.rows++
+}
+
+// Len returns the number of rows appended since the last NewRecord
+func (b *StructRecordBuilder[T]) Len() int {
+	return b.rows
+}
+
+// NewRecord returns a record of the rows appended so far and resets the builder
+func (b *StructRecordBuilder[T]) NewRecord() arrow.Record {
+	b.rows = 0
+	return b.builder.NewRecord()
+}
+
+// Release releases the builder's buffers
+func (b *StructRecordBuilder[T]) Release() {
+	b.builder.Release()
+}
+
+// structValueDecoder returns a function storing element i of an array of type
+// dt into a Go value of type t, or an error if the types don't match
+func structValueDecoder(t reflect.Type, dt arrow.DataType) (func(arr arrow.Array, i int, dst reflect.Value), error) {
+	if t == timeType {
+		ts, ok := dt.(*arrow.TimestampType)
+		if !ok {
+			return nil, fmt.Errorf("cannot decode %s into %s", dt, t)
+		}
+		return func(arr arrow.Array, i int, dst reflect.Value) {
+			dst.Set(reflect.ValueOf(arr.(*array.Timestamp).Value(i).ToTime(ts.Unit)))
+		}, nil
+	}
+
+	expected, _, err := structValueType(t)
+	if err != nil {
+		return nil, err
+	}
+	if !arrow.TypeEqual(expected, dt) {
+		return nil, fmt.Errorf("cannot decode %s into %s", dt, t)
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return func(arr arrow.Array, i int, dst reflect.Value) {
+			dst.SetBool(arr.(*array.Boolean).Value(i))
+		}, nil
+	case reflect.Int8:
+		return func(arr arrow.Array, i int, dst reflect.Value) {
+			dst.SetInt(int64(arr.(*array.Int8).Value(i)))
+		}, nil
+	case reflect.Int16:
+		return func(arr arrow.Array, i int, dst reflect.Value) {
+			dst.SetInt(int64(arr.(*array.Int16).Value(i)))
+		}, nil
+	case reflect.Int32:
+		return func(arr arrow.Array, i int, dst reflect.Value) {
+			dst.SetInt(int64(arr.(*array.Int32).Value(i)))
+		}, nil
+	case reflect.Int, reflect.Int64:
+		return func(arr arrow.Array, i int, dst reflect.Value) {
+			dst.SetInt(arr.(*array.Int64).Value(i))
+		}, nil
+	case reflect.Uint8:
+		return func(arr arrow.Array, i int, dst reflect.Value) {
+			dst.SetUint(uint64(arr.(*array.Uint8).Value(i)))
+		}, nil
+	case reflect.Uint16:
+		return func(arr arrow.Array, i int, dst reflect.Value) {
+			dst.SetUint(uint64(arr.(*array.Uint16).Value(i)))
+		}, nil
+	case reflect.Uint32:
+		return func(arr arrow.Array, i int, dst reflect.Value) {
+			dst.SetUint(uint64(arr.(*array.Uint32).Value(i)))
+		}, nil
+	case reflect.Uint, reflect.Uint64:
+		return func(arr arrow.Array, i int, dst reflect.Value) {
+			dst.SetUint(arr.(*array.Uint64).Value(i))
+		}, nil
+	case reflect.Float32:
+		return func(arr arrow.Array, i int, dst reflect.Value) {
+			dst.SetFloat(float64(arr.(*array.Float32).Value(i)))
+		}, nil
+	case reflect.Float64:
+		return func(arr arrow.Array, i int, dst reflect.Value) {
+			dst.SetFloat(arr.(*array.Float64).Value(i))
+		}, nil
+	case reflect.String:
+		return func(arr arrow.Array, i int, dst reflect.Value) {
+			dst.SetString(arr.(*array.String).Value(i))
+		}, nil
+	default: // []byte
+		return func(arr arrow.Array, i int, dst reflect.Value) {
+			// Copy out of the Arrow buffer, which the record owns
+			dst.SetBytes(append([]byte(nil), arr.(*array.Binary).Value(i)...))
+		}, nil
+	}
+}
+
+// structDecodeColumn maps a record column to a struct field
+type structDecodeColumn struct {
+	name     string
+	col      int
+	index    []int
+	nullable bool
+	decode   func(arr arrow.Array, i int, dst reflect.Value)
+}
+
+// StructDecoder converts the rows of Arrow records into values of struct type
+// T, the inverse of StructRecordBuilder
+type StructDecoder[T any] struct {
+	columns []structDecodeColumn
+}
+
+// NewStructDecoder creates a decoder from records with the given schema into
+// struct type T. Fields are matched to columns by name, following the same
+// rules as NewStructRecordBuilder; every field needs a column of the matching
+// type, and timestamps of any unit decode into time.Time. Columns without a
+// field are ignored.
+func NewStructDecoder[T any](schema *arrow.Schema) (*StructDecoder[T], error) {
+	t := reflect.TypeFor[T]()
+	fields, columns, err := structColumns(t)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &StructDecoder[T]{}
+	for i, field := range fields.Fields() {
+		indices := schema.FieldIndices(field.Name)
+		if len(indices) != 1 {
+			return nil, fmt.Errorf("column %q of %s not found in schema", field.Name, t)
+		}
+
+		goType := t.FieldByIndex(columns[i].index).Type
+		if columns[i].nullable {
+			goType = goType.Elem()
+		}
+		decode, err := structValueDecoder(goType, schema.Field(indices[0]).Type)
+		if err != nil {
+			return nil, fmt.Errorf("column %q: %w", field.Name, err)
+		}
+
+		d.columns = append(d.columns, structDecodeColumn{
+			name:     field.Name,
+			col:      indices[0],
+			index:    columns[i].index,
+			nullable: columns[i].nullable,
+			decode:   decode,
+		})
+	}
+	return d, nil
+}
+
+// Decode returns row i of rec. Nulls decode to nil pointers; a null in a
+// column whose field is not a pointer is an error.
+func (d *StructDecoder[T]) Decode(rec arrow.Record, i int) (T, error) {
+	var row T
+	v := reflect.ValueOf(&row).Elem()
+	for _, column := range d.columns {
+		arr := rec.Column(column.col)
+		dst := v.FieldByIndex(column.index)
+		if arr.IsNull(i) {
+			if !column.nullable {
+				return row, fmt.Errorf("null in row %d of column %q, which is not a pointer field", i, column.name)
+			}
+			continue
+		}
+		if column.nullable {
+			dst.Set(reflect.New(dst.Type().Elem()))
+			dst = dst.Elem()
+		}
+		column.decode(arr, i, dst)
+	}
+	return row, nil
+}
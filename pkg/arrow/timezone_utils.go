@@ -0,0 +1,107 @@
+package arrow
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+)
+
+// NormalizeTimezoneSchema returns schema with every timestamp column that has
+// a time zone set to zone. Timezone-naive timestamp columns are set to zone
+// too if assume is set, and left unchanged otherwise. Units are kept.
+func NormalizeTimezoneSchema(schema *arrow.Schema, zone, assume string) (*arrow.Schema, error) {
+	if _, err := time.LoadLocation(zone); err != nil {
+		return nil, fmt.Errorf("invalid time zone %q: %w", zone, err)
+	}
+	if assume != "" {
+		if _, err := time.LoadLocation(assume); err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %w", assume, err)
+		}
+	}
+
+	fields := schema.Fields()
+	for i, field := range fields {
+		ts, ok := field.Type.(*arrow.TimestampType)
+		if !ok || (ts.TimeZone == "" && assume == "") {
+			continue
+		}
+		fields[i].Type = &arrow.TimestampType{Unit: ts.Unit, TimeZone: zone}
+	}
+
+	md := schema.Metadata()
+	return arrow.NewSchema(fields, &md), nil
+}
+
+// NormalizeTimezoneRecord converts the timestamp columns of record to zone,
+// as NormalizeTimezoneSchema describes. Timestamps with a time zone already
+// hold UTC instants, so only their type changes and their buffers are shared.
+// Timezone-naive values are read as wall clock times in assume and converted
+// to the instants they denote; a wall time skipped or repeated by a daylight
+// saving transition resolves as time.Date does.
+func NormalizeTimezoneRecord(record arrow.Record, zone, assume string, mem memory.Allocator) (arrow.Record, error) {
+	schema, err := NormalizeTimezoneSchema(record.Schema(), zone, assume)
+	if err != nil {
+		return nil, err
+	}
+	var local *time.Location
+	if assume != "" {
+		// Validated by NormalizeTimezoneSchema
+		local, _ = time.LoadLocation(assume)
+	}
+
+	cols := make([]arrow.Array, record.NumCols())
+	defer func() {
+		for _, col := range cols {
+			if col != nil {
+				col.Release()
+			}
+		}
+	}()
+
+	for i, col := range record.Columns() {
+		target := schema.Field(i).Type
+		switch {
+		case arrow.TypeEqual(col.DataType(), target):
+			col.Retain()
+			cols[i] = col
+		case col.DataType().(*arrow.TimestampType).TimeZone != "":
+			data := array.NewData(target, col.Len(), col.Data().Buffers(), nil, col.NullN(), col.Data().Offset())
+			cols[i] = array.MakeFromData(data)
+			data.Release()
+		default:
+			localized, err := localizeTimestamps(col.(*array.Timestamp), target.(*arrow.TimestampType), local, mem)
+			if err != nil {
+				return nil, fmt.Errorf("failed to normalize column %q: %w", schema.Field(i).Name, err)
+			}
+			cols[i] = localized
+		}
+	}
+
+	return array.NewRecord(schema, cols, record.NumRows()), nil
+}
+
+// localizeTimestamps converts timezone-naive timestamps, read as wall clock
+// times in loc, to the instants they denote
+func localizeTimestamps(col *array.Timestamp, target *arrow.TimestampType, loc *time.Location, mem memory.Allocator) (arrow.Array, error) {
+	builder := array.NewTimestampBuilder(mem, target)
+	defer builder.Release()
+	builder.Reserve(col.Len())
+
+	for i := 0; i < col.Len(); i++ {
+		if col.IsNull(i) {
+			builder.AppendNull()
+			continue
+		}
+		wall := col.Value(i).ToTime(target.Unit).UTC()
+		instant := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(), loc)
+		value, err := arrow.TimestampFromTime(instant, target.Unit)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i, err)
+		}
+		builder.Append(value)
+	}
+	return builder.NewArray(), nil
+}
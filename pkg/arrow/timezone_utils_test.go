@@ -0,0 +1,59 @@
+package arrow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNormalizeTimezoneRecord tests that timestamps with a time zone keep
+// their instants and units, and that naive ones are converted only when a
+// zone is assumed for them
+func TestNormalizeTimezoneRecord(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	instant := time.Date(2024, time.July, 1, 12, 0, 0, 0, time.UTC)
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "tokyo", Type: &arrow.TimestampType{Unit: arrow.Second, TimeZone: "Asia/Tokyo"}, Nullable: true},
+		{Name: "naive", Type: &arrow.TimestampType{Unit: arrow.Millisecond}, Nullable: true},
+		{Name: "id", Type: arrow.PrimitiveTypes.Int32},
+	}, nil)
+	builder := array.NewRecordBuilder(mem, schema)
+	defer builder.Release()
+	builder.Field(0).(*array.TimestampBuilder).AppendValues([]arrow.Timestamp{arrow.Timestamp(instant.Unix()), 0}, []bool{true, false})
+	builder.Field(1).(*array.TimestampBuilder).AppendValues([]arrow.Timestamp{arrow.Timestamp(instant.UnixMilli()), 0}, []bool{true, false})
+	builder.Field(2).(*array.Int32Builder).AppendValues([]int32{1, 2}, nil)
+	record := builder.NewRecord()
+	defer record.Release()
+
+	normalized, err := NormalizeTimezoneRecord(record, "UTC", "", mem)
+	require.NoError(t, err, "Failed to normalize record")
+	defer normalized.Release()
+	tokyo := normalized.Column(0).(*array.Timestamp)
+	assert.Equal(t, &arrow.TimestampType{Unit: arrow.Second, TimeZone: "UTC"}, tokyo.DataType())
+	assert.Equal(t, instant, tokyo.Value(0).ToTime(arrow.Second).UTC())
+	assert.True(t, tokyo.IsNull(1))
+	assert.True(t, arrow.TypeEqual(schema.Field(1).Type, normalized.Schema().Field(1).Type), "Naive columns should be untouched")
+	assert.True(t, array.Equal(record.Column(1), normalized.Column(1)))
+
+	// Noon in Los Angeles is 19:00 UTC in July
+	assumed, err := NormalizeTimezoneRecord(record, "UTC", "America/Los_Angeles", mem)
+	require.NoError(t, err, "Failed to normalize record")
+	defer assumed.Release()
+	naive := assumed.Column(1).(*array.Timestamp)
+	assert.Equal(t, &arrow.TimestampType{Unit: arrow.Millisecond, TimeZone: "UTC"}, naive.DataType())
+	assert.Equal(t, instant.Add(7*time.Hour), naive.Value(0).ToTime(arrow.Millisecond).UTC())
+	assert.True(t, naive.IsNull(1))
+	assert.True(t, array.Equal(record.Column(2), assumed.Column(2)), "Other columns should be unchanged")
+
+	_, err = NormalizeTimezoneRecord(record, "Mars/Olympus", "", mem)
+	assert.ErrorContains(t, err, "invalid time zone")
+	_, err = NormalizeTimezoneRecord(record, "UTC", "Mars/Olympus", mem)
+	assert.ErrorContains(t, err, "invalid time zone")
+}
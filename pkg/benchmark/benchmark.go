@@ -0,0 +1,262 @@
+// Package benchmark provides a load-test harness for Arrow Flight servers. It
+// drives concurrent PutBatch and GetBatch workloads through a FlightClient and
+// reports throughput, latency percentiles and error counts gathered through
+// the client's metrics hook.
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+
+	arrow_utils "github.com/TFMV/temporal/pkg/arrow"
+	"github.com/TFMV/temporal/pkg/flight"
+)
+
+// Workload describes the load generated by a Benchmark
+type Workload struct {
+	// BatchRows is the number of rows in each uploaded batch (default: 1000)
+	BatchRows int
+	// ReadRatio is the fraction of operations that are reads, between 0 and 1.
+	// Reads start once the first write has succeeded.
+	ReadRatio float64
+	// Concurrency is the number of concurrent workers (default: 1)
+	Concurrency int
+	// Duration is how long workers keep starting new operations (default: 10s)
+	Duration time.Duration
+}
+
+// Config contains configuration for a Benchmark
+type Config struct {
+	// Client configures the Flight client used to generate load. Any Metrics
+	// hook set here still receives every call.
+	Client flight.FlightClientConfig
+	// Workload describes the load to generate
+	Workload Workload
+}
+
+// Benchmark runs a load test against a Flight server. Uploaded batches are left
+// on the server and expire with its TTL.
+type Benchmark struct {
+	config Config
+}
+
+// OperationReport summarizes the calls of one kind made during a run
+type OperationReport struct {
+	// Count is the number of completed calls, including failed ones
+	Count int64
+	// Errors is the number of failed calls
+	Errors int64
+	// Rows and Bytes are the totals transferred by successful calls
+	Rows  int64
+	Bytes int64
+	// OpsPerSecond, RowsPerSecond and BytesPerSecond are throughputs over
+	// the whole run
+	OpsPerSecond   float64
+	RowsPerSecond  float64
+	BytesPerSecond float64
+	// Latency percentiles of successful calls
+	P50 time.Duration
+	P90 time.Duration
+	P99 time.Duration
+	Max time.Duration
+}
+
+// Report is the result of a Benchmark run
+type Report struct {
+	// Elapsed is the wall time of the run, including draining in-flight calls
+	Elapsed time.Duration
+	// Puts summarizes the PutBatch calls
+	Puts OperationReport
+	// Gets summarizes the GetBatch calls
+	Gets OperationReport
+}
+
+// New creates a Benchmark, applying defaults to the workload
+func New(config Config) (*Benchmark, error) {
+	w := &config.Workload
+	if w.BatchRows <= 0 {
+		w.BatchRows = 1000
+	}
+	if w.Concurrency <= 0 {
+		w.Concurrency = 1
+	}
+	if w.Duration <= 0 {
+		w.Duration = 10 * time.Second
+	}
+	if w.ReadRatio < 0 || w.ReadRatio > 1 {
+		return nil, fmt.Errorf("read ratio %v must be between 0 and 1", w.ReadRatio)
+	}
+	return &Benchmark{config: config}, nil
+}
+
+// Run generates load for the configured duration and reports the results.
+// Calls still in flight when the duration ends are allowed to finish; cancelling
+// ctx stops the run early.
+func (b *Benchmark) Run(ctx context.Context) (*Report, error) {
+	recorder := newRecorder(b.config.Client.Metrics)
+	clientConfig := b.config.Client
+	clientConfig.Metrics = recorder
+
+	client, err := flight.NewFlightClient(clientConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Flight client: %w", err)
+	}
+	defer client.Close()
+
+	batch, err := arrow_utils.GenerateRandomBatch(b.config.Workload.BatchRows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate batch: %w", err)
+	}
+	defer batch.Release()
+
+	ids := &batchIDs{}
+	start := time.Now()
+	end := start.Add(b.config.Workload.Duration)
+
+	var wg sync.WaitGroup
+	for i := 0; i < b.config.Workload.Concurrency; i++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			b.worker(ctx, client, batch, ids, end, rand.New(rand.NewSource(seed)))
+		}(start.UnixNano() + int64(i))
+	}
+	wg.Wait()
+
+	elapsed := time.Since(start)
+	return &Report{
+		Elapsed: elapsed,
+		Puts:    recorder.report(flight.MethodPutBatch, elapsed),
+		Gets:    recorder.report(flight.MethodGetBatch, elapsed),
+	}, nil
+}
+
+// worker issues operations until the run ends. Errors are recorded by the
+// metrics hook, so they are not returned.
+func (b *Benchmark) worker(ctx context.Context, client *flight.FlightClient, batch arrow.Record, ids *batchIDs, end time.Time, rng *rand.Rand) {
+	for time.Now().Before(end) && ctx.Err() == nil {
+		if id, ok := ids.random(rng); ok && rng.Float64() < b.config.Workload.ReadRatio {
+			if rec, err := client.GetBatch(ctx, id); err == nil {
+				rec.Release()
+			}
+			continue
+		}
+
+		if id, err := client.PutBatch(ctx, batch); err == nil {
+			ids.add(id)
+		}
+	}
+}
+
+// batchIDs is the set of batches uploaded so far, shared by the workers
+type batchIDs struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+// add records a successfully uploaded batch
+func (b *batchIDs) add(id string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.ids = append(b.ids, id)
+}
+
+// random picks an uploaded batch, reporting false if there is none yet
+func (b *batchIDs) random(rng *rand.Rand) (string, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.ids) == 0 {
+		return "", false
+	}
+	return b.ids[rng.Intn(len(b.ids))], true
+}
+
+// recorder is the flight.Metrics hook collecting per-method statistics
+type recorder struct {
+	next    flight.Metrics
+	mu      sync.Mutex
+	methods map[string]*methodStats
+}
+
+// methodStats accumulates the calls of one method
+type methodStats struct {
+	count     int64
+	errors    int64
+	rows      int64
+	bytes     int64
+	latencies []time.Duration
+}
+
+// newRecorder creates a recorder that forwards every call to next, if set
+func newRecorder(next flight.Metrics) *recorder {
+	return &recorder{next: next, methods: make(map[string]*methodStats)}
+}
+
+// ObserveCall implements flight.Metrics
+func (r *recorder) ObserveCall(stats flight.CallStats) {
+	r.mu.Lock()
+	m, ok := r.methods[stats.Method]
+	if !ok {
+		m = &methodStats{}
+		r.methods[stats.Method] = m
+	}
+	m.count++
+	if stats.Err != nil {
+		m.errors++
+	} else {
+		m.rows += stats.Rows
+		m.bytes += stats.Bytes
+		m.latencies = append(m.latencies, stats.Duration)
+	}
+	r.mu.Unlock()
+
+	if r.next != nil {
+		r.next.ObserveCall(stats)
+	}
+}
+
+// report summarizes the calls of a method over a run of the given length
+func (r *recorder) report(method string, elapsed time.Duration) OperationReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, ok := r.methods[method]
+	if !ok {
+		return OperationReport{}
+	}
+
+	seconds := elapsed.Seconds()
+	report := OperationReport{
+		Count:          m.count,
+		Errors:         m.errors,
+		Rows:           m.rows,
+		Bytes:          m.bytes,
+		OpsPerSecond:   float64(m.count) / seconds,
+		RowsPerSecond:  float64(m.rows) / seconds,
+		BytesPerSecond: float64(m.bytes) / seconds,
+	}
+
+	latencies := slices.Clone(m.latencies)
+	slices.Sort(latencies)
+	report.P50 = percentile(latencies, 0.50)
+	report.P90 = percentile(latencies, 0.90)
+	report.P99 = percentile(latencies, 0.99)
+	report.Max = percentile(latencies, 1)
+	return report
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(0, rank)]
+}
@@ -0,0 +1,104 @@
+package benchmark
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TFMV/temporal/pkg/flight"
+)
+
+// startTestServer starts a local Flight server and returns its address
+func startTestServer(t *testing.T) string {
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err, "Failed to find available port")
+	addr := listener.Addr().String()
+	listener.Close()
+
+	server, err := flight.NewFlightServer(flight.FlightServerConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight server")
+	go server.Start()
+	t.Cleanup(server.Stop)
+
+	// Wait for the server to start
+	time.Sleep(100 * time.Millisecond)
+	return addr
+}
+
+// countingMetrics counts the calls it observes
+type countingMetrics struct {
+	calls atomic.Int64
+}
+
+// ObserveCall implements flight.Metrics
+func (m *countingMetrics) ObserveCall(flight.CallStats) {
+	m.calls.Add(1)
+}
+
+// TestBenchmarkRun tests that a mixed workload runs against a local server and reports sane numbers
+func TestBenchmarkRun(t *testing.T) {
+	addr := startTestServer(t)
+	metrics := &countingMetrics{}
+
+	bench, err := New(Config{
+		Client: flight.FlightClientConfig{Addr: addr, Metrics: metrics},
+		Workload: Workload{
+			BatchRows:   100,
+			ReadRatio:   0.5,
+			Concurrency: 4,
+			Duration:    500 * time.Millisecond,
+		},
+	})
+	require.NoError(t, err, "Failed to create benchmark")
+
+	report, err := bench.Run(context.Background())
+	require.NoError(t, err, "Benchmark run failed")
+
+	assert.GreaterOrEqual(t, report.Elapsed, 500*time.Millisecond)
+	for name, op := range map[string]OperationReport{"puts": report.Puts, "gets": report.Gets} {
+		assert.Positive(t, op.Count, "Expected some %s", name)
+		assert.Zero(t, op.Errors, "Expected no failed %s", name)
+		assert.Equal(t, op.Count*100, op.Rows, "Every %s should transfer a full batch", name)
+		assert.Positive(t, op.Bytes)
+		assert.Positive(t, op.OpsPerSecond)
+		assert.Positive(t, op.P50)
+		assert.LessOrEqual(t, op.P50, op.P90)
+		assert.LessOrEqual(t, op.P90, op.P99)
+		assert.LessOrEqual(t, op.P99, op.Max)
+	}
+
+	// The caller's metrics hook still sees every call
+	assert.Equal(t, report.Puts.Count+report.Gets.Count, metrics.calls.Load())
+}
+
+// TestBenchmarkErrors tests that failed calls are counted rather than aborting the run
+func TestBenchmarkErrors(t *testing.T) {
+	// Nothing listens on this address
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	bench, err := New(Config{
+		Client:   flight.FlightClientConfig{Addr: addr},
+		Workload: Workload{Duration: 200 * time.Millisecond},
+	})
+	require.NoError(t, err, "Failed to create benchmark")
+
+	report, err := bench.Run(context.Background())
+	require.NoError(t, err, "Benchmark run failed")
+	assert.Positive(t, report.Puts.Errors)
+	assert.Equal(t, report.Puts.Count, report.Puts.Errors)
+	assert.Zero(t, report.Gets.Count, "Reads never start without a successful write")
+}
+
+// TestInvalidWorkload tests workload validation
+func TestInvalidWorkload(t *testing.T) {
+	_, err := New(Config{Workload: Workload{ReadRatio: 1.5}})
+	assert.Error(t, err)
+}
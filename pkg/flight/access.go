@@ -0,0 +1,145 @@
+package flight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// AccessTokenHeader is the gRPC metadata key carrying the access token of a
+// request (GetOptions.AccessToken, WithAccessToken)
+const AccessTokenHeader = "flight-access-token"
+
+// WithAccessToken returns a context sending token with every call made under
+// it, for calls that address a restricted batch without a download, such as
+// GetNullCounts, Fingerprint, name updates or dropping the batch. Listings
+// made under it include the restricted batches the token may read.
+func WithAccessToken(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, AccessTokenHeader, token)
+}
+
+// ErrAccessDenied is returned when the server refuses a download of a
+// restricted batch with codes.PermissionDenied. The server's status stays
+// available through errors.As and status.Code.
+var ErrAccessDenied = errors.New("access to batch denied")
+
+// AccessPolicy restricts who may read a stored batch. It is enforced by the
+// server: the server resolves the token sent with each request to a principal
+// (see FlightServerConfig.ResolvePrincipal) and refuses requests whose
+// principal is not allowed. The policy covers every request addressing the
+// batch, including metadata actions, name updates and dropping it, and
+// listings leave the batch out. Batches stored without a policy are readable
+// by anyone.
+type AccessPolicy struct {
+	// AllowedPrincipals lists the principals that may read the batch. An
+	// empty list allows no one.
+	AllowedPrincipals []string `json:"allowedPrincipals"`
+}
+
+// PrincipalResolver maps the access token sent with a request to the
+// principal it identifies, returning an error if the token is not valid
+type PrincipalResolver func(ctx context.Context, token string) (string, error)
+
+// tokenPrincipal is the default PrincipalResolver: the token itself is the
+// principal, so policies list the capability tokens that grant access
+func tokenPrincipal(ctx context.Context, token string) (string, error) {
+	return token, nil
+}
+
+// authorize checks the access token of a request against the policy of the
+// batch it reads, which may be given by ID or name
+func (s *FlightServer) authorize(ctx context.Context, batchID string) error {
+	batchID, policy := s.accessPolicy(batchID)
+	if policy == nil {
+		return nil
+	}
+	return s.checkAccess(ctx, batchID, policy)
+}
+
+// canRead reports whether the request may read a stored batch, for listings
+// that leave out the batches it may not. Must be called with batchesMu held.
+func (s *FlightServer) canRead(ctx context.Context, batchID string) bool {
+	policy := s.policies[batchID]
+	return policy == nil || s.checkAccess(ctx, batchID, policy) == nil
+}
+
+// accessPolicy returns the ID of a batch given by ID or name and its access
+// policy, nil if it has none
+func (s *FlightServer) accessPolicy(batchID string) (string, *AccessPolicy) {
+	s.batchesMu.RLock()
+	defer s.batchesMu.RUnlock()
+
+	if target, ok := s.names[batchID]; ok {
+		batchID = target
+	}
+	return batchID, s.policies[batchID]
+}
+
+// requestPrincipal resolves the access token of a request to its principal,
+// or returns "" if the request sent no token
+func (s *FlightServer) requestPrincipal(ctx context.Context) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	tokens := md.Get(AccessTokenHeader)
+	if len(tokens) == 0 {
+		return "", nil
+	}
+	principal, err := s.resolvePrincipal(ctx, tokens[0])
+	if err != nil {
+		return "", status.Errorf(codes.PermissionDenied, "invalid access token: %v", err)
+	}
+	return principal, nil
+}
+
+// checkAccess checks the access token of a request against a batch's policy
+func (s *FlightServer) checkAccess(ctx context.Context, batchID string, policy *AccessPolicy) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	tokens := md.Get(AccessTokenHeader)
+	if len(tokens) == 0 {
+		return status.Errorf(codes.PermissionDenied, "batch %s requires an access token", batchID)
+	}
+
+	principal, err := s.resolvePrincipal(ctx, tokens[0])
+	if err != nil {
+		return status.Errorf(codes.PermissionDenied, "invalid access token: %v", err)
+	}
+	if !slices.Contains(policy.AllowedPrincipals, principal) {
+		return status.Errorf(codes.PermissionDenied, "access to batch %s denied", batchID)
+	}
+	return nil
+}
+
+// checkRetryPolicy refuses an upload reusing the ID of a stored batch unless
+// it asks for the batch's access policy, since the stored batch is kept: a
+// retry must not leave a batch public that its uploader meant to restrict, nor
+// report a restriction the batch does not have
+func checkRetryPolicy(batchID string, stored, requested *AccessPolicy) error {
+	if samePolicy(stored, requested) {
+		return nil
+	}
+	return status.Errorf(codes.AlreadyExists, "batch %s is already stored with another access policy", batchID)
+}
+
+// samePolicy reports whether two policies, either of which may be nil, allow
+// the same principals
+func samePolicy(a, b *AccessPolicy) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	x, y := slices.Clone(a.AllowedPrincipals), slices.Clone(b.AllowedPrincipals)
+	slices.Sort(x)
+	slices.Sort(y)
+	return slices.Equal(slices.Compact(x), slices.Compact(y))
+}
+
+// accessError marks PermissionDenied errors from the server with ErrAccessDenied
+func accessError(err error) error {
+	if status.Code(err) == codes.PermissionDenied {
+		return fmt.Errorf("%w: %w", ErrAccessDenied, err)
+	}
+	return err
+}
@@ -0,0 +1,260 @@
+package flight
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TestAccessPolicy tests that restricted batches are only readable with an allowed token
+func TestAccessPolicy(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	result, err := client.PutBatchWithOptions(ctx, batch, PutOptions{
+		Access: &AccessPolicy{AllowedPrincipals: []string{"secret-token"}},
+	})
+	require.NoError(t, err, "Failed to put restricted batch")
+
+	_, err = client.GetBatch(ctx, result.BatchID)
+	assert.ErrorIs(t, err, ErrAccessDenied, "A fetch without a token should be denied")
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = client.GetBatchWithOptions(ctx, result.BatchID, GetOptions{AccessToken: "wrong-token"})
+	assert.ErrorIs(t, err, ErrAccessDenied, "A fetch with the wrong token should be denied")
+
+	retrieved, err := client.GetBatchWithOptions(ctx, result.BatchID, GetOptions{AccessToken: "secret-token"})
+	require.NoError(t, err, "An allowed token should fetch the batch")
+	defer retrieved.Release()
+	assert.Equal(t, batch.NumRows(), retrieved.NumRows())
+
+	// Batches without a policy stay public
+	publicID, err := client.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to put public batch")
+	public, err := client.GetBatch(ctx, publicID)
+	require.NoError(t, err, "A public batch needs no token")
+	public.Release()
+}
+
+// TestAccessPolicyResolver tests that the server checks policies against the principal its resolver returns
+func TestAccessPolicyResolver(t *testing.T) {
+	tokens := map[string]string{"t-alice": "alice", "t-bob": "bob"}
+	server, err := NewFlightServer(FlightServerConfig{
+		ResolvePrincipal: func(ctx context.Context, token string) (string, error) {
+			principal, ok := tokens[token]
+			if !ok {
+				return "", fmt.Errorf("unknown token")
+			}
+			return principal, nil
+		},
+	})
+	require.NoError(t, err, "Failed to create Flight server")
+	defer server.Stop()
+	addr := startBareServer(t, server)
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	result, err := client.PutBatchWithOptions(ctx, batch, PutOptions{
+		Access: &AccessPolicy{AllowedPrincipals: []string{"alice"}},
+	})
+	require.NoError(t, err, "Failed to put restricted batch")
+
+	retrieved, err := client.GetBatchWithOptions(ctx, result.BatchID, GetOptions{AccessToken: "t-alice"})
+	require.NoError(t, err, "Alice should be allowed")
+	retrieved.Release()
+
+	_, err = client.GetBatchWithOptions(ctx, result.BatchID, GetOptions{AccessToken: "t-bob"})
+	assert.ErrorIs(t, err, ErrAccessDenied, "Bob is not an allowed principal")
+
+	_, err = client.GetBatchWithOptions(ctx, result.BatchID, GetOptions{AccessToken: "alice"})
+	assert.ErrorIs(t, err, ErrAccessDenied, "Tokens the resolver rejects should be denied")
+}
+
+// TestAccessPolicyNotSupported tests that a policy is never sent to a server that cannot enforce it
+func TestAccessPolicyNotSupported(t *testing.T) {
+	server := &limitedServer{}
+	addr := startBareServer(t, server)
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	_, err = client.PutBatchWithOptions(ctx, batch, PutOptions{Access: &AccessPolicy{AllowedPrincipals: []string{"a"}}})
+	assert.ErrorIs(t, err, ErrNotSupported)
+	assert.Zero(t, server.puts.Load(), "Nothing should be uploaded")
+}
+
+// TestAccessPolicyAllPaths tests that every request addressing a restricted
+// batch is checked against its policy, and that listings leave it out
+func TestAccessPolicyAllPaths(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	allowed := WithAccessToken(ctx, "secret-token")
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	// Subscribe before storing anything, so both uploads are notified
+	ids, err := client.SubscribeBatches(ctx, "")
+	require.NoError(t, err, "Failed to subscribe")
+
+	result, err := client.PutBatchWithOptions(ctx, batch, PutOptions{
+		Access: &AccessPolicy{AllowedPrincipals: []string{"secret-token"}},
+	})
+	require.NoError(t, err, "Failed to put restricted batch")
+	restricted := result.BatchID
+	publicID, err := client.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to put public batch")
+
+	select {
+	case id := <-ids:
+		assert.Equal(t, publicID, id, "Subscribers should only be told of batches they may read")
+	case <-ctx.Done():
+		t.Fatal("No batch notification received")
+	}
+
+	pointAt := func(ctx context.Context, action, name, batchID string) error {
+		body, err := json.Marshal(nameRequest{Name: name, BatchID: batchID})
+		require.NoError(t, err)
+		_, err = client.doAction(ctx, action, body)
+		return err
+	}
+	require.NoError(t, pointAt(allowed, ActionSwapName, "secret", restricted), "An allowed token should name the batch")
+
+	// Repointing the name comes last, since it moves the name off the batch
+	for _, tc := range []struct {
+		name string
+		call func(ctx context.Context) error
+	}{
+		{"null counts", func(ctx context.Context) error { _, err := client.GetNullCounts(ctx, restricted); return err }},
+		{"schema ID", func(ctx context.Context) error { _, err := client.GetSchemaID(ctx, restricted); return err }},
+		{"lineage", func(ctx context.Context) error { _, err := client.GetLineage(ctx, restricted); return err }},
+		{"fingerprint", func(ctx context.Context) error { _, err := client.Fingerprint(ctx, restricted); return err }},
+		{"schema", func(ctx context.Context) error { _, err := client.GetSchema(ctx, restricted); return err }},
+		{"resolve", func(ctx context.Context) error { _, err := client.ResolveName(ctx, "secret"); return err }},
+		{"name swap", func(ctx context.Context) error { return pointAt(ctx, ActionPublishName, "other", restricted) }},
+		{"repoint", func(ctx context.Context) error { return pointAt(ctx, ActionPublishName, "secret", publicID) }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, codes.PermissionDenied, status.Code(tc.call(ctx)), "A request without a token should be denied")
+			assert.Equal(t, codes.PermissionDenied, status.Code(tc.call(WithAccessToken(ctx, "wrong-token"))))
+			assert.NoError(t, tc.call(allowed), "An allowed token should pass")
+		})
+	}
+
+	t.Run("listing", func(t *testing.T) {
+		listed, err := client.ListBatches(ctx)
+		require.NoError(t, err, "Failed to list batches")
+		assert.NotContains(t, listed, restricted)
+		assert.Contains(t, listed, publicID)
+
+		page, _, err := client.ListBatchesPage(ctx, "", 10)
+		require.NoError(t, err, "Failed to list a page")
+		assert.NotContains(t, page, restricted)
+
+		entries, err := client.ListCatalog(ctx, nil)
+		require.NoError(t, err, "Failed to list catalog")
+		for _, entry := range entries {
+			assert.NotEqual(t, restricted, entry.BatchID)
+		}
+
+		listed, err = client.ListBatches(allowed)
+		require.NoError(t, err, "Failed to list batches")
+		assert.Contains(t, listed, restricted, "An allowed token should list the batch")
+	})
+
+	t.Run("drop", func(t *testing.T) {
+		_, err := client.doAction(ctx, ActionDropBatch, []byte(restricted))
+		assert.Equal(t, codes.PermissionDenied, status.Code(err), "Dropping without a token should be denied")
+		retrieved, err := client.GetBatchWithOptions(ctx, restricted, GetOptions{AccessToken: "secret-token"})
+		require.NoError(t, err, "A refused drop should keep the batch")
+		retrieved.Release()
+
+		_, err = client.doAction(allowed, ActionDropBatch, []byte(restricted))
+		assert.NoError(t, err, "An allowed token should drop the batch")
+	})
+}
+
+// TestAccessPolicyRetry tests that an upload reusing the ID of a stored batch
+// is refused when it asks for another access policy, leaving the stored batch
+// as it was
+func TestAccessPolicyRetry(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr, IDGenerator: ContentHashIDGenerator{}})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+	restricted := PutOptions{Access: &AccessPolicy{AllowedPrincipals: []string{"secret"}}}
+
+	// Public, then restricted
+	batchID, err := client.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to put public batch")
+	_, err = client.PutBatchWithOptions(ctx, batch, restricted)
+	assert.Equal(t, codes.AlreadyExists, status.Code(err), "Restricting a stored public batch should fail")
+	retrieved, err := client.GetBatch(ctx, batchID)
+	require.NoError(t, err, "The public batch should be kept")
+	retrieved.Release()
+
+	// Restricted, then public or with other principals
+	_, err = client.doAction(ctx, ActionDropBatch, []byte(batchID))
+	require.NoError(t, err, "Failed to drop batch")
+	_, err = client.PutBatchWithOptions(ctx, batch, restricted)
+	require.NoError(t, err, "Failed to put restricted batch")
+	_, err = client.PutBatch(ctx, batch)
+	assert.Equal(t, codes.AlreadyExists, status.Code(err), "A public retry of a restricted batch should fail")
+	_, err = client.PutBatchWithOptions(ctx, batch, PutOptions{Access: &AccessPolicy{AllowedPrincipals: []string{"other"}}})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err), "A retry with other principals should fail")
+	_, err = client.GetBatch(ctx, batchID)
+	assert.ErrorIs(t, err, ErrAccessDenied, "The batch should stay restricted")
+
+	// A retry with the same policy is accepted
+	result, err := client.PutBatchWithOptions(ctx, batch, restricted)
+	require.NoError(t, err, "A retry with the same policy should succeed")
+	assert.Equal(t, batchID, result.BatchID)
+}
@@ -0,0 +1,129 @@
+package flight
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// defaultCompressionSampleBytes is the default AdaptiveCompression.MinSampleBytes
+const defaultCompressionSampleBytes = 64 * 1024
+
+// compressionLevels orders the codecs from most to least CPU intensive; an
+// adaptive client steps down this list one codec at a time
+var compressionLevels = []string{CompressionZstd, CompressionLZ4, CompressionNone}
+
+// AdaptiveCompression downgrades the upload codec at runtime when encoding
+// turns out to cost more CPU than it saves in bandwidth, as on small edge
+// nodes. After each upload the client measures how fast the batch was
+// encoded, excluding the time spent sending it; if that falls below
+// MinThroughput, later uploads use the next cheaper codec (zstd, then lz4,
+// then none). Downgrades are never undone for the lifetime of the client.
+type AdaptiveCompression struct {
+	// MinThroughput is the slowest acceptable encoding rate, in uncompressed
+	// bytes per second
+	MinThroughput int64
+	// Floor is the cheapest codec to downgrade to: "lz4" (default) or "none"
+	Floor string
+	// MinSampleBytes is the smallest upload measured, since the timing of
+	// small batches is dominated by noise (default: 64 KiB)
+	MinSampleBytes int64
+	// Logger receives a line for every downgrade (default: log.Default())
+	Logger *log.Logger
+}
+
+// validate checks the settings against the configured codec
+func (a *AdaptiveCompression) validate(codec string) error {
+	if a.MinThroughput <= 0 {
+		return fmt.Errorf("minimum throughput must be positive, got %d", a.MinThroughput)
+	}
+	switch a.Floor {
+	case "", CompressionLZ4, CompressionNone:
+	default:
+		return fmt.Errorf("unsupported floor codec %q", a.Floor)
+	}
+	if codec == CompressionNone {
+		return fmt.Errorf("adaptive compression requires a compression codec")
+	}
+	return nil
+}
+
+// compressionState tracks the effective upload codec of an adaptive client
+type compressionState struct {
+	config AdaptiveCompression
+	codec  string
+	mu     sync.Mutex
+}
+
+// newCompressionState starts adapting from the configured codec
+func newCompressionState(config AdaptiveCompression, codec string) *compressionState {
+	if config.Floor == "" {
+		config.Floor = CompressionLZ4
+	}
+	if config.MinSampleBytes <= 0 {
+		config.MinSampleBytes = defaultCompressionSampleBytes
+	}
+	if config.Logger == nil {
+		config.Logger = log.Default()
+	}
+	return &compressionState{config: config, codec: codec}
+}
+
+// current returns the codec to use for the next upload
+func (s *compressionState) current() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.codec
+}
+
+// observe records how long encoding bytes of data with codec took, and
+// downgrades the codec if that was too slow. label is the WithLabel tag of
+// the upload, named in the downgrade's log line.
+func (s *compressionState) observe(codec string, bytes int64, elapsed time.Duration, label string) {
+	if bytes < s.config.MinSampleBytes {
+		return
+	}
+	throughput := float64(bytes) / max(elapsed, time.Nanosecond).Seconds()
+	if throughput >= float64(s.config.MinThroughput) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Concurrent uploads may report on a codec already downgraded from
+	if codec != s.codec || codec == s.config.Floor {
+		return
+	}
+	for i, level := range compressionLevels {
+		if level == codec {
+			s.codec = compressionLevels[i+1]
+			break
+		}
+	}
+	prefix := "flight: "
+	if label != "" {
+		prefix += "[" + label + "] "
+	}
+	s.config.Logger.Printf("%s%s encoding ran at %.0f bytes/s, below the minimum of %d; downgrading uploads to %s",
+		prefix, codec, throughput, s.config.MinThroughput, s.codec)
+}
+
+// EffectiveCompression returns the IPC codec used for the next upload, which
+// differs from FlightClientConfig.Compression once AdaptiveCompression has
+// downgraded it
+func (c *FlightClient) EffectiveCompression() string {
+	if c.adaptiveCompression == nil {
+		return c.compression
+	}
+	return c.adaptiveCompression.current()
+}
+
+// observeEncoding reports the encoding speed of an upload to AdaptiveCompression
+func (c *FlightClient) observeEncoding(ctx context.Context, codec string, bytes int64, elapsed time.Duration) {
+	if c.adaptiveCompression != nil && codec != CompressionNone {
+		c.adaptiveCompression.observe(codec, bytes, elapsed, CallLabel(ctx))
+	}
+}
@@ -0,0 +1,85 @@
+package flight
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAdaptiveCompressionDowngrade tests that slow encoding steps the codec down to the floor
+func TestAdaptiveCompressionDowngrade(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	// No real encoder sustains this rate, so every upload looks slow
+	var logs bytes.Buffer
+	client, err := NewFlightClient(FlightClientConfig{
+		Addr:        addr,
+		Compression: CompressionZstd,
+		AdaptiveCompression: &AdaptiveCompression{
+			MinThroughput:  1 << 50,
+			Floor:          CompressionNone,
+			MinSampleBytes: 1,
+			Logger:         log.New(&logs, "", 0),
+		},
+	})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	batch := createCompressibleBatch(t, 10000)
+	defer batch.Release()
+
+	timeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ctx := WithLabel(timeout, "nightly")
+
+	for _, want := range []string{CompressionZstd, CompressionLZ4, CompressionNone, CompressionNone} {
+		assert.Equal(t, want, client.EffectiveCompression())
+		result, err := client.PutBatchWithOptions(ctx, batch, PutOptions{})
+		require.NoError(t, err, "Failed to put batch")
+		assert.Equal(t, want, result.Compression, "The upload should use the effective codec")
+	}
+	assert.Contains(t, logs.String(), "flight: [nightly] zstd encoding")
+	assert.Contains(t, logs.String(), "downgrading uploads to lz4")
+	assert.Contains(t, logs.String(), "downgrading uploads to none")
+
+	// Downgraded uploads still round trip
+	batchID, err := client.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to put batch")
+	retrieved, err := client.GetBatch(ctx, batchID)
+	require.NoError(t, err, "Failed to get batch")
+	defer retrieved.Release()
+	assert.Equal(t, batch.NumRows(), retrieved.NumRows())
+}
+
+// TestAdaptiveCompressionKeepsFastCodec tests that fast encoding and small batches keep the configured codec
+func TestAdaptiveCompressionKeepsFastCodec(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	for name, adaptive := range map[string]*AdaptiveCompression{
+		"fast encoding": {MinThroughput: 1, MinSampleBytes: 1},
+		"small batch":   {MinThroughput: 1 << 50, MinSampleBytes: 1 << 40},
+	} {
+		t.Run(name, func(t *testing.T) {
+			client, err := NewFlightClient(FlightClientConfig{Addr: addr, Compression: CompressionZstd, AdaptiveCompression: adaptive})
+			require.NoError(t, err, "Failed to create Flight client")
+			defer client.Close()
+
+			batch := createCompressibleBatch(t, 10000)
+			defer batch.Release()
+
+			_, err = client.PutBatch(context.Background(), batch)
+			require.NoError(t, err, "Failed to put batch")
+			assert.Equal(t, CompressionZstd, client.EffectiveCompression())
+		})
+	}
+
+	_, err := NewFlightClient(FlightClientConfig{Addr: addr, AdaptiveCompression: &AdaptiveCompression{MinThroughput: 1}})
+	assert.Error(t, err, "Adaptive compression needs a codec to downgrade")
+}
@@ -0,0 +1,35 @@
+package flight
+
+import (
+	"fmt"
+
+	"github.com/apache/arrow-go/v18/arrow/memory"
+)
+
+// Allocators selectable by name with FlightClientConfig.AllocatorType
+const (
+	AllocatorGo      = "go"
+	AllocatorCgo     = "cgo"
+	AllocatorChecked = "checked"
+)
+
+// newAllocator returns a new allocator of the named type
+func newAllocator(name string) (memory.Allocator, error) {
+	switch name {
+	case "", AllocatorGo:
+		return memory.NewGoAllocator(), nil
+	case AllocatorCgo:
+		return cgoAllocator()
+	case AllocatorChecked:
+		return memory.NewCheckedAllocator(memory.NewGoAllocator()), nil
+	default:
+		return nil, fmt.Errorf("unsupported allocator type %q (want %q, %q or %q)", name, AllocatorGo, AllocatorCgo, AllocatorChecked)
+	}
+}
+
+// Allocator returns the allocator the client decodes downloads with, as set
+// by FlightClientConfig.Allocator or AllocatorType. With the "checked" type it
+// is a *memory.CheckedAllocator, whose CurrentAlloc reports leaked buffers.
+func (c *FlightClient) Allocator() memory.Allocator {
+	return c.allocator
+}
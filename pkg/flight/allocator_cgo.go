@@ -0,0 +1,13 @@
+//go:build cgo
+
+package flight
+
+import (
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/arrow/memory/mallocator"
+)
+
+// cgoAllocator returns an allocator backed by C malloc
+func cgoAllocator() (memory.Allocator, error) {
+	return mallocator.NewMallocator(), nil
+}
@@ -0,0 +1,14 @@
+//go:build !cgo
+
+package flight
+
+import (
+	"fmt"
+
+	"github.com/apache/arrow-go/v18/arrow/memory"
+)
+
+// cgoAllocator fails, since the C allocator needs cgo
+func cgoAllocator() (memory.Allocator, error) {
+	return nil, fmt.Errorf("the %q allocator requires a cgo build", AllocatorCgo)
+}
@@ -0,0 +1,62 @@
+package flight
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAllocatorType tests that each allocator name selects a working allocator
+func TestAllocatorType(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+	batchID := server.StoreBatch(batch)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for _, name := range []string{"", AllocatorGo, AllocatorCgo, AllocatorChecked} {
+		t.Run("type "+name, func(t *testing.T) {
+			client, err := NewFlightClient(FlightClientConfig{Addr: addr, AllocatorType: name})
+			if name == AllocatorCgo && err != nil {
+				require.ErrorContains(t, err, "requires a cgo build")
+				t.Skip("cgo is disabled")
+			}
+			require.NoError(t, err, "Failed to create Flight client")
+			defer client.Close()
+
+			switch name {
+			case "", AllocatorGo:
+				assert.IsType(t, &memory.GoAllocator{}, client.Allocator())
+			case AllocatorChecked:
+				assert.IsType(t, &memory.CheckedAllocator{}, client.Allocator())
+			}
+
+			retrieved, err := client.GetBatch(ctx, batchID)
+			require.NoError(t, err, "Failed to get batch")
+			assert.Equal(t, batch.NumRows(), retrieved.NumRows())
+			retrieved.Release()
+
+			if checked, ok := client.Allocator().(*memory.CheckedAllocator); ok {
+				assert.Zero(t, checked.CurrentAlloc(), "Released downloads should not leak")
+			}
+		})
+	}
+
+	// An explicit allocator wins over the type
+	explicit := memory.NewGoAllocator()
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr, Allocator: explicit, AllocatorType: AllocatorChecked})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+	assert.Same(t, explicit, client.Allocator())
+
+	_, err = NewFlightClient(FlightClientConfig{Addr: addr, AllocatorType: "jemalloc"})
+	assert.ErrorContains(t, err, `unsupported allocator type "jemalloc"`)
+}
@@ -0,0 +1,107 @@
+package flight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/apache/arrow-go/v18/arrow"
+)
+
+// BatchSet holds the records of a bulk download, keyed by batch ID. The set
+// owns the records: a single Release frees them all, and records needed
+// beyond that must be retained by the caller. Builds with the debug tag log
+// a warning for sets garbage collected without being released.
+type BatchSet struct {
+	batches map[string]arrow.Record
+	mu      sync.Mutex
+}
+
+// newBatchSet takes ownership of batches
+func newBatchSet(batches map[string]arrow.Record) *BatchSet {
+	s := &BatchSet{batches: batches}
+	trackBatchSet(s)
+	return s
+}
+
+// Get returns the record of a batch, valid until the set is released
+func (s *BatchSet) Get(batchID string) (arrow.Record, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	batch, ok := s.batches[batchID]
+	return batch, ok
+}
+
+// IDs returns the IDs of the batches in the set, sorted
+func (s *BatchSet) IDs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]string, 0, len(s.batches))
+	for batchID := range s.batches {
+		ids = append(ids, batchID)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// Len returns the number of batches in the set
+func (s *BatchSet) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.batches)
+}
+
+// Release releases every record in the set. Later calls do nothing.
+func (s *BatchSet) Release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, batch := range s.batches {
+		batch.Release()
+	}
+	s.batches = nil
+	untrackBatchSet(s)
+}
+
+// GetBatches retrieves several batches concurrently. If any download fails,
+// the others are released and the error reports each failed batch. The
+// caller must Release the returned set.
+func (c *FlightClient) GetBatches(ctx context.Context, batchIDs []string) (*BatchSet, error) {
+	batchIDs = slices.Compact(slices.Sorted(slices.Values(batchIDs)))
+	batches := make([]arrow.Record, len(batchIDs))
+	errs := make([]error, len(batchIDs))
+
+	var wg sync.WaitGroup
+	for i, batchID := range batchIDs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			batch, err := c.GetBatch(ctx, batchID)
+			if err != nil {
+				errs[i] = fmt.Errorf("batch %s: %w", batchID, err)
+				return
+			}
+			batches[i] = batch
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		for _, batch := range batches {
+			if batch != nil {
+				batch.Release()
+			}
+		}
+		return nil, err
+	}
+
+	set := make(map[string]arrow.Record, len(batchIDs))
+	for i, batchID := range batchIDs {
+		set[batchID] = batches[i]
+	}
+	return newBatchSet(set), nil
+}
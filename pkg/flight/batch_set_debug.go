@@ -0,0 +1,22 @@
+//go:build debug
+
+package flight
+
+import (
+	"log"
+	"runtime"
+)
+
+// trackBatchSet warns, in debug builds, when a set is garbage collected
+// without having been released
+func trackBatchSet(s *BatchSet) {
+	count := len(s.batches)
+	runtime.SetFinalizer(s, func(*BatchSet) {
+		log.Printf("flight: BatchSet of %d batches was never released; its records leaked", count)
+	})
+}
+
+// untrackBatchSet stops watching a released set
+func untrackBatchSet(s *BatchSet) {
+	runtime.SetFinalizer(s, nil)
+}
@@ -0,0 +1,9 @@
+//go:build !debug
+
+package flight
+
+// trackBatchSet watches for leaked sets in debug builds only
+func trackBatchSet(*BatchSet) {}
+
+// untrackBatchSet watches for leaked sets in debug builds only
+func untrackBatchSet(*BatchSet) {}
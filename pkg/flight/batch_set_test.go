@@ -0,0 +1,52 @@
+package flight
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetBatches tests that a bulk download is owned and released through one BatchSet
+func TestGetBatches(t *testing.T) {
+	server, err := NewFlightServer(FlightServerConfig{})
+	require.NoError(t, err)
+	defer server.Stop()
+	addr := startBareServer(t, server)
+
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+	first := server.StoreBatch(batch)
+	second := server.StoreBatch(batch)
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr, Allocator: mem})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	set, err := client.GetBatches(ctx, []string{second, first, second})
+	require.NoError(t, err, "Failed to get batches")
+	assert.Equal(t, 2, set.Len(), "Duplicate IDs should be fetched once")
+
+	retrieved, ok := set.Get(first)
+	require.True(t, ok)
+	assert.Equal(t, batch.NumRows(), retrieved.NumRows())
+	_, ok = set.Get("missing")
+	assert.False(t, ok)
+
+	set.Release()
+	set.Release()
+	assert.Zero(t, set.Len(), "A released set should be empty")
+
+	// A failed download releases the batches already received
+	_, err = client.GetBatches(ctx, []string{first, "missing"})
+	assert.ErrorContains(t, err, "batch missing")
+}
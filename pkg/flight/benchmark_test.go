@@ -0,0 +1,161 @@
+package flight
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/util"
+
+	arrow_utils "github.com/TFMV/temporal/pkg/arrow"
+)
+
+// benchmarkRows are the batch sizes benchmarked, from small control records
+// to batches of a few tens of megabytes
+var benchmarkRows = []int{1_000, 100_000, 1_000_000}
+
+// startBenchmarkServer starts a Flight server for a benchmark
+func startBenchmarkServer(b *testing.B, config FlightServerConfig) string {
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		b.Fatalf("Failed to find available port: %v", err)
+	}
+	config.Addr = listener.Addr().String()
+	listener.Close()
+
+	server, err := NewFlightServer(config)
+	if err != nil {
+		b.Fatalf("Failed to create Flight server: %v", err)
+	}
+	go server.Start()
+	b.Cleanup(server.Stop)
+	time.Sleep(100 * time.Millisecond)
+	return config.Addr
+}
+
+// newBenchmarkClient creates a client for a benchmark
+func newBenchmarkClient(b *testing.B, config FlightClientConfig) *FlightClient {
+	client, err := NewFlightClient(config)
+	if err != nil {
+		b.Fatalf("Failed to create Flight client: %v", err)
+	}
+	b.Cleanup(func() { client.Close() })
+	return client
+}
+
+// BenchmarkPutBatch measures uploads across batch sizes and codecs
+func BenchmarkPutBatch(b *testing.B) {
+	addr := startBenchmarkServer(b, FlightServerConfig{TTL: time.Minute})
+	for _, rows := range benchmarkRows {
+		batch := arrow_utils.CreateSampleRecordBatch(rows, nil)
+		for _, codec := range []string{CompressionNone, CompressionLZ4, CompressionZstd} {
+			b.Run(fmt.Sprintf("rows=%d/codec=%s", rows, codec), func(b *testing.B) {
+				client := newBenchmarkClient(b, FlightClientConfig{Addr: addr, Compression: codec})
+				ctx := context.Background()
+				b.SetBytes(util.TotalRecordSize(batch))
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					batchID, err := client.PutBatch(ctx, batch)
+					if err != nil {
+						b.Fatalf("Failed to put batch: %v", err)
+					}
+					b.StopTimer()
+					client.doAction(ctx, ActionDropBatch, []byte(batchID))
+					b.StartTimer()
+				}
+			})
+		}
+		batch.Release()
+	}
+}
+
+// BenchmarkGetBatch measures downloads across batch sizes and codecs. The
+// server sends batches as they were uploaded, so the codec is chosen by the
+// uploading client.
+func BenchmarkGetBatch(b *testing.B) {
+	addr := startBenchmarkServer(b, FlightServerConfig{TTL: time.Minute})
+	for _, rows := range benchmarkRows {
+		batch := arrow_utils.CreateSampleRecordBatch(rows, nil)
+		for _, codec := range []string{CompressionNone, CompressionLZ4, CompressionZstd} {
+			b.Run(fmt.Sprintf("rows=%d/codec=%s", rows, codec), func(b *testing.B) {
+				client := newBenchmarkClient(b, FlightClientConfig{Addr: addr, Compression: codec})
+				benchmarkGet(b, client, batch)
+			})
+		}
+		batch.Release()
+	}
+}
+
+// BenchmarkGetBatchAllocator measures the cost of each allocator on large downloads
+func BenchmarkGetBatchAllocator(b *testing.B) {
+	addr := startBenchmarkServer(b, FlightServerConfig{TTL: time.Minute})
+	batch := arrow_utils.CreateSampleRecordBatch(1_000_000, nil)
+	defer batch.Release()
+	for _, allocator := range []string{AllocatorGo, AllocatorCgo, AllocatorChecked} {
+		b.Run("allocator="+allocator, func(b *testing.B) {
+			client, err := NewFlightClient(FlightClientConfig{Addr: addr, AllocatorType: allocator})
+			if err != nil {
+				b.Skipf("Allocator unavailable: %v", err)
+			}
+			defer client.Close()
+			benchmarkGet(b, client, batch)
+		})
+	}
+}
+
+// BenchmarkGetBatchWindowSize measures large downloads with fixed HTTP/2
+// flow control windows against gRPC's dynamic sizing (window=0)
+func BenchmarkGetBatchWindowSize(b *testing.B) {
+	batch := arrow_utils.CreateSampleRecordBatch(1_000_000, nil)
+	defer batch.Release()
+	for _, window := range []int32{0, 1 << 20, 16 << 20} {
+		b.Run(fmt.Sprintf("window=%d", window), func(b *testing.B) {
+			addr := startBenchmarkServer(b, FlightServerConfig{
+				TTL:                   time.Minute,
+				InitialWindowSize:     window,
+				InitialConnWindowSize: window,
+			})
+			client := newBenchmarkClient(b, FlightClientConfig{
+				Addr:                  addr,
+				InitialWindowSize:     window,
+				InitialConnWindowSize: window,
+			})
+			benchmarkGet(b, client, batch)
+		})
+	}
+}
+
+// BenchmarkGetBatchChunked measures large downloads split by the server into
+// record batches of at most ChunkRows rows, which bounds the message size
+func BenchmarkGetBatchChunked(b *testing.B) {
+	batch := arrow_utils.CreateSampleRecordBatch(1_000_000, nil)
+	defer batch.Release()
+	for _, chunkRows := range []int64{0, 16_384, 131_072} {
+		b.Run(fmt.Sprintf("chunk=%d", chunkRows), func(b *testing.B) {
+			addr := startBenchmarkServer(b, FlightServerConfig{TTL: time.Minute, ChunkRows: chunkRows})
+			client := newBenchmarkClient(b, FlightClientConfig{Addr: addr})
+			benchmarkGet(b, client, batch)
+		})
+	}
+}
+
+// benchmarkGet uploads batch once and measures downloading it
+func benchmarkGet(b *testing.B, client *FlightClient, batch arrow.Record) {
+	ctx := context.Background()
+	batchID, err := client.PutBatch(ctx, batch)
+	if err != nil {
+		b.Fatalf("Failed to put batch: %v", err)
+	}
+	b.SetBytes(util.TotalRecordSize(batch))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		retrieved, err := client.GetBatch(ctx, batchID)
+		if err != nil {
+			b.Fatalf("Failed to get batch: %v", err)
+		}
+		retrieved.Release()
+	}
+}
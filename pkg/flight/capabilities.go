@@ -0,0 +1,203 @@
+package flight
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/flight"
+)
+
+// ActionCapabilities is the DoAction type used to discover the optional
+// features a server supports. The result body is a JSON-encoded
+// ServerCapabilities.
+const ActionCapabilities = "capabilities"
+
+// ServerCapabilities describes the optional features a Flight server supports
+type ServerCapabilities struct {
+	// Actions lists the custom DoAction types the server implements
+	Actions []string `json:"actions"`
+	// Compression lists the IPC codecs the server decodes in uploads
+	Compression []string `json:"compression"`
+	// TTL is how long the server keeps a stored batch (0 if unknown)
+	TTL time.Duration `json:"ttl,omitempty"`
+	// ClientBatchIDs reports whether uploads may name their batch
+	// (FlightClientConfig.IDGenerator)
+	ClientBatchIDs bool `json:"clientBatchIds,omitempty"`
+	// Lineage reports whether uploads may record their parent batches
+	Lineage bool `json:"lineage,omitempty"`
+	// DeltaUploads reports whether PutDelta is supported
+	DeltaUploads bool `json:"deltaUploads,omitempty"`
+	// StreamedUploads reports whether PutStream and PutStructStream are supported
+	StreamedUploads bool `json:"streamedUploads,omitempty"`
+	// ResumableDownloads reports whether interrupted downloads can resume
+	// from an offset (FlightClientConfig.ResumeAttempts)
+	ResumableDownloads bool `json:"resumableDownloads,omitempty"`
+	// AccessPolicies reports whether uploads may restrict who reads them
+	// (PutOptions.Access)
+	AccessPolicies bool `json:"accessPolicies,omitempty"`
+	// ContentDedup reports whether identical uploads are stored once
+	// (PutOptions.DedupeByContent)
+	ContentDedup bool `json:"contentDedup,omitempty"`
+	// KeyDedup reports whether uploads can be upserted by key columns
+	// (PutOptions.DedupKeys)
+	KeyDedup bool `json:"keyDedup,omitempty"`
+	// ListFilters reports whether ListFlights criteria built by ListFilter
+	// filter the listing (ListOptions.Criteria)
+	ListFilters bool `json:"listFilters,omitempty"`
+	// ProjectedDownloads reports whether downloads send only the requested
+	// columns (GetBatchProjected)
+	ProjectedDownloads bool `json:"projectedDownloads,omitempty"`
+	// ConditionalNames reports whether name updates can be made conditional
+	// on the name's ETag (NameOptions.IfMatch)
+	ConditionalNames bool `json:"conditionalNames,omitempty"`
+	// Transactions reports whether uploads can be staged and committed
+	// together (BeginTx)
+	Transactions bool `json:"transactions,omitempty"`
+	// SessionCommands lists the commands accepted over OpenSession, empty if
+	// sessions are not supported
+	SessionCommands []string `json:"sessionCommands,omitempty"`
+	// Transforms lists the transforms GetBatchWithPipeline can apply
+	Transforms []string `json:"transforms,omitempty"`
+}
+
+// HasAction reports whether the server implements a custom DoAction type
+func (c ServerCapabilities) HasAction(action string) bool {
+	return slices.Contains(c.Actions, action)
+}
+
+// serverCapabilities reports the features of this server
+func (s *FlightServer) serverCapabilities(stream flight.FlightService_DoActionServer) error {
+	capabilities := ServerCapabilities{
+		Compression:        []string{CompressionNone, CompressionLZ4, CompressionZstd},
+		TTL:                s.ttl,
+		ClientBatchIDs:     true,
+		Lineage:            true,
+		DeltaUploads:       true,
+		StreamedUploads:    true,
+		ResumableDownloads: true,
+		AccessPolicies:     true,
+		ContentDedup:       true,
+		ListFilters:        true,
+		ProjectedDownloads: true,
+		ConditionalNames:   true,
+		Transactions:       true,
+	}
+	for _, action := range serverActions {
+		capabilities.Actions = append(capabilities.Actions, action.Type)
+	}
+	for command := range s.sessionCommands {
+		capabilities.SessionCommands = append(capabilities.SessionCommands, command)
+	}
+	slices.Sort(capabilities.SessionCommands)
+	for name := range s.transforms {
+		capabilities.Transforms = append(capabilities.Transforms, name)
+	}
+	slices.Sort(capabilities.Transforms)
+
+	body, err := json.Marshal(capabilities)
+	if err != nil {
+		return fmt.Errorf("failed to encode capabilities: %w", err)
+	}
+	return stream.Send(&flight.Result{Body: body})
+}
+
+// Capabilities asks the server which optional features it supports.
+// ErrNotSupported is returned if the server predates capability discovery.
+// The first successful answer is cached, until the client fails over to
+// another address, and used by helpers such as PutDelta and PutStream to fail
+// fast with ErrNotSupported on servers lacking the feature they rely on.
+func (c *FlightClient) Capabilities(ctx context.Context) (ServerCapabilities, error) {
+	body, err := c.doAction(ctx, ActionCapabilities, nil)
+	if err != nil {
+		return ServerCapabilities{}, fmt.Errorf("failed to get server capabilities: %w", err)
+	}
+
+	var capabilities ServerCapabilities
+	if err := json.Unmarshal(body, &capabilities); err != nil {
+		return ServerCapabilities{}, fmt.Errorf("failed to decode server capabilities: %w", err)
+	}
+
+	c.capabilitiesMu.Lock()
+	c.capabilities, c.capabilitiesChecked = &capabilities, true
+	c.capabilitiesMu.Unlock()
+	return capabilities, nil
+}
+
+// requireFeature returns an ErrNotSupported error naming feature if the
+// server's capabilities say it lacks it. Capabilities are fetched once per
+// client; if the server cannot report them, the feature is assumed present
+// and the call itself detects missing support.
+func (c *FlightClient) requireFeature(ctx context.Context, feature string, supported func(ServerCapabilities) bool) error {
+	c.capabilitiesMu.Lock()
+	capabilities, checked := c.capabilities, c.capabilitiesChecked
+	c.capabilitiesMu.Unlock()
+
+	if !checked {
+		fetched, err := c.Capabilities(ctx)
+		if errors.Is(err, ErrNotSupported) {
+			c.capabilitiesMu.Lock()
+			c.capabilitiesChecked = true
+			c.capabilitiesMu.Unlock()
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		capabilities = &fetched
+	}
+
+	if capabilities != nil && !supported(*capabilities) {
+		return fmt.Errorf("%w: %s", ErrNotSupported, feature)
+	}
+	return nil
+}
+
+// ListActions returns the action types the server advertises through the
+// Flight ListActions method, including the standard and custom actions it
+// implements. With CacheActions set, the first successful answer is returned
+// by later calls without asking the server again.
+func (c *FlightClient) ListActions(ctx context.Context) ([]*flight.ActionType, error) {
+	if c.cacheActions {
+		c.actionsMu.Lock()
+		actions := c.actions
+		c.actionsMu.Unlock()
+		if actions != nil {
+			return actions, nil
+		}
+	}
+
+	client, release, err := c.acquire(ctx)
+	if err != nil {
+		return nil, labelError(ctx, err)
+	}
+	defer release()
+
+	stream, err := client.ListActions(ctx, &flight.Empty{})
+	if err != nil {
+		return nil, labelError(ctx, fmt.Errorf("failed to start ListActions stream: %w", err))
+	}
+
+	actions := []*flight.ActionType{}
+	for {
+		action, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, labelError(ctx, fmt.Errorf("error receiving action type: %w", err))
+		}
+		actions = append(actions, action)
+	}
+
+	if c.cacheActions {
+		c.actionsMu.Lock()
+		c.actions = actions
+		c.actionsMu.Unlock()
+	}
+	return actions, nil
+}
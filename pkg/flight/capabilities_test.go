@@ -0,0 +1,164 @@
+package flight
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCapabilities tests that the server reports its optional features
+func TestCapabilities(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	capabilities, err := client.Capabilities(ctx)
+	require.NoError(t, err, "Failed to get capabilities")
+	assert.True(t, capabilities.DeltaUploads)
+	assert.True(t, capabilities.StreamedUploads)
+	assert.True(t, capabilities.ContentDedup)
+	assert.True(t, capabilities.ListFilters)
+	assert.True(t, capabilities.ProjectedDownloads)
+	assert.True(t, capabilities.Transactions)
+	assert.True(t, capabilities.ConditionalNames)
+	assert.True(t, capabilities.HasAction(ActionCapabilities))
+	assert.True(t, capabilities.HasAction(ActionSwapName))
+	assert.Contains(t, capabilities.Compression, CompressionZstd)
+	assert.Contains(t, capabilities.SessionCommands, SessionCommandGet)
+	assert.Equal(t, 5*time.Minute, capabilities.TTL, "The test server keeps batches for 5 minutes")
+}
+
+// TestCapabilitiesNotSupported tests that servers without capability discovery yield ErrNotSupported
+func TestCapabilitiesNotSupported(t *testing.T) {
+	addr := startBareServer(t, &flight.BaseFlightServer{})
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = client.Capabilities(ctx)
+	assert.ErrorIs(t, err, ErrNotSupported)
+}
+
+// limitedServer is a Flight server reporting no optional features and counting
+// the uploads it receives
+type limitedServer struct {
+	flight.BaseFlightServer
+	puts atomic.Int32
+}
+
+// DoAction answers the capabilities action with an empty feature set
+func (s *limitedServer) DoAction(action *flight.Action, stream flight.FlightService_DoActionServer) error {
+	return stream.Send(&flight.Result{Body: []byte(`{"actions":["capabilities"]}`)})
+}
+
+// DoPut counts the upload and rejects it
+func (s *limitedServer) DoPut(stream flight.FlightService_DoPutServer) error {
+	s.puts.Add(1)
+	return nil
+}
+
+// TestCapabilitiesFailFast tests that helpers refuse features the server reports it lacks without uploading
+func TestCapabilitiesFailFast(t *testing.T) {
+	server := &limitedServer{}
+	addr := startBareServer(t, server)
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	_, err = client.PutDelta(ctx, "base", batch, nil, nil, DeltaOptions{Keys: []string{"id"}})
+	assert.ErrorIs(t, err, ErrNotSupported)
+
+	batch.Retain()
+	_, err = client.PutStream(ctx, sendRecords(batch), PutStreamOptions{})
+	assert.ErrorIs(t, err, ErrNotSupported)
+
+	assert.Zero(t, server.puts.Load(), "Nothing should be uploaded")
+}
+
+// TestListActions tests that the server's custom actions are listed
+func TestListActions(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	actions, err := client.ListActions(ctx)
+	require.NoError(t, err, "Failed to list actions")
+	require.Len(t, actions, len(serverActions))
+
+	types := make([]string, len(actions))
+	for i, action := range actions {
+		types[i] = action.Type
+		assert.NotEmpty(t, action.Description)
+	}
+	assert.Contains(t, types, ActionCapabilities)
+	assert.Contains(t, types, ActionNullCounts)
+}
+
+// countingActionsServer is a Flight server counting its ListActions calls
+type countingActionsServer struct {
+	flight.BaseFlightServer
+	calls atomic.Int32
+}
+
+// ListActions advertises a single action
+func (s *countingActionsServer) ListActions(request *flight.Empty, stream flight.FlightService_ListActionsServer) error {
+	s.calls.Add(1)
+	return stream.Send(&flight.ActionType{Type: "compact", Description: "Compact the store"})
+}
+
+// TestListActionsCache tests that cached action lists are fetched once
+func TestListActionsCache(t *testing.T) {
+	server := &countingActionsServer{}
+	addr := startBareServer(t, server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for _, cache := range []bool{true, false} {
+		server.calls.Store(0)
+		client, err := NewFlightClient(FlightClientConfig{Addr: addr, CacheActions: cache})
+		require.NoError(t, err, "Failed to create Flight client")
+
+		for i := 0; i < 3; i++ {
+			actions, err := client.ListActions(ctx)
+			require.NoError(t, err, "Failed to list actions")
+			require.Len(t, actions, 1)
+			assert.Equal(t, "compact", actions[0].Type)
+		}
+		if cache {
+			assert.Equal(t, int32(1), server.calls.Load(), "The cached list should be reused")
+		} else {
+			assert.Equal(t, int32(3), server.calls.Load(), "Every call should ask the server")
+		}
+		client.Close()
+	}
+}
@@ -0,0 +1,172 @@
+package flight
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+
+	"github.com/apache/arrow-go/v18/arrow/flight"
+)
+
+// CatalogEntry describes one stored batch, as listed by ListCatalog and
+// written by ExportCatalog
+type CatalogEntry struct {
+	BatchID string `json:"batchId"`
+	// Schema is the batch schema serialized as an Arrow IPC schema message,
+	// readable with flight.DeserializeSchema
+	Schema []byte `json:"schema"`
+	// Fields is a readable rendering of the schema's top-level fields
+	Fields []CatalogField `json:"fields"`
+	// Metadata holds the schema's key/value metadata
+	Metadata     map[string]string `json:"metadata,omitempty"`
+	TotalRecords int64             `json:"totalRecords"`
+	// TotalBytes is the size of the batch's Arrow buffers, or -1 if the server
+	// does not report it
+	TotalBytes int64 `json:"totalBytes"`
+	// Endpoints are where the batch can be fetched from
+	Endpoints []BatchEndpoint `json:"endpoints,omitempty"`
+	// Tags are the schema's key/value metadata, overlaid with any tags the
+	// server attached to the listing as {"tags": {...}} JSON AppMetadata
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// BatchEndpoint is one place a listed batch can be fetched from
+type BatchEndpoint struct {
+	// Ticket is the DoGet ticket of the endpoint
+	Ticket []byte `json:"ticket"`
+	// Locations are the URIs of the services holding the data; empty means
+	// the service that listed the batch
+	Locations []string `json:"locations,omitempty"`
+}
+
+// catalogInfoMetadata is the JSON AppMetadata a server can attach to a
+// listed FlightInfo to tag the batch
+type catalogInfoMetadata struct {
+	Tags map[string]string `json:"tags"`
+}
+
+// CatalogField describes a single schema field in a catalog export
+type CatalogField struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Nullable bool   `json:"nullable"`
+}
+
+// CatalogSummary totals a catalog export
+type CatalogSummary struct {
+	Batches      int
+	TotalRecords int64
+	// TotalBytes sums the entries that report their size
+	TotalBytes int64
+}
+
+// ExportCatalog writes a snapshot of every batch stored on the server to w as
+// JSON lines, one CatalogEntry per line
+func (c *FlightClient) ExportCatalog(ctx context.Context, w io.Writer) error {
+	_, err := c.ExportCatalogWithSummary(ctx, w)
+	return err
+}
+
+// ExportCatalogWithSummary is ExportCatalog, also returning the totals of the
+// exported entries. Entries are written as they are listed, so the catalog is
+// never held in memory; if w implements Flusher it is flushed after every entry.
+func (c *FlightClient) ExportCatalogWithSummary(ctx context.Context, w io.Writer) (CatalogSummary, error) {
+	flusher, _ := w.(Flusher)
+	encoder := json.NewEncoder(w)
+
+	var summary CatalogSummary
+	err := c.listFlights(ctx, nil, func(info *flight.FlightInfo) error {
+		entry, err := c.catalogEntry(info)
+		if err != nil {
+			return err
+		}
+		if err := encoder.Encode(entry); err != nil {
+			return fmt.Errorf("failed to write catalog entry for batch %s: %w", entry.BatchID, err)
+		}
+		if flusher != nil {
+			if err := flusher.Flush(); err != nil {
+				return fmt.Errorf("failed to flush catalog: %w", err)
+			}
+		}
+
+		summary.Batches++
+		summary.TotalRecords += entry.TotalRecords
+		if entry.TotalBytes > 0 {
+			summary.TotalBytes += entry.TotalBytes
+		}
+		return nil
+	})
+	return summary, err
+}
+
+// ListCatalog lists the batches matching a ListFlights criteria expression
+// with everything their FlightInfo tells about them, for browsing staged
+// data. A nil criteria lists every batch. Unlike ExportCatalog the entries
+// are held in memory; ListBatches returns just the IDs.
+func (c *FlightClient) ListCatalog(ctx context.Context, criteria []byte) ([]CatalogEntry, error) {
+	var entries []CatalogEntry
+	err := c.listFlights(ctx, criteria, func(info *flight.FlightInfo) error {
+		entry, err := c.catalogEntry(info)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// catalogEntry converts a FlightInfo into a catalog entry
+func (c *FlightClient) catalogEntry(info *flight.FlightInfo) (CatalogEntry, error) {
+	batchID := string(info.GetFlightDescriptor().GetCmd())
+
+	entry := CatalogEntry{
+		BatchID:      batchID,
+		Schema:       info.Schema,
+		TotalRecords: info.TotalRecords,
+		TotalBytes:   info.TotalBytes,
+	}
+	if len(info.Schema) > 0 {
+		schema, err := flight.DeserializeSchema(info.Schema, c.allocator)
+		if err != nil {
+			return CatalogEntry{}, fmt.Errorf("failed to decode schema of batch %s: %w", batchID, err)
+		}
+		for _, field := range schema.Fields() {
+			entry.Fields = append(entry.Fields, CatalogField{
+				Name:     field.Name,
+				Type:     field.Type.String(),
+				Nullable: field.Nullable,
+			})
+		}
+		if md := schema.Metadata(); md.Len() > 0 {
+			entry.Metadata = make(map[string]string, md.Len())
+			for i, key := range md.Keys() {
+				entry.Metadata[key] = md.Values()[i]
+			}
+			entry.Tags = maps.Clone(entry.Metadata)
+		}
+	}
+
+	// Metadata that is not JSON tags, such as a page cursor, is not a tag
+	var meta catalogInfoMetadata
+	if len(info.AppMetadata) > 0 && json.Unmarshal(info.AppMetadata, &meta) == nil && len(meta.Tags) > 0 {
+		if entry.Tags == nil {
+			entry.Tags = make(map[string]string, len(meta.Tags))
+		}
+		maps.Copy(entry.Tags, meta.Tags)
+	}
+
+	for _, endpoint := range info.Endpoint {
+		e := BatchEndpoint{Ticket: endpoint.GetTicket().GetTicket()}
+		for _, location := range endpoint.Location {
+			e.Locations = append(e.Locations, location.Uri)
+		}
+		entry.Endpoints = append(entry.Endpoints, e)
+	}
+	return entry, nil
+}
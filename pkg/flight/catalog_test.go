@@ -0,0 +1,144 @@
+package flight
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExportCatalog tests that a catalog export describes every stored batch
+func TestExportCatalog(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ids := make(map[string]bool)
+	for i := 0; i < 3; i++ {
+		batchID, err := client.PutBatch(ctx, batch)
+		require.NoError(t, err, "Failed to put batch")
+		ids[batchID] = true
+	}
+
+	var buf bytes.Buffer
+	summary, err := client.ExportCatalogWithSummary(ctx, &buf)
+	require.NoError(t, err, "Failed to export catalog")
+	assert.Equal(t, 3, summary.Batches)
+	assert.Equal(t, 3*batch.NumRows(), summary.TotalRecords)
+	assert.Positive(t, summary.TotalBytes)
+
+	decoder := json.NewDecoder(&buf)
+	for decoder.More() {
+		var entry CatalogEntry
+		require.NoError(t, decoder.Decode(&entry), "Catalog lines should be valid JSON")
+		assert.True(t, ids[entry.BatchID], "Unexpected batch %s in catalog", entry.BatchID)
+		delete(ids, entry.BatchID)
+
+		assert.Equal(t, batch.NumRows(), entry.TotalRecords)
+		assert.Positive(t, entry.TotalBytes)
+		require.Len(t, entry.Fields, 3)
+		assert.Equal(t, CatalogField{Name: "name", Type: "utf8", Nullable: false}, entry.Fields[1])
+
+		schema, err := flight.DeserializeSchema(entry.Schema, memory.NewGoAllocator())
+		require.NoError(t, err, "Exported schema should deserialize")
+		assert.True(t, schema.Equal(batch.Schema()))
+	}
+	assert.Empty(t, ids, "Every batch should be exported")
+}
+
+// taggedListServer is a Flight server listing one batch with tags in its AppMetadata
+type taggedListServer struct {
+	flight.BaseFlightServer
+	schema *arrow.Schema
+}
+
+// ListFlights sends the batch, served from two locations
+func (s *taggedListServer) ListFlights(request *flight.Criteria, stream flight.FlightService_ListFlightsServer) error {
+	return stream.Send(&flight.FlightInfo{
+		Schema:           flight.SerializeSchema(s.schema, memory.NewGoAllocator()),
+		FlightDescriptor: &flight.FlightDescriptor{Type: flight.DescriptorCMD, Cmd: []byte("sales")},
+		Endpoint: []*flight.FlightEndpoint{{
+			Ticket:   &flight.Ticket{Ticket: []byte("sales")},
+			Location: []*flight.Location{{Uri: "grpc://a:8815"}, {Uri: "grpc://b:8815"}},
+		}},
+		TotalRecords: 10,
+		TotalBytes:   -1,
+		AppMetadata:  []byte(`{"tags": {"owner": "finance", "stage": "raw"}}`),
+	})
+}
+
+// TestListCatalog tests that catalog entries carry the details of each listed batch
+func TestListCatalog(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+	md := arrow.NewMetadata([]string{"stage"}, []string{"curated"})
+	tagged := array.NewRecord(arrow.NewSchema(batch.Schema().Fields(), &md), batch.Columns(), batch.NumRows())
+	defer tagged.Release()
+	batchID, err := client.PutBatch(ctx, tagged)
+	require.NoError(t, err, "Failed to put batch")
+
+	entries, err := client.ListCatalog(ctx, nil)
+	require.NoError(t, err, "Failed to list catalog")
+	require.Len(t, entries, 1)
+	entry := entries[0]
+	assert.Equal(t, batchID, entry.BatchID)
+	schema, err := flight.DeserializeSchema(entry.Schema, memory.NewGoAllocator())
+	require.NoError(t, err, "Entry schema should deserialize")
+	assert.True(t, schema.Equal(tagged.Schema()))
+	assert.Equal(t, map[string]string{"stage": "curated"}, entry.Metadata)
+	assert.Equal(t, batch.NumRows(), entry.TotalRecords)
+	assert.Positive(t, entry.TotalBytes)
+	assert.Equal(t, map[string]string{"stage": "curated"}, entry.Tags, "Schema metadata should become tags")
+	require.Len(t, entry.Endpoints, 1)
+	assert.Equal(t, []string{"grpc://" + addr}, entry.Endpoints[0].Locations)
+
+	// Paging criteria pass through, and the page cursor is not a tag
+	expression, err := json.Marshal(listCriteria{PageSize: 1})
+	require.NoError(t, err)
+	_, err = client.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to put batch")
+	entries, err = client.ListCatalog(ctx, expression)
+	require.NoError(t, err, "Failed to list catalog")
+	require.Len(t, entries, 1)
+	assert.NotContains(t, entries[0].Tags, "nextCursor")
+
+	// Tags the server attaches override the schema's
+	other := startBareServer(t, &taggedListServer{schema: tagged.Schema()})
+	otherClient, err := NewFlightClient(FlightClientConfig{Addr: other})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer otherClient.Close()
+	entries, err = otherClient.ListCatalog(ctx, nil)
+	require.NoError(t, err, "Failed to list catalog")
+	require.Len(t, entries, 1)
+	assert.Equal(t, map[string]string{"owner": "finance", "stage": "raw"}, entries[0].Tags)
+	assert.Equal(t, map[string]string{"stage": "curated"}, entries[0].Metadata, "Server tags do not change the schema metadata")
+	assert.Equal(t, int64(-1), entries[0].TotalBytes)
+	assert.Equal(t, []BatchEndpoint{{Ticket: []byte("sales"), Locations: []string{"grpc://a:8815", "grpc://b:8815"}}}, entries[0].Endpoints)
+}
@@ -0,0 +1,45 @@
+//go:build cgo
+
+package flight
+
+import (
+	"context"
+
+	"github.com/apache/arrow-go/v18/arrow/cdata"
+)
+
+// GetBatchCArray retrieves a batch and exports it over the Arrow C Data
+// Interface, as a struct array whose children are the batch's columns, for
+// zero-copy consumption by a foreign runtime such as Arrow C++ or PyArrow.
+// It is only available in cgo builds.
+//
+// Ownership of both returned structs passes to the caller:
+//
+//   - Exactly one release must happen for each struct, either by a consumer
+//     that imported it (such as pyarrow.RecordBatch._import_from_c, which
+//     moves the struct and releases it when the imported batch is freed), or
+//     by calling cdata.ReleaseCArrowArray and cdata.ReleaseCArrowSchema. An
+//     unreleased array keeps the batch's buffers alive and leaks them.
+//   - The structs are allocated by Go and zeroed as the interface requires.
+//     Consumers must move them (copy the struct and mark the source released)
+//     rather than keep the pointers, which Go may free once unreferenced.
+//   - The array's buffers are shared with the downloaded batch, not copied,
+//     and are read-only. They live in Go memory, which stays valid until
+//     release because the export holds a reference to it; see
+//     cdata.ExportArrowRecordBatch for the rules on passing Go memory to C.
+//
+// The schema describes the batch as a struct type carrying the batch's
+// schema metadata, the usual way record batches cross the interface.
+func (c *FlightClient) GetBatchCArray(ctx context.Context, batchID string) (*cdata.CArrowArray, *cdata.CArrowSchema, error) {
+	batch, err := c.GetBatch(ctx, batchID)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The export holds its own reference to the batch's buffers
+	defer batch.Release()
+
+	array := new(cdata.CArrowArray)
+	schema := new(cdata.CArrowSchema)
+	cdata.ExportArrowRecordBatch(batch, array, schema)
+	return array, schema, nil
+}
@@ -0,0 +1,48 @@
+//go:build cgo
+
+package flight
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/cdata"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetBatchCArray tests that an exported batch imports back over the C Data Interface unchanged
+func TestGetBatchCArray(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	batchID, err := client.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to put batch")
+
+	carray, cschema, err := client.GetBatchCArray(ctx, batchID)
+	require.NoError(t, err, "Failed to export batch")
+
+	// Importing moves both structs, releasing them when the record is released
+	imported, err := cdata.ImportCRecordBatch(carray, cschema)
+	require.NoError(t, err, "Failed to import batch")
+	defer imported.Release()
+
+	assert.True(t, imported.Schema().Equal(batch.Schema()), "Schema should survive the round trip")
+	assert.True(t, array.RecordEqual(batch, imported), "Data should survive the round trip")
+
+	_, _, err = client.GetBatchCArray(ctx, "missing")
+	assert.Error(t, err)
+}
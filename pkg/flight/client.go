@@ -2,39 +2,440 @@ package flight
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
 	"github.com/apache/arrow-go/v18/arrow/flight"
 	"github.com/apache/arrow-go/v18/arrow/ipc"
 	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/arrow/util"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+
+	arrow_utils "github.com/TFMV/temporal/pkg/arrow"
+)
+
+// Supported IPC compression codecs for uploads
+const (
+	CompressionNone = "none"
+	CompressionLZ4  = "lz4"
+	CompressionZstd = "zstd"
 )
 
+// defaultMaxMessageSize is the default largest gRPC message of clients and
+// servers, and so of a record batch
+const defaultMaxMessageSize = 64 * 1024 * 1024
+
 // FlightClient is a client for the Arrow Flight server
 type FlightClient struct {
-	client    flight.Client
-	addr      string
-	allocator memory.Allocator
-	conn      *grpc.ClientConn
+	config          FlightClientConfig // With defaults applied, for clients of other servers
+	client          flight.Client      // nil while the connection is closed for idleness
+	addr            string             // Active address, one of addrs
+	allocator       memory.Allocator
+	conn            *grpc.ClientConn
+	compression     string
+	ipc             IPCOptions
+	metrics         Metrics
+	idGenerator     IDGenerator
+	acceptEOFResult bool
+	scheduler       *callScheduler  // Limits concurrent calls; nil if unlimited
+	streams         streamLimiter   // Limits concurrent streams; nil if unlimited
+	root            context.Context // Aborts every call when done; nil if unset
+	stopRoot        func() bool     // Stops closing the client when root ends
+	adaptiveTimeout *AdaptiveTimeout
+	resumeAttempts  int
+	resumePerBatch  bool
+	dialOpts        []grpc.DialOption
+	pool            *ConnPool // Shares the connection with other clients, if set
+	poolKey         connKey   // Identifies the connections this client can share
+	idleTimeout     time.Duration
+	idleTimer       *time.Timer
+	inFlight        int       // Number of operations currently using the connection
+	lastUsed        time.Time // When the last operation finished
+	closed          bool
+	done            chan struct{} // Closed when the client is closed
+	closeOnce       sync.Once
+	connMu          sync.Mutex
+
+	// Primary address followed by the failover addresses, the index of the
+	// active one, and connections still used by calls started before a
+	// failover
+	addrs            []string
+	active           int
+	retired          []flight.Client
+	failbackInterval time.Duration
+	failbackTimer    *time.Timer
+
+	// Server capabilities cached by Capabilities; nil once checked if the
+	// server cannot report them
+	capabilities        *ServerCapabilities
+	capabilitiesChecked bool
+	capabilitiesMu      sync.Mutex
+
+	// Action types cached by ListActions when CacheActions is set
+	cacheActions bool
+	actions      []*flight.ActionType
+	actionsMu    sync.Mutex
+
+	// Effective upload codec; nil unless AdaptiveCompression is set
+	adaptiveCompression *compressionState
+
+	// Validates and registers upload schemas; nil if unset
+	schemaRegistry SchemaRegistry
+
+	// Checked against every uploaded record
+	constraints []arrow_utils.Constraint
+
+	// Recent transfer rates, for EstimateTransfer
+	throughput throughputTracker
+
+	// Signs DoGet tickets; nil if unset
+	ticketSigner TicketSigner
+
+	slowCallThreshold time.Duration
+	onSlowCall        func(SlowCall)
 }
 
 // FlightClientConfig contains configuration options for the Flight client
 type FlightClientConfig struct {
 	// Address to connect to (e.g., "localhost:8080")
 	Addr string
+	// Context, if set, is the root context of the client: when it ends, every
+	// outstanding call is aborted and the client is closed, so later calls
+	// fail with ErrClientClosed. Each call can still be bounded by its own
+	// context.
+	Context context.Context
 	// Memory allocator to use
 	Allocator memory.Allocator
+	// AllocatorType selects the allocator by name when Allocator is unset, so
+	// it can be set from a config file: "go" (default), "cgo" for C malloc,
+	// only in cgo builds, or "checked" for a Go allocator wrapped in a
+	// memory.CheckedAllocator to track leaks
+	AllocatorType string
+	// IPC compression codec for uploads: "none" (default), "lz4" or "zstd".
+	// Downloads are decoded with whichever codec the server used.
+	Compression string
+	// AdaptiveCompression, if set, downgrades Compression to a cheaper codec
+	// when encoding proves too slow; see EffectiveCompression
+	AdaptiveCompression *AdaptiveCompression
+	// AdaptiveTimeout, if set, bounds every PutBatch and GetBatch (including
+	// streamed reads) by a deadline scaled to the size of the batch. Uploads
+	// are sized from the record; downloads from the size the server reports
+	// in GetFlightInfo, and get no adaptive deadline if it reports none.
+	AdaptiveTimeout *AdaptiveTimeout
+	// DeadlineHint sends the time remaining before each call's deadline in
+	// the DeadlineHintHeader metadata, read on the server with DeadlineHint,
+	// so handlers such as DoAction can budget their work. gRPC always
+	// sends the deadline itself in the standard grpc-timeout header, which
+	// cancels the server's context when it expires; the hint is for
+	// servers that want the remaining time explicitly.
+	DeadlineHint bool
+	// CacheActions makes ListActions ask the server once and return the
+	// same list afterwards, until the client fails over to another address
+	CacheActions bool
+	// MaxConcurrentCalls, if positive, limits the number of calls (including
+	// open streams and sessions) in flight at once across every goroutine
+	// using the client. Each operation holds a slot from its start until it
+	// completes, or until its stream or session is closed. Calls beyond the
+	// limit wait until a slot frees up or their context ends, and are
+	// admitted by the priority set with WithPriority. ActiveCalls and
+	// QueuedCalls report the current usage. Clients sharing a ConnPool
+	// connection are limited separately.
+	MaxConcurrentCalls int
+	// MaxConcurrentStreams, if positive, caps the operations the client has
+	// open at once, for servers that limit concurrent streams per client.
+	// Every Put, Get, List and other operation, from any goroutine, takes a
+	// slot when it starts and gives it back when it completes or its stream
+	// or session is closed; operations at the cap block until a slot frees
+	// up or their context ends. Unlike MaxConcurrentCalls, waiting operations
+	// are not ordered by priority. When both are set, a call is admitted by
+	// MaxConcurrentCalls first. Clients sharing a ConnPool connection are
+	// limited separately.
+	MaxConcurrentStreams int
+	// Close the connection after this long without operations and reconnect
+	// on the next call (default: disabled)
+	IdleTimeout time.Duration
+	// TCPKeepAlive sets the OS-level TCP keepalive period of the connection's
+	// socket (SO_KEEPALIVE): the kernel probes an idle connection after this
+	// long and at this interval, which keeps NAT and firewall entries alive
+	// and detects dead peers without involving gRPC. Unlike gRPC keepalive
+	// pings, the probes carry no data and need no server cooperation, but
+	// they only prove the peer's TCP stack is reachable, not that the server
+	// is responsive. Zero keeps Go's default (15s); a negative value disables
+	// TCP keepalive.
+	TCPKeepAlive time.Duration
+	// MaxMessageSize is the largest gRPC message the client sends or
+	// receives (default: 64 MiB). Every record batch travels as one message,
+	// so uploads and downloads of larger record batches fail unless this is
+	// raised (along with the server's limit) or the batches are split.
+	MaxMessageSize int
+	// InitialWindowSize and InitialConnWindowSize set the HTTP/2 flow control
+	// windows of each stream and of the whole connection, in bytes. By default
+	// gRPC starts at 64 KiB and grows them by estimating the bandwidth-delay
+	// product; setting either disables that estimation and fixes the window,
+	// which can speed up large transfers over high-latency links where the
+	// estimate ramps up slowly. Values below 64 KiB are ignored.
+	InitialWindowSize     int32
+	InitialConnWindowSize int32
+	// StatsHandler, if set, is installed on the connection with
+	// grpc.WithStatsHandler and sees every RPC and connection event,
+	// including the exact wire size of each message. It is lower level than
+	// Metrics, which reports per-operation totals, and suits integrations
+	// that already standardize on gRPC stats handlers. Clients in a ConnPool
+	// share a connection only if they use the same handler, so it must be a
+	// comparable value such as a pointer; ConnPool.NewClient rejects others.
+	StatsHandler stats.Handler
+	// ServiceConfig is a gRPC service config in JSON form, applied with
+	// grpc.WithDefaultServiceConfig. It can declare per-method timeouts and
+	// retry policies for the "arrow.flight.protocol.FlightService" methods.
+	//
+	// Apart from ResumeAttempts, the client never retries failed calls;
+	// retries are left to the caller, such as the Temporal activity retry
+	// policy used by the workflows. gRPC retries happen inside each call, so enabling both
+	// multiplies the attempts made (and the time taken) before an activity
+	// fails. Prefer one layer: either gRPC retries for fast transient errors
+	// with few activity attempts, or activity retries alone.
+	ServiceConfig string
+	// FailoverAddrs are addresses to fall back to, in order, when the server
+	// at Addr is unavailable. A call failing with codes.Unavailable (after any
+	// retries from ServiceConfig) still returns its error, but switches the
+	// client to the next address for later calls, wrapping around after the
+	// last. ActiveAddr reports the address in use. Failover clients cannot
+	// share connections through a ConnPool.
+	FailoverAddrs []string
+	// FailbackInterval is how often a client that failed over probes Addr,
+	// switching back to it once it accepts connections again (default: 30s;
+	// negative disables failback)
+	FailbackInterval time.Duration
+	// Metrics, if set, is notified of every completed PutBatch and GetBatch
+	// call. The otelmetrics package records them as OpenTelemetry metrics.
+	Metrics Metrics
+	// OnSlowCall, if set, is called once for each PutBatch and GetBatch call
+	// (including streamed reads, when the stream is closed) that took longer
+	// than SlowCallThreshold, failed or not, for targeted alerting without
+	// aggregating Metrics. It runs on the caller's goroutine when the call
+	// completes, so it should return quickly. Both must be set to take effect.
+	OnSlowCall        func(call SlowCall)
+	SlowCallThreshold time.Duration
+	// IPC tunes the Arrow IPC encoding, for interoperability with other servers
+	IPC IPCOptions
+	// ResumeAttempts is how many times a download interrupted by an Unavailable
+	// or Aborted error is resumed from the first row not yet received (default:
+	// 0, never). Resuming requires server support for offset tickets.
+	ResumeAttempts int
+	// ResumePerBatch makes ResumeAttempts a budget for each record batch
+	// rather than for the whole download: it is restored whenever a batch
+	// arrives, so a long stream survives any number of isolated interruptions
+	// while a batch that keeps failing still ends it. A record batch that
+	// fails to decode is then also refetched, from its first row.
+	ResumePerBatch bool
+	// VerifyCompatibility makes NewFlightClient connect immediately and fail
+	// with ErrIncompatibleServer if the server's protocol or Arrow major
+	// version differs from the client's
+	VerifyCompatibility bool
+	// IDGenerator, if set, names uploaded batches on the client instead of
+	// letting the server assign IDs. A retried upload with an ID the server
+	// already holds returns the stored batch rather than storing a duplicate,
+	// so a deterministic generator such as ContentHashIDGenerator makes
+	// PutBatch idempotent. Generated IDs must not be empty. Streamed uploads
+	// are always named by the server.
+	//
+	// There is no default generator: server-assigned IDs keep working with
+	// servers that do not accept client IDs, and uploads named on the client
+	// are never deduplicated by content (DedupeByContent), since the caller
+	// expects the batch under the ID it chose.
+	IDGenerator IDGenerator
+	// AcceptEOFResult treats an upload whose result stream ends without a
+	// result, after the whole batch was written, as a success, for servers
+	// that close the stream once they have stored a batch. It only applies
+	// to batches named on the client (IDGenerator or TypedClient), whose ID
+	// is known without the result; server-named uploads still fail.
+	//
+	// The outcome of such an upload is ambiguous: an EOF is also what a
+	// server that failed without reporting an error looks like, so the
+	// batch may not be stored. Enable this only for servers known to close
+	// the stream on success, and prefer an idempotent ID generator so a
+	// later retry of a lost upload stores the same batch. Uploads that need
+	// an acknowledgement (access policies, deltas, streamed uploads) still
+	// fail.
+	AcceptEOFResult bool
+	// SchemaRegistry, if set, validates the schema of every upload before it
+	// is sent, failing with ErrSchemaRejected if the registry refuses it, and
+	// registers it. The registry's schema ID is stored with the batch, for
+	// consumers to read with GetSchemaID.
+	SchemaRegistry SchemaRegistry
+	// Constraints, if set, are checked against every record uploaded by
+	// PutBatch, PutStream and their variants before it is sent, failing the
+	// upload with ErrConstraintViolation (see arrow_utils.ValidateConstraints).
+	// Streamed uploads are checked record by record, so the rows reported are
+	// those of the offending record.
+	Constraints []arrow_utils.Constraint
+	// TicketSigner, if set, signs every DoGet ticket the client sends, for
+	// servers that verify tickets with the same signer, along with the batch
+	// IDs of the other reads such a server verifies (see
+	// FlightServerConfig.TicketSigner)
+	TicketSigner TicketSigner
+}
+
+// PutOptions contains per-call options for PutBatchWithOptions
+type PutOptions struct {
+	// Lineage lists the IDs of the batches this batch was derived from
+	Lineage []string
+	// Columns, if set, uploads only the named columns in the given order. The
+	// caller's record is not modified.
+	Columns []string
+	// RequireSorted, if set, rejects the batch with ErrNotSorted unless it is
+	// sorted in ascending order by these columns. The check runs before any
+	// projection, so the sort keys need not be among the uploaded columns.
+	RequireSorted []string
+	// NullsLast makes the RequireSorted check expect nulls after all other
+	// values rather than before them
+	NullsLast bool
+	// Constraints, if set, rejects the batch with ErrConstraintViolation if
+	// any row breaks them, in addition to FlightClientConfig.Constraints. Like
+	// RequireSorted, the check runs before any projection.
+	Constraints []arrow_utils.Constraint
+	// Access, if set, restricts reads of the batch to the principals it
+	// allows; readers must pass a token in GetOptions.AccessToken. The
+	// server must enforce access policies: if it does not, the upload is
+	// dropped and ErrNotSupported is returned rather than leaving the batch
+	// readable by anyone. An upload reusing the ID of a stored batch with
+	// another policy fails with codes.AlreadyExists.
+	Access *AccessPolicy
+	// DedupeByContent sends the batch's RecordFingerprint so that a server
+	// already holding a batch with the same contents returns its ID instead
+	// of storing the upload again; PutBatchResult.Deduplicated reports when it
+	// did. The batch is still transferred. Uploads with an access policy,
+	// dedup keys or a client-chosen ID (IDGenerator, TypedClient) are never
+	// deduplicated, and servers without content deduplication store the
+	// batch as usual.
+	DedupeByContent bool
+	// DedupKeys, if set, asks the server to upsert the batch by these key
+	// columns, keeping only the latest row per key across uploads. The
+	// columns must be in the uploaded batch. Servers that cannot deduplicate
+	// by key yield ErrDedupUnsupported; an upload they stored anyway is
+	// dropped.
+	DedupKeys []string
+
+	// txID stages the upload in a transaction (Tx.PutBatchWithOptions)
+	txID string
+}
+
+// PutBatchResult describes the outcome of a PutBatchWithOptions call
+type PutBatchResult struct {
+	// BatchID is the ID assigned to the batch by the server
+	BatchID string
+	// Compression is the IPC codec used for the upload
+	Compression string
+	// UncompressedBytes is the size of the batch's Arrow buffers before encoding
+	UncompressedBytes int64
+	// CompressedBytes is the size of the IPC message bodies written to the stream
+	CompressedBytes int64
+	// Deduplicated is set when the server already held a batch with the same
+	// contents (PutOptions.DedupeByContent) and BatchID is that batch's ID
+	Deduplicated bool
+
+	// deltaApplied is set when the server acknowledged a delta upload
+	deltaApplied bool
+	// accessApplied is set when the server acknowledged an access policy
+	accessApplied bool
+	// dedupApplied is set when the server acknowledged dedup keys
+	dedupApplied bool
+	// existing is set when the server reported that the batch was already
+	// stored, so the upload did not create it
+	existing bool
+}
+
+// CompressionRatio returns CompressedBytes / UncompressedBytes, or 1 if the
+// batch was empty
+func (r *PutBatchResult) CompressionRatio() float64 {
+	if r.UncompressedBytes == 0 {
+		return 1
+	}
+	return float64(r.CompressedBytes) / float64(r.UncompressedBytes)
+}
+
+// countingStream wraps a Flight data stream and counts the body bytes sent through it
+type countingStream struct {
+	flight.DataStreamWriter
+	bodyBytes int64
+	sendTime  time.Duration // Time spent in Send, to tell encoding from transfer
+}
+
+// Send forwards the message and records the size of its body
+func (s *countingStream) Send(data *flight.FlightData) error {
+	s.bodyBytes += int64(len(data.DataBody))
+	start := time.Now()
+	err := s.DataStreamWriter.Send(data)
+	s.sendTime += time.Since(start)
+	return err
 }
 
 // NewFlightClient creates a new Arrow Flight client
 func NewFlightClient(config FlightClientConfig) (*FlightClient, error) {
+	return newFlightClient(config, nil)
+}
+
+// newFlightClient creates a Flight client, sharing its connection through pool
+// if set
+func newFlightClient(config FlightClientConfig, pool *ConnPool) (*FlightClient, error) {
 	if config.Addr == "" {
 		config.Addr = "localhost:8080"
 	}
+	allocator, err := newAllocator(config.AllocatorType)
+	if err != nil {
+		return nil, err
+	}
 	if config.Allocator == nil {
-		config.Allocator = memory.NewGoAllocator()
+		config.Allocator = allocator
+	}
+	switch config.Compression {
+	case "":
+		config.Compression = CompressionNone
+	case CompressionNone, CompressionLZ4, CompressionZstd:
+	default:
+		return nil, fmt.Errorf("unsupported compression codec %q", config.Compression)
+	}
+	if err := config.IPC.validate(); err != nil {
+		return nil, fmt.Errorf("invalid IPC options: %w", err)
+	}
+	if config.AdaptiveCompression != nil {
+		if err := config.AdaptiveCompression.validate(config.Compression); err != nil {
+			return nil, fmt.Errorf("invalid adaptive compression: %w", err)
+		}
+	}
+	if config.AdaptiveTimeout != nil {
+		if err := config.AdaptiveTimeout.validate(); err != nil {
+			return nil, fmt.Errorf("invalid adaptive timeout: %w", err)
+		}
+	}
+
+	if config.MaxMessageSize == 0 {
+		config.MaxMessageSize = defaultMaxMessageSize
+	}
+	for _, addr := range config.FailoverAddrs {
+		if addr == "" {
+			return nil, fmt.Errorf("invalid failover address: must not be empty")
+		}
+	}
+	if len(config.FailoverAddrs) > 0 && pool != nil {
+		return nil, fmt.Errorf("failover addresses cannot be used with a ConnPool")
+	}
+	if pool != nil && !poolable(config.StatsHandler, config.Context) {
+		return nil, fmt.Errorf("a ConnPool needs a comparable StatsHandler and Context, such as pointers, to tell shared connections apart")
+	}
+	if config.FailbackInterval == 0 {
+		config.FailbackInterval = defaultFailbackInterval
 	}
 
 	// Set up gRPC options
@@ -42,136 +443,665 @@ func NewFlightClient(config FlightClientConfig) (*FlightClient, error) {
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 		// Set maximum message sizes for large batches
 		grpc.WithDefaultCallOptions(
-			grpc.MaxCallRecvMsgSize(64*1024*1024), // 64MB
-			grpc.MaxCallSendMsgSize(64*1024*1024), // 64MB
+			grpc.MaxCallRecvMsgSize(config.MaxMessageSize),
+			grpc.MaxCallSendMsgSize(config.MaxMessageSize),
 		),
+		// Expose server status codes and details as *FlightServerError
+		grpc.WithChainUnaryInterceptor(unaryErrorInterceptor),
+		grpc.WithChainStreamInterceptor(streamErrorInterceptor),
+	}
+	if config.ServiceConfig != "" {
+		if !json.Valid([]byte(config.ServiceConfig)) {
+			return nil, fmt.Errorf("invalid gRPC service config: not valid JSON")
+		}
+		// gRPC validates the config's contents when the client is created
+		opts = append(opts, grpc.WithDefaultServiceConfig(config.ServiceConfig))
+	}
+	if config.DeadlineHint {
+		opts = append(opts,
+			grpc.WithChainUnaryInterceptor(unaryDeadlineInterceptor),
+			grpc.WithChainStreamInterceptor(streamDeadlineInterceptor),
+		)
+	}
+	if config.StatsHandler != nil {
+		opts = append(opts, grpc.WithStatsHandler(config.StatsHandler))
+	}
+	if config.TCPKeepAlive != 0 {
+		opts = append(opts, grpc.WithContextDialer(tcpDialer(config.TCPKeepAlive)))
+	}
+	if config.InitialWindowSize > 0 {
+		opts = append(opts, grpc.WithInitialWindowSize(config.InitialWindowSize))
+	}
+	if config.InitialConnWindowSize > 0 {
+		opts = append(opts, grpc.WithInitialConnWindowSize(config.InitialConnWindowSize))
+	}
+
+	c := &FlightClient{
+		config:         config,
+		done:           make(chan struct{}),
+		addr:           config.Addr,
+		allocator:      config.Allocator,
+		conn:           nil, // We don't need to store the connection separately
+		compression:    config.Compression,
+		ipc:            config.IPC,
+		metrics:        config.Metrics,
+		idGenerator:    config.IDGenerator,
+		resumeAttempts: config.ResumeAttempts,
+		dialOpts:       opts,
+		pool:           pool,
+		poolKey: connKey{
+			addr:          config.Addr,
+			serviceConfig: config.ServiceConfig,
+			tcpKeepAlive:  config.TCPKeepAlive,
+			statsHandler:  config.StatsHandler,
+			root:          config.Context,
+			deadlineHint:  config.DeadlineHint,
+			maxMsgSize:    config.MaxMessageSize,
+			windowSize:    config.InitialWindowSize,
+			connWindow:    config.InitialConnWindowSize,
+		},
+		idleTimeout:     config.IdleTimeout,
+		adaptiveTimeout: config.AdaptiveTimeout,
+		cacheActions:    config.CacheActions,
+		acceptEOFResult: config.AcceptEOFResult,
+		resumePerBatch:  config.ResumePerBatch,
+		schemaRegistry:  config.SchemaRegistry,
+		constraints:     config.Constraints,
+		ticketSigner:    config.TicketSigner,
+
+		slowCallThreshold: config.SlowCallThreshold,
+		onSlowCall:        config.OnSlowCall,
+
+		addrs:            append([]string{config.Addr}, config.FailoverAddrs...),
+		failbackInterval: config.FailbackInterval,
+	}
+	if config.AdaptiveCompression != nil {
+		c.adaptiveCompression = newCompressionState(*config.AdaptiveCompression, config.Compression)
+	}
+	if config.MaxConcurrentCalls > 0 {
+		c.scheduler = newCallScheduler(config.MaxConcurrentCalls)
+	}
+	if config.MaxConcurrentStreams > 0 {
+		c.streams = make(streamLimiter, config.MaxConcurrentStreams)
+	}
+	if config.Context != nil {
+		if err := config.Context.Err(); err != nil {
+			return nil, fmt.Errorf("client context already ended: %w", err)
+		}
+		c.root = config.Context
+		c.dialOpts = append(c.dialOpts,
+			grpc.WithChainUnaryInterceptor(c.unaryRootInterceptor),
+			grpc.WithChainStreamInterceptor(c.streamRootInterceptor),
+		)
+	}
+	if len(config.FailoverAddrs) > 0 {
+		c.dialOpts = append(c.dialOpts,
+			grpc.WithChainUnaryInterceptor(c.unaryFailoverInterceptor),
+			grpc.WithChainStreamInterceptor(c.streamFailoverInterceptor),
+		)
 	}
 
 	// Create a Flight client with the gRPC options
-	client, err := flight.NewClientWithMiddleware(config.Addr, nil, nil, opts...)
+	client, err := c.dial()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Flight client: %w", err)
+		return nil, err
 	}
+	c.client = client
 
-	return &FlightClient{
-		client:    client,
-		addr:      config.Addr,
-		allocator: config.Allocator,
-		conn:      nil, // We don't need to store the connection separately
-	}, nil
+	if config.VerifyCompatibility {
+		ctx, cancel := context.WithTimeout(context.Background(), compatibilityCheckTimeout)
+		defer cancel()
+		if err := c.CheckCompatibility(ctx); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+
+	c.connMu.Lock()
+	c.armIdleTimer()
+	if c.root != nil {
+		c.stopRoot = context.AfterFunc(c.root, func() { c.Close() })
+	}
+	c.connMu.Unlock()
+
+	return c, nil
 }
 
-// Close closes the Flight client
+// Close closes the Flight client. It is safe to call concurrently with other
+// operations and more than once: calls started afterwards fail with
+// ErrClientClosed, calls still in flight are cancelled as the connection
+// closes, and every Close after the first returns nil.
 func (c *FlightClient) Close() error {
-	c.client.Close()
+	c.closeOnce.Do(c.teardown)
 	return nil
 }
 
+// teardown marks the client closed and closes its connection
+func (c *FlightClient) teardown() {
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
+
+	c.closed = true
+	close(c.done)
+	if c.stopRoot != nil {
+		c.stopRoot()
+	}
+	if c.idleTimer != nil {
+		c.idleTimer.Stop()
+	}
+	if c.failbackTimer != nil {
+		c.failbackTimer.Stop()
+	}
+	if c.client != nil {
+		c.client.Close()
+		c.client = nil
+	}
+	c.closeRetired()
+}
+
+// writerOptions returns the IPC options used to write a record with the given
+// schema and codec
+func (c *FlightClient) writerOptions(schema *arrow.Schema, codec string) []ipc.Option {
+	opts := []ipc.Option{
+		ipc.WithSchema(schema),
+		ipc.WithAllocator(c.allocator),
+		ipc.WithDictionaryDeltas(c.ipc.DictionaryDeltas),
+	}
+	switch codec {
+	case CompressionLZ4:
+		opts = append(opts, ipc.WithLZ4())
+	case CompressionZstd:
+		opts = append(opts, ipc.WithZstd())
+	}
+	if codec != CompressionNone && c.ipc.MinSpaceSavings > 0 {
+		opts = append(opts, ipc.WithMinSpaceSavings(c.ipc.MinSpaceSavings))
+	}
+	return opts
+}
+
 // PutBatch sends a batch to the Flight server and returns the batch ID
 func (c *FlightClient) PutBatch(ctx context.Context, batch arrow.Record) (string, error) {
+	result, err := c.PutBatchWithOptions(ctx, batch, PutOptions{})
+	if err != nil {
+		return "", err
+	}
+	return result.BatchID, nil
+}
+
+// PutBatchWithOptions sends a batch to the Flight server and reports the assigned
+// batch ID along with the encoded size of the upload
+func (c *FlightClient) PutBatchWithOptions(ctx context.Context, batch arrow.Record, options PutOptions) (*PutBatchResult, error) {
+	return c.putBatchWithOptions(ctx, batch, options, "")
+}
+
+// putBatchWithOptions implements PutBatchWithOptions, storing the batch under
+// batchID if set
+func (c *FlightClient) putBatchWithOptions(ctx context.Context, batch arrow.Record, options PutOptions, batchID string) (*PutBatchResult, error) {
+	meta := putMetadata{BatchID: batchID, Lineage: options.Lineage, Access: options.Access, DedupKeys: options.DedupKeys, TxID: options.txID}
+	if options.Access == nil && len(options.DedupKeys) == 0 {
+		return c.putBatch(ctx, batch, options, meta)
+	}
+
+	if options.Access != nil {
+		if err := c.requireFeature(ctx, "access policies", func(s ServerCapabilities) bool { return s.AccessPolicies }); err != nil {
+			return nil, err
+		}
+	}
+	if len(options.DedupKeys) > 0 {
+		err := c.requireFeature(ctx, "key deduplication", func(s ServerCapabilities) bool { return s.KeyDedup })
+		if errors.Is(err, ErrNotSupported) {
+			return nil, ErrDedupUnsupported
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	result, err := c.putBatch(ctx, batch, options, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	// Drop a batch the server stored without applying what was asked of it. A
+	// batch that was already stored is not the upload's to drop, and was
+	// stored with whatever policy it had before.
+	var unsupported error
+	switch {
+	case options.Access != nil && !result.accessApplied:
+		unsupported = fmt.Errorf("%w: access policies", ErrNotSupported)
+	case len(options.DedupKeys) > 0 && !result.dedupApplied:
+		unsupported = ErrDedupUnsupported
+	}
+	if unsupported != nil && result.existing {
+		return nil, fmt.Errorf("batch %s already stored without the requested options: %w", result.BatchID, unsupported)
+	}
+	if unsupported != nil {
+		err := unsupported
+		if _, dropErr := c.doAction(context.WithoutCancel(ctx), ActionDropBatch, []byte(result.BatchID)); dropErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to drop batch %s: %w", result.BatchID, dropErr))
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// putBatch uploads a batch with the given structured metadata and reports the
+// call to the metrics hook
+func (c *FlightClient) putBatch(ctx context.Context, batch arrow.Record, options PutOptions, meta putMetadata) (*PutBatchResult, error) {
+	start := time.Now()
+	ctx, cancel := c.withTransferTimeout(ctx, util.TotalRecordSize(batch))
+	defer cancel()
+	result, err := c.doPut(ctx, batch, options, meta)
+	err = labelError(ctx, err)
+
+	stats := CallStats{
+		Method:      MethodPutBatch,
+		Duration:    time.Since(start),
+		Rows:        batch.NumRows(),
+		Compression: c.EffectiveCompression(),
+		Label:       CallLabel(ctx),
+		Err:         err,
+	}
+	if result != nil {
+		stats.BatchID = result.BatchID
+		stats.Compression = result.Compression
+		stats.Bytes = result.CompressedBytes
+		stats.UncompressedBytes = result.UncompressedBytes
+	}
+	c.observe(stats)
+
+	return result, err
+}
+
+// doPut implements putBatch
+func (c *FlightClient) doPut(ctx context.Context, batch arrow.Record, options PutOptions, meta putMetadata) (*PutBatchResult, error) {
+	// Enforce the sort order contract before anything is sent
+	if len(options.RequireSorted) > 0 {
+		row, err := arrow_utils.FirstUnsortedRow(batch, options.RequireSorted, !options.NullsLast)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check sort order: %w", err)
+		}
+		if row >= 0 {
+			return nil, fmt.Errorf("%w: row %d is out of order by %v", ErrNotSorted, row, options.RequireSorted)
+		}
+	}
+	if err := c.checkConstraints(ctx, batch, options.Constraints); err != nil {
+		return nil, err
+	}
+
+	// Project the record to the requested columns
+	if len(options.Columns) > 0 {
+		projected, err := arrow_utils.SelectColumns(batch, options.Columns)
+		if err != nil {
+			return nil, fmt.Errorf("failed to project batch: %w", err)
+		}
+		defer projected.Release()
+		batch = projected
+	}
+
+	// The dedup keys must be among the uploaded columns
+	for _, key := range meta.DedupKeys {
+		if !batch.Schema().HasField(key) {
+			return nil, fmt.Errorf("dedup key column %q is not in the batch schema", key)
+		}
+	}
+
+	// Check the outgoing schema against the registry
+	schemaID, err := c.registerSchema(ctx, batch.Schema())
+	if err != nil {
+		return nil, err
+	}
+	meta.SchemaID = schemaID
+
+	// Name the batch on the client if it has a generator
+	if c.idGenerator != nil && meta.BatchID == "" {
+		batchID, err := c.idGenerator.NewBatchID(batch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate batch ID: %w", err)
+		}
+		if batchID == "" {
+			return nil, fmt.Errorf("failed to generate batch ID: the generator returned an empty ID")
+		}
+		meta.BatchID = batchID
+	}
+
+	// Hash the contents for the server to find an identical stored batch
+	if options.DedupeByContent && meta.Delta == nil && meta.Access == nil && meta.TxID == "" && len(meta.DedupKeys) == 0 {
+		if meta.ContentHash, err = RecordFingerprint(batch); err != nil {
+			return nil, fmt.Errorf("failed to hash batch contents: %w", err)
+		}
+	}
+
 	// Create a Flight descriptor
 	descriptor := &flight.FlightDescriptor{
 		Type: flight.DescriptorCMD,
 		Cmd:  []byte("put"),
 	}
 
+	client, release, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	// Start a DoPut stream
-	stream, err := c.client.DoPut(ctx)
+	stream, err := client.DoPut(ctx)
 	if err != nil {
-		return "", fmt.Errorf("failed to start DoPut stream: %w", err)
+		return nil, fmt.Errorf("failed to start DoPut stream: %w", err)
 	}
 
-	// First, send the descriptor
-	if err := stream.Send(&flight.FlightData{
-		FlightDescriptor: descriptor,
-	}); err != nil {
-		return "", fmt.Errorf("failed to send descriptor: %w", err)
+	// First, send the descriptor along with any structured metadata
+	var appMetadata []byte
+	if !meta.isEmpty() {
+		if appMetadata, err = json.Marshal(meta); err != nil {
+			return nil, fmt.Errorf("failed to encode put metadata: %w", err)
+		}
+	}
+	if !c.ipc.DescriptorWithSchema {
+		if err := stream.Send(&flight.FlightData{
+			FlightDescriptor: descriptor,
+			AppMetadata:      appMetadata,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to send descriptor: %w", err)
+		}
+		appMetadata = nil
 	}
 
-	// Create a writer for the stream
-	writer := flight.NewRecordWriter(stream, ipc.WithSchema(batch.Schema()))
+	// Create a writer for the stream, counting the encoded bytes
+	codec := c.EffectiveCompression()
+	counter := &countingStream{DataStreamWriter: stream}
+	writer := flight.NewRecordWriter(counter, c.writerOptions(batch.Schema(), codec)...)
+	if c.ipc.DescriptorWithSchema {
+		writer.SetFlightDescriptor(descriptor)
+	}
 
-	// Write the batch to the stream
-	if err := writer.Write(batch); err != nil {
+	// Write the batch to the stream, timing the encoding apart from the sends
+	start := time.Now()
+	if err := writer.WriteWithAppMetadata(batch, appMetadata); err != nil {
 		// Make sure to close the writer even if writing fails
 		writer.Close()
-		return "", fmt.Errorf("failed to write batch to stream: %w", err)
+		return nil, fmt.Errorf("failed to write batch to stream: %w", err)
 	}
 
 	// Close the writer to signal the end of the stream
 	if err := writer.Close(); err != nil {
-		return "", fmt.Errorf("failed to close writer: %w", err)
+		return nil, fmt.Errorf("failed to close writer: %w", err)
 	}
+	// Half-close too, for servers that read the upload to its end before
+	// replying, as the reference implementations do
+	if err := stream.CloseSend(); err != nil {
+		return nil, fmt.Errorf("failed to close send direction: %w", err)
+	}
+	uncompressedBytes := util.TotalRecordSize(batch)
+	c.observeEncoding(ctx, codec, uncompressedBytes, time.Since(start)-counter.sendTime)
 
 	// Get the result
+	var decoded putResult
 	result, err := stream.Recv()
+	switch {
+	case errors.Is(err, io.EOF) && c.acceptEOFResult && meta.BatchID != "":
+		// The server closed the stream after the write; assume it stored the batch
+		decoded.BatchID = meta.BatchID
+	case err != nil:
+		return nil, fmt.Errorf("failed to receive result: %w", err)
+	default:
+		if decoded, err = decodePutResult(result.AppMetadata); err != nil {
+			return nil, err
+		}
+	}
+
+	return &PutBatchResult{
+		BatchID:           decoded.BatchID,
+		Compression:       codec,
+		UncompressedBytes: uncompressedBytes,
+		CompressedBytes:   counter.bodyBytes,
+		Deduplicated:      decoded.Deduped,
+		deltaApplied:      decoded.Delta,
+		accessApplied:     decoded.Access,
+		dedupApplied:      decoded.DedupKeys,
+		existing:          decoded.Existing,
+	}, nil
+}
+
+// checkConstraints checks a record against the client's constraints and any
+// given for the upload
+func (c *FlightClient) checkConstraints(ctx context.Context, batch arrow.Record, extra []arrow_utils.Constraint) error {
+	constraints := c.constraints
+	if len(extra) > 0 {
+		constraints = append(slices.Clip(constraints), extra...)
+	}
+	if len(constraints) == 0 {
+		return nil
+	}
+
+	err := arrow_utils.ValidateConstraints(ctx, batch, constraints, c.allocator)
+	var violated *arrow_utils.ConstraintError
+	if errors.As(err, &violated) {
+		return fmt.Errorf("%w: %w", ErrConstraintViolation, err)
+	}
 	if err != nil {
-		return "", fmt.Errorf("failed to receive result: %w", err)
+		return fmt.Errorf("failed to check constraints: %w", err)
 	}
+	return nil
+}
+
+// GetOptions contains per-call options for GetBatchWithOptions and
+// GetBatchStreamWithOptions
+type GetOptions struct {
+	// MaxRows aborts the download with ErrLimitExceeded once more rows than
+	// this have been received (0 means unlimited)
+	MaxRows int64
+	// MaxBatches aborts the download with ErrLimitExceeded once more record
+	// batches than this have been received (0 means unlimited)
+	MaxBatches int
+	// Allocator, if set, is used instead of the client's allocator for the
+	// buffers allocated while decoding (decompressing) and combining the
+	// downloaded records, so a large transfer can be isolated in a scratch
+	// allocator
+	Allocator memory.Allocator
+	// AccessToken is sent to the server with the download, in the
+	// AccessTokenHeader metadata, to read batches stored with an
+	// AccessPolicy. Refused downloads fail with ErrAccessDenied.
+	AccessToken string
+	// ReadAhead, if positive, makes a BatchStream receive up to this many
+	// record batches in the background while the caller processes earlier
+	// ones, so decoding and the network overlap with a slow consumer. At most
+	// ReadAhead decoded records are held beyond the one being processed
+	// (default: 0, records are received when Next is called).
+	ReadAhead int
+	// Coerce casts the named columns to the given types after decoding, for
+	// example to widen int32 columns to int64 or to change a timestamp unit.
+	// Other columns are left unchanged, and naming a column the batch lacks
+	// fails the download.
+	Coerce map[string]arrow.DataType
+	// AllowLossy permits Coerce casts that lose information, such as
+	// narrowing integers that overflow or dropping timestamp precision,
+	// which otherwise fail the download
+	AllowLossy bool
+	// NormalizeTimezone, if set, converts every timestamp column with a time
+	// zone to this zone (such as "UTC") after Coerce, keeping its unit. The
+	// instants are unchanged; only the zone they are presented in differs.
+	// Timezone-naive columns are left alone unless AssumeTimezone is set.
+	NormalizeTimezone string
+	// AssumeTimezone makes NormalizeTimezone also convert timezone-naive
+	// timestamp columns, reading their values as wall clock times in this zone
+	AssumeTimezone string
+	// ExpectedSchema, if set, fails the download with ErrSchemaMismatch when
+	// the batch's fields (after Coerce and NormalizeTimezone) differ from its
+	// fields. Names, types and nullability are compared; metadata is ignored.
+	ExpectedSchema *arrow.Schema
+	// AlignSchema is called once when a download fails ExpectedSchema, with
+	// the expected schema and the batch's latest schema from GetSchema. The
+	// download is retried once, expecting the schema it returns, so callers
+	// can migrate a cached expectation while reading. Returning an error, or a
+	// schema that again differs from the batch, fails the download.
+	AlignSchema func(ctx context.Context, expected, latest *arrow.Schema) (*arrow.Schema, error)
+	// Mask hides the values of the named columns, such as PII the caller is
+	// not cleared to see, once they are decoded and coerced (see
+	// arrow_utils.MaskRecord). It applies whatever the server sends, as a
+	// second line of defense behind server-side access control. Naming a
+	// column the batch lacks fails the download.
+	Mask map[string]arrow_utils.MaskStrategy
+	// AllowSchemaChange lets a BatchStream continue when the server starts a
+	// new IPC stream with a different schema part way through the download:
+	// Next then returns records of the new schema (check each record's
+	// Schema) instead of failing with ErrSchemaChanged. Coerce, Mask and
+	// ExpectedSchema apply to every schema. GetBatch, which returns a single
+	// record, still fails with ErrSchemaChanged.
+	AllowSchemaChange bool
+	// Columns, if set, downloads only the named columns in the given order
+	// (see GetBatchProjected). Coerce, Mask and ExpectedSchema see the
+	// selected columns.
+	Columns []string
 
-	// Return the batch ID
-	return string(result.AppMetadata), nil
+	// pipeline names the server transforms to apply (GetBatchWithPipeline)
+	pipeline []string
 }
 
-// GetBatch retrieves a batch from the Flight server by ID
+// GetBatch retrieves a batch from the Flight server by ID. The caller owns the
+// only reference to the returned record: a single Release frees it, and
+// nothing else needs to be released. Retain it only to share it further.
 func (c *FlightClient) GetBatch(ctx context.Context, batchID string) (arrow.Record, error) {
-	// Create a Flight ticket
-	ticket := &flight.Ticket{
-		Ticket: []byte(batchID),
+	return c.GetBatchWithOptions(ctx, batchID, GetOptions{})
+}
+
+// GetBatchWithOptions retrieves a batch from the Flight server by ID. If the
+// stream carries several record batches they are combined into one record.
+// As with GetBatch, the caller owns the only reference to the record.
+func (c *FlightClient) GetBatchWithOptions(ctx context.Context, batchID string, options GetOptions) (arrow.Record, error) {
+	stream, err := c.GetBatchStreamWithOptions(ctx, batchID, options)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	// Read every record batch
+	var batches []arrow.Record
+	for {
+		batch, err := stream.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			releaseRecords(batches)
+			return nil, err
+		}
+		batches = append(batches, batch)
+		if !batch.Schema().Equal(stream.Schema()) {
+			releaseRecords(batches)
+			return nil, fmt.Errorf("%w: records of schema %s follow %s; read them with GetBatchStream", ErrSchemaChanged, batch.Schema(), stream.Schema())
+		}
 	}
 
-	// Start a DoGet stream
-	stream, err := c.client.DoGet(ctx, ticket)
+	return combineRecords(stream.allocator, stream.Schema(), batches)
+}
+
+// GetBatchBorrow retrieves a batch like GetBatchWithOptions, for callers that
+// consume the record within a scope. The returned release function frees the
+// record and is safe to call more than once, so it can be deferred alongside
+// early releases. The record must not be released directly or used after
+// release; retain it to keep it beyond the scope. No copy is made either way:
+// GetBatch already hands over the only reference to the downloaded buffers.
+func (c *FlightClient) GetBatchBorrow(ctx context.Context, batchID string, options GetOptions) (arrow.Record, func(), error) {
+	batch, err := c.GetBatchWithOptions(ctx, batchID, options)
 	if err != nil {
-		return nil, fmt.Errorf("failed to start DoGet stream: %w", err)
+		return nil, nil, err
+	}
+	return batch, sync.OnceFunc(batch.Release), nil
+}
+
+// combineRecords combines the record batches of a download into one record,
+// taking ownership of batches
+func combineRecords(mem memory.Allocator, schema *arrow.Schema, batches []arrow.Record) (arrow.Record, error) {
+	switch len(batches) {
+	case 0:
+		// A stream carrying only a schema is a valid empty result
+		return emptyRecord(mem, schema), nil
+	case 1:
+		return batches[0], nil
+	default:
+		defer releaseRecords(batches)
+		return arrow_utils.ConcatRecords(mem, schema, batches)
+	}
+}
+
+// releaseRecords releases every record in records
+func releaseRecords(records []arrow.Record) {
+	for _, rec := range records {
+		rec.Release()
 	}
+}
 
-	// Create a reader for the stream
-	reader, err := flight.NewRecordReader(stream)
+// GetSchema retrieves the schema of a stored batch, including its field and
+// schema-level metadata, without transferring any data
+func (c *FlightClient) GetSchema(ctx context.Context, batchID string) (*arrow.Schema, error) {
+	client, release, err := c.acquire(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create record reader: %w", err)
+		return nil, err
 	}
-	defer reader.Release()
+	defer release()
 
-	// Read the batch
-	if !reader.Next() {
-		if err := reader.Err(); err != nil {
-			return nil, fmt.Errorf("error reading batch: %w", err)
-		}
-		return nil, fmt.Errorf("no batch received")
+	result, err := client.GetSchema(ctx, &flight.FlightDescriptor{
+		Type: flight.DescriptorCMD,
+		Cmd:  c.batchCommand(batchID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schema for batch %s: %w", batchID, err)
 	}
 
-	// Get the batch and retain it
-	batch := reader.Record()
-	batch.Retain() // Important: Retain the batch so it's not released when the reader is released
+	schema, err := flight.DeserializeSchema(result.Schema, c.allocator)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode schema: %w", err)
+	}
+	return schema, nil
+}
 
-	return batch, nil
+// emptyRecord returns a zero-row record with the given schema
+func emptyRecord(mem memory.Allocator, schema *arrow.Schema) arrow.Record {
+	builder := array.NewRecordBuilder(mem, schema)
+	defer builder.Release()
+	return builder.NewRecord()
 }
 
-// ListBatches lists all batches in the Flight server
+// ListBatches lists all batches in the Flight server. A server that lists a
+// batch once per endpoint may return the same ID more than once; see
+// ListBatchesWithOptions to drop the duplicates.
 func (c *FlightClient) ListBatches(ctx context.Context) ([]string, error) {
-	// Create a Flight criteria
-	criteria := &flight.Criteria{}
+	return c.ListBatchesWithOptions(ctx, ListOptions{})
+}
 
-	// Start a ListFlights stream
-	stream, err := c.client.ListFlights(ctx, criteria)
+// doAction runs a custom server action and returns the body of its first result
+// (nil if there was none). Servers that don't know the action yield ErrNotSupported.
+func (c *FlightClient) doAction(ctx context.Context, actionType string, body []byte) ([]byte, error) {
+	client, release, err := c.acquire(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to start ListFlights stream: %w", err)
+		return nil, err
 	}
+	defer release()
 
-	// Read all flight infos
-	var batchIDs []string
+	stream, err := client.DoAction(ctx, &flight.Action{Type: actionType, Body: body})
+	if err != nil {
+		return nil, labelError(ctx, fmt.Errorf("failed to start %s action: %w", actionType, err))
+	}
+
+	// Read the stream to the end so the call completes cleanly
+	var first []byte
+	received := false
 	for {
-		info, err := stream.Recv()
+		result, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
 		if err != nil {
-			// Check if we've reached the end of the stream
-			if err.Error() == "EOF" {
-				break
+			if status.Code(err) == codes.Unimplemented {
+				return nil, labelError(ctx, fmt.Errorf("%w: %s action", ErrNotSupported, actionType))
 			}
-			return nil, fmt.Errorf("error receiving flight info: %w", err)
+			return nil, labelError(ctx, err)
+		}
+		if !received {
+			first = result.Body
+			received = true
 		}
-		batchIDs = append(batchIDs, string(info.FlightDescriptor.Cmd))
 	}
 
-	return batchIDs, nil
+	return first, nil
 }
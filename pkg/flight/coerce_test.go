@@ -0,0 +1,105 @@
+package flight
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createCoercionBatch creates a batch with an int32, an int64 and a millisecond timestamp column
+func createCoercionBatch(t *testing.T, wide []int64, stamps []arrow.Timestamp) arrow.Record {
+	schema := arrow.NewSchema(
+		[]arrow.Field{
+			{Name: "narrow", Type: arrow.PrimitiveTypes.Int32},
+			{Name: "wide", Type: arrow.PrimitiveTypes.Int64},
+			{Name: "at", Type: &arrow.TimestampType{Unit: arrow.Millisecond, TimeZone: "UTC"}},
+		},
+		nil,
+	)
+
+	builder := array.NewRecordBuilder(memory.NewGoAllocator(), schema)
+	defer builder.Release()
+	builder.Field(0).(*array.Int32Builder).AppendValues([]int32{1, 2}, nil)
+	builder.Field(1).(*array.Int64Builder).AppendValues(wide, nil)
+	builder.Field(2).(*array.TimestampBuilder).AppendValues(stamps, nil)
+	return builder.NewRecord()
+}
+
+// TestGetBatchCoerce tests that downloads cast the listed columns and reject lossy casts by default
+func TestGetBatchCoerce(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	exact := createCoercionBatch(t, []int64{10, 20}, []arrow.Timestamp{1000, 2000})
+	defer exact.Release()
+	exactID, err := client.PutBatch(ctx, exact)
+	require.NoError(t, err, "Failed to put batch")
+
+	lossy := createCoercionBatch(t, []int64{10, 1 << 40}, []arrow.Timestamp{1500, 2000})
+	defer lossy.Release()
+	lossyID, err := client.PutBatch(ctx, lossy)
+	require.NoError(t, err, "Failed to put batch")
+
+	seconds := &arrow.TimestampType{Unit: arrow.Second, TimeZone: "UTC"}
+
+	t.Run("widening", func(t *testing.T) {
+		retrieved, err := client.GetBatchWithOptions(ctx, exactID, GetOptions{
+			Coerce: map[string]arrow.DataType{"narrow": arrow.PrimitiveTypes.Int64},
+		})
+		require.NoError(t, err, "Failed to get batch")
+		defer retrieved.Release()
+
+		assert.Equal(t, []int64{1, 2}, retrieved.Column(0).(*array.Int64).Int64Values())
+		assert.True(t, arrow.TypeEqual(arrow.PrimitiveTypes.Int64, retrieved.Column(1).DataType()), "Unlisted columns should be unchanged")
+		assert.True(t, arrow.TypeEqual(exact.Schema().Field(2).Type, retrieved.Schema().Field(2).Type))
+	})
+
+	t.Run("narrowing", func(t *testing.T) {
+		coerce := map[string]arrow.DataType{"wide": arrow.PrimitiveTypes.Int32}
+		retrieved, err := client.GetBatchWithOptions(ctx, exactID, GetOptions{Coerce: coerce})
+		require.NoError(t, err, "Values in range should narrow safely")
+		assert.Equal(t, []int32{10, 20}, retrieved.Column(1).(*array.Int32).Int32Values())
+		retrieved.Release()
+
+		_, err = client.GetBatchWithOptions(ctx, lossyID, GetOptions{Coerce: coerce})
+		assert.ErrorContains(t, err, `column "wide"`, "Overflowing values should fail")
+
+		retrieved, err = client.GetBatchWithOptions(ctx, lossyID, GetOptions{Coerce: coerce, AllowLossy: true})
+		require.NoError(t, err, "Lossy casts should be allowed on request")
+		assert.Equal(t, int32(10), retrieved.Column(1).(*array.Int32).Value(0))
+		retrieved.Release()
+	})
+
+	t.Run("timestamp unit", func(t *testing.T) {
+		coerce := map[string]arrow.DataType{"at": seconds}
+		retrieved, err := client.GetBatchWithOptions(ctx, exactID, GetOptions{Coerce: coerce})
+		require.NoError(t, err, "Whole seconds should convert exactly")
+		assert.True(t, arrow.TypeEqual(seconds, retrieved.Schema().Field(2).Type))
+		assert.Equal(t, []arrow.Timestamp{1, 2}, retrieved.Column(2).(*array.Timestamp).TimestampValues())
+		retrieved.Release()
+
+		_, err = client.GetBatchWithOptions(ctx, lossyID, GetOptions{Coerce: coerce})
+		assert.Error(t, err, "Dropping milliseconds should fail")
+
+		retrieved, err = client.GetBatchWithOptions(ctx, lossyID, GetOptions{Coerce: coerce, AllowLossy: true})
+		require.NoError(t, err)
+		assert.Equal(t, []arrow.Timestamp{1, 2}, retrieved.Column(2).(*array.Timestamp).TimestampValues())
+		retrieved.Release()
+	})
+
+	_, err = client.GetBatchWithOptions(ctx, exactID, GetOptions{Coerce: map[string]arrow.DataType{"missing": arrow.PrimitiveTypes.Int64}})
+	assert.ErrorContains(t, err, "missing")
+}
@@ -0,0 +1,96 @@
+package flight
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ActionCompact is the DoAction type used to ask a server to compact its
+// stored batches. The action body is a ListFlights criteria expression
+// selecting the batches to compact (empty for all of them) and the result body
+// is a JSON-encoded CompactionResult.
+const ActionCompact = "compact"
+
+// ActionCompactBatch is the DoAction type used to ask a server to rewrite one
+// batch into optimally sized record batches. The action body is the batch ID
+// and the result body is a JSON-encoded BatchCompactionResult.
+const ActionCompactBatch = "compact-batch"
+
+// ErrCompactionUnsupported is returned by Compact when the server cannot
+// compact its storage. It wraps ErrNotSupported.
+var ErrCompactionUnsupported = fmt.Errorf("%w: compaction", ErrNotSupported)
+
+// CompactionResult reports what a server compaction achieved
+type CompactionResult struct {
+	// BatchesBefore is the number of matching batches before compaction
+	BatchesBefore int `json:"batchesBefore"`
+	// BatchesAfter is the number of batches they were compacted into
+	BatchesAfter int `json:"batchesAfter"`
+	// BytesReclaimed is the storage the server freed, in bytes
+	BytesReclaimed int64 `json:"bytesReclaimed"`
+}
+
+// BatchCompactionResult reports the outcome of compacting one batch. The
+// record batch counts are zero if the server did not report them.
+type BatchCompactionResult struct {
+	// BatchID is the ID of the compacted batch
+	BatchID string `json:"batchId"`
+	// BatchesBefore is the number of record batches the batch held before
+	BatchesBefore int `json:"batchesBefore,omitempty"`
+	// BatchesAfter is the number of record batches it was rewritten into
+	BatchesAfter int `json:"batchesAfter,omitempty"`
+}
+
+// Compact asks the server to compact the stored batches matching criteria, a
+// ListFlights criteria expression such as one built by ListFilter (nil for
+// every batch), and reports the server's statistics. How batches are merged
+// or rewritten is up to the server, so Compact suits maintenance windows
+// rather than the hot path. ErrCompactionUnsupported is returned if the server
+// does not implement compaction.
+func (c *FlightClient) Compact(ctx context.Context, criteria []byte) (CompactionResult, error) {
+	body, err := c.doAction(ctx, ActionCompact, criteria)
+	if errors.Is(err, ErrNotSupported) {
+		return CompactionResult{}, ErrCompactionUnsupported
+	}
+	if err != nil {
+		return CompactionResult{}, fmt.Errorf("failed to compact batches: %w", err)
+	}
+
+	var result CompactionResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		return CompactionResult{}, fmt.Errorf("failed to decode compaction result: %w", err)
+	}
+	return result, nil
+}
+
+// CompactBatch asks the server to rewrite a batch fragmented by appends into
+// optimally sized record batches and returns the ID of the compacted batch,
+// which may differ from batchID. ErrCompactionUnsupported is returned if the
+// server does not implement compaction.
+func (c *FlightClient) CompactBatch(ctx context.Context, batchID string) (string, error) {
+	result, err := c.CompactBatchWithResult(ctx, batchID)
+	return result.BatchID, err
+}
+
+// CompactBatchWithResult compacts a batch like CompactBatch and also reports
+// the record batch counts before and after, if the server provides them
+func (c *FlightClient) CompactBatchWithResult(ctx context.Context, batchID string) (BatchCompactionResult, error) {
+	body, err := c.doAction(ctx, ActionCompactBatch, []byte(batchID))
+	if errors.Is(err, ErrNotSupported) {
+		return BatchCompactionResult{}, ErrCompactionUnsupported
+	}
+	if err != nil {
+		return BatchCompactionResult{}, fmt.Errorf("failed to compact batch %s: %w", batchID, err)
+	}
+
+	var result BatchCompactionResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		return BatchCompactionResult{}, fmt.Errorf("failed to decode compaction result: %w", err)
+	}
+	if result.BatchID == "" {
+		return BatchCompactionResult{}, fmt.Errorf("compaction result for batch %s has no batch ID", batchID)
+	}
+	return result, nil
+}
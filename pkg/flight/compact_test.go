@@ -0,0 +1,105 @@
+package flight
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// compactingServer is a Flight server that answers compaction with fixed
+// statistics and records the criteria or batch ID it was sent
+type compactingServer struct {
+	flight.BaseFlightServer
+	criteria []byte
+	batchID  string
+}
+
+// DoAction reports a compaction of three batches into one, and a batch
+// compaction of five record batches into two under a new ID
+func (s *compactingServer) DoAction(action *flight.Action, stream flight.FlightService_DoActionServer) error {
+	switch action.Type {
+	case ActionCompact:
+		s.criteria = action.Body
+		return stream.Send(&flight.Result{Body: []byte(`{"batchesBefore":3,"batchesAfter":1,"bytesReclaimed":4096}`)})
+	case ActionCompactBatch:
+		s.batchID = string(action.Body)
+		return stream.Send(&flight.Result{Body: []byte(`{"batchId":"compacted","batchesBefore":5,"batchesAfter":2}`)})
+	default:
+		return status.Errorf(codes.Unimplemented, "unknown action %q", action.Type)
+	}
+}
+
+// TestCompact tests that compaction statistics are reported and that servers
+// without compaction yield ErrCompactionUnsupported
+func TestCompact(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("supported", func(t *testing.T) {
+		server := &compactingServer{}
+		client, err := NewFlightClient(FlightClientConfig{Addr: startBareServer(t, server)})
+		require.NoError(t, err, "Failed to create Flight client")
+		defer client.Close()
+
+		criteria := FilterByPrefix("events-")
+		result, err := client.Compact(ctx, criteria)
+		require.NoError(t, err, "Failed to compact")
+		assert.Equal(t, CompactionResult{BatchesBefore: 3, BatchesAfter: 1, BytesReclaimed: 4096}, result)
+		assert.Equal(t, criteria, server.criteria, "The criteria should reach the server")
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		server, addr := startTestServer(t)
+		defer server.Stop()
+
+		client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+		require.NoError(t, err, "Failed to create Flight client")
+		defer client.Close()
+
+		_, err = client.Compact(ctx, nil)
+		assert.ErrorIs(t, err, ErrCompactionUnsupported)
+		assert.ErrorIs(t, err, ErrNotSupported)
+	})
+}
+
+// TestCompactBatch tests that a compacted batch's new ID and record batch
+// counts are reported and that servers without compaction yield
+// ErrCompactionUnsupported
+func TestCompactBatch(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("supported", func(t *testing.T) {
+		server := &compactingServer{}
+		client, err := NewFlightClient(FlightClientConfig{Addr: startBareServer(t, server)})
+		require.NoError(t, err, "Failed to create Flight client")
+		defer client.Close()
+
+		newID, err := client.CompactBatch(ctx, "appended")
+		require.NoError(t, err, "Failed to compact batch")
+		assert.Equal(t, "compacted", newID)
+		assert.Equal(t, "appended", server.batchID, "The batch ID should reach the server")
+
+		result, err := client.CompactBatchWithResult(ctx, "appended")
+		require.NoError(t, err, "Failed to compact batch")
+		assert.Equal(t, BatchCompactionResult{BatchID: "compacted", BatchesBefore: 5, BatchesAfter: 2}, result)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		server, addr := startTestServer(t)
+		defer server.Stop()
+
+		client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+		require.NoError(t, err, "Failed to create Flight client")
+		defer client.Close()
+
+		_, err = client.CompactBatch(ctx, "any")
+		assert.ErrorIs(t, err, ErrCompactionUnsupported)
+	})
+}
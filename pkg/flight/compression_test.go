@@ -0,0 +1,156 @@
+package flight
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createCompressibleBatch creates a batch of repeated values that compresses well
+func createCompressibleBatch(t *testing.T, numRows int) arrow.Record {
+	schema := arrow.NewSchema(
+		[]arrow.Field{
+			{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+			{Name: "category", Type: arrow.BinaryTypes.String},
+		},
+		nil,
+	)
+
+	builder := array.NewRecordBuilder(memory.NewGoAllocator(), schema)
+	defer builder.Release()
+
+	for i := 0; i < numRows; i++ {
+		builder.Field(0).(*array.Int64Builder).Append(42)
+		builder.Field(1).(*array.StringBuilder).Append("the same category every time")
+	}
+
+	return builder.NewRecord()
+}
+
+// TestPutBatchCompressionRatio tests that compressed uploads report their compression ratio
+func TestPutBatchCompressionRatio(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{
+		Addr:        addr,
+		Compression: CompressionZstd,
+	})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	batch := createCompressibleBatch(t, 10000)
+	defer batch.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := client.PutBatchWithOptions(ctx, batch, PutOptions{})
+	require.NoError(t, err, "Failed to put batch")
+	assert.Equal(t, CompressionZstd, result.Compression)
+	assert.Positive(t, result.UncompressedBytes)
+	assert.Less(t, result.CompressionRatio(), 0.1, "Repeated values should compress well")
+
+	// The server decodes the compressed stream transparently
+	retrieved, err := client.GetBatch(ctx, result.BatchID)
+	require.NoError(t, err, "Failed to get batch")
+	defer retrieved.Release()
+	assert.Equal(t, batch.NumRows(), retrieved.NumRows())
+}
+
+// TestCompressionHistogram tests that compression ratios reach the metrics hook and its histogram
+func TestCompressionHistogram(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	histogram := NewCompressionHistogram()
+	recorder := &recordingMetrics{}
+	client, err := NewFlightClient(FlightClientConfig{
+		Addr:        addr,
+		Compression: CompressionZstd,
+		Metrics:     MultiMetrics(histogram, recorder),
+	})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	batch := createCompressibleBatch(t, 10000)
+	defer batch.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for range 3 {
+		_, err := client.PutBatch(ctx, batch)
+		require.NoError(t, err, "Failed to put batch")
+	}
+	_, err = client.GetBatch(ctx, "missing")
+	require.Error(t, err)
+
+	recorder.mu.Lock()
+	stats := recorder.calls
+	recorder.mu.Unlock()
+	require.Len(t, stats, 4)
+	assert.Equal(t, MethodPutBatch, stats[0].Method)
+	assert.Equal(t, CompressionZstd, stats[0].Compression)
+	assert.Less(t, stats[0].CompressionRatio(), 0.1, "Repeated values should compress well")
+
+	summary := histogram.Summary()
+	assert.Equal(t, int64(3), summary.Uploads, "Only uploads should be counted")
+	assert.Less(t, summary.Ratio(), 0.1)
+	assert.Equal(t, int64(3), summary.Buckets[0].Count, "Every upload should fall in the lowest bucket")
+	assert.True(t, math.IsInf(summary.Buckets[len(summary.Buckets)-1].UpperBound, 1))
+}
+
+// TestUnsupportedCompression tests that unknown codecs are rejected at construction
+func TestUnsupportedCompression(t *testing.T) {
+	_, err := NewFlightClient(FlightClientConfig{Compression: "snappy"})
+	assert.Error(t, err)
+}
+
+// compressedGetServer is a Flight server whose DoGet sends a record compressed with a fixed codec
+type compressedGetServer struct {
+	flight.BaseFlightServer
+	batch arrow.Record
+	codec ipc.Option
+}
+
+// DoGet writes the record with the server's codec
+func (s *compressedGetServer) DoGet(request *flight.Ticket, stream flight.FlightService_DoGetServer) error {
+	writer := flight.NewRecordWriter(stream, ipc.WithSchema(s.batch.Schema()), s.codec)
+	defer writer.Close()
+	return writer.Write(s.batch)
+}
+
+// TestGetBatchDecompressesAnyCodec tests that downloads decode regardless of the client's compression setting
+func TestGetBatchDecompressesAnyCodec(t *testing.T) {
+	batch := createCompressibleBatch(t, 10000)
+	defer batch.Release()
+
+	for name, codec := range map[string]ipc.Option{"zstd": ipc.WithZstd(), "lz4": ipc.WithLZ4()} {
+		t.Run(name, func(t *testing.T) {
+			addr := startBareServer(t, &compressedGetServer{batch: batch, codec: codec})
+
+			client, err := NewFlightClient(FlightClientConfig{Addr: addr, Compression: CompressionNone})
+			require.NoError(t, err, "Failed to create Flight client")
+			defer client.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			retrieved, err := client.GetBatch(ctx, "any")
+			require.NoError(t, err, "Compressed stream should decode")
+			defer retrieved.Release()
+
+			assert.True(t, array.RecordEqual(batch, retrieved), "Decoded record should match")
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package flight
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"google.golang.org/grpc"
+)
+
+// dial creates a new Flight client connection to the configured address, or
+// borrows one from the client's pool
+func (c *FlightClient) dial() (flight.Client, error) {
+	if c.pool != nil {
+		return c.pool.acquire(c.poolKey, c.addr, c.dialOpts)
+	}
+
+	conn, err := grpc.NewClient(c.addr, c.dialOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Flight client: %w", err)
+	}
+	return &connClient{Client: flight.NewClientFromConn(conn, nil), conn: conn}, nil
+}
+
+// connClient is a Flight client that owns its connection. Closing the clients
+// made by flight.NewClientWithMiddleware also clears their service stub, which
+// makes calls still in flight on another goroutine panic; closing a
+// connClient only closes the connection, so those calls fail with a gRPC error.
+type connClient struct {
+	flight.Client
+	conn *grpc.ClientConn
+}
+
+// Close closes the connection
+func (c *connClient) Close() error {
+	return c.conn.Close()
+}
+
+// tcpDialer returns a gRPC context dialer whose TCP connections use the given
+// keepalive period, or have keepalive disabled if it is negative
+func tcpDialer(keepAlive time.Duration) func(context.Context, string) (net.Conn, error) {
+	dialer := newTCPDialer(keepAlive)
+	return func(ctx context.Context, addr string) (net.Conn, error) {
+		return dialer.DialContext(ctx, "tcp", addr)
+	}
+}
+
+// newTCPDialer returns a net.Dialer that sets SO_KEEPALIVE and the keepalive
+// idle time and probe interval on the sockets it dials
+func newTCPDialer(keepAlive time.Duration) *net.Dialer {
+	if keepAlive < 0 {
+		return &net.Dialer{KeepAlive: -1}
+	}
+	return &net.Dialer{
+		KeepAlive: keepAlive,
+		KeepAliveConfig: net.KeepAliveConfig{
+			Enable:   true,
+			Idle:     keepAlive,
+			Interval: keepAlive,
+		},
+	}
+}
+
+// acquire returns the Flight client for a new operation, reconnecting lazily if
+// the connection was closed while idle. If MaxConcurrentCalls is set, it first
+// waits for a call slot by the priority set on ctx, and then for a stream slot
+// if MaxConcurrentStreams is set. The returned release function must be
+// called once the operation (including any stream it opened) has finished.
+func (c *FlightClient) acquire(ctx context.Context) (flight.Client, func(), error) {
+	admitCtx, stop := c.withRoot(ctx)
+	admitted, err := c.scheduler.admit(admitCtx)
+	if err != nil {
+		stop()
+		return nil, nil, err
+	}
+	closed, err := c.streams.open(admitCtx)
+	stop()
+	if err != nil {
+		admitted()
+		return nil, nil, err
+	}
+	done := func() {
+		closed()
+		admitted()
+	}
+
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
+
+	if c.closed {
+		done()
+		return nil, nil, ErrClientClosed
+	}
+
+	if c.client == nil {
+		client, err := c.dial()
+		if err != nil {
+			done()
+			return nil, nil, err
+		}
+		c.client = client
+	}
+
+	// An operation in flight keeps the connection open
+	c.inFlight++
+	if c.idleTimer != nil {
+		c.idleTimer.Stop()
+	}
+
+	var once sync.Once
+	return c.client, func() {
+		once.Do(func() {
+			c.release()
+			done()
+		})
+	}, nil
+}
+
+// ActiveCalls returns the number of calls in flight, including open streams
+// and sessions
+func (c *FlightClient) ActiveCalls() int {
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
+	return c.inFlight
+}
+
+// QueuedCalls returns the number of calls waiting for a slot under
+// FlightClientConfig.MaxConcurrentCalls
+func (c *FlightClient) QueuedCalls() int {
+	if c.scheduler == nil {
+		return 0
+	}
+	c.scheduler.mu.Lock()
+	defer c.scheduler.mu.Unlock()
+	return c.scheduler.waiting()
+}
+
+// streamLimiter is a semaphore of the streams a client may have open at once,
+// holding a token per open stream
+type streamLimiter chan struct{}
+
+// open waits for a stream slot, returning the function that gives it back. A
+// nil limiter opens every stream immediately.
+func (l streamLimiter) open(ctx context.Context) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	select {
+	case l <- struct{}{}:
+		return func() { <-l }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// release marks an operation as finished and re-arms the idle timer when the
+// client has no more operations in flight
+func (c *FlightClient) release() {
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
+
+	c.inFlight--
+	c.lastUsed = time.Now()
+	if c.inFlight == 0 {
+		c.closeRetired()
+	}
+	if c.inFlight == 0 && !c.closed {
+		c.armIdleTimer()
+	}
+}
+
+// armIdleTimer (re)starts the idle timer. Must be called with connMu held.
+func (c *FlightClient) armIdleTimer() {
+	if c.idleTimeout <= 0 {
+		return
+	}
+	if c.idleTimer == nil {
+		c.idleTimer = time.AfterFunc(c.idleTimeout, c.closeIdle)
+		return
+	}
+	c.idleTimer.Reset(c.idleTimeout)
+}
+
+// closeIdle closes the underlying connection if the client is still idle. The
+// next operation reconnects transparently.
+func (c *FlightClient) closeIdle() {
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
+
+	// A call may have started (or finished and re-armed the timer) while this
+	// timer was firing
+	if c.client == nil || c.inFlight > 0 || time.Since(c.lastUsed) < c.idleTimeout {
+		return
+	}
+
+	c.client.Close()
+	c.client = nil
+}
@@ -0,0 +1,357 @@
+package flight
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+)
+
+// isConnected reports whether the client currently holds an open connection
+func isConnected(c *FlightClient) bool {
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
+	return c.client != nil
+}
+
+// TestIdleTimeoutReconnect tests that an idle client closes its connection and reopens it on demand
+func TestIdleTimeoutReconnect(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{
+		Addr:        addr,
+		IdleTimeout: 100 * time.Millisecond,
+	})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batchID, err := client.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to put batch")
+	assert.True(t, isConnected(client), "Client should be connected right after a call")
+
+	// Wait for the idle timer to close the connection
+	assert.Eventually(t, func() bool { return !isConnected(client) }, 2*time.Second, 20*time.Millisecond,
+		"Idle connection should be closed")
+
+	// The next call reconnects transparently
+	retrieved, err := client.GetBatch(ctx, batchID)
+	require.NoError(t, err, "Failed to get batch after idle close")
+	defer retrieved.Release()
+	assert.Equal(t, batch.NumRows(), retrieved.NumRows())
+	assert.True(t, isConnected(client), "Client should reconnect on the next call")
+}
+
+// TestClosedClient tests that operations fail after Close
+func TestClosedClient(t *testing.T) {
+	client, err := NewFlightClient(FlightClientConfig{Addr: "localhost:0"})
+	require.NoError(t, err, "Failed to create Flight client")
+	require.NoError(t, client.Close())
+
+	_, err = client.ListBatches(context.Background())
+	assert.ErrorIs(t, err, ErrClientClosed)
+}
+
+// TestConcurrentClose tests that Close is safe while uploads and downloads are in flight, and idempotent
+func TestConcurrentClose(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+	batchID := server.StoreBatch(batch)
+
+	for round := 0; round < 20; round++ {
+		client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+		require.NoError(t, err, "Failed to create Flight client")
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 16)
+		for worker := 0; worker < 8; worker++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					var err error
+					if worker%2 == 0 {
+						_, err = client.PutBatch(context.Background(), batch)
+					} else {
+						var retrieved arrow.Record
+						if retrieved, err = client.GetBatch(context.Background(), batchID); err == nil {
+							retrieved.Release()
+						}
+					}
+					if err != nil {
+						errs <- err
+						return
+					}
+				}
+			}()
+		}
+
+		// Close from several goroutines while the calls run
+		time.Sleep(time.Duration(round%5) * time.Millisecond)
+		var closers sync.WaitGroup
+		for range 3 {
+			closers.Add(1)
+			go func() {
+				defer closers.Done()
+				assert.NoError(t, client.Close())
+			}()
+		}
+		closers.Wait()
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			code := status.Code(err)
+			assert.True(t, errors.Is(err, ErrClientClosed) || code == codes.Canceled || code == codes.Unavailable,
+				"Calls should fail as closed or cancelled, got %v", err)
+		}
+		assert.NoError(t, client.Close(), "A second Close should succeed")
+	}
+}
+
+// TestIdleTimeoutConcurrentCallers tests that calls racing the idle timer always find a usable connection
+func TestIdleTimeoutConcurrentCallers(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{
+		Addr:        addr,
+		IdleTimeout: time.Millisecond,
+	})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8*20)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				// Stagger the calls so some start right as the timer fires
+				time.Sleep(time.Duration((i+j)%3) * time.Millisecond)
+				if _, err := client.ListBatches(ctx); err != nil {
+					errs <- err
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err, "Calls should reconnect transparently")
+	}
+}
+
+// TestTCPKeepAlive tests that the TCP keepalive setting reaches the dialer and the connection works
+func TestTCPKeepAlive(t *testing.T) {
+	dialer := newTCPDialer(30 * time.Second)
+	assert.True(t, dialer.KeepAliveConfig.Enable)
+	assert.Equal(t, 30*time.Second, dialer.KeepAliveConfig.Idle)
+	assert.Equal(t, 30*time.Second, dialer.KeepAliveConfig.Interval)
+	assert.Negative(t, newTCPDialer(-1).KeepAlive, "A negative period should disable keepalive")
+
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr, TCPKeepAlive: 30 * time.Second})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	batchID, err := client.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to put batch over the custom dialer")
+
+	retrieved, err := client.GetBatch(ctx, batchID)
+	require.NoError(t, err, "Failed to get batch over the custom dialer")
+	defer retrieved.Release()
+	assert.Equal(t, batch.NumRows(), retrieved.NumRows())
+}
+
+// countingStatsHandler is a gRPC stats handler counting RPCs and payload bytes
+type countingStatsHandler struct {
+	mu       sync.Mutex
+	rpcs     int
+	outBytes int
+	inBytes  int
+}
+
+// TagRPC implements stats.Handler
+func (h *countingStatsHandler) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+// HandleRPC implements stats.Handler
+func (h *countingStatsHandler) HandleRPC(_ context.Context, s stats.RPCStats) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	switch s := s.(type) {
+	case *stats.Begin:
+		h.rpcs++
+	case *stats.OutPayload:
+		h.outBytes += s.WireLength
+	case *stats.InPayload:
+		h.inBytes += s.WireLength
+	}
+}
+
+// TagConn implements stats.Handler
+func (h *countingStatsHandler) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+// HandleConn implements stats.Handler
+func (h *countingStatsHandler) HandleConn(context.Context, stats.ConnStats) {}
+
+// TestStatsHandler tests that an injected stats handler observes the client's RPCs
+func TestStatsHandler(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	handler := &countingStatsHandler{}
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr, StatsHandler: handler})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	batchID, err := client.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to put batch")
+
+	retrieved, err := client.GetBatch(ctx, batchID)
+	require.NoError(t, err, "Failed to get batch")
+	retrieved.Release()
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	assert.Equal(t, 2, handler.rpcs, "Both calls should be observed")
+	assert.Positive(t, handler.outBytes)
+	assert.Positive(t, handler.inBytes)
+}
+
+// blockingGetServer is a Flight server whose DoGet blocks until the call is cancelled
+type blockingGetServer struct {
+	flight.BaseFlightServer
+	started chan struct{}
+}
+
+// DoGet waits for the call to end
+func (s *blockingGetServer) DoGet(request *flight.Ticket, stream flight.FlightService_DoGetServer) error {
+	close(s.started)
+	<-stream.Context().Done()
+	return stream.Context().Err()
+}
+
+// TestClientContext tests that ending the client's root context aborts outstanding calls and closes the client
+func TestClientContext(t *testing.T) {
+	server := &blockingGetServer{started: make(chan struct{})}
+	addr := startBareServer(t, server)
+
+	root, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr, Context: root})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := client.GetBatch(context.Background(), "any")
+		errs <- err
+	}()
+
+	select {
+	case <-server.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Download did not start")
+	}
+	cancel()
+
+	select {
+	case err := <-errs:
+		assert.Error(t, err, "The outstanding call should be aborted")
+	case <-time.After(5 * time.Second):
+		t.Fatal("Outstanding call was not aborted")
+	}
+
+	require.Eventually(t, func() bool {
+		_, err := client.ListBatches(context.Background())
+		return errors.Is(err, ErrClientClosed)
+	}, time.Second, 10*time.Millisecond, "New calls should fail once the client is closed")
+
+	_, err = NewFlightClient(FlightClientConfig{Addr: addr, Context: root})
+	assert.Error(t, err, "An ended context cannot start a client")
+}
+
+// hintServer is a Flight server recording the deadline hint of its DoAction calls
+type hintServer struct {
+	flight.BaseFlightServer
+	hints chan time.Duration
+}
+
+// DoAction records the hint, or -1 if there is none
+func (s *hintServer) DoAction(action *flight.Action, stream flight.FlightService_DoActionServer) error {
+	hint, ok := DeadlineHint(stream.Context())
+	if !ok {
+		hint = -1
+	}
+	s.hints <- hint
+	return nil
+}
+
+// TestDeadlineHint tests that the remaining time is sent only when enabled
+func TestDeadlineHint(t *testing.T) {
+	server := &hintServer{hints: make(chan time.Duration, 1)}
+	addr := startBareServer(t, server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for _, enabled := range []bool{true, false} {
+		client, err := NewFlightClient(FlightClientConfig{Addr: addr, DeadlineHint: enabled})
+		require.NoError(t, err, "Failed to create Flight client")
+
+		_, err = client.doAction(ctx, "any", nil)
+		require.NoError(t, err, "Action should succeed")
+		hint := <-server.hints
+		if enabled {
+			assert.Greater(t, hint, 4*time.Second, "The hint should be the time left")
+			assert.LessOrEqual(t, hint, 5*time.Second)
+		} else {
+			assert.Equal(t, time.Duration(-1), hint, "No hint should be sent unless enabled")
+		}
+		client.Close()
+	}
+}
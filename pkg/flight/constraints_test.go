@@ -0,0 +1,55 @@
+package flight
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	arrow_utils "github.com/TFMV/temporal/pkg/arrow"
+)
+
+// TestPutBatchConstraints tests that uploads breaking the client's or the
+// call's constraints are rejected before they are sent
+func TestPutBatchConstraints(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{
+		Addr:        addr,
+		Constraints: []arrow_utils.Constraint{{Column: "id", Kind: arrow_utils.ConstraintRange, Min: 1}},
+	})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	_, err = client.PutBatch(ctx, batch)
+	require.NoError(t, err, "A valid batch should be uploaded")
+
+	// Per-call constraints add to the client's
+	_, err = client.PutBatchWithOptions(ctx, batch, PutOptions{
+		Constraints: []arrow_utils.Constraint{{Column: "value", Kind: arrow_utils.ConstraintRange, Max: 4.0}},
+		Columns:     []string{"id"},
+	})
+	require.ErrorIs(t, err, ErrConstraintViolation)
+	var violated *arrow_utils.ConstraintError
+	require.ErrorAs(t, err, &violated)
+	assert.Equal(t, []int64{3, 4}, violated.Violations[0].Rows)
+
+	// Streamed records are checked too
+	records := sendRecords(createRecord(t, []string{"id"}, map[string]any{"id": []int32{1, 0}}))
+	_, err = client.PutStream(ctx, records, PutStreamOptions{})
+	assert.ErrorIs(t, err, ErrConstraintViolation)
+
+	entries, err := client.ListCatalog(ctx, nil)
+	require.NoError(t, err, "Failed to list catalog")
+	assert.Len(t, entries, 1, "Only the valid batch should be stored")
+}
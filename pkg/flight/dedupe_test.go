@@ -0,0 +1,187 @@
+package flight
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPutBatchDedupeByContent tests that identical uploads are stored once
+func TestPutBatchDedupeByContent(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	first, err := client.PutBatchWithOptions(ctx, batch, PutOptions{DedupeByContent: true})
+	require.NoError(t, err, "Failed to put batch")
+	assert.False(t, first.Deduplicated, "The first upload should be stored")
+
+	second, err := client.PutBatchWithOptions(ctx, batch, PutOptions{DedupeByContent: true})
+	require.NoError(t, err, "Failed to put batch")
+	assert.True(t, second.Deduplicated, "An identical upload should reuse the stored batch")
+	assert.Equal(t, first.BatchID, second.BatchID)
+
+	// Uploads without the option, or with other contents, are stored anew
+	plain, err := client.PutBatchWithOptions(ctx, batch, PutOptions{})
+	require.NoError(t, err, "Failed to put batch")
+	assert.False(t, plain.Deduplicated)
+	assert.NotEqual(t, first.BatchID, plain.BatchID)
+
+	projected, err := client.PutBatchWithOptions(ctx, batch, PutOptions{DedupeByContent: true, Columns: []string{"id"}})
+	require.NoError(t, err, "Failed to put batch")
+	assert.False(t, projected.Deduplicated)
+	assert.NotEqual(t, first.BatchID, projected.BatchID)
+
+	// Restricted batches are never shared
+	restricted, err := client.PutBatchWithOptions(ctx, batch, PutOptions{DedupeByContent: true, Access: &AccessPolicy{AllowedPrincipals: []string{"alice"}}})
+	require.NoError(t, err, "Failed to put batch")
+	assert.False(t, restricted.Deduplicated)
+	assert.NotEqual(t, first.BatchID, restricted.BatchID)
+
+	// Once the stored batch is dropped its contents are stored again
+	_, err = client.doAction(ctx, ActionDropBatch, []byte(first.BatchID))
+	require.NoError(t, err, "Failed to drop batch")
+	third, err := client.PutBatchWithOptions(ctx, batch, PutOptions{DedupeByContent: true})
+	require.NoError(t, err, "Failed to put batch")
+	assert.False(t, third.Deduplicated)
+	assert.NotEqual(t, first.BatchID, third.BatchID)
+
+	retrieved, err := client.GetBatch(ctx, third.BatchID)
+	require.NoError(t, err, "Failed to get batch")
+	defer retrieved.Release()
+	assert.Equal(t, batch.NumRows(), retrieved.NumRows())
+
+	// Uploads under a client-chosen ID are stored under that ID
+	typed := NewTypedClient[string](client, StringCodec{})
+	named, err := typed.Put(ctx, "named", batch, PutOptions{DedupeByContent: true})
+	require.NoError(t, err, "Failed to put batch")
+	assert.False(t, named.Deduplicated, "A named upload should not be deduplicated")
+	assert.Equal(t, "named", named.BatchID)
+	fromName, err := typed.Get(ctx, "named", GetOptions{})
+	require.NoError(t, err, "A named upload should be readable under its name")
+	fromName.Release()
+}
+
+// TestPutBatchDedupeHashMismatch tests that the server rejects a content hash that does not match the upload
+func TestPutBatchDedupeHashMismatch(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	_, err = client.putBatch(ctx, batch, PutOptions{}, putMetadata{ContentHash: "sha256:bogus"})
+	assert.ErrorContains(t, err, "does not match the uploaded batch")
+}
+
+// upsertServer is a Flight server that acknowledges dedup keys and records
+// the ones it was sent
+type upsertServer struct {
+	flight.BaseFlightServer
+	mu   sync.Mutex
+	keys []string
+}
+
+// DoPut reads the upload and acknowledges its dedup keys
+func (s *upsertServer) DoPut(stream flight.FlightService_DoPutServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	var meta putMetadata
+	if err := json.Unmarshal(first.AppMetadata, &meta); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.keys = meta.DedupKeys
+	s.mu.Unlock()
+
+	for {
+		if _, err := stream.Recv(); err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+	ack, err := json.Marshal(putResult{BatchID: "upserted", DedupKeys: len(meta.DedupKeys) > 0})
+	if err != nil {
+		return err
+	}
+	return stream.Send(&flight.PutResult{AppMetadata: ack})
+}
+
+// TestPutBatchDedupKeys tests that dedup keys are validated and reach a server
+// that upserts by them, and that other servers yield ErrDedupUnsupported
+func TestPutBatchDedupKeys(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	t.Run("supported", func(t *testing.T) {
+		server := &upsertServer{}
+		client, err := NewFlightClient(FlightClientConfig{Addr: startBareServer(t, server)})
+		require.NoError(t, err, "Failed to create Flight client")
+		defer client.Close()
+
+		result, err := client.PutBatchWithOptions(ctx, batch, PutOptions{DedupKeys: []string{"id"}})
+		require.NoError(t, err, "Failed to put batch")
+		assert.Equal(t, "upserted", result.BatchID)
+		server.mu.Lock()
+		assert.Equal(t, []string{"id"}, server.keys, "The dedup keys should reach the server")
+		server.mu.Unlock()
+
+		// Keys missing from the uploaded columns are rejected before sending
+		server.mu.Lock()
+		server.keys = nil
+		server.mu.Unlock()
+		_, err = client.PutBatchWithOptions(ctx, batch, PutOptions{DedupKeys: []string{"missing"}})
+		assert.ErrorContains(t, err, `"missing"`)
+		_, err = client.PutBatchWithOptions(ctx, batch, PutOptions{DedupKeys: []string{"id"}, Columns: []string{"name"}})
+		assert.ErrorContains(t, err, `"id"`)
+		server.mu.Lock()
+		assert.Nil(t, server.keys, "Invalid uploads should not be sent")
+		server.mu.Unlock()
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		server, addr := startTestServer(t)
+		defer server.Stop()
+
+		client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+		require.NoError(t, err, "Failed to create Flight client")
+		defer client.Close()
+
+		_, err = client.PutBatchWithOptions(ctx, batch, PutOptions{DedupKeys: []string{"id"}})
+		assert.ErrorIs(t, err, ErrDedupUnsupported)
+		assert.ErrorIs(t, err, ErrNotSupported)
+		entries, err := client.ListCatalog(ctx, nil)
+		require.NoError(t, err, "Failed to list catalog")
+		assert.Empty(t, entries, "Nothing should be stored")
+	})
+}
@@ -0,0 +1,154 @@
+package flight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	arrow_utils "github.com/TFMV/temporal/pkg/arrow"
+)
+
+// DeltaOptions contains options for PutDelta
+type DeltaOptions struct {
+	// Keys are the columns that identify a row. Required.
+	Keys []string
+}
+
+// PutDelta uploads a delta against the base batch and returns the ID of the new
+// batch the server builds from it; the base batch is left unchanged. Added rows
+// are appended, updated rows replace the base rows with the same key, and rows
+// whose key appears in deletedKeys are removed (see arrow_utils.ApplyDelta for
+// the exact rules). Any of added, updated and deletedKeys may be nil.
+//
+// deletedKeys holds the values of the single key column, or, with several key
+// columns, is a struct array with one field per key column.
+//
+// The server must support delta uploads; this server does. ErrNotSupported is
+// returned without uploading if the server's capabilities lack them. Servers
+// that cannot report capabilities and don't support deltas store the rows as
+// a plain batch, so that batch is dropped and ErrNotSupported is returned.
+func (c *FlightClient) PutDelta(ctx context.Context, baseID string, added, updated arrow.Record, deletedKeys arrow.Array, options DeltaOptions) (string, error) {
+	if len(options.Keys) == 0 {
+		return "", fmt.Errorf("at least one key column is required")
+	}
+
+	if err := c.requireFeature(ctx, "delta uploads", func(s ServerCapabilities) bool { return s.DeltaUploads }); err != nil {
+		return "", err
+	}
+
+	delta := &deltaMetadata{BaseID: baseID, Keys: options.Keys}
+
+	// Encode the deleted keys as an IPC stream
+	if deletedKeys != nil {
+		deleted, err := deletedKeysRecord(deletedKeys, options.Keys)
+		if err != nil {
+			return "", err
+		}
+		delta.Deleted, err = arrow_utils.NewSerializer(c.allocator).SerializeRecord(deleted)
+		deleted.Release()
+		if err != nil {
+			return "", fmt.Errorf("failed to encode deleted keys: %w", err)
+		}
+	}
+
+	// Send the added rows followed by the updated rows
+	upserts, err := c.deltaUpserts(ctx, baseID, added, updated)
+	if err != nil {
+		return "", err
+	}
+	defer upserts.Release()
+	if added != nil {
+		delta.Added = added.NumRows()
+	}
+
+	result, err := c.putBatch(ctx, upserts, PutOptions{}, putMetadata{Lineage: []string{baseID}, Delta: delta})
+	if err != nil {
+		return "", fmt.Errorf("failed to put delta for batch %s: %w", baseID, err)
+	}
+
+	if !result.deltaApplied {
+		err := fmt.Errorf("%w: delta uploads", ErrNotSupported)
+		if _, dropErr := c.doAction(context.WithoutCancel(ctx), ActionDropBatch, []byte(result.BatchID)); dropErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to drop batch %s: %w", result.BatchID, dropErr))
+		}
+		return "", err
+	}
+
+	return result.BatchID, nil
+}
+
+// deltaUpserts combines the added and updated rows into the record uploaded by
+// PutDelta. With neither, an empty record with the base batch's schema is used.
+func (c *FlightClient) deltaUpserts(ctx context.Context, baseID string, added, updated arrow.Record) (arrow.Record, error) {
+	switch {
+	case added != nil && updated != nil:
+		upserts, err := arrow_utils.ConcatRecords(c.allocator, added.Schema(), []arrow.Record{added, updated})
+		if err != nil {
+			return nil, fmt.Errorf("added and updated rows must share a schema: %w", err)
+		}
+		return upserts, nil
+	case added != nil:
+		added.Retain()
+		return added, nil
+	case updated != nil:
+		updated.Retain()
+		return updated, nil
+	default:
+		schema, err := c.GetSchema(ctx, baseID)
+		if err != nil {
+			return nil, err
+		}
+		return emptyRecord(c.allocator, schema), nil
+	}
+}
+
+// deletedKeysRecord wraps the deleted key values in a record of the key columns
+func deletedKeysRecord(deletedKeys arrow.Array, keys []string) (arrow.Record, error) {
+	if structKeys, ok := deletedKeys.(*array.Struct); ok {
+		return array.RecordFromStructArray(structKeys, nil), nil
+	}
+	if len(keys) != 1 {
+		return nil, fmt.Errorf("deleted keys for %d key columns must be a struct array", len(keys))
+	}
+
+	schema := arrow.NewSchema([]arrow.Field{{Name: keys[0], Type: deletedKeys.DataType(), Nullable: true}}, nil)
+	return array.NewRecord(schema, []arrow.Array{deletedKeys}, int64(deletedKeys.Len())), nil
+}
+
+// applyDelta builds the batch described by a delta upload
+func (s *FlightServer) applyDelta(delta *deltaMetadata, upserts arrow.Record) (arrow.Record, error) {
+	base, ok := s.acquireBatch(delta.BaseID)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "batch with ID %s not found", delta.BaseID)
+	}
+	defer base.Release()
+
+	if delta.Added < 0 || delta.Added > upserts.NumRows() {
+		return nil, status.Errorf(codes.InvalidArgument, "delta declares %d added rows of %d", delta.Added, upserts.NumRows())
+	}
+	added := upserts.NewSlice(0, delta.Added)
+	defer added.Release()
+	updated := upserts.NewSlice(delta.Added, upserts.NumRows())
+	defer updated.Release()
+
+	var deleted arrow.Record
+	if len(delta.Deleted) > 0 {
+		var err error
+		deleted, err = arrow_utils.NewSerializer(s.allocator).DeserializeRecord(delta.Deleted)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid deleted keys: %v", err)
+		}
+		defer deleted.Release()
+	}
+
+	merged, err := arrow_utils.ApplyDelta(context.Background(), base, added, updated, deleted, delta.Keys, s.allocator)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to apply delta to batch %s: %v", delta.BaseID, err)
+	}
+	return merged, nil
+}
@@ -0,0 +1,168 @@
+package flight
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// createPeopleBatch creates a batch of people keyed by id
+func createPeopleBatch(t *testing.T, ids []int64, names []string) arrow.Record {
+	schema := arrow.NewSchema(
+		[]arrow.Field{
+			{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+			{Name: "name", Type: arrow.BinaryTypes.String},
+		},
+		nil,
+	)
+
+	builder := array.NewRecordBuilder(memory.NewGoAllocator(), schema)
+	defer builder.Release()
+	builder.Field(0).(*array.Int64Builder).AppendValues(ids, nil)
+	builder.Field(1).(*array.StringBuilder).AppendValues(names, nil)
+
+	return builder.NewRecord()
+}
+
+// peopleRows returns the rows of a people batch as id -> name pairs in order
+func peopleRows(rec arrow.Record) ([]int64, []string) {
+	ids := rec.Column(0).(*array.Int64)
+	names := rec.Column(1).(*array.String)
+	outIDs := make([]int64, rec.NumRows())
+	outNames := make([]string, rec.NumRows())
+	for i := range outIDs {
+		outIDs[i] = ids.Value(i)
+		outNames[i] = names.Value(i)
+	}
+	return outIDs, outNames
+}
+
+// TestPutDelta tests applying deltas and reading the merged result
+func TestPutDelta(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	base := createPeopleBatch(t, []int64{1, 2, 3, 4}, []string{"ann", "bob", "cat", "dan"})
+	defer base.Release()
+	baseID, err := client.PutBatch(ctx, base)
+	require.NoError(t, err, "Failed to put base batch")
+
+	added := createPeopleBatch(t, []int64{5}, []string{"eve"})
+	defer added.Release()
+	updated := createPeopleBatch(t, []int64{2}, []string{"bobby"})
+	defer updated.Release()
+
+	deletedBuilder := array.NewInt64Builder(memory.NewGoAllocator())
+	deletedBuilder.AppendValues([]int64{3, 99}, nil)
+	deleted := deletedBuilder.NewInt64Array()
+	deletedBuilder.Release()
+	defer deleted.Release()
+
+	options := DeltaOptions{Keys: []string{"id"}}
+
+	t.Run("Merge", func(t *testing.T) {
+		versionID, err := client.PutDelta(ctx, baseID, added, updated, deleted, options)
+		require.NoError(t, err, "Failed to put delta")
+		assert.NotEqual(t, baseID, versionID)
+
+		merged, err := client.GetBatch(ctx, versionID)
+		require.NoError(t, err, "Failed to get merged batch")
+		defer merged.Release()
+
+		ids, names := peopleRows(merged)
+		assert.Equal(t, []int64{1, 2, 4, 5}, ids)
+		assert.Equal(t, []string{"ann", "bobby", "dan", "eve"}, names)
+
+		// The base batch is unchanged and recorded as the parent
+		original, err := client.GetBatch(ctx, baseID)
+		require.NoError(t, err)
+		defer original.Release()
+		assert.Equal(t, base.NumRows(), original.NumRows())
+
+		parents, err := client.GetLineage(ctx, versionID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{baseID}, parents)
+	})
+
+	t.Run("DeleteOnly", func(t *testing.T) {
+		versionID, err := client.PutDelta(ctx, baseID, nil, nil, deleted, options)
+		require.NoError(t, err, "Failed to put delta")
+
+		merged, err := client.GetBatch(ctx, versionID)
+		require.NoError(t, err)
+		defer merged.Release()
+		ids, _ := peopleRows(merged)
+		assert.Equal(t, []int64{1, 2, 4}, ids)
+	})
+
+	t.Run("UpdateMissingKey", func(t *testing.T) {
+		missing := createPeopleBatch(t, []int64{42}, []string{"nobody"})
+		defer missing.Release()
+
+		_, err := client.PutDelta(ctx, baseID, nil, missing, nil, options)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("AddExistingKey", func(t *testing.T) {
+		_, err := client.PutDelta(ctx, baseID, updated, nil, nil, options)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("UnknownBase", func(t *testing.T) {
+		_, err := client.PutDelta(ctx, "missing", added, nil, nil, options)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
+
+// plainPutServer is a Flight server that stores uploads without understanding deltas
+type plainPutServer struct {
+	flight.BaseFlightServer
+}
+
+// DoPut reads up to the first record batch and replies with a bare batch ID
+func (s *plainPutServer) DoPut(stream flight.FlightService_DoPutServer) error {
+	for {
+		data, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		if len(data.DataBody) > 0 {
+			break
+		}
+	}
+	return stream.Send(&flight.PutResult{AppMetadata: []byte("batch-plain")})
+}
+
+// TestPutDeltaUnsupported tests that servers without delta support are detected
+func TestPutDeltaUnsupported(t *testing.T) {
+	addr := startBareServer(t, &plainPutServer{})
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	added := createPeopleBatch(t, []int64{5}, []string{"eve"})
+	defer added.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = client.PutDelta(ctx, "base", added, nil, nil, DeltaOptions{Keys: []string{"id"}})
+	assert.ErrorIs(t, err, ErrNotSupported)
+}
@@ -0,0 +1,146 @@
+package flight
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+)
+
+// DescriptorCodec maps a structured batch ID type to the batch ID string the
+// server stores it under, which is carried in Flight descriptors and tickets.
+// Encoding must be deterministic so the same ID always names the same batch.
+// Implementations must be safe for concurrent use.
+type DescriptorCodec[ID any] interface {
+	// EncodeID returns the batch ID string for id
+	EncodeID(id ID) (string, error)
+	// DecodeID parses a batch ID string back into a structured ID
+	DecodeID(batchID string) (ID, error)
+}
+
+// StringCodec is the DescriptorCodec for opaque string IDs, passing them
+// through unchanged as FlightClient does
+type StringCodec struct{}
+
+// EncodeID implements DescriptorCodec
+func (StringCodec) EncodeID(id string) (string, error) {
+	return id, nil
+}
+
+// DecodeID implements DescriptorCodec
+func (StringCodec) DecodeID(batchID string) (string, error) {
+	return batchID, nil
+}
+
+// JSONCodec is a DescriptorCodec encoding IDs as JSON, for struct IDs such as
+// a tenant, dataset and partition. Struct fields are encoded in declaration
+// order, so equal IDs encode identically; map-typed IDs are sorted by key.
+type JSONCodec[ID any] struct{}
+
+// EncodeID implements DescriptorCodec
+func (JSONCodec[ID]) EncodeID(id ID) (string, error) {
+	encoded, err := json.Marshal(id)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode batch ID: %w", err)
+	}
+	return string(encoded), nil
+}
+
+// DecodeID implements DescriptorCodec
+func (JSONCodec[ID]) DecodeID(batchID string) (ID, error) {
+	var id ID
+	if err := json.Unmarshal([]byte(batchID), &id); err != nil {
+		return id, fmt.Errorf("failed to decode batch ID %q: %w", batchID, err)
+	}
+	return id, nil
+}
+
+// TypedClient wraps a FlightClient to address batches by a structured ID
+// type, converted with a DescriptorCodec. Batches are uploaded under their
+// encoded ID, which requires a server accepting client-named batches
+// (ServerCapabilities.ClientBatchIDs).
+type TypedClient[ID any] struct {
+	client *FlightClient
+	codec  DescriptorCodec[ID]
+}
+
+// NewTypedClient creates a TypedClient. It does not take ownership of client.
+func NewTypedClient[ID any](client *FlightClient, codec DescriptorCodec[ID]) *TypedClient[ID] {
+	return &TypedClient[ID]{client: client, codec: codec}
+}
+
+// Client returns the wrapped FlightClient
+func (t *TypedClient[ID]) Client() *FlightClient {
+	return t.client
+}
+
+// Descriptor returns the Flight descriptor of the batch named id
+func (t *TypedClient[ID]) Descriptor(id ID) (*flight.FlightDescriptor, error) {
+	batchID, err := t.codec.EncodeID(id)
+	if err != nil {
+		return nil, err
+	}
+	return &flight.FlightDescriptor{Type: flight.DescriptorCMD, Cmd: t.client.batchCommand(batchID)}, nil
+}
+
+// Ticket returns the DoGet ticket of the batch named id
+func (t *TypedClient[ID]) Ticket(id ID) (*flight.Ticket, error) {
+	batchID, err := t.codec.EncodeID(id)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := t.client.encodeTicket(ticket{BatchID: batchID})
+	if err != nil {
+		return nil, err
+	}
+	return &flight.Ticket{Ticket: raw}, nil
+}
+
+// Put uploads a batch under id. Uploading a second batch under the same ID
+// keeps the first, as with FlightClientConfig.IDGenerator.
+func (t *TypedClient[ID]) Put(ctx context.Context, id ID, batch arrow.Record, options PutOptions) (*PutBatchResult, error) {
+	batchID, err := t.codec.EncodeID(id)
+	if err != nil {
+		return nil, err
+	}
+	return t.client.putBatchWithOptions(ctx, batch, options, batchID)
+}
+
+// Get retrieves the batch named id
+func (t *TypedClient[ID]) Get(ctx context.Context, id ID, options GetOptions) (arrow.Record, error) {
+	batchID, err := t.codec.EncodeID(id)
+	if err != nil {
+		return nil, err
+	}
+	return t.client.GetBatchWithOptions(ctx, batchID, options)
+}
+
+// GetStream opens a BatchStream for the batch named id
+func (t *TypedClient[ID]) GetStream(ctx context.Context, id ID, options GetOptions) (*BatchStream, error) {
+	batchID, err := t.codec.EncodeID(id)
+	if err != nil {
+		return nil, err
+	}
+	return t.client.GetBatchStreamWithOptions(ctx, batchID, options)
+}
+
+// List returns the IDs of the stored batches. Batches whose IDs the codec
+// cannot decode, such as those named by the server, are left out.
+func (t *TypedClient[ID]) List(ctx context.Context) ([]ID, error) {
+	batchIDs, err := t.client.ListBatches(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]ID, 0, len(batchIDs))
+	for _, batchID := range batchIDs {
+		id, err := t.codec.DecodeID(batchID)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
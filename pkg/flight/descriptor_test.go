@@ -0,0 +1,88 @@
+package flight
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// partitionID is a structured batch ID
+type partitionID struct {
+	Tenant    string `json:"tenant"`
+	Dataset   string `json:"dataset"`
+	Partition int    `json:"partition"`
+}
+
+// TestTypedClient tests that batches are stored and listed under structured IDs
+func TestTypedClient(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	typed := NewTypedClient[partitionID](client, JSONCodec[partitionID]{})
+	id := partitionID{Tenant: "acme", Dataset: "sensors", Partition: 7}
+
+	result, err := typed.Put(ctx, id, batch, PutOptions{})
+	require.NoError(t, err, "Failed to put batch")
+	assert.Equal(t, `{"tenant":"acme","dataset":"sensors","partition":7}`, result.BatchID)
+
+	retrieved, err := typed.Get(ctx, id, GetOptions{})
+	require.NoError(t, err, "Failed to get batch")
+	defer retrieved.Release()
+	assert.Equal(t, batch.NumRows(), retrieved.NumRows())
+
+	descriptor, err := typed.Descriptor(id)
+	require.NoError(t, err)
+	assert.Equal(t, result.BatchID, string(descriptor.Cmd))
+
+	// Server-named batches are not listed as structured IDs
+	_, err = client.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to put batch")
+	ids, err := typed.List(ctx)
+	require.NoError(t, err, "Failed to list batches")
+	assert.Equal(t, []partitionID{id}, ids)
+
+	_, err = typed.Get(ctx, partitionID{Tenant: "other"}, GetOptions{})
+	assert.Error(t, err, "Unknown IDs should not be found")
+}
+
+// TestStringCodec tests that the string codec keeps raw batch IDs
+func TestStringCodec(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batchID, err := client.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to put batch")
+
+	typed := NewTypedClient[string](client, StringCodec{})
+	ticket, err := typed.Ticket(batchID)
+	require.NoError(t, err)
+	assert.Equal(t, batchID, string(ticket.Ticket), "Tickets should be the bare ID")
+
+	ids, err := typed.List(ctx)
+	require.NoError(t, err, "Failed to list batches")
+	assert.Equal(t, []string{batchID}, ids)
+}
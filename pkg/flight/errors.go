@@ -0,0 +1,151 @@
+package flight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrNotSupported is returned when the connected Flight server does not
+// implement an optional capability the client relies on
+var ErrNotSupported = errors.New("operation not supported by Flight server")
+
+// ErrClientClosed is returned by operations started after Close
+var ErrClientClosed = errors.New("flight client is closed")
+
+// ErrSessionClosed is returned by Session requests made after the session ended
+var ErrSessionClosed = errors.New("flight session is closed")
+
+// ErrWriterClosed is returned by PutWriter.Write after Close
+var ErrWriterClosed = errors.New("put writer is closed")
+
+// ErrLimitExceeded is returned when a download exceeds the row or batch limits
+// set in GetOptions
+var ErrLimitExceeded = errors.New("download limit exceeded")
+
+// ErrSchemaMismatch is returned when a downloaded batch does not have the
+// schema set in GetOptions.ExpectedSchema
+var ErrSchemaMismatch = errors.New("batch schema does not match the expected schema")
+
+// ErrSchemaChanged is returned when the server changes the schema part way
+// through a download and GetOptions.AllowSchemaChange is not set, or when a
+// download combined into one record changes schema
+var ErrSchemaChanged = errors.New("batch schema changed mid-stream")
+
+// ErrNotSorted is returned by PutBatchWithOptions when a batch is not sorted by
+// the columns listed in PutOptions.RequireSorted
+var ErrNotSorted = errors.New("batch is not sorted")
+
+// ErrConstraintViolation is returned by uploads whose rows break the
+// configured constraints. It wraps the *arrow_utils.ConstraintError listing
+// the offending rows.
+var ErrConstraintViolation = errors.New("batch violates constraints")
+
+// ErrPreconditionFailed is returned by conditional name updates when the name
+// no longer has the ETag given in NameOptions.IfMatch
+var ErrPreconditionFailed = errors.New("precondition failed")
+
+// ErrQuorumNotMet is returned by MultiClient writes that fewer targets than the
+// configured quorum accepted
+var ErrQuorumNotMet = errors.New("write quorum not met")
+
+// ErrDedupUnsupported is returned by uploads with PutOptions.DedupKeys when
+// the server cannot deduplicate rows by key. It wraps ErrNotSupported.
+var ErrDedupUnsupported = fmt.Errorf("%w: key deduplication", ErrNotSupported)
+
+// ErrTxDone is returned by operations on a Tx that was already committed or
+// rolled back
+var ErrTxDone = errors.New("transaction already committed or rolled back")
+
+// FlightServerError is a gRPC status returned by the Flight server, kept
+// intact so callers can inspect it with errors.As. status.Code keeps working
+// on errors that wrap it. Unimplemented errors, returned by minimal servers
+// for the RPCs they lack (such as ListFlights, ListActions, GetSchema or
+// PollFlightInfo), also match ErrNotSupported with errors.Is.
+type FlightServerError struct {
+	Code    codes.Code
+	Message string
+	// Details holds the error detail messages attached to the status (such as
+	// errdetails.BadRequest). Details whose type is not linked into the
+	// binary cannot be parsed and are omitted.
+	Details []proto.Message
+
+	status *status.Status
+}
+
+// Error implements the error interface
+func (e *FlightServerError) Error() string {
+	return fmt.Sprintf("flight server error: code = %s desc = %s", e.Code, e.Message)
+}
+
+// Is reports whether the error is an Unimplemented status and target is
+// ErrNotSupported, so callers can branch on missing RPCs without inspecting
+// status codes
+func (e *FlightServerError) Is(target error) bool {
+	return target == ErrNotSupported && e.Code == codes.Unimplemented
+}
+
+// GRPCStatus returns the original status so status.Code and status.FromError
+// see through the typed error
+func (e *FlightServerError) GRPCStatus() *status.Status {
+	return e.status
+}
+
+// serverError converts a gRPC status error into a *FlightServerError. Other
+// errors, including io.EOF at the end of a stream, are returned unchanged.
+func serverError(err error) error {
+	if err == nil {
+		return nil
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	serverErr := &FlightServerError{Code: st.Code(), Message: st.Message(), status: st}
+	for _, detail := range st.Details() {
+		if msg, ok := detail.(proto.Message); ok {
+			serverErr.Details = append(serverErr.Details, msg)
+		}
+	}
+	return serverErr
+}
+
+// unaryErrorInterceptor converts the status errors of unary calls into
+// *FlightServerError
+func unaryErrorInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return serverError(invoker(ctx, method, req, reply, cc, opts...))
+}
+
+// streamErrorInterceptor converts the status errors of streaming calls into
+// *FlightServerError
+func streamErrorInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		return nil, serverError(err)
+	}
+	return &errorStream{ClientStream: stream}, nil
+}
+
+// errorStream is a client stream whose receive errors are converted into
+// *FlightServerError
+type errorStream struct {
+	grpc.ClientStream
+}
+
+// RecvMsg receives the next message, converting status errors
+func (s *errorStream) RecvMsg(m any) error {
+	return serverError(s.ClientStream.RecvMsg(m))
+}
+
+// Header returns the response header, converting status errors
+func (s *errorStream) Header() (metadata.MD, error) {
+	md, err := s.ClientStream.Header()
+	return md, serverError(err)
+}
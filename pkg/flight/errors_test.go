@@ -0,0 +1,118 @@
+package flight
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// validatingServer is a Flight server whose DoPut rejects every batch with a
+// detailed validation error
+type validatingServer struct {
+	flight.BaseFlightServer
+}
+
+// DoPut rejects the upload, naming the offending column
+func (s *validatingServer) DoPut(stream flight.FlightService_DoPutServer) error {
+	if _, err := stream.Recv(); err != nil {
+		return err
+	}
+
+	st, err := status.New(codes.InvalidArgument, "batch failed validation").WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: "value", Description: "must not be negative"},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return st.Err()
+}
+
+// TestFlightServerErrorDetails tests that status details attached by the server are exposed to callers
+func TestFlightServerErrorDetails(t *testing.T) {
+	addr := startBareServer(t, &validatingServer{})
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = client.PutBatch(ctx, batch)
+	require.Error(t, err)
+
+	var serverErr *FlightServerError
+	require.True(t, errors.As(err, &serverErr), "Error should wrap a FlightServerError")
+	assert.Equal(t, codes.InvalidArgument, serverErr.Code)
+	assert.Equal(t, "batch failed validation", serverErr.Message)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "status.Code should see through the typed error")
+
+	require.Len(t, serverErr.Details, 1)
+	badRequest, ok := serverErr.Details[0].(*errdetails.BadRequest)
+	require.True(t, ok, "Detail should be parsed as BadRequest")
+	require.Len(t, badRequest.FieldViolations, 1)
+	assert.Equal(t, "value", badRequest.FieldViolations[0].Field)
+}
+
+// TestUnimplementedRPCs tests that RPCs a minimal server lacks fail with
+// ErrNotSupported while keeping their status code
+func TestUnimplementedRPCs(t *testing.T) {
+	addr := startBareServer(t, &flight.BaseFlightServer{})
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	calls := map[string]func() error{
+		"ListFlights": func() error {
+			_, err := client.ListBatches(ctx)
+			return err
+		},
+		"ListActions": func() error {
+			_, err := client.ListActions(ctx)
+			return err
+		},
+		"GetSchema": func() error {
+			_, err := client.GetSchema(ctx, "any")
+			return err
+		},
+		"DoGet": func() error {
+			_, err := client.GetBatch(ctx, "any")
+			return err
+		},
+	}
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			err := call()
+			assert.ErrorIs(t, err, ErrNotSupported)
+			assert.Equal(t, codes.Unimplemented, status.Code(err))
+		})
+	}
+
+	// Other server errors are not mistaken for missing support
+	server, serverAddr := startTestServer(t)
+	defer server.Stop()
+	full, err := NewFlightClient(FlightClientConfig{Addr: serverAddr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer full.Close()
+	_, err = full.GetSchema(ctx, "missing")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.NotErrorIs(t, err, ErrNotSupported)
+}
@@ -0,0 +1,54 @@
+package flight
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/flight"
+)
+
+// TransferEstimate is the expected cost of downloading a batch
+type TransferEstimate struct {
+	// Bytes is the size of the batch's Arrow buffers, as reported by the server
+	Bytes int64
+	// Rows is the number of rows in the batch
+	Rows int64
+	// Throughput is the client's download rate (see FlightClient.Throughput),
+	// in Arrow bytes per second, or 0 before any download completed
+	Throughput float64
+	// Samples is the number of downloads Throughput is based on
+	Samples int
+	// Duration is Bytes / Throughput, or 0 without a measured throughput
+	Duration time.Duration
+}
+
+// EstimateTransfer estimates how long downloading a batch would take, from
+// the size the server reports through GetFlightInfo and the client's rolling
+// download throughput. Throughput is measured in uncompressed Arrow bytes
+// over the whole call, so it accounts for compression and round trips as
+// seen by this client; it reflects recent conditions and may be noisy after
+// only a few small transfers. Servers that cannot report the size yield
+// ErrNotSupported.
+func (c *FlightClient) EstimateTransfer(ctx context.Context, batchID string) (TransferEstimate, error) {
+	client, release, err := c.acquire(ctx)
+	if err != nil {
+		return TransferEstimate{}, labelError(ctx, err)
+	}
+	defer release()
+
+	info, err := client.GetFlightInfo(ctx, &flight.FlightDescriptor{Type: flight.DescriptorCMD, Cmd: c.batchCommand(batchID)})
+	if err != nil {
+		return TransferEstimate{}, labelError(ctx, fmt.Errorf("failed to get flight info for batch %s: %w", batchID, err))
+	}
+	if info.TotalBytes < 0 {
+		return TransferEstimate{}, labelError(ctx, fmt.Errorf("%w: size of batch %s", ErrNotSupported, batchID))
+	}
+
+	estimate := TransferEstimate{Bytes: info.TotalBytes, Rows: info.TotalRecords}
+	estimate.Throughput, estimate.Samples = c.throughput.download.get()
+	if estimate.Throughput > 0 {
+		estimate.Duration = time.Duration(float64(estimate.Bytes) / estimate.Throughput * float64(time.Second))
+	}
+	return estimate, nil
+}
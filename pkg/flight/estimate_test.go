@@ -0,0 +1,55 @@
+package flight
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/arrow/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEstimateTransfer tests that estimates combine the server's size with the measured throughput
+func TestEstimateTransfer(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+	batchID := server.StoreBatch(batch)
+
+	// Without earlier transfers only the size is known
+	estimate, err := client.EstimateTransfer(ctx, batchID)
+	require.NoError(t, err, "Failed to estimate transfer")
+	assert.Equal(t, util.TotalRecordSize(batch), estimate.Bytes)
+	assert.Equal(t, batch.NumRows(), estimate.Rows)
+	assert.Zero(t, estimate.Throughput)
+	assert.Zero(t, estimate.Duration)
+
+	for range 3 {
+		retrieved, err := client.GetBatch(ctx, batchID)
+		require.NoError(t, err, "Failed to get batch")
+		retrieved.Release()
+	}
+	_, err = client.GetBatch(ctx, "missing")
+	require.Error(t, err)
+
+	estimate, err = client.EstimateTransfer(ctx, batchID)
+	require.NoError(t, err, "Failed to estimate transfer")
+	assert.Equal(t, 3, estimate.Samples, "Failed transfers should not be measured")
+	assert.Positive(t, estimate.Throughput)
+	assert.Positive(t, estimate.Duration)
+	assert.InDelta(t, float64(estimate.Bytes)/estimate.Throughput, estimate.Duration.Seconds(), 1e-6)
+
+	_, err = client.EstimateTransfer(ctx, "missing")
+	assert.Error(t, err)
+}
@@ -0,0 +1,273 @@
+package flight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+)
+
+// Transform runs a request/response DoExchange against a server that computes
+// a result from an input record: the input is sent with a command descriptor,
+// the sending side is half-closed, and the result stream is read to the end
+// and combined into one record. The result's schema may differ from the
+// input's. Servers answering with several record batches have them
+// concatenated.
+func (c *FlightClient) Transform(ctx context.Context, input arrow.Record, command []byte) (arrow.Record, error) {
+	result, err := c.transform(ctx, input, command)
+	return result, labelError(ctx, err)
+}
+
+// transform implements Transform
+func (c *FlightClient) transform(ctx context.Context, input arrow.Record, command []byte) (arrow.Record, error) {
+	conn, release, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// Cancelling on return tears down the call if the result is not read fully
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := conn.DoExchange(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start DoExchange: %w", err)
+	}
+
+	// Send the input with the command in the first message's descriptor
+	writer := flight.NewRecordWriter(stream, c.writerOptions(input.Schema(), c.EffectiveCompression())...)
+	writer.SetFlightDescriptor(&flight.FlightDescriptor{Type: flight.DescriptorCMD, Cmd: command})
+	if err := writer.Write(input); err != nil {
+		writer.Close()
+		return nil, fmt.Errorf("failed to send input record: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close IPC writer: %w", err)
+	}
+
+	// Half-close so the server knows the input is complete
+	if err := stream.CloseSend(); err != nil {
+		return nil, fmt.Errorf("failed to close send direction: %w", err)
+	}
+
+	reader, err := flight.NewRecordReader(stream, c.readerOptions(c.allocator)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transform result: %w", err)
+	}
+	defer reader.Release()
+
+	var batches []arrow.Record
+	for reader.Next() {
+		batch := reader.Record()
+		batch.Retain()
+		batches = append(batches, batch)
+	}
+	if err := reader.Err(); err != nil {
+		releaseRecords(batches)
+		return nil, fmt.Errorf("error reading transform result: %w", err)
+	}
+
+	return combineRecords(c.allocator, reader.Schema(), batches)
+}
+
+// exchangeResultBuffer is how many results TransformStream receives ahead of
+// the caller
+const exchangeResultBuffer = 4
+
+// ExchangeStream is a streaming DoExchange started by TransformStream. Inputs
+// are sent and results received by two goroutines, so the server may answer
+// before the input ends.
+type ExchangeStream struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	release func()
+	wg      sync.WaitGroup // Tracks the send and receive goroutines
+	results chan readResult
+	lastErr error // The error last returned by Next
+
+	mu      sync.Mutex
+	sendErr error // Why sending failed, reported in place of the abort it causes
+}
+
+// TransformStream runs a streaming DoExchange against a transforming server:
+// the records received from inputs are sent as they arrive, with the command
+// in the first message's descriptor, and the sending side is half-closed once
+// inputs is closed. Results are read concurrently with Next. TransformStream
+// takes ownership of the records it receives, which must all have the schema
+// of the first.
+//
+// Cancelling ctx aborts the exchange: both goroutines stop, even if blocked on
+// inputs or on results the caller has not read, and Next fails with the
+// context's error. The caller must Close the stream.
+func (c *FlightClient) TransformStream(ctx context.Context, inputs <-chan arrow.Record, command []byte) (*ExchangeStream, error) {
+	conn, release, err := c.acquire(ctx)
+	if err != nil {
+		return nil, labelError(ctx, err)
+	}
+
+	// Cancelling tears down the call and stops both goroutines
+	ctx, cancel := context.WithCancel(ctx)
+	stream, err := conn.DoExchange(ctx)
+	if err != nil {
+		cancel()
+		release()
+		return nil, labelError(ctx, fmt.Errorf("failed to start DoExchange: %w", err))
+	}
+
+	s := &ExchangeStream{
+		ctx:     ctx,
+		cancel:  cancel,
+		release: release,
+		results: make(chan readResult, exchangeResultBuffer),
+	}
+	s.wg.Add(2)
+	go s.send(c, stream, inputs, command)
+	go s.receive(c, stream)
+	return s, nil
+}
+
+// send writes the input records to the stream until inputs is closed
+func (s *ExchangeStream) send(c *FlightClient, stream flight.FlightService_DoExchangeClient, inputs <-chan arrow.Record, command []byte) {
+	defer s.wg.Done()
+
+	var writer *flight.Writer
+	for {
+		var rec arrow.Record
+		select {
+		case r, ok := <-inputs:
+			if !ok {
+				if writer != nil {
+					if err := writer.Close(); err != nil && !errors.Is(err, io.EOF) {
+						s.failSend(fmt.Errorf("failed to close IPC writer: %w", err))
+						return
+					}
+				}
+				// Half-close so the server knows the input is complete
+				if err := stream.CloseSend(); err != nil {
+					s.failSend(fmt.Errorf("failed to close send direction: %w", err))
+				}
+				return
+			}
+			rec = r
+		case <-s.ctx.Done():
+			if writer != nil {
+				writer.Close()
+			}
+			return
+		}
+
+		if writer == nil {
+			writer = flight.NewRecordWriter(stream, c.writerOptions(rec.Schema(), c.EffectiveCompression())...)
+			writer.SetFlightDescriptor(&flight.FlightDescriptor{Type: flight.DescriptorCMD, Cmd: command})
+		}
+		err := writer.Write(rec)
+		rec.Release()
+		if errors.Is(err, io.EOF) {
+			// The server has already ended the call; the result stream says why
+			writer.Close()
+			return
+		}
+		if err != nil {
+			writer.Close()
+			s.failSend(fmt.Errorf("failed to send input record: %w", err))
+			return
+		}
+	}
+}
+
+// failSend records why sending failed and aborts the exchange
+func (s *ExchangeStream) failSend(err error) {
+	s.mu.Lock()
+	s.sendErr = err
+	s.mu.Unlock()
+	s.cancel()
+}
+
+// receive reads the result records into the results channel until the
+// stream ends or the exchange is aborted
+func (s *ExchangeStream) receive(c *FlightClient, stream flight.FlightService_DoExchangeClient) {
+	defer s.wg.Done()
+	defer close(s.results)
+
+	reader, err := flight.NewRecordReader(stream, c.readerOptions(c.allocator)...)
+	if err != nil {
+		s.deliver(readResult{err: s.receiveError("failed to read transform result", err)})
+		return
+	}
+	defer reader.Release()
+
+	for reader.Next() {
+		rec := reader.Record()
+		rec.Retain()
+		if !s.deliver(readResult{record: rec}) {
+			rec.Release()
+			return
+		}
+	}
+	if err := reader.Err(); err != nil {
+		s.deliver(readResult{err: s.receiveError("error reading transform result", err)})
+		return
+	}
+	s.deliver(readResult{err: io.EOF})
+}
+
+// deliver hands a result to Next, reporting false if the exchange was aborted
+// first
+func (s *ExchangeStream) deliver(result readResult) bool {
+	select {
+	case s.results <- result:
+		return true
+	case <-s.ctx.Done():
+		return false
+	}
+}
+
+// receiveError wraps a receive failure, preferring the send failure that
+// caused it
+func (s *ExchangeStream) receiveError(msg string, err error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sendErr != nil {
+		return s.sendErr
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
+
+// Next returns the next result record, or io.EOF once the server has ended
+// the result stream. The caller owns the returned record and must release it.
+func (s *ExchangeStream) Next() (arrow.Record, error) {
+	result, ok := <-s.results
+	if !ok {
+		if s.lastErr == nil {
+			// The exchange was aborted before a final result was delivered
+			s.mu.Lock()
+			s.lastErr = s.sendErr
+			s.mu.Unlock()
+			if s.lastErr == nil {
+				s.lastErr = fmt.Errorf("exchange aborted: %w", s.ctx.Err())
+			}
+			s.lastErr = labelError(s.ctx, s.lastErr)
+		}
+		return nil, s.lastErr
+	}
+	s.lastErr = labelError(s.ctx, result.err)
+	return result.record, s.lastErr
+}
+
+// Close aborts the exchange if it is still running, waits for its goroutines
+// to stop and releases the connection. Results not yet read are discarded.
+func (s *ExchangeStream) Close() {
+	s.cancel()
+	for result := range s.results {
+		if result.record != nil {
+			result.record.Release()
+		}
+	}
+	s.wg.Wait()
+	s.release()
+}
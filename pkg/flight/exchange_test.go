@@ -0,0 +1,216 @@
+package flight
+
+import (
+	"context"
+	"io"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// doublingServer is a Flight server whose DoExchange returns its input with
+// the float64 column named by the command doubled
+type doublingServer struct {
+	flight.BaseFlightServer
+}
+
+// DoExchange doubles the requested column of every input record once the
+// client has half-closed
+func (s *doublingServer) DoExchange(stream flight.FlightService_DoExchangeServer) error {
+	reader, err := flight.NewRecordReader(stream)
+	if err != nil {
+		return err
+	}
+	defer reader.Release()
+
+	column := string(reader.LatestFlightDescriptor().GetCmd())
+	indices := reader.Schema().FieldIndices(column)
+	if len(indices) != 1 {
+		return status.Errorf(codes.InvalidArgument, "unknown column %q", column)
+	}
+
+	var results []arrow.Record
+	defer func() { releaseRecords(results) }()
+	for reader.Next() {
+		rec := reader.Record()
+		values := rec.Column(indices[0]).(*array.Float64)
+
+		builder := array.NewFloat64Builder(memory.NewGoAllocator())
+		for i := 0; i < values.Len(); i++ {
+			builder.Append(2 * values.Value(i))
+		}
+		doubled := builder.NewArray()
+		builder.Release()
+
+		cols := append([]arrow.Array(nil), rec.Columns()...)
+		cols[indices[0]] = doubled
+		results = append(results, array.NewRecord(rec.Schema(), cols, rec.NumRows()))
+		doubled.Release()
+	}
+	if err := reader.Err(); err != nil {
+		return err
+	}
+
+	writer := flight.NewRecordWriter(stream, ipc.WithSchema(reader.Schema()))
+	defer writer.Close()
+	for _, rec := range results {
+		if err := writer.Write(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TestTransform tests a request/response exchange against a transforming server
+func TestTransform(t *testing.T) {
+	addr := startBareServer(t, &doublingServer{})
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := client.Transform(ctx, batch, []byte("value"))
+	require.NoError(t, err, "Failed to transform batch")
+	defer result.Release()
+
+	require.Equal(t, batch.NumRows(), result.NumRows())
+	input := batch.Column(2).(*array.Float64)
+	output := result.Column(2).(*array.Float64)
+	for i := 0; i < input.Len(); i++ {
+		assert.Equal(t, 2*input.Value(i), output.Value(i), "Row %d should be doubled", i)
+	}
+	assert.True(t, array.Equal(batch.Column(1), result.Column(1)), "Other columns should be unchanged")
+
+	_, err = client.Transform(ctx, batch, []byte("missing"))
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+// echoServer is a Flight server whose DoExchange sends every input record
+// back as soon as it arrives
+type echoServer struct {
+	flight.BaseFlightServer
+}
+
+// DoExchange echoes the input records until the client half-closes
+func (s *echoServer) DoExchange(stream flight.FlightService_DoExchangeServer) error {
+	reader, err := flight.NewRecordReader(stream)
+	if err != nil {
+		return err
+	}
+	defer reader.Release()
+
+	writer := flight.NewRecordWriter(stream, ipc.WithSchema(reader.Schema()))
+	defer writer.Close()
+	for reader.Next() {
+		if err := writer.Write(reader.Record()); err != nil {
+			return err
+		}
+	}
+	return reader.Err()
+}
+
+// TestTransformStream tests that results are received while the input is still being sent
+func TestTransformStream(t *testing.T) {
+	addr := startBareServer(t, &echoServer{})
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	inputs := make(chan arrow.Record)
+	stream, err := client.TransformStream(ctx, inputs, []byte("echo"))
+	require.NoError(t, err, "Failed to start exchange")
+	defer stream.Close()
+
+	// Each input is answered before the next is sent
+	for i := 0; i < 3; i++ {
+		batch.Retain()
+		inputs <- batch
+		result, err := stream.Next()
+		require.NoError(t, err, "Failed to receive result %d", i)
+		assert.True(t, array.RecordEqual(batch, result), "Result %d should echo the input", i)
+		result.Release()
+	}
+	close(inputs)
+
+	_, err = stream.Next()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+// TestTransformStreamCancel tests that cancelling an active exchange stops it promptly without leaking goroutines
+func TestTransformStreamCancel(t *testing.T) {
+	addr := startBareServer(t, &echoServer{})
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	// Warm up the connection so its goroutines are counted in the baseline
+	_, err = client.Capabilities(context.Background())
+	require.ErrorIs(t, err, ErrNotSupported)
+	baseline := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	inputs := make(chan arrow.Record)
+	stream, err := client.TransformStream(ctx, inputs, []byte("echo"))
+	require.NoError(t, err, "Failed to start exchange")
+
+	// Send more records than the result buffer holds without reading any, so
+	// the receiver is blocked on a full channel when the context is cancelled
+	for i := 0; i < exchangeResultBuffer+2; i++ {
+		batch.Retain()
+		inputs <- batch
+	}
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		var err error
+		for err == nil {
+			var result arrow.Record
+			if result, err = stream.Next(); result != nil {
+				result.Release()
+			}
+		}
+		stream.Close()
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(2 * time.Second):
+		t.Fatal("The exchange should stop promptly once cancelled")
+	}
+
+	assert.Eventually(t, func() bool { return runtime.NumGoroutine() <= baseline },
+		2*time.Second, 10*time.Millisecond, "The exchange goroutines should exit")
+}
@@ -0,0 +1,57 @@
+package flight
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/apache/arrow-go/v18/arrow"
+
+	arrow_utils "github.com/TFMV/temporal/pkg/arrow"
+)
+
+// sameFields reports whether two schemas have the same field names, types
+// and nullability, ignoring metadata
+func sameFields(a, b *arrow.Schema) bool {
+	if a.NumFields() != b.NumFields() {
+		return false
+	}
+	for i, field := range a.Fields() {
+		other := b.Field(i)
+		if field.Name != other.Name || field.Nullable != other.Nullable || !arrow.TypeEqual(field.Type, other.Type) {
+			return false
+		}
+	}
+	return true
+}
+
+// alignExpectedSchema fetches the latest schema of a batch whose download
+// failed ExpectedSchema and asks options.AlignSchema for the schema to expect
+// instead. The returned options have no AlignSchema, so the download is
+// retried at most once.
+func (c *FlightClient) alignExpectedSchema(ctx context.Context, batchID string, options GetOptions) (GetOptions, error) {
+	latest, err := c.GetSchema(ctx, batchID)
+	if err != nil {
+		return options, fmt.Errorf("failed to refresh schema: %w", err)
+	}
+	if len(options.Coerce) > 0 {
+		if latest, err = arrow_utils.CoerceSchema(latest, options.Coerce); err != nil {
+			return options, fmt.Errorf("failed to coerce columns: %w", err)
+		}
+	}
+	if options.NormalizeTimezone != "" {
+		if latest, err = arrow_utils.NormalizeTimezoneSchema(latest, options.NormalizeTimezone, options.AssumeTimezone); err != nil {
+			return options, fmt.Errorf("failed to normalize time zones: %w", err)
+		}
+	}
+
+	aligned, err := options.AlignSchema(ctx, options.ExpectedSchema, latest)
+	if err != nil {
+		return options, fmt.Errorf("failed to align schema: %w", err)
+	}
+	if aligned == nil {
+		return options, fmt.Errorf("%w: no schema to expect after refresh", ErrSchemaMismatch)
+	}
+	options.ExpectedSchema = aligned
+	options.AlignSchema = nil
+	return options, nil
+}
@@ -0,0 +1,70 @@
+package flight
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExpectedSchemaRefresh tests that a download retries once with an aligned schema after the batch evolved
+func TestExpectedSchemaRefresh(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	original := createGenerationBatch(1, 3)
+	defer original.Release()
+	_, err = client.PutBatchAtomic(ctx, "events", original)
+	require.NoError(t, err, "Failed to put batch")
+	cached, err := client.GetSchema(ctx, "events")
+	require.NoError(t, err, "Failed to get schema")
+
+	// The batch is rewritten with a new schema after the reader cached its own
+	evolved := createTestBatch(t, memory.NewGoAllocator())
+	defer evolved.Release()
+	_, err = client.PutBatchAtomic(ctx, "events", evolved)
+	require.NoError(t, err, "Failed to put batch")
+
+	_, err = client.GetBatchWithOptions(ctx, "events", GetOptions{ExpectedSchema: cached})
+	assert.ErrorIs(t, err, ErrSchemaMismatch, "Without a hook the mismatch should fail the download")
+
+	calls := 0
+	retrieved, err := client.GetBatchWithOptions(ctx, "events", GetOptions{
+		ExpectedSchema: cached,
+		AlignSchema: func(ctx context.Context, expected, latest *arrow.Schema) (*arrow.Schema, error) {
+			calls++
+			assert.True(t, expected.Equal(cached))
+			cached = latest
+			return latest, nil
+		},
+	})
+	require.NoError(t, err, "The download should succeed with the aligned schema")
+	defer retrieved.Release()
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, int64(3), retrieved.NumCols())
+	assert.Equal(t, evolved.NumRows(), retrieved.NumRows())
+	assert.True(t, cached.Equal(evolved.Schema()), "The hook should see the latest schema")
+
+	// A hook that keeps returning a stale schema is not retried again
+	calls = 0
+	_, err = client.GetBatchWithOptions(ctx, "events", GetOptions{
+		ExpectedSchema: original.Schema(),
+		AlignSchema: func(ctx context.Context, expected, latest *arrow.Schema) (*arrow.Schema, error) {
+			calls++
+			return expected, nil
+		},
+	})
+	assert.ErrorIs(t, err, ErrSchemaMismatch)
+	assert.Equal(t, 1, calls, "The hook should run only once per download")
+}
@@ -0,0 +1,186 @@
+package flight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+)
+
+// Flusher is implemented by writers that can push buffered data downstream,
+// such as bufio.Writer or MultipartWriter
+type Flusher interface {
+	Flush() error
+}
+
+// GetBatchToWriter streams a batch into w in Arrow IPC stream format without
+// holding the whole batch in memory. Each record batch is written as it
+// arrives, and if w implements Flusher it is flushed after every record.
+func (c *FlightClient) GetBatchToWriter(ctx context.Context, batchID string, w io.Writer) error {
+	stream, err := c.GetBatchStream(ctx, batchID)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	flusher, _ := w.(Flusher)
+	writer := ipc.NewWriter(w, ipc.WithSchema(stream.Schema()), ipc.WithAllocator(c.allocator))
+
+	for {
+		rec, err := stream.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			writer.Close()
+			return err
+		}
+
+		err = writer.Write(rec)
+		rec.Release()
+		if err != nil {
+			writer.Close()
+			return fmt.Errorf("failed to write record: %w", err)
+		}
+
+		if flusher != nil {
+			if err := flusher.Flush(); err != nil {
+				writer.Close()
+				return fmt.Errorf("failed to flush writer: %w", err)
+			}
+		}
+	}
+
+	// Closing writes the end-of-stream marker
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to close IPC writer: %w", err)
+	}
+	if flusher != nil {
+		if err := flusher.Flush(); err != nil {
+			return fmt.Errorf("failed to flush writer: %w", err)
+		}
+	}
+	return nil
+}
+
+// PutBatchFromIPC uploads an Arrow IPC stream read from r, such as a file
+// written by GetBatchToWriter. Each record batch is decoded as it is read and
+// re-encoded onto a single DoPut with the client's IPC and compression
+// options, after the client's constraint checks, so only one record is held
+// in memory and the stream is stored as one batch whose ID is the result.
+// Servers without streamed uploads store each record batch of the stream as
+// its own batch instead, returned in stream order.
+func (c *FlightClient) PutBatchFromIPC(ctx context.Context, r io.Reader) ([]string, error) {
+	reader, err := ipc.NewReader(r, ipc.WithAllocator(c.allocator))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create IPC reader: %w", err)
+	}
+	defer reader.Release()
+
+	// The reader owns its current record, so each is retained for the upload
+	next := func() (arrow.Record, error) {
+		if !reader.Next() {
+			if err := reader.Err(); err != nil {
+				return nil, fmt.Errorf("failed to read IPC stream: %w", err)
+			}
+			return nil, io.EOF
+		}
+		rec := reader.Record()
+		rec.Retain()
+		return rec, nil
+	}
+
+	err = c.requireFeature(ctx, "streamed uploads", func(s ServerCapabilities) bool { return s.StreamedUploads })
+	if errors.Is(err, ErrNotSupported) {
+		var batchIDs []string
+		for {
+			rec, err := next()
+			if err == io.EOF {
+				return batchIDs, nil
+			}
+			if err != nil {
+				return batchIDs, err
+			}
+			batchID, err := c.PutBatch(ctx, rec)
+			rec.Release()
+			if err != nil {
+				return batchIDs, err
+			}
+			batchIDs = append(batchIDs, batchID)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	batchID, err := c.putStream(ctx, reader.Schema(), next)
+	if err != nil {
+		return nil, err
+	}
+	return []string{batchID}, nil
+}
+
+// PartUploader uploads the numbered parts of a multipart object, for example the
+// UploadPart calls of an S3 multipart upload. Part numbers start at 1. The data
+// slice is not reused after UploadPart returns, so it may be retained.
+type PartUploader interface {
+	UploadPart(partNumber int, data []byte) error
+}
+
+// MultipartWriter adapts a PartUploader to an io.Writer. Written data is
+// buffered and uploaded as a part on Flush once at least minPartSize bytes are
+// pending, so memory use is bounded by the part size plus one record. Close
+// uploads whatever remains as the final part; completing the multipart upload
+// is left to the caller.
+type MultipartWriter struct {
+	uploader    PartUploader
+	minPartSize int
+	buf         []byte
+	parts       int
+}
+
+// NewMultipartWriter creates a MultipartWriter that uploads parts of at least
+// minPartSize bytes (except the last)
+func NewMultipartWriter(uploader PartUploader, minPartSize int) *MultipartWriter {
+	return &MultipartWriter{uploader: uploader, minPartSize: minPartSize}
+}
+
+// Write buffers p for the next part
+func (w *MultipartWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	return len(p), nil
+}
+
+// Flush uploads the buffered data as a part if it has reached the minimum part size
+func (w *MultipartWriter) Flush() error {
+	if len(w.buf) < w.minPartSize {
+		return nil
+	}
+	return w.upload()
+}
+
+// Close uploads any remaining buffered data as the final part
+func (w *MultipartWriter) Close() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	return w.upload()
+}
+
+// Parts returns the number of parts uploaded so far
+func (w *MultipartWriter) Parts() int {
+	return w.parts
+}
+
+// upload sends the buffered data as the next part
+func (w *MultipartWriter) upload() error {
+	w.parts++
+	if err := w.uploader.UploadPart(w.parts, w.buf); err != nil {
+		return fmt.Errorf("failed to upload part %d: %w", w.parts, err)
+	}
+	w.buf = nil
+	return nil
+}
@@ -0,0 +1,199 @@
+package flight
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingUploader is a PartUploader that keeps every uploaded part
+type recordingUploader struct {
+	parts [][]byte
+}
+
+// UploadPart implements PartUploader
+func (u *recordingUploader) UploadPart(partNumber int, data []byte) error {
+	if partNumber != len(u.parts)+1 {
+		return assert.AnError
+	}
+	u.parts = append(u.parts, data)
+	return nil
+}
+
+// TestGetBatchToWriter tests streaming a batch into an IPC stream
+func TestGetBatchToWriter(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batchID, err := client.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to put batch")
+
+	var buf bytes.Buffer
+	require.NoError(t, client.GetBatchToWriter(ctx, batchID, &buf))
+
+	reader, err := ipc.NewReader(&buf)
+	require.NoError(t, err, "Output should be an IPC stream")
+	defer reader.Release()
+	require.True(t, reader.Next())
+	assert.True(t, batch.Schema().Equal(reader.Record().Schema()))
+	assert.Equal(t, batch.NumRows(), reader.Record().NumRows())
+	assert.False(t, reader.Next())
+}
+
+// TestGetBatchToMultipartWriter tests that a multi-record download is uploaded in bounded parts
+func TestGetBatchToMultipartWriter(t *testing.T) {
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	addr := startBareServer(t, &multiBatchServer{batch: batch, count: 10})
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	const minPartSize = 1024
+	uploader := &recordingUploader{}
+	writer := NewMultipartWriter(uploader, minPartSize)
+	require.NoError(t, client.GetBatchToWriter(ctx, "any", writer))
+	require.NoError(t, writer.Close())
+
+	require.Greater(t, len(uploader.parts), 1, "Records should be flushed in several parts")
+	assert.Equal(t, len(uploader.parts), writer.Parts())
+	for _, part := range uploader.parts[:len(uploader.parts)-1] {
+		assert.GreaterOrEqual(t, len(part), minPartSize, "Only the last part may be short")
+	}
+
+	// The parts reassemble into a valid IPC stream
+	reader, err := ipc.NewReader(bytes.NewReader(bytes.Join(uploader.parts, nil)))
+	require.NoError(t, err, "Parts should form an IPC stream")
+	defer reader.Release()
+
+	var numRows int64
+	for reader.Next() {
+		numRows += reader.Record().NumRows()
+	}
+	require.NoError(t, reader.Err())
+	assert.Equal(t, 10*batch.NumRows(), numRows)
+}
+
+// TestPutBatchFromIPC tests uploading a multi-record IPC stream as one batch
+func TestPutBatchFromIPC(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	var buf bytes.Buffer
+	writer := ipc.NewWriter(&buf, ipc.WithSchema(batch.Schema()))
+	for i := 0; i < 3; i++ {
+		require.NoError(t, writer.Write(batch))
+	}
+	require.NoError(t, writer.Close())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batchIDs, err := client.PutBatchFromIPC(ctx, &buf)
+	require.NoError(t, err, "Failed to upload IPC stream")
+	require.Len(t, batchIDs, 1, "The stream should be stored as one batch")
+
+	retrieved, err := client.GetBatch(ctx, batchIDs[0])
+	require.NoError(t, err, "Failed to get batch")
+	defer retrieved.Release()
+	assert.True(t, batch.Schema().Equal(retrieved.Schema()))
+	assert.Equal(t, 3*batch.NumRows(), retrieved.NumRows())
+	for i := 0; i < 3; i++ {
+		slice := retrieved.NewSlice(int64(i)*batch.NumRows(), int64(i+1)*batch.NumRows())
+		assert.True(t, array.RecordEqual(batch, slice), "Record %d should round-trip", i)
+		slice.Release()
+	}
+
+	_, err = client.PutBatchFromIPC(ctx, bytes.NewReader([]byte("not an IPC stream")))
+	assert.ErrorContains(t, err, "failed to create IPC reader")
+}
+
+// unstreamedServer is a FlightServer that reports no support for streamed
+// uploads
+type unstreamedServer struct {
+	*FlightServer
+}
+
+// DoAction answers capabilities without StreamedUploads and passes other
+// actions through
+func (s *unstreamedServer) DoAction(action *flight.Action, stream flight.FlightService_DoActionServer) error {
+	if action.Type != ActionCapabilities {
+		return s.FlightServer.DoAction(action, stream)
+	}
+	body, err := json.Marshal(ServerCapabilities{ClientBatchIDs: true})
+	if err != nil {
+		return err
+	}
+	return stream.Send(&flight.Result{Body: body})
+}
+
+// TestPutBatchFromIPCUnstreamed tests that servers without streamed uploads
+// store each record batch of the stream as its own batch
+func TestPutBatchFromIPCUnstreamed(t *testing.T) {
+	server, err := NewFlightServer(FlightServerConfig{})
+	require.NoError(t, err, "Failed to create Flight server")
+	defer server.Stop()
+	addr := startBareServer(t, &unstreamedServer{FlightServer: server})
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	var buf bytes.Buffer
+	writer := ipc.NewWriter(&buf, ipc.WithSchema(batch.Schema()))
+	for i := int64(0); i < 3; i++ {
+		slice := batch.NewSlice(i, i+1)
+		require.NoError(t, writer.Write(slice))
+		slice.Release()
+	}
+	require.NoError(t, writer.Close())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batchIDs, err := client.PutBatchFromIPC(ctx, &buf)
+	require.NoError(t, err, "Failed to upload IPC stream")
+	require.Len(t, batchIDs, 3, "Each record batch should be stored on its own")
+	for i, batchID := range batchIDs {
+		stored, err := server.RetrieveBatch(batchID)
+		require.NoError(t, err, "Failed to retrieve batch %d", i)
+		slice := batch.NewSlice(int64(i), int64(i+1))
+		assert.True(t, array.RecordEqual(slice, stored), "Batch %d should hold record %d of the stream", i, i)
+		slice.Release()
+		stored.Release()
+	}
+}
@@ -0,0 +1,178 @@
+package flight
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// defaultFailbackInterval is how often a failed-over client probes its
+// primary address when FlightClientConfig.FailbackInterval is unset
+const defaultFailbackInterval = 30 * time.Second
+
+// ActiveAddr returns the address the client currently sends calls to: the
+// configured Addr, or one of the FailoverAddrs after a failover
+func (c *FlightClient) ActiveAddr() string {
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
+	return c.addr
+}
+
+// unaryFailoverInterceptor fails over to the next address when a unary call
+// finds the active server unavailable
+func (c *FlightClient) unaryFailoverInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	c.checkUnavailable(cc, err)
+	return err
+}
+
+// streamFailoverInterceptor fails over to the next address when a streaming
+// call finds the active server unavailable, either when opening the stream or
+// while receiving from it
+func (c *FlightClient) streamFailoverInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		c.checkUnavailable(cc, err)
+		return nil, err
+	}
+	return &failoverStream{ClientStream: stream, client: c, cc: cc}, nil
+}
+
+// failoverStream reports the errors received on a client stream to its client
+type failoverStream struct {
+	grpc.ClientStream
+	client *FlightClient
+	cc     *grpc.ClientConn
+}
+
+// Header implements grpc.ClientStream
+func (s *failoverStream) Header() (metadata.MD, error) {
+	md, err := s.ClientStream.Header()
+	s.client.checkUnavailable(s.cc, err)
+	return md, err
+}
+
+// RecvMsg implements grpc.ClientStream. Send errors surface here as well,
+// since SendMsg only reports io.EOF when the stream breaks.
+func (s *failoverStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	s.client.checkUnavailable(s.cc, err)
+	return err
+}
+
+// checkUnavailable fails over if err reports that the server behind cc is
+// unavailable. gRPC retries, if configured in the service config, have
+// already been exhausted by the time the error reaches an interceptor.
+func (c *FlightClient) checkUnavailable(cc *grpc.ClientConn, err error) {
+	if err == nil || errors.Is(err, io.EOF) || status.Code(err) != codes.Unavailable {
+		return
+	}
+	c.failover(cc.Target())
+}
+
+// failover switches to the address after failed, unless failed is no longer
+// the active address because an earlier call already moved on. The new
+// address is dialed on the next call.
+func (c *FlightClient) failover(failed string) {
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
+
+	if c.closed || failed != c.addr {
+		return
+	}
+	c.switchAddr((c.active+1)%len(c.addrs), nil)
+}
+
+// switchAddr makes addrs[index] the active address, using client as its
+// connection, or dialing lazily if client is nil. The previous connection is
+// closed once the calls still using it have finished, and the capabilities
+// and actions cached for the previous server are dropped. Must be called
+// with connMu held.
+func (c *FlightClient) switchAddr(index int, client flight.Client) {
+	if c.client != nil {
+		if c.inFlight == 0 {
+			c.client.Close()
+		} else {
+			c.retired = append(c.retired, c.client)
+		}
+	}
+	c.client = client
+	c.active = index
+	c.addr = c.addrs[index]
+
+	// The new server may support other features and actions
+	c.capabilitiesMu.Lock()
+	c.capabilities, c.capabilitiesChecked = nil, false
+	c.capabilitiesMu.Unlock()
+	c.actionsMu.Lock()
+	c.actions = nil
+	c.actionsMu.Unlock()
+
+	if index != 0 && c.failbackInterval > 0 {
+		if c.failbackTimer == nil {
+			c.failbackTimer = time.AfterFunc(c.failbackInterval, c.probePrimary)
+		} else {
+			c.failbackTimer.Reset(c.failbackInterval)
+		}
+	}
+}
+
+// closeRetired closes the connections replaced by a failover. Must be called
+// with connMu held and no calls in flight.
+func (c *FlightClient) closeRetired() {
+	for _, client := range c.retired {
+		client.Close()
+	}
+	c.retired = nil
+}
+
+// probePrimary dials the primary address and switches back to it once it
+// accepts connections again, or checks again after FailbackInterval
+func (c *FlightClient) probePrimary() {
+	conn, err := grpc.NewClient(c.addrs[0], c.dialOpts...)
+	ready := false
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), c.failbackInterval)
+		ready = waitReady(ctx, conn)
+		cancel()
+	}
+
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
+
+	if c.closed || c.active == 0 || !ready {
+		if conn != nil {
+			conn.Close()
+		}
+		if !c.closed && c.active != 0 {
+			c.failbackTimer.Reset(c.failbackInterval)
+		}
+		return
+	}
+	c.switchAddr(0, &connClient{Client: flight.NewClientFromConn(conn, nil), conn: conn})
+}
+
+// waitReady connects conn and reports whether it becomes ready before ctx
+// ends
+func waitReady(ctx context.Context, conn *grpc.ClientConn) bool {
+	for {
+		state := conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return true
+		case connectivity.Idle:
+			conn.Connect()
+		}
+		if !conn.WaitForStateChange(ctx, state) {
+			return false
+		}
+	}
+}
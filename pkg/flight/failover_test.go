@@ -0,0 +1,131 @@
+package flight
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TestFailover tests that a client fails over to the next address when its
+// primary is unavailable, and switches back once the primary recovers
+func TestFailover(t *testing.T) {
+	fallback, fallbackAddr := startTestServer(t)
+	defer fallback.Stop()
+
+	// Reserve an address for the primary, which starts out down
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err, "Failed to find available port")
+	primaryAddr := listener.Addr().String()
+	listener.Close()
+
+	client, err := NewFlightClient(FlightClientConfig{
+		Addr:             primaryAddr,
+		FailoverAddrs:    []string{fallbackAddr},
+		FailbackInterval: 50 * time.Millisecond,
+	})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+	assert.Equal(t, primaryAddr, client.ActiveAddr())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	// The failing call returns its error, and later calls use the fallback
+	_, err = client.PutBatch(ctx, batch)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, fallbackAddr, client.ActiveAddr())
+
+	batchID, err := client.PutBatch(ctx, batch)
+	require.NoError(t, err, "Calls after failover should reach the fallback")
+	retrieved, err := client.GetBatch(ctx, batchID)
+	require.NoError(t, err, "Failed to get batch from the fallback")
+	retrieved.Release()
+
+	// Once the primary accepts connections, the client switches back to it
+	primary, err := NewFlightServer(FlightServerConfig{})
+	require.NoError(t, err, "Failed to create Flight server")
+	defer primary.Stop()
+	listener, err = net.Listen("tcp", primaryAddr)
+	require.NoError(t, err, "Failed to listen on the primary address")
+	server := grpc.NewServer()
+	flight.RegisterFlightServiceServer(server, primary)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	require.Eventually(t, func() bool { return client.ActiveAddr() == primaryAddr },
+		5*time.Second, 20*time.Millisecond, "The client should fail back to the primary")
+
+	batchID, err = client.PutBatch(ctx, batch)
+	require.NoError(t, err, "Calls after failback should reach the primary")
+	stored, err := primary.RetrieveBatch(batchID)
+	require.NoError(t, err, "The batch should be stored on the primary")
+	stored.Release()
+
+	// Failover clients cannot share pooled connections
+	_, err = NewConnPool().NewClient(FlightClientConfig{Addr: primaryAddr, FailoverAddrs: []string{fallbackAddr}})
+	assert.Error(t, err)
+}
+
+// TestFailoverDropsCachedCapabilities tests that the capabilities and actions
+// cached for the primary are asked again of the server failed over to
+func TestFailoverDropsCachedCapabilities(t *testing.T) {
+	primary, primaryAddr := startTestServer(t)
+	fallback, err := NewFlightServer(FlightServerConfig{})
+	require.NoError(t, err, "Failed to create Flight server")
+	defer fallback.Stop()
+	fallbackAddr := startBareServer(t, &unstreamedServer{FlightServer: fallback})
+
+	client, err := NewFlightClient(FlightClientConfig{
+		Addr:          primaryAddr,
+		FailoverAddrs: []string{fallbackAddr},
+		CacheActions:  true,
+	})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	capabilities, err := client.Capabilities(ctx)
+	require.NoError(t, err, "Failed to get capabilities")
+	require.True(t, capabilities.StreamedUploads)
+	_, err = client.ListActions(ctx)
+	require.NoError(t, err, "Failed to list actions")
+
+	primary.Stop()
+	require.Eventually(t, func() bool {
+		client.ListBatches(ctx)
+		return client.ActiveAddr() == fallbackAddr
+	}, 5*time.Second, 20*time.Millisecond, "The client should fail over")
+
+	client.actionsMu.Lock()
+	assert.Nil(t, client.actions, "Cached actions should be dropped on failover")
+	client.actionsMu.Unlock()
+
+	// The fallback's lack of streamed uploads is noticed
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+	var buf bytes.Buffer
+	writer := ipc.NewWriter(&buf, ipc.WithSchema(batch.Schema()))
+	for i := 0; i < 2; i++ {
+		require.NoError(t, writer.Write(batch))
+	}
+	require.NoError(t, writer.Close())
+	batchIDs, err := client.PutBatchFromIPC(ctx, &buf)
+	require.NoError(t, err, "Failed to upload IPC stream")
+	assert.Len(t, batchIDs, 2, "Records should be stored one by one on the fallback")
+}
@@ -0,0 +1,112 @@
+package flight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ActionFingerprint is the DoAction type used to fingerprint a stored batch.
+// The action body is the batch ID or name and the result body is the
+// fingerprint.
+const ActionFingerprint = "fingerprint"
+
+// RecordFingerprint returns a fingerprint of a record's contents: the SHA-256
+// hash of its IPC encoding. Values hidden under nulls are part of the
+// encoding, as with ContentHashIDGenerator.
+func RecordFingerprint(rec arrow.Record) (string, error) {
+	sum, err := contentHash(rec)
+	if err != nil {
+		return "", err
+	}
+	return "sha256:" + sum, nil
+}
+
+// fingerprint computes the fingerprint of a stored batch
+func (s *FlightServer) fingerprint(batchID string, stream flight.FlightService_DoActionServer) error {
+	if err := s.authorize(stream.Context(), batchID); err != nil {
+		return err
+	}
+
+	batch, ok := s.acquireBatch(batchID)
+	if !ok {
+		return status.Errorf(codes.NotFound, "batch with ID %s not found", batchID)
+	}
+	defer batch.Release()
+
+	fingerprint, err := RecordFingerprint(batch)
+	if err != nil {
+		return err
+	}
+	return stream.Send(&flight.Result{Body: []byte(fingerprint)})
+}
+
+// Fingerprint returns a stable fingerprint of a batch's contents, computed by
+// the server without transferring the batch. Batches given by name change
+// fingerprint when the name is pointed at different data. Against servers
+// without the fingerprint action the batch is downloaded and fingerprinted
+// locally; fingerprints are therefore only comparable with others obtained
+// from the same server.
+func (c *FlightClient) Fingerprint(ctx context.Context, batchID string) (string, error) {
+	fingerprint, batch, err := c.fingerprint(ctx, batchID)
+	if batch != nil {
+		batch.Release()
+	}
+	return fingerprint, err
+}
+
+// fingerprint asks the server for a batch's fingerprint, or downloads and
+// fingerprints it, returning the downloaded record, which the caller owns
+func (c *FlightClient) fingerprint(ctx context.Context, batchID string) (string, arrow.Record, error) {
+	body, err := c.doAction(ctx, ActionFingerprint, c.batchCommand(batchID))
+	if err == nil {
+		return string(body), nil, nil
+	}
+	if !errors.Is(err, ErrNotSupported) {
+		return "", nil, fmt.Errorf("failed to fingerprint batch %s: %w", batchID, err)
+	}
+
+	batch, err := c.GetBatch(ctx, batchID)
+	if err != nil {
+		return "", nil, err
+	}
+	fingerprint, err := RecordFingerprint(batch)
+	if err != nil {
+		batch.Release()
+		return "", nil, err
+	}
+	return fingerprint, batch, nil
+}
+
+// GetBatchIfChanged retrieves a batch unless its fingerprint still equals
+// knownFingerprint, in which case it returns a nil record and changed is
+// false. The batch is fetched after its fingerprint, so a name pointed at new
+// data in between yields the newer data; call Fingerprint again to track it.
+// Against servers without the fingerprint action the batch is always
+// downloaded, and discarded if unchanged.
+func (c *FlightClient) GetBatchIfChanged(ctx context.Context, batchID, knownFingerprint string) (arrow.Record, bool, error) {
+	fingerprint, batch, err := c.fingerprint(ctx, batchID)
+	if err != nil {
+		return nil, false, err
+	}
+	if fingerprint == knownFingerprint {
+		if batch != nil {
+			batch.Release()
+		}
+		return nil, false, nil
+	}
+	if batch != nil {
+		return batch, true, nil
+	}
+
+	batch, err = c.GetBatch(ctx, batchID)
+	if err != nil {
+		return nil, false, err
+	}
+	return batch, true, nil
+}
@@ -0,0 +1,92 @@
+package flight
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetBatchIfChanged tests that unchanged batches are skipped and changed ones fetched
+func TestGetBatchIfChanged(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	first := createGenerationBatch(1, 3)
+	defer first.Release()
+	_, err = client.PublishBatch(ctx, "latest", first)
+	require.NoError(t, err, "Failed to publish batch")
+
+	known, err := client.Fingerprint(ctx, "latest")
+	require.NoError(t, err, "Failed to fingerprint batch")
+	local, err := RecordFingerprint(first)
+	require.NoError(t, err)
+	assert.Equal(t, local, known, "The server should fingerprint the stored contents")
+
+	// Unchanged
+	batch, changed, err := client.GetBatchIfChanged(ctx, "latest", known)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Nil(t, batch)
+
+	// Changed
+	second := createGenerationBatch(2, 3)
+	defer second.Release()
+	_, err = client.PublishBatch(ctx, "latest", second)
+	require.NoError(t, err, "Failed to publish batch")
+
+	batch, changed, err = client.GetBatchIfChanged(ctx, "latest", known)
+	require.NoError(t, err)
+	require.True(t, changed)
+	defer batch.Release()
+	assert.Equal(t, second.NumRows(), batch.NumRows())
+
+	updated, err := client.Fingerprint(ctx, "latest")
+	require.NoError(t, err)
+	assert.NotEqual(t, known, updated)
+
+	_, err = client.Fingerprint(ctx, "missing")
+	assert.Error(t, err)
+}
+
+// TestFingerprintFallback tests that batches are fingerprinted locally when the server cannot
+func TestFingerprintFallback(t *testing.T) {
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	addr := startBareServer(t, &multiBatchServer{batch: batch, count: 1})
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	known, err := client.Fingerprint(ctx, "any")
+	require.NoError(t, err, "Failed to fingerprint batch")
+	local, err := RecordFingerprint(batch)
+	require.NoError(t, err)
+	assert.Equal(t, local, known)
+
+	retrieved, changed, err := client.GetBatchIfChanged(ctx, "any", known)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Nil(t, retrieved)
+
+	retrieved, changed, err = client.GetBatchIfChanged(ctx, "any", "sha256:stale")
+	require.NoError(t, err)
+	require.True(t, changed)
+	defer retrieved.Release()
+	assert.Equal(t, batch.NumRows(), retrieved.NumRows())
+}
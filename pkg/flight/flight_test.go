@@ -8,6 +8,8 @@ import (
 
 	"github.com/apache/arrow-go/v18/arrow"
 	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
 	"github.com/apache/arrow-go/v18/arrow/memory"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -250,3 +252,182 @@ func TestFlightServerClientLargeBatch(t *testing.T) {
 	assert.Equal(t, int32(last), idCol.Value(last), "Last ID should match")
 	assert.Equal(t, float64(last)*1.1, valueCol.Value(last), "Last value should match")
 }
+
+// schemaOnlyServer is a Flight server whose DoGet streams a schema without any record batches
+type schemaOnlyServer struct {
+	flight.BaseFlightServer
+	schema *arrow.Schema
+}
+
+// DoGet writes only the schema message
+func (s *schemaOnlyServer) DoGet(request *flight.Ticket, stream flight.FlightService_DoGetServer) error {
+	writer := flight.NewRecordWriter(stream, ipc.WithSchema(s.schema))
+	return writer.Close()
+}
+
+// TestGetBatchSchemaOnly tests that a stream with a schema but no batches yields an empty record
+func TestGetBatchSchemaOnly(t *testing.T) {
+	schema := arrow.NewSchema(
+		[]arrow.Field{
+			{Name: "id", Type: arrow.PrimitiveTypes.Int32},
+			{Name: "name", Type: arrow.BinaryTypes.String},
+		},
+		nil,
+	)
+	addr := startBareServer(t, &schemaOnlyServer{schema: schema})
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batch, err := client.GetBatch(ctx, "empty")
+	require.NoError(t, err, "An empty result should not be an error")
+	defer batch.Release()
+
+	assert.Equal(t, int64(0), batch.NumRows(), "Batch should have no rows")
+	assert.True(t, schema.Equal(batch.Schema()), "Batch should carry the stream's schema")
+}
+
+// multiBatchServer is a Flight server whose DoGet streams the same record several times
+type multiBatchServer struct {
+	flight.BaseFlightServer
+	batch arrow.Record
+	count int
+}
+
+// DoGet writes the record count times
+func (s *multiBatchServer) DoGet(request *flight.Ticket, stream flight.FlightService_DoGetServer) error {
+	writer := flight.NewRecordWriter(stream, ipc.WithSchema(s.batch.Schema()))
+	defer writer.Close()
+
+	for i := 0; i < s.count; i++ {
+		if err := writer.Write(s.batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TestGetBatchLimits tests the MaxRows and MaxBatches safety limits
+func TestGetBatchLimits(t *testing.T) {
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	addr := startBareServer(t, &multiBatchServer{batch: batch, count: 3})
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Without limits all record batches are combined
+	combined, err := client.GetBatch(ctx, "any")
+	require.NoError(t, err, "Failed to get batch")
+	assert.Equal(t, 3*batch.NumRows(), combined.NumRows(), "All batches should be combined")
+	assert.Equal(t, "three", combined.Column(1).(*array.String).Value(12), "Rows should keep their order")
+	combined.Release()
+
+	// Limits that are not reached have no effect
+	withinLimits, err := client.GetBatchWithOptions(ctx, "any", GetOptions{MaxRows: 15, MaxBatches: 3})
+	require.NoError(t, err, "Limits should not be hit")
+	withinLimits.Release()
+
+	_, err = client.GetBatchWithOptions(ctx, "any", GetOptions{MaxBatches: 2})
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+
+	_, err = client.GetBatchWithOptions(ctx, "any", GetOptions{MaxRows: 7})
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+}
+
+// TestGetBatchAllocatorOverride tests that a per-call allocator receives the downloaded buffers
+func TestGetBatchAllocatorOverride(t *testing.T) {
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	servers := map[string]struct {
+		server flight.FlightServer
+		count  int
+	}{
+		"decompressed": {&compressedGetServer{batch: batch, codec: ipc.WithZstd()}, 1},
+		"concatenated": {&multiBatchServer{batch: batch, count: 3}, 3},
+	}
+
+	for name, tc := range servers {
+		t.Run(name, func(t *testing.T) {
+			addr := startBareServer(t, tc.server)
+
+			clientMem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+			client, err := NewFlightClient(FlightClientConfig{Addr: addr, Allocator: clientMem})
+			require.NoError(t, err, "Failed to create Flight client")
+			defer client.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			scratch := memory.NewCheckedAllocator(memory.NewGoAllocator())
+			retrieved, err := client.GetBatchWithOptions(ctx, "any", GetOptions{Allocator: scratch})
+			require.NoError(t, err, "Failed to get batch")
+			assert.Equal(t, int64(tc.count)*batch.NumRows(), retrieved.NumRows())
+			assert.Positive(t, scratch.CurrentAlloc(), "Record should be allocated from the scratch allocator")
+			assert.Zero(t, clientMem.CurrentAlloc(), "Client allocator should not be used")
+
+			retrieved.Release()
+			scratch.AssertSize(t, 0)
+		})
+	}
+}
+
+// TestGetBatchOwnership tests that a downloaded record holds no reference
+// beyond the caller's, so a single Release frees it
+func TestGetBatchOwnership(t *testing.T) {
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	// Compressed downloads are decoded into the client's allocator
+	addr := startBareServer(t, &compressedGetServer{batch: batch, codec: ipc.WithZstd()})
+
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr, Allocator: mem})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	retrieved, err := client.GetBatch(ctx, "any")
+	require.NoError(t, err, "Failed to get batch")
+	assert.Positive(t, mem.CurrentAlloc(), "The record should be allocated by the client")
+
+	retrieved.Release()
+	mem.AssertSize(t, 0)
+}
+
+// TestGetBatchBorrow tests that a borrowed record holds the batch's data and
+// is freed exactly once however often it is released
+func TestGetBatchBorrow(t *testing.T) {
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	addr := startBareServer(t, &compressedGetServer{batch: batch, codec: ipc.WithZstd()})
+
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr, Allocator: mem})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	borrowed, release, err := client.GetBatchBorrow(ctx, "any", GetOptions{})
+	require.NoError(t, err, "Failed to borrow batch")
+	assert.True(t, array.RecordEqual(batch, borrowed), "The borrowed record should hold the batch")
+
+	release()
+	release()
+	mem.AssertSize(t, 0)
+}
@@ -0,0 +1,75 @@
+package flight
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+)
+
+// IDGenerator names batches on the client before they are uploaded.
+//
+// For PutBatch to be idempotent, every attempt of the same logical upload must
+// receive the same ID: the generator must be deterministic in the batch (or in
+// a key the caller controls, such as a workflow and activity ID), and must not
+// return the same ID for different data, since the server keeps whichever
+// batch it stored first. Generators that return a fresh ID per call only
+// avoid server round trips for naming; they do not deduplicate retries.
+// Implementations must be safe for concurrent use.
+type IDGenerator interface {
+	// NewBatchID returns the ID to store batch under
+	NewBatchID(batch arrow.Record) (string, error)
+}
+
+// IDGeneratorFunc adapts a function to the IDGenerator interface
+type IDGeneratorFunc func(batch arrow.Record) (string, error)
+
+// NewBatchID implements IDGenerator
+func (f IDGeneratorFunc) NewBatchID(batch arrow.Record) (string, error) {
+	return f(batch)
+}
+
+// RandomIDGenerator returns a random 128-bit ID per batch
+type RandomIDGenerator struct{}
+
+// NewBatchID implements IDGenerator
+func (RandomIDGenerator) NewBatchID(arrow.Record) (string, error) {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return "", fmt.Errorf("failed to read random bytes: %w", err)
+	}
+	return "batch-" + hex.EncodeToString(id[:]), nil
+}
+
+// ContentHashIDGenerator derives the ID from a SHA-256 hash of the batch's
+// IPC encoding, so identical batches get identical IDs. Values hidden under
+// nulls are part of the encoding, so batches that are logically equal but
+// were built differently may hash differently.
+type ContentHashIDGenerator struct{}
+
+// NewBatchID implements IDGenerator
+func (ContentHashIDGenerator) NewBatchID(batch arrow.Record) (string, error) {
+	sum, err := contentHash(batch)
+	if err != nil {
+		return "", err
+	}
+	return "batch-" + sum, nil
+}
+
+// contentHash returns the hex SHA-256 hash of a record's IPC encoding
+func contentHash(batch arrow.Record) (string, error) {
+	hash := sha256.New()
+	writer := ipc.NewWriter(hash, ipc.WithSchema(batch.Schema()), ipc.WithAllocator(memory.DefaultAllocator))
+	if err := writer.Write(batch); err != nil {
+		writer.Close()
+		return "", fmt.Errorf("failed to encode batch for hashing: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("failed to encode batch for hashing: %w", err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
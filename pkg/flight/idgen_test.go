@@ -0,0 +1,215 @@
+package flight
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCustomIDGenerator tests that a configured generator names uploaded batches
+func TestCustomIDGenerator(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	var calls atomic.Int64
+	generator := IDGeneratorFunc(func(batch arrow.Record) (string, error) {
+		return fmt.Sprintf("custom-%d-%d", calls.Add(1), batch.NumRows()), nil
+	})
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr, IDGenerator: generator})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batchID, err := client.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to put batch")
+	assert.Equal(t, "custom-1-5", batchID, "The generated ID should be used")
+	assert.Equal(t, int64(1), calls.Load())
+
+	retrieved, err := client.GetBatch(ctx, batchID)
+	require.NoError(t, err, "Batch should be stored under the generated ID")
+	retrieved.Release()
+}
+
+// TestEmptyGeneratedID tests that an empty generated ID fails the upload
+// instead of falling back to a server-assigned ID
+func TestEmptyGeneratedID(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	generator := IDGeneratorFunc(func(arrow.Record) (string, error) { return "", nil })
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr, IDGenerator: generator})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = client.PutBatch(ctx, batch)
+	assert.ErrorContains(t, err, "empty ID")
+	batchIDs, err := client.ListBatches(ctx)
+	require.NoError(t, err, "Failed to list batches")
+	assert.Empty(t, batchIDs, "Nothing should be stored")
+}
+
+// TestContentHashIdempotentPut tests that retried uploads of the same data don't store duplicates
+func TestContentHashIdempotentPut(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr, IDGenerator: ContentHashIDGenerator{}})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	first, err := client.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to put batch")
+	second, err := client.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to retry put")
+	assert.Equal(t, first, second, "Identical batches should get identical IDs")
+
+	// Different data gets a different ID
+	slice := batch.NewSlice(0, 2)
+	defer slice.Release()
+	third, err := client.PutBatch(ctx, slice)
+	require.NoError(t, err, "Failed to put slice")
+	assert.NotEqual(t, first, third)
+
+	batches, err := client.ListBatches(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{first, third}, batches, "Retries should not store duplicates")
+}
+
+// eofPutServer is a Flight server that reads each upload and closes the
+// result stream without sending a result
+type eofPutServer struct {
+	flight.BaseFlightServer
+	rows atomic.Int64
+}
+
+// DoPut reads the uploaded record and returns without a result
+func (s *eofPutServer) DoPut(stream flight.FlightService_DoPutServer) error {
+	if _, err := stream.Recv(); err != nil {
+		return err
+	}
+	reader, err := flight.NewRecordReader(stream)
+	if err != nil {
+		return err
+	}
+	defer reader.Release()
+	if !reader.Next() {
+		return reader.Err()
+	}
+	s.rows.Add(reader.Record().NumRows())
+	return nil
+}
+
+// TestAcceptEOFResult tests that an EOF-only result stream counts as success for client-named uploads
+func TestAcceptEOFResult(t *testing.T) {
+	server := &eofPutServer{}
+	addr := startBareServer(t, server)
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for name, tc := range map[string]struct {
+		config FlightClientConfig
+		ok     bool
+	}{
+		"accepted for client IDs": {config: FlightClientConfig{AcceptEOFResult: true, IDGenerator: ContentHashIDGenerator{}}, ok: true},
+		"rejected by default":     {config: FlightClientConfig{IDGenerator: ContentHashIDGenerator{}}},
+		"rejected for server IDs": {config: FlightClientConfig{AcceptEOFResult: true}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			tc.config.Addr = addr
+			client, err := NewFlightClient(tc.config)
+			require.NoError(t, err, "Failed to create Flight client")
+			defer client.Close()
+
+			batchID, err := client.PutBatch(ctx, batch)
+			if !tc.ok {
+				assert.ErrorContains(t, err, "failed to receive result")
+				return
+			}
+			require.NoError(t, err, "The upload should succeed")
+			expected, err := ContentHashIDGenerator{}.NewBatchID(batch)
+			require.NoError(t, err)
+			assert.Equal(t, expected, batchID, "The ID should come from the generator")
+		})
+	}
+	assert.Equal(t, 3*batch.NumRows(), server.rows.Load(), "Every upload should reach the server")
+}
+
+// TestServerGeneratedIDsUnique tests that concurrent uploads without a client
+// ID are each stored under their own server-assigned ID
+func TestServerGeneratedIDsUnique(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	const uploads = 50
+	ids := make(chan string, uploads)
+	var wg sync.WaitGroup
+	for i := 0; i < uploads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := client.PutBatchWithOptions(ctx, batch, PutOptions{})
+			if assert.NoError(t, err, "Failed to put batch") {
+				assert.False(t, result.existing, "A generated ID should not be taken for a retry")
+				ids <- result.BatchID
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[string]bool)
+	for id := range ids {
+		assert.False(t, seen[id], "Batch ID %s was assigned twice", id)
+		seen[id] = true
+	}
+	batchIDs, err := client.ListBatches(ctx)
+	require.NoError(t, err, "Failed to list batches")
+	assert.Len(t, batchIDs, uploads, "Every upload should be stored")
+
+	for i := 0; i < 1000; i++ {
+		id := generateBatchID()
+		assert.False(t, seen[id], "Generated ID %s repeated", id)
+		seen[id] = true
+	}
+}
@@ -0,0 +1,49 @@
+package flight
+
+import (
+	"fmt"
+
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+)
+
+// IPCOptions tunes the Arrow IPC encoding used by the client's record writers
+// and readers. arrow-go always writes V5 metadata, 8-byte aligned messages
+// and continuation-marked framing, so those are not configurable; V4 metadata
+// and legacy framing from older peers are still read.
+type IPCOptions struct {
+	// DictionaryDeltas lets writers emit dictionary deltas instead of
+	// replacement dictionaries
+	DictionaryDeltas bool
+	// MinSpaceSavings skips compressing a buffer unless compression saves at
+	// least this fraction of its size (0 compresses every buffer). Only used
+	// with Compression.
+	MinSpaceSavings float64
+	// PreserveEndianness keeps non-native endian data received from the server
+	// as-is instead of converting it to native byte order
+	PreserveEndianness bool
+	// DescriptorWithSchema sends an upload's descriptor, and its put
+	// metadata, on the first data message together with the schema, which is
+	// what the Arrow reference implementations (C++, Java, Python) do and
+	// what stricter servers expect. By default the descriptor is sent as a
+	// standalone message ahead of the data, as earlier versions of this
+	// client did; FlightServer accepts either.
+	DescriptorWithSchema bool
+}
+
+// validate checks that the options are in range
+func (o IPCOptions) validate() error {
+	if o.MinSpaceSavings < 0 || o.MinSpaceSavings > 1 {
+		return fmt.Errorf("minimum space savings %v must be between 0 and 1", o.MinSpaceSavings)
+	}
+	return nil
+}
+
+// readerOptions returns the IPC options used to read records from the server
+// into buffers allocated from mem
+func (c *FlightClient) readerOptions(mem memory.Allocator) []ipc.Option {
+	return []ipc.Option{
+		ipc.WithAllocator(mem),
+		ipc.WithEnsureNativeEndian(!c.ipc.PreserveEndianness),
+	}
+}
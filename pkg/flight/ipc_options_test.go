@@ -0,0 +1,143 @@
+package flight
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TestIPCOptionsMinSpaceSavings tests that writer IPC options are applied to uploads
+func TestIPCOptionsMinSpaceSavings(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	// No buffer can save 100%, so nothing is compressed
+	client, err := NewFlightClient(FlightClientConfig{
+		Addr:        addr,
+		Compression: CompressionZstd,
+		IPC:         IPCOptions{MinSpaceSavings: 1, DictionaryDeltas: true},
+	})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	batch := createCompressibleBatch(t, 10000)
+	defer batch.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := client.PutBatchWithOptions(ctx, batch, PutOptions{})
+	require.NoError(t, err, "Failed to put batch")
+	assert.Greater(t, result.CompressionRatio(), 0.9, "Buffers should be sent uncompressed")
+
+	retrieved, err := client.GetBatch(ctx, result.BatchID)
+	require.NoError(t, err, "Failed to get batch")
+	defer retrieved.Release()
+	assert.Equal(t, batch.NumRows(), retrieved.NumRows())
+}
+
+// TestInvalidIPCOptions tests that out of range IPC settings are rejected
+func TestInvalidIPCOptions(t *testing.T) {
+	for _, savings := range []float64{-0.1, 2} {
+		_, err := NewFlightClient(FlightClientConfig{IPC: IPCOptions{MinSpaceSavings: savings}})
+		assert.Error(t, err, "Space savings of %v should be rejected", savings)
+	}
+
+	client, err := NewFlightClient(FlightClientConfig{IPC: IPCOptions{MinSpaceSavings: 0.5}})
+	require.NoError(t, err)
+	client.Close()
+}
+
+// strictPutServer is a Flight server that, like the reference
+// implementations, rejects uploads whose first message carries no schema
+type strictPutServer struct {
+	flight.BaseFlightServer
+}
+
+// DoPut requires the descriptor and schema together and acknowledges the rows received
+func (s *strictPutServer) DoPut(stream flight.FlightService_DoPutServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	if first.FlightDescriptor == nil || len(first.DataHeader) == 0 {
+		return status.Error(codes.InvalidArgument, "unexpected message: want the descriptor with the schema")
+	}
+
+	reader, err := flight.NewRecordReader(&replayReader{DataStreamReader: stream, first: first})
+	if err != nil {
+		return err
+	}
+	defer reader.Release()
+	var rows int64
+	for reader.Next() {
+		rows += reader.Record().NumRows()
+	}
+	if err := reader.Err(); err != nil {
+		return err
+	}
+	return stream.Send(&flight.PutResult{AppMetadata: []byte(fmt.Sprintf("rows-%d", rows))})
+}
+
+// TestIPCOptionsDescriptorWithSchema tests sending the upload descriptor with the schema
+func TestIPCOptionsDescriptorWithSchema(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	addr := startBareServer(t, &strictPutServer{})
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+	_, err = client.PutBatch(ctx, batch)
+	assert.ErrorContains(t, err, "unexpected message", "A standalone descriptor should be rejected")
+
+	strict, err := NewFlightClient(FlightClientConfig{Addr: addr, IPC: IPCOptions{DescriptorWithSchema: true}})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer strict.Close()
+	batchID, err := strict.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to put batch")
+	assert.Equal(t, "rows-5", batchID)
+
+	// FlightServer reads the put metadata from the first data message
+	server, addr := startTestServer(t)
+	defer server.Stop()
+	named, err := NewFlightClient(FlightClientConfig{
+		Addr:        addr,
+		IDGenerator: ContentHashIDGenerator{},
+		IPC:         IPCOptions{DescriptorWithSchema: true},
+	})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer named.Close()
+
+	want, err := ContentHashIDGenerator{}.NewBatchID(batch)
+	require.NoError(t, err)
+	batchID, err = named.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to put batch")
+	assert.Equal(t, want, batchID, "The client-chosen ID should be honored")
+
+	records := make(chan arrow.Record, 2)
+	batch.Retain()
+	records <- batch
+	batch.Retain()
+	records <- batch
+	close(records)
+	streamedID, err := named.PutStream(ctx, records, PutStreamOptions{})
+	require.NoError(t, err, "Failed to put stream")
+
+	retrieved, err := named.GetBatch(ctx, streamedID)
+	require.NoError(t, err, "Failed to get batch")
+	defer retrieved.Release()
+	assert.Equal(t, 2*batch.NumRows(), retrieved.NumRows())
+}
@@ -0,0 +1,57 @@
+package flight
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// labelKey is the context key of a call label
+type labelKey struct{}
+
+// WithLabel tags the calls made with ctx with a label naming the logical
+// operation they belong to, such as "load-sales-2024". The client's methods
+// then prefix their errors with the label in brackets, as do the streams,
+// exchanges and sessions they open, and adaptive compression names it when
+// an upload triggers a downgrade. Uploads and downloads also report it in
+// CallStats.Label, which is how observers such as metrics exporters see it;
+// the client records no spans of its own. An empty label removes the tag.
+func WithLabel(ctx context.Context, label string) context.Context {
+	return context.WithValue(ctx, labelKey{}, label)
+}
+
+// CallLabel returns the label set on ctx with WithLabel, or "" if there is none
+func CallLabel(ctx context.Context) string {
+	label, _ := ctx.Value(labelKey{}).(string)
+	return label
+}
+
+// LabeledError is an error returned by a call tagged with WithLabel
+type LabeledError struct {
+	Label string
+	Err   error
+}
+
+// Error implements error
+func (e *LabeledError) Error() string {
+	return "[" + e.Label + "] " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *LabeledError) Unwrap() error {
+	return e.Err
+}
+
+// labelError tags err with the label of ctx, unless it already carries it.
+// io.EOF, which ends streams rather than reporting a failure, is not tagged.
+func labelError(ctx context.Context, err error) error {
+	label := CallLabel(ctx)
+	if err == nil || err == io.EOF || label == "" {
+		return err
+	}
+	var labeled *LabeledError
+	if errors.As(err, &labeled) && labeled.Label == label {
+		return err
+	}
+	return &LabeledError{Label: label, Err: err}
+}
@@ -0,0 +1,98 @@
+package flight
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithLabel tests that a call label tags the errors and metrics of the calls made with it
+func TestWithLabel(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	metrics := &recordingMetrics{}
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr, Metrics: metrics})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	timeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ctx := WithLabel(timeout, "load-sales-2024")
+	assert.Equal(t, "load-sales-2024", CallLabel(ctx))
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	batchID, err := client.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to put batch")
+
+	_, err = client.GetBatch(ctx, "missing")
+	assert.Regexp(t, `^\[load-sales-2024\] failed to create record reader: `, err.Error())
+
+	_, err = client.GetBatchWithOptions(ctx, batchID, GetOptions{MaxRows: 1})
+	assert.ErrorIs(t, err, ErrLimitExceeded, "The cause should survive the label")
+	assert.Regexp(t, `^\[load-sales-2024\] `, err.Error())
+
+	var labeled *LabeledError
+	require.ErrorAs(t, err, &labeled)
+	assert.Equal(t, "load-sales-2024", labeled.Label)
+
+	_, err = client.PutBatchWithOptions(ctx, batch, PutOptions{Columns: []string{"unknown"}})
+	assert.ErrorContains(t, err, "[load-sales-2024] failed to project batch")
+
+	metrics.mu.Lock()
+	calls := append([]CallStats(nil), metrics.calls...)
+	metrics.mu.Unlock()
+	require.Len(t, calls, 4)
+	for _, call := range calls {
+		assert.Equal(t, "load-sales-2024", call.Label, "%s call should carry the label", call.Method)
+	}
+
+	// Calls without a label are untouched
+	_, err = client.GetBatch(timeout, "missing")
+	assert.NotContains(t, err.Error(), "[")
+}
+
+// TestWithLabelOtherCalls tests that listings, estimates, actions, exchanges
+// and sessions tag their errors with the call label too
+func TestWithLabelOtherCalls(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	timeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ctx := WithLabel(timeout, "nightly")
+
+	_, err = client.ListCatalog(ctx, []byte("not json"))
+	assert.ErrorContains(t, err, "[nightly] ")
+	_, err = client.EstimateTransfer(ctx, "missing")
+	assert.ErrorContains(t, err, "[nightly] ")
+
+	session, err := client.OpenSession(ctx)
+	require.NoError(t, err, "Failed to open session")
+	_, err = session.Do(ctx, "missing", nil)
+	assert.ErrorContains(t, err, "[nightly] ")
+	session.Close()
+
+	transformer, err := NewFlightClient(FlightClientConfig{Addr: startBareServer(t, &doublingServer{})})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer transformer.Close()
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+	_, err = transformer.Transform(ctx, batch, []byte("missing"))
+	assert.ErrorContains(t, err, "[nightly] ")
+
+	cancelled, cancelNow := context.WithCancel(ctx)
+	cancelNow()
+	_, err = client.ListActions(cancelled)
+	assert.ErrorContains(t, err, "[nightly] ")
+}
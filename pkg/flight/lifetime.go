@@ -0,0 +1,90 @@
+package flight
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// withRoot derives a context from ctx that is also cancelled, with cause
+// ErrClientClosed, when the client's root context ends. The returned function
+// must be called once the context is no longer needed.
+func (c *FlightClient) withRoot(ctx context.Context) (context.Context, func()) {
+	if c.root == nil {
+		return ctx, func() {}
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	stop := context.AfterFunc(c.root, func() { cancel(ErrClientClosed) })
+	return ctx, func() {
+		stop()
+		cancel(nil)
+	}
+}
+
+// unaryRootInterceptor aborts unary calls when the client's root context ends
+func (c *FlightClient) unaryRootInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	ctx, done := c.withRoot(ctx)
+	defer done()
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// streamRootInterceptor aborts streaming calls when the client's root context
+// ends
+func (c *FlightClient) streamRootInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	ctx, done := c.withRoot(ctx)
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		done()
+		return nil, err
+	}
+	// The stream's context ends when the call completes
+	context.AfterFunc(stream.Context(), done)
+	return stream, nil
+}
+
+// DeadlineHintHeader is the gRPC metadata key carrying the time remaining
+// before the client's deadline, in milliseconds, when
+// FlightClientConfig.DeadlineHint is enabled
+const DeadlineHintHeader = "flight-deadline-remaining-ms"
+
+// withDeadlineHint adds the remaining time of ctx's deadline to the outgoing
+// metadata. Contexts without a deadline are returned unchanged.
+func withDeadlineHint(ctx context.Context) context.Context {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return ctx
+	}
+	remaining := max(time.Until(deadline).Milliseconds(), 0)
+	return metadata.AppendToOutgoingContext(ctx, DeadlineHintHeader, strconv.FormatInt(remaining, 10))
+}
+
+// unaryDeadlineInterceptor sends the deadline hint with unary calls
+func unaryDeadlineInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(withDeadlineHint(ctx), method, req, reply, cc, opts...)
+}
+
+// streamDeadlineInterceptor sends the deadline hint with streaming calls
+func streamDeadlineInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return streamer(withDeadlineHint(ctx), desc, cc, method, opts...)
+}
+
+// DeadlineHint returns the time the client had left before its deadline when
+// it sent the request, as found in the DeadlineHintHeader of ctx's incoming
+// metadata. Server handlers can use it to budget expensive work. It reports
+// false if the client sent no hint.
+func DeadlineHint(ctx context.Context) (time.Duration, bool) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(DeadlineHintHeader)
+	if len(values) == 0 {
+		return 0, false
+	}
+	ms, err := strconv.ParseInt(values[0], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return time.Duration(ms) * time.Millisecond, true
+}
@@ -0,0 +1,58 @@
+package flight
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ActionGetLineage is the DoAction type used to fetch a batch's recorded parents.
+// The action body is the batch ID and the result body is a JSON array of IDs.
+const ActionGetLineage = "lineage"
+
+// getLineage returns the parent batch IDs recorded when the batch was uploaded
+func (s *FlightServer) getLineage(batchID string, stream flight.FlightService_DoActionServer) error {
+	if err := s.authorize(stream.Context(), batchID); err != nil {
+		return err
+	}
+
+	s.batchesMu.RLock()
+	_, ok := s.batches[batchID]
+	parents := s.lineage[batchID]
+	s.batchesMu.RUnlock()
+
+	if !ok {
+		return status.Errorf(codes.NotFound, "batch with ID %s not found", batchID)
+	}
+	if parents == nil {
+		parents = []string{}
+	}
+
+	body, err := json.Marshal(parents)
+	if err != nil {
+		return fmt.Errorf("failed to encode lineage: %w", err)
+	}
+	return stream.Send(&flight.Result{Body: body})
+}
+
+// GetLineage returns the IDs of the batches the given batch was derived from, as
+// recorded through PutOptions.Lineage. An empty slice is returned when no
+// lineage was recorded.
+func (c *FlightClient) GetLineage(ctx context.Context, batchID string) ([]string, error) {
+	body, err := c.doAction(ctx, ActionGetLineage, []byte(batchID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lineage for batch %s: %w", batchID, err)
+	}
+
+	parents := []string{}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &parents); err != nil {
+			return nil, fmt.Errorf("failed to decode lineage: %w", err)
+		}
+	}
+	return parents, nil
+}
@@ -0,0 +1,55 @@
+package flight
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBatchLineage tests recording and retrieving a chain of derived batches
+func TestBatchLineage(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// source -> derived -> final, where final also depends on a second source
+	sourceID, err := client.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to put source batch")
+	otherID, err := client.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to put other source batch")
+
+	derived, err := client.PutBatchWithOptions(ctx, batch, PutOptions{Lineage: []string{sourceID}})
+	require.NoError(t, err, "Failed to put derived batch")
+	final, err := client.PutBatchWithOptions(ctx, batch, PutOptions{Lineage: []string{derived.BatchID, otherID}})
+	require.NoError(t, err, "Failed to put final batch")
+
+	parents, err := client.GetLineage(ctx, final.BatchID)
+	require.NoError(t, err, "Failed to get lineage")
+	assert.Equal(t, []string{derived.BatchID, otherID}, parents)
+
+	parents, err = client.GetLineage(ctx, derived.BatchID)
+	require.NoError(t, err, "Failed to get lineage")
+	assert.Equal(t, []string{sourceID}, parents)
+
+	// Batches without recorded lineage have no parents
+	parents, err = client.GetLineage(ctx, sourceID)
+	require.NoError(t, err, "Missing lineage should not be an error")
+	assert.NotNil(t, parents)
+	assert.Empty(t, parents)
+
+	_, err = client.GetLineage(ctx, "missing")
+	assert.Error(t, err, "Unknown batches should fail")
+}
@@ -0,0 +1,169 @@
+package flight
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+)
+
+// ListOptions configures ListBatchesWithOptions
+type ListOptions struct {
+	// Dedup drops repeated batch IDs, keeping the first occurrence, for
+	// servers that list the same batch once per endpoint serving it. By
+	// default IDs are returned exactly as the server sent them.
+	Dedup bool
+	// Criteria is a ListFlights criteria expression, such as one built by
+	// ListFilter, for servers that filter their listing. Servers that ignore
+	// criteria list every batch.
+	Criteria []byte
+}
+
+// ListFilter selects the batches a FlightServer lists. Every set condition
+// must hold for a batch to be listed.
+type ListFilter struct {
+	// Prefix keeps the batches whose ID starts with it
+	Prefix string `json:"prefix,omitempty"`
+	// Tags keeps the batches whose schema metadata holds every key with the
+	// given value
+	Tags map[string]string `json:"tags,omitempty"`
+	// SchemaFingerprint keeps the batches whose schema has this
+	// SchemaFingerprint
+	SchemaFingerprint string `json:"schemaFingerprint,omitempty"`
+}
+
+// Criteria encodes the filter as a ListFlights criteria expression
+func (f ListFilter) Criteria() []byte {
+	// Strings and a string map always encode
+	expression, _ := json.Marshal(f)
+	return expression
+}
+
+// FilterByPrefix returns criteria listing the batches whose ID starts with prefix
+func FilterByPrefix(prefix string) []byte {
+	return ListFilter{Prefix: prefix}.Criteria()
+}
+
+// FilterByTag returns criteria listing the batches tagged key=value
+func FilterByTag(key, value string) []byte {
+	return ListFilter{Tags: map[string]string{key: value}}.Criteria()
+}
+
+// FilterBySchemaFingerprint returns criteria listing the batches whose schema
+// has the given SchemaFingerprint
+func FilterBySchemaFingerprint(fingerprint string) []byte {
+	return ListFilter{SchemaFingerprint: fingerprint}.Criteria()
+}
+
+// SchemaFingerprint identifies a schema by its fields, ignoring metadata, so
+// batches of the same shape can be listed together
+func SchemaFingerprint(schema *arrow.Schema) string {
+	return schemaKey(schema)
+}
+
+// matches reports whether a stored batch passes the filter
+func (f ListFilter) matches(batchID string, batch arrow.Record) bool {
+	if !strings.HasPrefix(batchID, f.Prefix) {
+		return false
+	}
+	if len(f.Tags) > 0 {
+		md := batch.Schema().Metadata()
+		for key, value := range f.Tags {
+			if i := md.FindKey(key); i < 0 || md.Values()[i] != value {
+				return false
+			}
+		}
+	}
+	return f.SchemaFingerprint == "" || SchemaFingerprint(batch.Schema()) == f.SchemaFingerprint
+}
+
+// ListBatchesWithOptions lists the batches in the Flight server, as
+// ListBatches, with the given options
+func (c *FlightClient) ListBatchesWithOptions(ctx context.Context, options ListOptions) ([]string, error) {
+	var batchIDs []string
+	seen := make(map[string]bool)
+	err := c.listFlights(ctx, options.Criteria, func(info *flight.FlightInfo) error {
+		batchID := string(info.FlightDescriptor.Cmd)
+		if options.Dedup {
+			if seen[batchID] {
+				return nil
+			}
+			seen[batchID] = true
+		}
+		batchIDs = append(batchIDs, batchID)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return batchIDs, nil
+}
+
+// ListBatchesByEndpoint lists all batches in the Flight server grouped by the
+// location URI of the endpoints serving them. Each group holds a batch ID at
+// most once. Endpoints without a location, which are served by this server,
+// are grouped under the empty string.
+func (c *FlightClient) ListBatchesByEndpoint(ctx context.Context) (map[string][]string, error) {
+	groups := make(map[string][]string)
+	seen := make(map[string]map[string]bool)
+	add := func(location, batchID string) {
+		if seen[location] == nil {
+			seen[location] = make(map[string]bool)
+		}
+		if !seen[location][batchID] {
+			seen[location][batchID] = true
+			groups[location] = append(groups[location], batchID)
+		}
+	}
+
+	err := c.listFlights(ctx, nil, func(info *flight.FlightInfo) error {
+		batchID := string(info.FlightDescriptor.Cmd)
+		for _, endpoint := range info.Endpoint {
+			if len(endpoint.Location) == 0 {
+				add("", batchID)
+			}
+			for _, location := range endpoint.Location {
+				add(location.Uri, batchID)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// listFlights calls visit for each FlightInfo listed for the criteria
+// expression, stopping at the first error visit returns. A nil expression
+// lists every batch.
+func (c *FlightClient) listFlights(ctx context.Context, criteria []byte, visit func(*flight.FlightInfo) error) error {
+	client, release, err := c.acquire(ctx)
+	if err != nil {
+		return labelError(ctx, err)
+	}
+	defer release()
+
+	stream, err := client.ListFlights(ctx, &flight.Criteria{Expression: criteria})
+	if err != nil {
+		return labelError(ctx, fmt.Errorf("failed to start ListFlights stream: %w", err))
+	}
+
+	for {
+		info, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return labelError(ctx, fmt.Errorf("error receiving flight info: %w", err))
+		}
+		if err := visit(info); err != nil {
+			return labelError(ctx, err)
+		}
+	}
+}
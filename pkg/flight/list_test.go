@@ -0,0 +1,160 @@
+package flight
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// replicatedListServer is a Flight server that lists each batch once per
+// endpoint serving it
+type replicatedListServer struct {
+	flight.BaseFlightServer
+}
+
+// ListFlights sends a FlightInfo per batch and endpoint
+func (s *replicatedListServer) ListFlights(request *flight.Criteria, stream flight.FlightService_ListFlightsServer) error {
+	for _, listed := range []struct{ batchID, location string }{
+		{"a", "grpc://node1:8815"},
+		{"b", "grpc://node1:8815"},
+		{"a", "grpc://node2:8815"},
+		{"c", ""},
+		{"b", "grpc://node2:8815"},
+	} {
+		endpoint := &flight.FlightEndpoint{}
+		if listed.location != "" {
+			endpoint.Location = []*flight.Location{{Uri: listed.location}}
+		}
+		info := &flight.FlightInfo{
+			FlightDescriptor: &flight.FlightDescriptor{Type: flight.DescriptorCMD, Cmd: []byte(listed.batchID)},
+			Endpoint:         []*flight.FlightEndpoint{endpoint},
+		}
+		if err := stream.Send(info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TestListBatchesDuplicates tests deduplicating and grouping batch IDs listed by several endpoints
+func TestListBatchesDuplicates(t *testing.T) {
+	addr := startBareServer(t, &replicatedListServer{})
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// The default keeps the server's listing as is
+	batchIDs, err := client.ListBatches(ctx)
+	require.NoError(t, err, "Failed to list batches")
+	assert.Equal(t, []string{"a", "b", "a", "c", "b"}, batchIDs)
+
+	batchIDs, err = client.ListBatchesWithOptions(ctx, ListOptions{Dedup: true})
+	require.NoError(t, err, "Failed to list batches")
+	assert.Equal(t, []string{"a", "b", "c"}, batchIDs, "The first occurrence of each ID should be kept")
+
+	groups, err := client.ListBatchesByEndpoint(ctx)
+	require.NoError(t, err, "Failed to list batches")
+	assert.Equal(t, map[string][]string{
+		"grpc://node1:8815": {"a", "b"},
+		"grpc://node2:8815": {"a", "b"},
+		"":                  {"c"},
+	}, groups)
+}
+
+// criteriaRecordingServer lists a FlightServer's batches, recording the
+// criteria expressions it receives and optionally ignoring them
+type criteriaRecordingServer struct {
+	flight.BaseFlightServer
+	server *FlightServer
+	ignore bool
+
+	mu          sync.Mutex
+	expressions [][]byte
+}
+
+// ListFlights records the criteria and lists the batches
+func (s *criteriaRecordingServer) ListFlights(request *flight.Criteria, stream flight.FlightService_ListFlightsServer) error {
+	s.mu.Lock()
+	s.expressions = append(s.expressions, request.Expression)
+	ignore := s.ignore
+	s.mu.Unlock()
+	if ignore {
+		request = &flight.Criteria{}
+	}
+	return s.server.ListFlights(request, stream)
+}
+
+// TestListBatchesCriteria tests filtering the listing by prefix, tag and schema fingerprint
+func TestListBatchesCriteria(t *testing.T) {
+	server, _ := startTestServer(t)
+	defer server.Stop()
+	recorder := &criteriaRecordingServer{server: server}
+	addr := startBareServer(t, recorder)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Batches are named by upload order with the team in the prefix
+	var uploads int
+	serverClient, err := NewFlightClient(FlightClientConfig{
+		Addr: server.addr,
+		IDGenerator: IDGeneratorFunc(func(arrow.Record) (string, error) {
+			uploads++
+			return fmt.Sprintf("sales-%d", uploads), nil
+		}),
+	})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer serverClient.Close()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+	md := arrow.NewMetadata([]string{"stage"}, []string{"curated"})
+	tagged := array.NewRecord(arrow.NewSchema(batch.Schema().Fields(), &md), batch.Columns(), batch.NumRows())
+	defer tagged.Release()
+	narrow := array.NewRecord(arrow.NewSchema(batch.Schema().Fields()[:1], nil), batch.Columns()[:1], batch.NumRows())
+	defer narrow.Release()
+
+	for _, rec := range []arrow.Record{batch, tagged, narrow} {
+		_, err := serverClient.PutBatch(ctx, rec)
+		require.NoError(t, err, "Failed to put batch")
+	}
+	otherID := server.StoreBatch(batch)
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	list := func(criteria []byte) []string {
+		batchIDs, err := client.ListBatchesWithOptions(ctx, ListOptions{Criteria: criteria})
+		require.NoError(t, err, "Failed to list batches")
+		return batchIDs
+	}
+
+	assert.ElementsMatch(t, []string{"sales-1", "sales-2", "sales-3"}, list(FilterByPrefix("sales-")))
+	assert.Equal(t, []string{"sales-2"}, list(FilterByTag("stage", "curated")))
+	assert.ElementsMatch(t, []string{"sales-1", "sales-2", otherID}, list(FilterBySchemaFingerprint(SchemaFingerprint(batch.Schema()))),
+		"Schema metadata should not change the fingerprint")
+	assert.Empty(t, list(ListFilter{Prefix: "sales-", Tags: map[string]string{"stage": "raw"}}.Criteria()))
+	assert.Len(t, list(nil), 4)
+
+	recorder.mu.Lock()
+	assert.Equal(t, FilterByPrefix("sales-"), recorder.expressions[0], "The criteria should reach the server")
+
+	// Servers that ignore criteria list everything
+	recorder.ignore = true
+	recorder.mu.Unlock()
+	assert.Len(t, list(FilterByTag("stage", "curated")), 4)
+}
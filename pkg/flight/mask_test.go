@@ -0,0 +1,103 @@
+package flight
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	arrow_utils "github.com/TFMV/temporal/pkg/arrow"
+)
+
+// TestGetBatchMask tests each masking strategy on string and numeric columns
+func TestGetBatchMask(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+	batchID, err := client.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to put batch")
+
+	// get downloads the batch with masks, checking that nothing leaks
+	get := func(t *testing.T, masks map[string]arrow_utils.MaskStrategy) arrow.Record {
+		mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+		t.Cleanup(func() { mem.AssertSize(t, 0) })
+		retrieved, err := client.GetBatchWithOptions(ctx, batchID, GetOptions{Allocator: mem, Mask: masks})
+		require.NoError(t, err, "Failed to get masked batch")
+		t.Cleanup(retrieved.Release)
+		require.Equal(t, batch.NumRows(), retrieved.NumRows())
+		return retrieved
+	}
+
+	t.Run("nullify", func(t *testing.T) {
+		masked := get(t, map[string]arrow_utils.MaskStrategy{"id": {}, "name": {Kind: arrow_utils.MaskNullify}})
+		for _, name := range []string{"id", "name"} {
+			i := masked.Schema().FieldIndices(name)[0]
+			assert.True(t, arrow.TypeEqual(batch.Schema().Field(i).Type, masked.Schema().Field(i).Type), "%s should keep its type", name)
+			assert.True(t, masked.Schema().Field(i).Nullable)
+			assert.Equal(t, masked.Column(i).Len(), masked.Column(i).NullN(), "%s should be all null", name)
+		}
+		assert.True(t, array.Equal(batch.Column(2), masked.Column(2)), "Unmasked columns should be unchanged")
+	})
+
+	t.Run("hash", func(t *testing.T) {
+		hash := map[string]arrow_utils.MaskStrategy{
+			"id":    {Kind: arrow_utils.MaskHash},
+			"name":  {Kind: arrow_utils.MaskHash},
+			"value": {Kind: arrow_utils.MaskHash},
+		}
+		masked := get(t, hash)
+		again := get(t, hash)
+		salted := get(t, map[string]arrow_utils.MaskStrategy{"name": {Kind: arrow_utils.MaskHash, Salt: []byte("pepper")}})
+
+		assert.Equal(t, arrow.PrimitiveTypes.Int32, masked.Schema().Field(0).Type, "Integers keep their type")
+		assert.Equal(t, arrow.BinaryTypes.String, masked.Schema().Field(1).Type, "Strings keep their type")
+		assert.Equal(t, arrow.BinaryTypes.String, masked.Schema().Field(2).Type, "Floats become digests")
+
+		ids, names := masked.Column(0).(*array.Int32), masked.Column(1).(*array.String)
+		original := batch.Column(1).(*array.String)
+		for i := 0; i < names.Len(); i++ {
+			assert.Len(t, names.Value(i), 64, "Row %d should hold a hex SHA-256 digest", i)
+			assert.NotEqual(t, original.Value(i), names.Value(i))
+			assert.NotEqual(t, names.Value(i), salted.Column(1).(*array.String).Value(i), "The salt should change the hash")
+			assert.Len(t, masked.Column(2).(*array.String).Value(i), 64)
+		}
+		assert.NotEqual(t, batch.Column(0).(*array.Int32).Int32Values(), ids.Int32Values())
+		assert.True(t, array.RecordEqual(masked, again), "Hashes should be deterministic")
+	})
+
+	t.Run("redact", func(t *testing.T) {
+		masked := get(t, map[string]arrow_utils.MaskStrategy{
+			"id":    {Kind: arrow_utils.MaskRedact},
+			"name":  {Kind: arrow_utils.MaskRedact},
+			"value": {Kind: arrow_utils.MaskRedact, Constant: -1.0},
+		})
+		assert.True(t, batch.Schema().Equal(masked.Schema()), "Redaction should keep the schema")
+		for i := 0; i < int(masked.NumRows()); i++ {
+			assert.Equal(t, int32(0), masked.Column(0).(*array.Int32).Value(i))
+			assert.Equal(t, "REDACTED", masked.Column(1).(*array.String).Value(i))
+			assert.Equal(t, -1.0, masked.Column(2).(*array.Float64).Value(i))
+		}
+
+		custom := get(t, map[string]arrow_utils.MaskStrategy{"name": {Kind: arrow_utils.MaskRedact, Constant: "***"}})
+		assert.Equal(t, "***", custom.Column(1).(*array.String).Value(0))
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		_, err := client.GetBatchWithOptions(ctx, batchID, GetOptions{Mask: map[string]arrow_utils.MaskStrategy{"ssn": {}}})
+		assert.ErrorContains(t, err, `column "ssn" not found`)
+	})
+}
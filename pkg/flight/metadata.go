@@ -0,0 +1,86 @@
+package flight
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// putMetadata is the structured AppMetadata sent alongside the DoPut descriptor.
+// Servers that don't understand it ignore it; fields are omitted when unset so
+// plain uploads carry no metadata at all.
+type putMetadata struct {
+	// BatchID is the ID the client wants the batch stored under
+	BatchID string `json:"batchId,omitempty"`
+	// Lineage lists the IDs of the batches the uploaded batch was derived from
+	Lineage []string `json:"lineage,omitempty"`
+	// Delta, if set, asks the server to apply the upload to a base batch
+	Delta *deltaMetadata `json:"delta,omitempty"`
+	// Streamed marks an upload of any number of record batches, ended by the
+	// client half-closing the stream. Without it the server stores the first
+	// record batch and replies straight away.
+	Streamed bool `json:"streamed,omitempty"`
+	// Access, if set, restricts who may read the stored batch
+	Access *AccessPolicy `json:"access,omitempty"`
+	// SchemaID is the registry ID of the batch's schema
+	SchemaID string `json:"schemaId,omitempty"`
+	// ContentHash is the RecordFingerprint of the uploaded batch, sent to ask
+	// the server to reuse a stored batch with the same contents
+	ContentHash string `json:"contentHash,omitempty"`
+	// TxID, if set, stages the upload in an open transaction instead of
+	// storing it
+	TxID string `json:"txId,omitempty"`
+	// DedupKeys, if set, asks the server to upsert the batch by these key
+	// columns
+	DedupKeys []string `json:"dedupKeys,omitempty"`
+}
+
+// isEmpty reports whether there is nothing to send
+func (m putMetadata) isEmpty() bool {
+	return m.BatchID == "" && len(m.Lineage) == 0 && m.Delta == nil && !m.Streamed && m.Access == nil && m.SchemaID == "" && m.ContentHash == "" && m.TxID == "" && len(m.DedupKeys) == 0
+}
+
+// deltaMetadata describes a PutDelta upload. The uploaded record holds the
+// added rows followed by the updated rows.
+type deltaMetadata struct {
+	// BaseID is the batch the delta applies to
+	BaseID string `json:"baseId"`
+	// Keys are the key columns identifying rows
+	Keys []string `json:"keys"`
+	// Added is the number of leading rows of the upload that are additions
+	Added int64 `json:"added"`
+	// Deleted is an IPC stream holding the key columns of the deleted rows
+	Deleted []byte `json:"deleted,omitempty"`
+}
+
+// putResult is the JSON PutResult metadata sent when the server has more to
+// report than the batch ID, or when the ID starts with '{'. Other plain
+// uploads get the bare batch ID, which is what older clients expect.
+type putResult struct {
+	BatchID string `json:"batchId"`
+	// Delta acknowledges that the upload was applied as a delta
+	Delta bool `json:"delta,omitempty"`
+	// Streamed acknowledges that every record batch of the upload was stored
+	Streamed bool `json:"streamed,omitempty"`
+	// Access acknowledges that the upload's access policy is enforced
+	Access bool `json:"access,omitempty"`
+	// Deduped reports that the batch ID is that of a stored batch with the
+	// same contents, which was kept instead of the upload
+	Deduped bool `json:"deduped,omitempty"`
+	// DedupKeys acknowledges that the upload was upserted by its dedup keys
+	DedupKeys bool `json:"dedupKeys,omitempty"`
+	// Existing reports that a batch was already stored under the ID, so the
+	// upload did not create it
+	Existing bool `json:"existing,omitempty"`
+}
+
+// decodePutResult parses the AppMetadata of a PutResult in either form
+func decodePutResult(appMetadata []byte) (putResult, error) {
+	if len(appMetadata) == 0 || appMetadata[0] != '{' {
+		return putResult{BatchID: string(appMetadata)}, nil
+	}
+	var result putResult
+	if err := json.Unmarshal(appMetadata, &result); err != nil {
+		return putResult{}, fmt.Errorf("invalid put result: %w", err)
+	}
+	return result, nil
+}
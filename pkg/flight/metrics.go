@@ -0,0 +1,195 @@
+package flight
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/flight"
+)
+
+// Method names reported in CallStats
+const (
+	MethodPutBatch = "PutBatch"
+	MethodGetBatch = "GetBatch"
+)
+
+// Metrics receives instrumentation from a FlightClient. It is called once for
+// every completed PutBatch and GetBatch call (including streamed reads, which
+// complete when the stream is closed). Implementations must be safe for
+// concurrent use and should return quickly.
+type Metrics interface {
+	ObserveCall(stats CallStats)
+}
+
+// CallStats describes a single completed client call
+type CallStats struct {
+	// Method is the client operation, one of the Method constants
+	Method string
+	// BatchID is the batch written or read, empty if unknown
+	BatchID string
+	// Duration is the wall time of the call
+	Duration time.Duration
+	// Rows is the number of rows written or read
+	Rows int64
+	// Bytes is the number of IPC body bytes sent or received
+	Bytes int64
+	// UncompressedBytes is the size of the Arrow buffers uploaded before
+	// encoding, or downloaded after decoding
+	UncompressedBytes int64
+	// Compression is the IPC codec used for an upload
+	Compression string
+	// Label is the call's WithLabel tag, empty if it has none
+	Label string
+	// Err is the error the call failed with, nil on success
+	Err error
+}
+
+// CompressionRatio returns Bytes / UncompressedBytes for an upload, or 1 if
+// nothing was uploaded
+func (s CallStats) CompressionRatio() float64 {
+	if s.UncompressedBytes == 0 {
+		return 1
+	}
+	return float64(s.Bytes) / float64(s.UncompressedBytes)
+}
+
+// SlowCall describes a call that took longer than
+// FlightClientConfig.SlowCallThreshold
+type SlowCall struct {
+	CallStats
+	// Peer is the address of the server the call was made to
+	Peer string
+}
+
+// multiMetrics fans calls out to several hooks
+type multiMetrics []Metrics
+
+// ObserveCall implements Metrics
+func (m multiMetrics) ObserveCall(stats CallStats) {
+	for _, metrics := range m {
+		metrics.ObserveCall(stats)
+	}
+}
+
+// MultiMetrics returns a Metrics hook notifying each of the given hooks in turn
+func MultiMetrics(metrics ...Metrics) Metrics {
+	return multiMetrics(metrics)
+}
+
+// defaultRatioBounds are the CompressionHistogram bucket bounds used when
+// none are given
+var defaultRatioBounds = []float64{0.1, 0.25, 0.5, 0.75, 1}
+
+// CompressionHistogram is a Metrics hook aggregating the compression ratio of
+// successful compressed uploads into a histogram, for capacity planning
+type CompressionHistogram struct {
+	mu                sync.Mutex
+	bounds            []float64
+	counts            []int64 // One per bound, plus one for ratios above the last
+	uncompressedBytes int64
+	compressedBytes   int64
+}
+
+// CompressionSummary is a snapshot of a CompressionHistogram
+type CompressionSummary struct {
+	// Uploads is the number of compressed uploads observed
+	Uploads int64
+	// UncompressedBytes and CompressedBytes total the uploads' sizes
+	UncompressedBytes int64
+	CompressedBytes   int64
+	// Buckets counts the uploads by ratio. Each bucket holds the ratios up to
+	// its UpperBound and above the previous one; the last has an infinite bound.
+	Buckets []CompressionBucket
+}
+
+// CompressionBucket is a histogram bucket of a CompressionSummary
+type CompressionBucket struct {
+	UpperBound float64
+	Count      int64
+}
+
+// Ratio returns the compression ratio over all observed uploads, or 1 if
+// there were none
+func (s CompressionSummary) Ratio() float64 {
+	if s.UncompressedBytes == 0 {
+		return 1
+	}
+	return float64(s.CompressedBytes) / float64(s.UncompressedBytes)
+}
+
+// NewCompressionHistogram creates a histogram with the given ascending
+// bucket bounds (default: 0.1, 0.25, 0.5, 0.75, 1)
+func NewCompressionHistogram(bounds ...float64) *CompressionHistogram {
+	if len(bounds) == 0 {
+		bounds = defaultRatioBounds
+	}
+	bounds = slices.Clone(bounds)
+	slices.Sort(bounds)
+	return &CompressionHistogram{bounds: bounds, counts: make([]int64, len(bounds)+1)}
+}
+
+// ObserveCall implements Metrics, recording successful compressed uploads
+func (h *CompressionHistogram) ObserveCall(stats CallStats) {
+	if stats.Method != MethodPutBatch || stats.Err != nil || stats.UncompressedBytes == 0 ||
+		stats.Compression == "" || stats.Compression == CompressionNone {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	bucket, _ := slices.BinarySearch(h.bounds, stats.CompressionRatio())
+	h.counts[bucket]++
+	h.uncompressedBytes += stats.UncompressedBytes
+	h.compressedBytes += stats.Bytes
+}
+
+// Summary returns the uploads observed so far
+func (h *CompressionHistogram) Summary() CompressionSummary {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	summary := CompressionSummary{
+		UncompressedBytes: h.uncompressedBytes,
+		CompressedBytes:   h.compressedBytes,
+		Buckets:           make([]CompressionBucket, len(h.counts)),
+	}
+	for i, count := range h.counts {
+		bound := math.Inf(1)
+		if i < len(h.bounds) {
+			bound = h.bounds[i]
+		}
+		summary.Buckets[i] = CompressionBucket{UpperBound: bound, Count: count}
+		summary.Uploads += count
+	}
+	return summary
+}
+
+// observe reports a completed call to the configured metrics and slow call
+// hooks
+func (c *FlightClient) observe(stats CallStats) {
+	c.throughput.observe(stats)
+	if c.metrics != nil {
+		c.metrics.ObserveCall(stats)
+	}
+	if c.onSlowCall != nil && c.slowCallThreshold > 0 && stats.Duration > c.slowCallThreshold {
+		c.onSlowCall(SlowCall{CallStats: stats, Peer: c.ActiveAddr()})
+	}
+}
+
+// countingReader wraps a Flight data stream and counts the body bytes received through it
+type countingReader struct {
+	flight.DataStreamReader
+	bodyBytes int64
+}
+
+// Recv reads the next message and records the size of its body
+func (r *countingReader) Recv() (*flight.FlightData, error) {
+	data, err := r.DataStreamReader.Recv()
+	if data != nil {
+		r.bodyBytes += int64(len(data.DataBody))
+	}
+	return data, err
+}
@@ -0,0 +1,65 @@
+package flight
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOnSlowCall tests that calls over the latency threshold are reported once
+// with their details, and faster calls are not
+func TestOnSlowCall(t *testing.T) {
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	addr := startBareServer(t, &slowGetServer{batch: batch, totalBytes: -1, delay: 200 * time.Millisecond})
+
+	var mu sync.Mutex
+	var slow []SlowCall
+	client, err := NewFlightClient(FlightClientConfig{
+		Addr:              addr,
+		SlowCallThreshold: 100 * time.Millisecond,
+		OnSlowCall: func(call SlowCall) {
+			mu.Lock()
+			defer mu.Unlock()
+			slow = append(slow, call)
+		},
+	})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	retrieved, err := client.GetBatch(ctx, "slow-batch")
+	require.NoError(t, err, "Failed to get batch")
+	retrieved.Release()
+
+	mu.Lock()
+	require.Len(t, slow, 1, "The slow call should be reported once")
+	call := slow[0]
+	mu.Unlock()
+	assert.Equal(t, MethodGetBatch, call.Method)
+	assert.Equal(t, "slow-batch", call.BatchID)
+	assert.Equal(t, addr, call.Peer)
+	assert.GreaterOrEqual(t, call.Duration, 200*time.Millisecond)
+	assert.NoError(t, call.Err)
+
+	// Calls within the budget are not reported
+	fast, err := NewFlightClient(FlightClientConfig{
+		Addr:              addr,
+		SlowCallThreshold: time.Minute,
+		OnSlowCall:        func(call SlowCall) { t.Errorf("Unexpected slow call %+v", call) },
+	})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer fast.Close()
+
+	retrieved, err = fast.GetBatch(ctx, "slow-batch")
+	require.NoError(t, err, "Failed to get batch")
+	retrieved.Release()
+}
@@ -0,0 +1,132 @@
+package flight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/apache/arrow-go/v18/arrow"
+)
+
+// MultiClientConfig contains configuration for a MultiClient
+type MultiClientConfig struct {
+	// Clients are the Flight clients of the replication targets. Creating
+	// them through a ConnPool reuses connections across MultiClients.
+	Clients []*FlightClient
+	// Quorum is the number of targets that must accept a write for it to
+	// succeed. Zero requires every target.
+	Quorum int
+}
+
+// MultiClient replicates writes to several Flight servers concurrently
+type MultiClient struct {
+	clients []*FlightClient
+	quorum  int
+}
+
+// TargetResult is the outcome of a replicated write on a single target
+type TargetResult struct {
+	// Addr is the address of the target server
+	Addr string
+	// Result is the target's put result, nil if the write failed
+	Result *PutBatchResult
+	// Err is the error returned by the target, nil if the write succeeded
+	Err error
+}
+
+// MultiPutResult collects the per-target outcomes of a replicated write, in
+// the order the clients were configured
+type MultiPutResult struct {
+	Targets []TargetResult
+}
+
+// Succeeded returns the targets that accepted the write
+func (r *MultiPutResult) Succeeded() []TargetResult {
+	var targets []TargetResult
+	for _, target := range r.Targets {
+		if target.Err == nil {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// Failed returns the targets that rejected the write
+func (r *MultiPutResult) Failed() []TargetResult {
+	var targets []TargetResult
+	for _, target := range r.Targets {
+		if target.Err != nil {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// NewMultiClient creates a MultiClient over the given clients. The MultiClient
+// takes ownership of the clients and closes them on Close.
+func NewMultiClient(config MultiClientConfig) (*MultiClient, error) {
+	if len(config.Clients) == 0 {
+		return nil, fmt.Errorf("at least one client is required")
+	}
+
+	quorum := config.Quorum
+	if quorum == 0 {
+		quorum = len(config.Clients)
+	}
+	if quorum < 0 || quorum > len(config.Clients) {
+		return nil, fmt.Errorf("quorum %d out of range for %d clients", config.Quorum, len(config.Clients))
+	}
+
+	return &MultiClient{
+		clients: config.Clients,
+		quorum:  quorum,
+	}, nil
+}
+
+// PutBatch writes a batch to every target concurrently
+func (m *MultiClient) PutBatch(ctx context.Context, batch arrow.Record) (*MultiPutResult, error) {
+	return m.PutBatchWithOptions(ctx, batch, PutOptions{})
+}
+
+// PutBatchWithOptions writes a batch to every target concurrently and waits for
+// all of them to finish. The per-target results are always returned; the error
+// wraps ErrQuorumNotMet, along with each target's failure, if fewer targets than
+// the quorum accepted the write.
+func (m *MultiClient) PutBatchWithOptions(ctx context.Context, batch arrow.Record, options PutOptions) (*MultiPutResult, error) {
+	result := &MultiPutResult{Targets: make([]TargetResult, len(m.clients))}
+
+	var wg sync.WaitGroup
+	for i, client := range m.clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := client.PutBatchWithOptions(ctx, batch, options)
+			result.Targets[i] = TargetResult{Addr: client.ActiveAddr(), Result: res, Err: err}
+		}()
+	}
+	wg.Wait()
+
+	failed := result.Failed()
+	if len(result.Targets)-len(failed) >= m.quorum {
+		return result, nil
+	}
+
+	errs := []error{fmt.Errorf("%w: %d of %d targets succeeded, need %d",
+		ErrQuorumNotMet, len(result.Targets)-len(failed), len(result.Targets), m.quorum)}
+	for _, target := range failed {
+		errs = append(errs, fmt.Errorf("target %s: %w", target.Addr, target.Err))
+	}
+	return result, errors.Join(errs...)
+}
+
+// Close closes every wrapped client
+func (m *MultiClient) Close() error {
+	var errs []error
+	for _, client := range m.clients {
+		if err := client.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
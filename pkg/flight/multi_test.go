@@ -0,0 +1,103 @@
+package flight
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rejectingServer is a Flight server that rejects every RPC
+type rejectingServer struct {
+	flight.BaseFlightServer
+}
+
+// newReplicationTargets creates two working targets and one that rejects writes
+func newReplicationTargets(t *testing.T) ([]*FlightClient, []*FlightServer) {
+	var clients []*FlightClient
+	var servers []*FlightServer
+	for i := 0; i < 2; i++ {
+		server, addr := startTestServer(t)
+		t.Cleanup(server.Stop)
+		servers = append(servers, server)
+
+		client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+		require.NoError(t, err, "Failed to create Flight client")
+		clients = append(clients, client)
+	}
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: startBareServer(t, &rejectingServer{})})
+	require.NoError(t, err, "Failed to create Flight client")
+	clients = append(clients, client)
+
+	return clients, servers
+}
+
+// TestMultiClientQuorum tests that a write succeeds when a quorum of targets accepts it
+func TestMultiClientQuorum(t *testing.T) {
+	clients, servers := newReplicationTargets(t)
+
+	multi, err := NewMultiClient(MultiClientConfig{Clients: clients, Quorum: 2})
+	require.NoError(t, err, "Failed to create multi client")
+	defer multi.Close()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := multi.PutBatch(ctx, batch)
+	require.NoError(t, err, "Two of three targets should meet the quorum")
+	require.Len(t, result.Targets, 3)
+	assert.Len(t, result.Succeeded(), 2)
+
+	failed := result.Failed()
+	require.Len(t, failed, 1)
+	assert.Equal(t, clients[2].addr, failed[0].Addr)
+	assert.Error(t, failed[0].Err)
+
+	// Every successful target holds the batch
+	for i, target := range result.Targets[:2] {
+		stored, err := servers[i].RetrieveBatch(target.Result.BatchID)
+		require.NoError(t, err, "Target %s should hold the batch", target.Addr)
+		stored.Release()
+	}
+}
+
+// TestMultiClientQuorumNotMet tests that a write fails when too few targets accept it
+func TestMultiClientQuorumNotMet(t *testing.T) {
+	clients, _ := newReplicationTargets(t)
+
+	multi, err := NewMultiClient(MultiClientConfig{Clients: clients})
+	require.NoError(t, err, "Failed to create multi client")
+	defer multi.Close()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := multi.PutBatch(ctx, batch)
+	assert.ErrorIs(t, err, ErrQuorumNotMet)
+	assert.Contains(t, err.Error(), clients[2].addr, "The error should name the failed target")
+	assert.Len(t, result.Succeeded(), 2)
+}
+
+// TestMultiClientInvalidQuorum tests quorum validation
+func TestMultiClientInvalidQuorum(t *testing.T) {
+	_, err := NewMultiClient(MultiClientConfig{})
+	assert.Error(t, err)
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: "localhost:0"})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	_, err = NewMultiClient(MultiClientConfig{Clients: []*FlightClient{client}, Quorum: 2})
+	assert.Error(t, err)
+}
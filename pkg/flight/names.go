@@ -0,0 +1,209 @@
+package flight
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// ActionSwapName is the DoAction type used to atomically point a name at a
+	// batch. The action body is a JSON encoded nameRequest.
+	ActionSwapName = "swap"
+	// ActionPublishName is the DoAction type used to atomically point a name
+	// at a batch while keeping the batch it used to point at. The action body
+	// is a JSON encoded nameRequest.
+	ActionPublishName = "publish"
+	// ActionResolveName is the DoAction type used to look up the batch a name
+	// points at. The action body is the name and the result body the batch ID.
+	ActionResolveName = "resolve"
+	// ActionDropBatch is the DoAction type used to release a batch. The action
+	// body is the batch ID.
+	ActionDropBatch = "drop"
+)
+
+// dropBatch releases a batch the request may read
+func (s *FlightServer) dropBatch(ctx context.Context, batchID string) error {
+	if err := s.authorize(ctx, batchID); err != nil {
+		return err
+	}
+	s.ReleaseBatch(batchID)
+	return nil
+}
+
+// nameRequest is the body of the name actions
+type nameRequest struct {
+	Name    string `json:"name"`
+	BatchID string `json:"batchId"`
+	// IfMatch, if set, is the ETag the name must still have for the update
+	// to apply
+	IfMatch string `json:"ifMatch,omitempty"`
+}
+
+// NameOptions contains options for PutBatchAtomicWithOptions and
+// PublishBatchWithOptions
+type NameOptions struct {
+	// IfMatch, if set, updates the name only if it still has this ETag, as
+	// returned by GetNamedBatch or ResolveName, for read-modify-write cycles
+	// that must not overwrite a concurrent update. If the name has changed
+	// or does not exist, the upload is dropped and ErrPreconditionFailed is
+	// returned. The server must support conditional updates; ErrNotSupported
+	// is returned without uploading if its capabilities lack them.
+	IfMatch string
+}
+
+// pointName points a name at an existing batch. Unless keepPrevious is set,
+// the batch it used to point at is released. Readers resolving the name see
+// either the old or the new batch. A name's ETag is the ID of the batch it
+// points at, which changes with every update. The request must be allowed to
+// read both the new batch and the one the name points at.
+func (s *FlightServer) pointName(ctx context.Context, body []byte, keepPrevious bool) error {
+	var req nameRequest
+	if err := json.Unmarshal(body, &req); err != nil || req.Name == "" || req.BatchID == "" {
+		return status.Error(codes.InvalidArgument, "a name and a batch ID are required")
+	}
+	if err := s.authorize(ctx, req.BatchID); err != nil {
+		return err
+	}
+	if err := s.authorize(ctx, req.Name); err != nil {
+		return err
+	}
+
+	s.batchesMu.Lock()
+	defer s.batchesMu.Unlock()
+
+	if _, ok := s.batches[req.BatchID]; !ok {
+		return status.Errorf(codes.NotFound, "batch with ID %s not found", req.BatchID)
+	}
+
+	previous, hadPrevious := s.names[req.Name]
+	if req.IfMatch != "" && previous != req.IfMatch {
+		return status.Errorf(codes.FailedPrecondition, "name %s no longer has ETag %s", req.Name, req.IfMatch)
+	}
+	s.names[req.Name] = req.BatchID
+	if hadPrevious && previous != req.BatchID && !keepPrevious {
+		s.removeBatchLocked(previous)
+	}
+
+	return nil
+}
+
+// resolveName returns the ID of the batch a name points at
+func (s *FlightServer) resolveName(name string, stream flight.FlightService_DoActionServer) error {
+	if err := s.authorize(stream.Context(), name); err != nil {
+		return err
+	}
+
+	s.batchesMu.RLock()
+	batchID, ok := s.names[name]
+	s.batchesMu.RUnlock()
+	if !ok {
+		return status.Errorf(codes.NotFound, "name %s not found", name)
+	}
+
+	return stream.Send(&flight.Result{Body: []byte(batchID)})
+}
+
+// PutBatchAtomic uploads a batch and atomically makes name refer to it, so that
+// GetBatch(name) returns either the previous data or the new data but never
+// nothing or a partial upload. The batch previously behind the name is discarded.
+//
+// The batch is first stored under a temporary ID. If the swap fails, the
+// temporary batch is dropped (best effort) unless the server already held it,
+// and the name keeps pointing at the previous data. The new batch ID is returned on success.
+func (c *FlightClient) PutBatchAtomic(ctx context.Context, name string, batch arrow.Record) (string, error) {
+	return c.putAndPoint(ctx, ActionSwapName, name, batch, NameOptions{})
+}
+
+// PutBatchAtomicWithOptions is PutBatchAtomic with options, such as a
+// condition on the name's current ETag
+func (c *FlightClient) PutBatchAtomicWithOptions(ctx context.Context, name string, batch arrow.Record, options NameOptions) (string, error) {
+	return c.putAndPoint(ctx, ActionSwapName, name, batch, options)
+}
+
+// PublishBatch uploads a new version of a named batch and atomically points
+// name at it, returning the version's batch ID. Unlike PutBatchAtomic, earlier
+// versions are kept: they remain readable by their version ID until they
+// expire or are released, so readers holding an old version are never cut
+// off. Use ResolveName to find the current version. ErrNotSupported is
+// returned if the server cannot publish versions; the uploaded version is
+// dropped in that case.
+func (c *FlightClient) PublishBatch(ctx context.Context, name string, batch arrow.Record) (string, error) {
+	return c.putAndPoint(ctx, ActionPublishName, name, batch, NameOptions{})
+}
+
+// PublishBatchWithOptions is PublishBatch with options, such as a condition
+// on the name's current ETag
+func (c *FlightClient) PublishBatchWithOptions(ctx context.Context, name string, batch arrow.Record, options NameOptions) (string, error) {
+	return c.putAndPoint(ctx, ActionPublishName, name, batch, options)
+}
+
+// ResolveName returns the batch ID that name currently points at, which is
+// also the name's ETag
+func (c *FlightClient) ResolveName(ctx context.Context, name string) (string, error) {
+	body, err := c.doAction(ctx, ActionResolveName, []byte(name))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve name %s: %w", name, err)
+	}
+	return string(body), nil
+}
+
+// GetNamedBatch retrieves the batch a name points at along with the name's
+// ETag, to pass as NameOptions.IfMatch when writing back a modified batch.
+// The batch is read by the ID the name resolved to, so it is the version the
+// ETag identifies even if the name moves on meanwhile.
+func (c *FlightClient) GetNamedBatch(ctx context.Context, name string) (arrow.Record, string, error) {
+	etag, err := c.ResolveName(ctx, name)
+	if err != nil {
+		return nil, "", err
+	}
+	batch, err := c.GetBatch(ctx, etag)
+	if err != nil {
+		return nil, "", err
+	}
+	return batch, etag, nil
+}
+
+// putAndPoint uploads a batch and points name at it with the given name
+// action, dropping the upload if the name cannot be updated
+func (c *FlightClient) putAndPoint(ctx context.Context, action, name string, batch arrow.Record, options NameOptions) (string, error) {
+	if options.IfMatch != "" {
+		if err := c.requireFeature(ctx, "conditional name updates", func(s ServerCapabilities) bool { return s.ConditionalNames }); err != nil {
+			return "", err
+		}
+	}
+
+	result, err := c.PutBatchWithOptions(ctx, batch, PutOptions{})
+	if err != nil {
+		return "", err
+	}
+	batchID := result.BatchID
+
+	body, err := json.Marshal(nameRequest{Name: name, BatchID: batchID, IfMatch: options.IfMatch})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode %s request: %w", action, err)
+	}
+
+	if _, err := c.doAction(ctx, action, body); err != nil {
+		if status.Code(err) == codes.FailedPrecondition {
+			err = fmt.Errorf("%w: %w", ErrPreconditionFailed, err)
+		}
+		// Roll back the upload; the name still refers to the old batch. A
+		// batch stored before the upload, such as one with the same
+		// content-derived ID, may be in use and is kept.
+		if !result.existing {
+			if _, dropErr := c.doAction(context.WithoutCancel(ctx), ActionDropBatch, []byte(batchID)); dropErr != nil {
+				err = errors.Join(err, fmt.Errorf("failed to drop batch %s: %w", batchID, dropErr))
+			}
+		}
+		return "", fmt.Errorf("failed to point %s at batch %s: %w", name, batchID, err)
+	}
+
+	return batchID, nil
+}
@@ -0,0 +1,253 @@
+package flight
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createGenerationBatch creates a batch whose every row holds the same generation number
+func createGenerationBatch(generation int64, numRows int) arrow.Record {
+	schema := arrow.NewSchema([]arrow.Field{{Name: "generation", Type: arrow.PrimitiveTypes.Int64}}, nil)
+
+	builder := array.NewRecordBuilder(memory.NewGoAllocator(), schema)
+	defer builder.Release()
+	for i := 0; i < numRows; i++ {
+		builder.Field(0).(*array.Int64Builder).Append(generation)
+	}
+	return builder.NewRecord()
+}
+
+// TestPutBatchAtomic tests that readers of a name never observe partial data while it is replaced
+func TestPutBatchAtomic(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	const numRows = 1000
+	first := createGenerationBatch(0, numRows)
+	defer first.Release()
+	firstID, err := client.PutBatchAtomic(ctx, "current", first)
+	require.NoError(t, err, "Failed to publish first generation")
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				batch, err := client.GetBatch(ctx, "current")
+				if !assert.NoError(t, err, "Name should always resolve") {
+					return
+				}
+				col := batch.Column(0).(*array.Int64)
+				assert.Equal(t, int64(numRows), batch.NumRows(), "Reader saw a partial batch")
+				for i := 1; i < col.Len(); i++ {
+					if col.Value(i) != col.Value(0) {
+						t.Errorf("Reader saw mixed generations %d and %d", col.Value(0), col.Value(i))
+						break
+					}
+				}
+				batch.Release()
+			}
+		}()
+	}
+
+	var lastID string
+	for gen := int64(1); gen <= 20; gen++ {
+		batch := createGenerationBatch(gen, numRows)
+		lastID, err = client.PutBatchAtomic(ctx, "current", batch)
+		batch.Release()
+		require.NoError(t, err, "Failed to publish generation %d", gen)
+	}
+	close(stop)
+	wg.Wait()
+
+	// The name points at the last generation and replaced batches are discarded
+	latest, err := client.GetBatch(ctx, "current")
+	require.NoError(t, err, "Failed to get latest generation")
+	defer latest.Release()
+	assert.Equal(t, int64(20), latest.Column(0).(*array.Int64).Value(0))
+
+	_, err = client.GetBatch(ctx, firstID)
+	assert.Error(t, err, "Replaced batches should be discarded")
+	_, err = client.GetBatch(ctx, lastID)
+	assert.NoError(t, err, "The current batch stays addressable by ID")
+}
+
+// TestPutBatchAtomicRollback tests that a failed swap drops the uploaded batch
+func TestPutBatchAtomicRollback(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// An empty name is rejected by the server
+	_, err = client.PutBatchAtomic(ctx, "", batch)
+	require.Error(t, err, "Swap should fail")
+
+	ids, err := client.ListBatches(ctx)
+	require.NoError(t, err, "Failed to list batches")
+	assert.Empty(t, ids, "The temporary upload should be dropped")
+}
+
+// TestPublishBatch tests that publishing repoints a name while old versions stay readable
+func TestPublishBatch(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = client.ResolveName(ctx, "latest")
+	assert.Error(t, err, "An unpublished name should not resolve")
+
+	first := createGenerationBatch(1, 3)
+	defer first.Release()
+	firstID, err := client.PublishBatch(ctx, "latest", first)
+	require.NoError(t, err, "Failed to publish first version")
+
+	second := createGenerationBatch(2, 3)
+	defer second.Release()
+	secondID, err := client.PublishBatch(ctx, "latest", second)
+	require.NoError(t, err, "Failed to publish second version")
+	assert.NotEqual(t, firstID, secondID)
+
+	resolved, err := client.ResolveName(ctx, "latest")
+	require.NoError(t, err, "Failed to resolve name")
+	assert.Equal(t, secondID, resolved)
+
+	current, err := client.GetBatch(ctx, "latest")
+	require.NoError(t, err, "Failed to get batch by name")
+	defer current.Release()
+	assert.Equal(t, int64(2), current.Column(0).(*array.Int64).Value(0))
+
+	old, err := client.GetBatch(ctx, firstID)
+	require.NoError(t, err, "The previous version should stay addressable")
+	defer old.Release()
+	assert.Equal(t, int64(1), old.Column(0).(*array.Int64).Value(0))
+}
+
+// TestPutBatchAtomicIfMatch tests that a conditional update applies with the
+// name's current ETag and fails with ErrPreconditionFailed with a stale one
+func TestPutBatchAtomicIfMatch(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	first := createGenerationBatch(1, 3)
+	defer first.Release()
+	_, err = client.PutBatchAtomic(ctx, "dataset", first)
+	require.NoError(t, err, "Failed to put batch")
+
+	// Read, modify and write back under the ETag that was read
+	current, etag, err := client.GetNamedBatch(ctx, "dataset")
+	require.NoError(t, err, "Failed to get named batch")
+	assert.True(t, array.RecordEqual(first, current))
+	current.Release()
+
+	second := createGenerationBatch(2, 3)
+	defer second.Release()
+	newID, err := client.PutBatchAtomicWithOptions(ctx, "dataset", second, NameOptions{IfMatch: etag})
+	require.NoError(t, err, "An update with the current ETag should apply")
+
+	_, newETag, err := client.GetNamedBatch(ctx, "dataset")
+	require.NoError(t, err, "Failed to get named batch")
+	assert.Equal(t, newID, newETag, "The update should change the ETag")
+
+	// A writer still holding the first ETag is refused
+	third := createGenerationBatch(3, 3)
+	defer third.Release()
+	_, err = client.PutBatchAtomicWithOptions(ctx, "dataset", third, NameOptions{IfMatch: etag})
+	require.ErrorIs(t, err, ErrPreconditionFailed)
+	_, err = client.PublishBatchWithOptions(ctx, "missing", third, NameOptions{IfMatch: etag})
+	require.ErrorIs(t, err, ErrPreconditionFailed, "A name that does not exist has no ETag to match")
+
+	latest, _, err := client.GetNamedBatch(ctx, "dataset")
+	require.NoError(t, err, "Failed to get named batch")
+	defer latest.Release()
+	assert.True(t, array.RecordEqual(second, latest), "A refused update should leave the name unchanged")
+
+	ids, err := client.ListBatches(ctx)
+	require.NoError(t, err, "Failed to list batches")
+	assert.Equal(t, []string{newID}, ids, "Refused uploads should be dropped")
+}
+
+// TestPutBatchAtomicIfMatchContentHash tests that a refused conditional
+// update of unchanged content keeps the batch the name points at, which the
+// upload shares an ID with
+func TestPutBatchAtomicIfMatchContentHash(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr, IDGenerator: ContentHashIDGenerator{}})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	first := createGenerationBatch(1, 3)
+	defer first.Release()
+	second := createGenerationBatch(2, 3)
+	defer second.Release()
+
+	_, err = client.PutBatchAtomic(ctx, "report", first)
+	require.NoError(t, err, "Failed to put batch")
+	_, stale, err := client.GetNamedBatch(ctx, "report")
+	require.NoError(t, err, "Failed to get named batch")
+	current, err := client.PutBatchAtomic(ctx, "report", second)
+	require.NoError(t, err, "Failed to put batch")
+
+	// Writing the current contents back under the stale ETag uploads the
+	// batch the name points at
+	id, err := client.PutBatchAtomicWithOptions(ctx, "report", second, NameOptions{IfMatch: stale})
+	require.ErrorIs(t, err, ErrPreconditionFailed)
+	assert.Empty(t, id)
+
+	resolved, err := client.ResolveName(ctx, "report")
+	require.NoError(t, err, "The name should still resolve")
+	assert.Equal(t, current, resolved)
+	latest, err := client.GetBatch(ctx, resolved)
+	require.NoError(t, err, "The batch behind the name should be kept")
+	defer latest.Release()
+	assert.True(t, array.RecordEqual(second, latest))
+}
@@ -0,0 +1,132 @@
+// Package otelmetrics records FlightClient instrumentation as OpenTelemetry
+// metrics. Install it with
+//
+//	metrics, err := otelmetrics.New(provider)
+//	client, err := flight.NewFlightClient(flight.FlightClientConfig{Metrics: metrics})
+package otelmetrics
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/TFMV/temporal/pkg/flight"
+)
+
+// ScopeName is the instrumentation scope of the meter the instruments are
+// created with
+const ScopeName = "github.com/TFMV/temporal/pkg/flight"
+
+// Instrument names
+const (
+	CallsName             = "flight.client.calls"
+	DurationName          = "flight.client.duration"
+	RowsName              = "flight.client.rows"
+	BytesName             = "flight.client.bytes"
+	UncompressedBytesName = "flight.client.uncompressed_bytes"
+)
+
+// Attribute keys set on every measurement. Compression is set on uploads
+// only, and Label only on calls tagged with flight.WithLabel when
+// Options.Labels is set.
+const (
+	MethodKey      = attribute.Key("flight.method")
+	OutcomeKey     = attribute.Key("flight.outcome")
+	CompressionKey = attribute.Key("flight.compression")
+	LabelKey       = attribute.Key("flight.label")
+)
+
+// Values of OutcomeKey
+const (
+	OutcomeOK    = "ok"
+	OutcomeError = "error"
+)
+
+// Metrics is a flight.Metrics hook recording each completed call as
+// OpenTelemetry instruments: a call counter, a latency histogram in seconds,
+// and counters of the rows and IPC bytes transferred and of the Arrow bytes
+// before encoding or after decoding
+type Metrics struct {
+	calls             metric.Int64Counter
+	duration          metric.Float64Histogram
+	rows              metric.Int64Counter
+	bytes             metric.Int64Counter
+	uncompressedBytes metric.Int64Counter
+	labels            bool
+}
+
+// Options configures the recorded attributes
+type Options struct {
+	// Labels records the flight.WithLabel tag of each call as LabelKey. Every
+	// distinct label is a separate time series, so enable it only when labels
+	// come from a small fixed set, never from batch IDs or user input.
+	Labels bool
+}
+
+// New creates the instruments with a meter from provider, without label
+// attributes
+func New(provider metric.MeterProvider) (*Metrics, error) {
+	return NewWithOptions(provider, Options{})
+}
+
+// NewWithOptions creates the instruments with a meter from provider and
+// records attributes as configured by options
+func NewWithOptions(provider metric.MeterProvider, options Options) (*Metrics, error) {
+	meter := provider.Meter(ScopeName)
+
+	m := Metrics{labels: options.Labels}
+	var err error
+	if m.calls, err = meter.Int64Counter(CallsName,
+		metric.WithDescription("Completed Flight client calls"),
+		metric.WithUnit("{call}")); err != nil {
+		return nil, fmt.Errorf("failed to create %s counter: %w", CallsName, err)
+	}
+	if m.duration, err = meter.Float64Histogram(DurationName,
+		metric.WithDescription("Wall time of Flight client calls"),
+		metric.WithUnit("s")); err != nil {
+		return nil, fmt.Errorf("failed to create %s histogram: %w", DurationName, err)
+	}
+	if m.rows, err = meter.Int64Counter(RowsName,
+		metric.WithDescription("Rows written or read by Flight client calls"),
+		metric.WithUnit("{row}")); err != nil {
+		return nil, fmt.Errorf("failed to create %s counter: %w", RowsName, err)
+	}
+	if m.bytes, err = meter.Int64Counter(BytesName,
+		metric.WithDescription("IPC body bytes sent or received by Flight client calls"),
+		metric.WithUnit("By")); err != nil {
+		return nil, fmt.Errorf("failed to create %s counter: %w", BytesName, err)
+	}
+	if m.uncompressedBytes, err = meter.Int64Counter(UncompressedBytesName,
+		metric.WithDescription("Arrow buffer bytes uploaded before encoding or downloaded after decoding"),
+		metric.WithUnit("By")); err != nil {
+		return nil, fmt.Errorf("failed to create %s counter: %w", UncompressedBytesName, err)
+	}
+	return &m, nil
+}
+
+// ObserveCall implements flight.Metrics
+func (m *Metrics) ObserveCall(stats flight.CallStats) {
+	outcome := OutcomeOK
+	if stats.Err != nil {
+		outcome = OutcomeError
+	}
+	attrs := []attribute.KeyValue{MethodKey.String(stats.Method), OutcomeKey.String(outcome)}
+	if stats.Method == flight.MethodPutBatch && stats.Compression != "" {
+		attrs = append(attrs, CompressionKey.String(stats.Compression))
+	}
+	if m.labels && stats.Label != "" {
+		attrs = append(attrs, LabelKey.String(stats.Label))
+	}
+	set := metric.WithAttributeSet(attribute.NewSet(attrs...))
+
+	// Calls complete after their context may have ended, so record under a
+	// fresh one
+	ctx := context.Background()
+	m.calls.Add(ctx, 1, set)
+	m.duration.Record(ctx, stats.Duration.Seconds(), set)
+	m.rows.Add(ctx, stats.Rows, set)
+	m.bytes.Add(ctx, stats.Bytes, set)
+	m.uncompressedBytes.Add(ctx, stats.UncompressedBytes, set)
+}
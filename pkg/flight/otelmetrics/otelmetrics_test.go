@@ -0,0 +1,111 @@
+package otelmetrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/TFMV/temporal/pkg/flight"
+)
+
+// TestMetrics tests that observed calls are recorded by every instrument with
+// their attributes
+func TestMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer provider.Shutdown(context.Background())
+
+	metrics, err := NewWithOptions(provider, Options{Labels: true})
+	require.NoError(t, err, "Failed to create metrics")
+
+	var hook flight.Metrics = metrics
+	hook.ObserveCall(flight.CallStats{
+		Method:            flight.MethodPutBatch,
+		Duration:          250 * time.Millisecond,
+		Rows:              100,
+		Bytes:             400,
+		UncompressedBytes: 800,
+		Compression:       flight.CompressionZstd,
+		Label:             "ingest",
+	})
+	hook.ObserveCall(flight.CallStats{Method: flight.MethodGetBatch, Duration: time.Second, Rows: 100, Bytes: 800})
+	hook.ObserveCall(flight.CallStats{Method: flight.MethodGetBatch, Duration: time.Second, Err: errors.New("boom")})
+
+	var collected metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &collected), "Failed to collect metrics")
+	require.Len(t, collected.ScopeMetrics, 1)
+	assert.Equal(t, ScopeName, collected.ScopeMetrics[0].Scope.Name)
+
+	instruments := make(map[string]metricdata.Aggregation)
+	for _, m := range collected.ScopeMetrics[0].Metrics {
+		instruments[m.Name] = m.Data
+	}
+
+	put := attribute.NewSet(
+		MethodKey.String(flight.MethodPutBatch),
+		OutcomeKey.String(OutcomeOK),
+		CompressionKey.String(flight.CompressionZstd),
+		LabelKey.String("ingest"),
+	)
+	get := attribute.NewSet(MethodKey.String(flight.MethodGetBatch), OutcomeKey.String(OutcomeOK))
+	failed := attribute.NewSet(MethodKey.String(flight.MethodGetBatch), OutcomeKey.String(OutcomeError))
+
+	sums := func(name string) map[attribute.Set]int64 {
+		data, ok := instruments[name].(metricdata.Sum[int64])
+		require.True(t, ok, "%s should be an integer sum", name)
+		values := make(map[attribute.Set]int64)
+		for _, point := range data.DataPoints {
+			values[point.Attributes] = point.Value
+		}
+		return values
+	}
+	assert.Equal(t, map[attribute.Set]int64{put: 1, get: 1, failed: 1}, sums(CallsName))
+	assert.Equal(t, map[attribute.Set]int64{put: 100, get: 100, failed: 0}, sums(RowsName))
+	assert.Equal(t, map[attribute.Set]int64{put: 400, get: 800, failed: 0}, sums(BytesName))
+	assert.Equal(t, map[attribute.Set]int64{put: 800, get: 0, failed: 0}, sums(UncompressedBytesName))
+
+	durations, ok := instruments[DurationName].(metricdata.Histogram[float64])
+	require.True(t, ok, "%s should be a histogram", DurationName)
+	require.Len(t, durations.DataPoints, 3)
+	for _, point := range durations.DataPoints {
+		assert.Equal(t, uint64(1), point.Count)
+		if point.Attributes.Equals(&put) {
+			assert.Equal(t, 0.25, point.Sum)
+		} else {
+			assert.Equal(t, 1.0, point.Sum)
+		}
+	}
+}
+
+// TestMetricsWithoutLabels tests that labels are not recorded unless enabled
+func TestMetricsWithoutLabels(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer provider.Shutdown(context.Background())
+
+	metrics, err := New(provider)
+	require.NoError(t, err, "Failed to create metrics")
+	metrics.ObserveCall(flight.CallStats{Method: flight.MethodGetBatch, Duration: time.Second, Label: "ingest"})
+
+	var collected metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &collected), "Failed to collect metrics")
+	require.Len(t, collected.ScopeMetrics, 1)
+
+	get := attribute.NewSet(MethodKey.String(flight.MethodGetBatch), OutcomeKey.String(OutcomeOK))
+	for _, m := range collected.ScopeMetrics[0].Metrics {
+		if m.Name != CallsName {
+			continue
+		}
+		data, ok := m.Data.(metricdata.Sum[int64])
+		require.True(t, ok, "%s should be an integer sum", CallsName)
+		require.Len(t, data.DataPoints, 1)
+		assert.True(t, data.DataPoints[0].Attributes.Equals(&get), "The label should not be recorded")
+	}
+}
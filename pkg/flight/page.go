@@ -0,0 +1,141 @@
+package flight
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// listCriteria is the JSON Criteria expression of a filtered or paginated
+// ListFlights
+type listCriteria struct {
+	ListFilter
+
+	// Cursor is where the page starts, empty for the first page
+	Cursor string `json:"cursor,omitempty"`
+	// PageSize is the maximum number of batches in the page
+	PageSize int `json:"pageSize"`
+}
+
+// listPageInfo is the AppMetadata of the last FlightInfo of a page that has
+// a successor
+type listPageInfo struct {
+	NextCursor string `json:"nextCursor"`
+}
+
+// decodeListCriteria parses a ListFlights criteria expression. An empty
+// expression lists every batch.
+func decodeListCriteria(expression []byte) (listCriteria, error) {
+	var criteria listCriteria
+	if len(expression) == 0 {
+		return criteria, nil
+	}
+	if err := json.Unmarshal(expression, &criteria); err != nil {
+		return criteria, status.Errorf(codes.InvalidArgument, "invalid list criteria: %v", err)
+	}
+	if criteria.PageSize < 0 {
+		return criteria, status.Errorf(codes.InvalidArgument, "page size must not be negative, got %d", criteria.PageSize)
+	}
+	return criteria, nil
+}
+
+// listPage sends the stored batches in ID order, starting after the cursor and
+// stopping after a page of criteria.PageSize batches. The last FlightInfo of a
+// page with more batches after it carries the next cursor.
+func (s *FlightServer) listPage(criteria listCriteria, stream flight.FlightService_ListFlightsServer) error {
+	s.batchesMu.RLock()
+	defer s.batchesMu.RUnlock()
+
+	ids := make([]string, 0, len(s.batches))
+	for batchID, batch := range s.batches {
+		if criteria.matches(batchID, batch) && s.canRead(stream.Context(), batchID) {
+			ids = append(ids, batchID)
+		}
+	}
+	slices.Sort(ids)
+
+	// The cursor is the last ID of the previous page
+	start, found := slices.BinarySearch(ids, criteria.Cursor)
+	if found {
+		start++
+	}
+	ids = ids[start:]
+
+	more := len(ids) > criteria.PageSize
+	if more {
+		ids = ids[:criteria.PageSize]
+	}
+
+	for i, batchID := range ids {
+		descriptor := &flight.FlightDescriptor{Type: flight.DescriptorCMD, Cmd: []byte(batchID)}
+		info := s.flightInfo(batchID, s.batches[batchID], descriptor)
+		if more && i == len(ids)-1 {
+			meta, err := json.Marshal(listPageInfo{NextCursor: batchID})
+			if err != nil {
+				return fmt.Errorf("failed to encode page info: %w", err)
+			}
+			info.AppMetadata = meta
+		}
+		if err := stream.Send(info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListBatchesPage lists up to pageSize batch IDs starting at cursor, which is
+// empty for the first page. The returned cursor fetches the next page and is
+// empty once the listing is exhausted; it is opaque and should only be passed
+// back to ListBatchesPage. Pages are ordered by batch ID, so batches stored
+// while iterating may or may not be listed. A server that ignores pagination
+// returns every batch in one page with an empty cursor.
+func (c *FlightClient) ListBatchesPage(ctx context.Context, cursor string, pageSize int) ([]string, string, error) {
+	if pageSize <= 0 {
+		return nil, "", fmt.Errorf("page size must be positive, got %d", pageSize)
+	}
+
+	expression, err := json.Marshal(listCriteria{Cursor: cursor, PageSize: pageSize})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode list criteria: %w", err)
+	}
+
+	client, release, err := c.acquire(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	defer release()
+
+	stream, err := client.ListFlights(ctx, &flight.Criteria{Expression: expression})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to start ListFlights stream: %w", err)
+	}
+
+	var batchIDs []string
+	var next string
+	for {
+		info, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("error receiving flight info: %w", err)
+		}
+		batchIDs = append(batchIDs, string(info.FlightDescriptor.Cmd))
+
+		if len(info.AppMetadata) > 0 {
+			var page listPageInfo
+			if err := json.Unmarshal(info.AppMetadata, &page); err == nil {
+				next = page.NextCursor
+			}
+		}
+	}
+
+	return batchIDs, next, nil
+}
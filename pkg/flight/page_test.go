@@ -0,0 +1,100 @@
+package flight
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestListBatchesPage tests iterating the catalog across several pages
+func TestListBatchesPage(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	stored := make(map[string]bool)
+	for i := 0; i < 7; i++ {
+		batchID, err := client.PutBatch(ctx, batch)
+		require.NoError(t, err, "Failed to put batch")
+		stored[batchID] = true
+	}
+
+	listed := make(map[string]bool)
+	var pages []int
+	cursor := ""
+	for {
+		ids, next, err := client.ListBatchesPage(ctx, cursor, 3)
+		require.NoError(t, err, "Failed to list page")
+		pages = append(pages, len(ids))
+		for _, id := range ids {
+			assert.False(t, listed[id], "Batch %s listed twice", id)
+			listed[id] = true
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	assert.Equal(t, []int{3, 3, 1}, pages)
+	assert.Equal(t, stored, listed, "Every batch should be listed once")
+
+	_, _, err = client.ListBatchesPage(ctx, "", 0)
+	assert.Error(t, err, "The page size must be positive")
+}
+
+// TestListBatchesPageIgnored tests that a server without pagination returns everything in one page
+func TestListBatchesPageIgnored(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+	for i := 0; i < 4; i++ {
+		_, err := client.PutBatch(ctx, batch)
+		require.NoError(t, err, "Failed to put batch")
+	}
+
+	// A server that ignores the criteria lists every batch
+	addr = startBareServer(t, &unpaginatedServer{server: server})
+	plain, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer plain.Close()
+
+	ids, next, err := plain.ListBatchesPage(ctx, "", 2)
+	require.NoError(t, err, "Failed to list page")
+	assert.Len(t, ids, 4)
+	assert.Empty(t, next)
+}
+
+// unpaginatedServer lists a FlightServer's batches ignoring the criteria
+type unpaginatedServer struct {
+	flight.BaseFlightServer
+	server *FlightServer
+}
+
+// ListFlights lists every batch
+func (s *unpaginatedServer) ListFlights(request *flight.Criteria, stream flight.FlightService_ListFlightsServer) error {
+	return s.server.ListFlights(&flight.Criteria{}, stream)
+}
@@ -0,0 +1,154 @@
+package flight
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/compress"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+)
+
+// parquetCodecs maps the codec names accepted in ParquetOptions to Parquet codecs
+var parquetCodecs = map[string]compress.Compression{
+	"none":    compress.Codecs.Uncompressed,
+	"snappy":  compress.Codecs.Snappy,
+	"gzip":    compress.Codecs.Gzip,
+	"brotli":  compress.Codecs.Brotli,
+	"zstd":    compress.Codecs.Zstd,
+	"lz4_raw": compress.Codecs.Lz4Raw,
+}
+
+// parquetEncodings maps the encoding names accepted in ParquetColumnOptions to
+// Parquet encodings
+var parquetEncodings = map[string]parquet.Encoding{
+	"plain":                   parquet.Encodings.Plain,
+	"rle":                     parquet.Encodings.RLE,
+	"delta_binary_packed":     parquet.Encodings.DeltaBinaryPacked,
+	"delta_length_byte_array": parquet.Encodings.DeltaLengthByteArray,
+	"delta_byte_array":        parquet.Encodings.DeltaByteArray,
+	"byte_stream_split":       parquet.Encodings.ByteStreamSplit,
+}
+
+// ParquetOptions configures GetBatchToParquet
+type ParquetOptions struct {
+	// Compression is the codec for every column without its own setting:
+	// "none" (default), "snappy", "gzip", "brotli", "zstd" or "lz4_raw"
+	Compression string
+	// Columns overrides the writer settings of individual top-level columns,
+	// keyed by column name. Unknown column names are rejected.
+	Columns map[string]ParquetColumnOptions
+}
+
+// ParquetColumnOptions overrides the Parquet writer settings of one column
+type ParquetColumnOptions struct {
+	// Compression is the column's codec (default: ParquetOptions.Compression)
+	Compression string
+	// Encoding is the column's value encoding, such as "delta_binary_packed"
+	// for sorted integers. Setting it disables dictionary encoding for the
+	// column, since Parquet otherwise only uses it as the dictionary fallback.
+	Encoding string
+	// DisableDictionary turns off dictionary encoding, which is on by default
+	DisableDictionary bool
+}
+
+// writerProperties converts the options into Parquet writer properties,
+// validating the column names against schema
+func (o ParquetOptions) writerProperties(schema *arrow.Schema) ([]parquet.WriterProperty, error) {
+	var props []parquet.WriterProperty
+	if o.Compression != "" {
+		codec, ok := parquetCodecs[o.Compression]
+		if !ok {
+			return nil, fmt.Errorf("unsupported Parquet compression codec %q", o.Compression)
+		}
+		props = append(props, parquet.WithCompression(codec))
+	}
+
+	for name, column := range o.Columns {
+		if len(schema.FieldIndices(name)) == 0 {
+			return nil, fmt.Errorf("unknown column %q in Parquet options", name)
+		}
+
+		if column.Compression != "" {
+			codec, ok := parquetCodecs[column.Compression]
+			if !ok {
+				return nil, fmt.Errorf("unsupported Parquet compression codec %q for column %q", column.Compression, name)
+			}
+			props = append(props, parquet.WithCompressionFor(name, codec))
+		}
+		if column.Encoding != "" {
+			encoding, ok := parquetEncodings[column.Encoding]
+			if !ok {
+				return nil, fmt.Errorf("unsupported Parquet encoding %q for column %q", column.Encoding, name)
+			}
+			props = append(props, parquet.WithEncodingFor(name, encoding))
+		}
+		if column.DisableDictionary || column.Encoding != "" {
+			props = append(props, parquet.WithDictionaryFor(name, false))
+		}
+	}
+	return props, nil
+}
+
+// GetBatchToParquet streams a batch into w as a Parquet file, writing one row
+// group per record batch received. If w implements Flusher it is flushed after
+// every row group. w is not closed.
+func (c *FlightClient) GetBatchToParquet(ctx context.Context, batchID string, w io.Writer, options ParquetOptions) error {
+	stream, err := c.GetBatchStream(ctx, batchID)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	props, err := options.writerProperties(stream.Schema())
+	if err != nil {
+		return err
+	}
+	props = append(props, parquet.WithAllocator(c.allocator))
+
+	// Hide any Close method so the Parquet writer leaves w open
+	flusher, _ := w.(Flusher)
+	writer, err := pqarrow.NewFileWriter(stream.Schema(), struct{ io.Writer }{w},
+		parquet.NewWriterProperties(props...), pqarrow.NewArrowWriterProperties(pqarrow.WithAllocator(c.allocator)))
+	if err != nil {
+		return fmt.Errorf("failed to create Parquet writer: %w", err)
+	}
+
+	for {
+		rec, err := stream.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			writer.Close()
+			return err
+		}
+
+		err = writer.Write(rec)
+		rec.Release()
+		if err != nil {
+			writer.Close()
+			return fmt.Errorf("failed to write row group: %w", err)
+		}
+
+		if flusher != nil {
+			if err := flusher.Flush(); err != nil {
+				writer.Close()
+				return fmt.Errorf("failed to flush writer: %w", err)
+			}
+		}
+	}
+
+	// Closing writes the file footer
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to close Parquet writer: %w", err)
+	}
+	if flusher != nil {
+		if err := flusher.Flush(); err != nil {
+			return fmt.Errorf("failed to flush writer: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package flight
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/compress"
+	"github.com/apache/arrow-go/v18/parquet/file"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetBatchToParquet tests that per-column codecs and encodings reach the Parquet file
+func TestGetBatchToParquet(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batchID, err := client.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to put batch")
+
+	var buf bytes.Buffer
+	err = client.GetBatchToParquet(ctx, batchID, &buf, ParquetOptions{
+		Compression: "gzip",
+		Columns: map[string]ParquetColumnOptions{
+			"id":   {Compression: "snappy", Encoding: "delta_binary_packed"},
+			"name": {Compression: "zstd"},
+		},
+	})
+	require.NoError(t, err, "Failed to export batch")
+
+	reader, err := file.NewParquetReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err, "Output should be a Parquet file")
+	defer reader.Close()
+
+	// Each column chunk carries its configured codec and encoding
+	rowGroup := reader.MetaData().RowGroup(0)
+	codecs := []compress.Compression{compress.Codecs.Snappy, compress.Codecs.Zstd, compress.Codecs.Gzip}
+	for i, codec := range codecs {
+		chunk, err := rowGroup.ColumnChunk(i)
+		require.NoError(t, err)
+		assert.Equal(t, codec, chunk.Compression(), "Column %d codec", i)
+	}
+	idChunk, err := rowGroup.ColumnChunk(0)
+	require.NoError(t, err)
+	assert.Contains(t, idChunk.Encodings(), parquet.Encodings.DeltaBinaryPacked)
+
+	// The file reads back to the original data
+	fileReader, err := pqarrow.NewFileReader(reader, pqarrow.ArrowReadProperties{}, memory.NewGoAllocator())
+	require.NoError(t, err)
+	table, err := fileReader.ReadTable(ctx)
+	require.NoError(t, err, "Failed to read Parquet file")
+	defer table.Release()
+
+	tableReader := array.NewTableReader(table, -1)
+	defer tableReader.Release()
+	require.True(t, tableReader.Next())
+	assert.True(t, array.RecordEqual(batch, tableReader.Record()), "Round-tripped record should match")
+}
+
+// TestGetBatchToParquetUnknownColumn tests that options naming unknown columns are rejected
+func TestGetBatchToParquetUnknownColumn(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batchID, err := client.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to put batch")
+
+	var buf bytes.Buffer
+	err = client.GetBatchToParquet(ctx, batchID, &buf, ParquetOptions{
+		Columns: map[string]ParquetColumnOptions{"missing": {Compression: "zstd"}},
+	})
+	assert.ErrorContains(t, err, `unknown column "missing"`)
+	assert.Zero(t, buf.Len(), "Nothing should be written for invalid options")
+}
@@ -0,0 +1,141 @@
+package flight
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RecordTransform is a server-side function applied to a batch during DoGet
+// when a client names it in a pipeline. It returns a new record, which the
+// server releases once sent, and must not release its input.
+type RecordTransform func(ctx context.Context, batch arrow.Record) (arrow.Record, error)
+
+// runPipeline applies the named transforms to batch in order. The caller owns
+// the returned record.
+func (s *FlightServer) runPipeline(ctx context.Context, batch arrow.Record, pipeline []string) (arrow.Record, error) {
+	batch.Retain()
+	for _, name := range pipeline {
+		transform, ok := s.transforms[name]
+		if !ok {
+			batch.Release()
+			return nil, status.Errorf(codes.InvalidArgument, "unknown transform %q", name)
+		}
+		transformed, err := transform(ctx, batch)
+		batch.Release()
+		if err != nil {
+			return nil, fmt.Errorf("transform %s failed: %w", name, err)
+		}
+		batch = transformed
+	}
+	return batch, nil
+}
+
+// GetBatchWithPipeline streams a batch after the server has applied a chain of
+// its transforms to it, named in the order they run. The names are checked
+// against the transforms the server advertises when it reports its
+// capabilities. Interrupted downloads resume as with GetBatchStream, so the
+// transforms should be deterministic. The caller must release the reader.
+func (c *FlightClient) GetBatchWithPipeline(ctx context.Context, batchID string, pipeline []string) (array.RecordReader, error) {
+	if len(pipeline) == 0 {
+		return nil, fmt.Errorf("pipeline must name at least one transform")
+	}
+
+	var missing []string
+	err := c.requireFeature(ctx, "transforms", func(s ServerCapabilities) bool {
+		for _, name := range pipeline {
+			if !slices.Contains(s.Transforms, name) {
+				missing = append(missing, name)
+			}
+		}
+		return len(missing) == 0
+	})
+	if err != nil && len(missing) > 0 {
+		return nil, fmt.Errorf("%w: transforms %s", ErrNotSupported, strings.Join(missing, ", "))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := c.GetBatchStreamWithOptions(ctx, batchID, GetOptions{pipeline: pipeline})
+	if err != nil {
+		return nil, err
+	}
+	return newStreamReader(stream), nil
+}
+
+// streamReader adapts a BatchStream to array.RecordReader
+type streamReader struct {
+	stream  *BatchStream
+	refs    atomic.Int64
+	current arrow.Record
+	err     error
+}
+
+// newStreamReader wraps stream, which is closed when the reader is released
+func newStreamReader(stream *BatchStream) *streamReader {
+	r := &streamReader{stream: stream}
+	r.refs.Store(1)
+	return r
+}
+
+// Retain increases the reference count
+func (r *streamReader) Retain() {
+	r.refs.Add(1)
+}
+
+// Release decreases the reference count, closing the stream at zero
+func (r *streamReader) Release() {
+	if r.refs.Add(-1) == 0 {
+		if r.current != nil {
+			r.current.Release()
+			r.current = nil
+		}
+		r.stream.Close()
+	}
+}
+
+// Schema returns the schema of the transformed records
+func (r *streamReader) Schema() *arrow.Schema {
+	return r.stream.Schema()
+}
+
+// Next advances to the next record, reporting false at the end of the stream
+// or on error
+func (r *streamReader) Next() bool {
+	if r.current != nil {
+		r.current.Release()
+		r.current = nil
+	}
+	if r.err != nil {
+		return false
+	}
+
+	record, err := r.stream.Next()
+	if err != nil {
+		if err != io.EOF {
+			r.err = err
+		}
+		return false
+	}
+	r.current = record
+	return true
+}
+
+// Record returns the current record, valid until the next call to Next
+func (r *streamReader) Record() arrow.Record {
+	return r.current
+}
+
+// Err returns the error that ended the stream, if any
+func (r *streamReader) Err() error {
+	return r.err
+}
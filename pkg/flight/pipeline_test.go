@@ -0,0 +1,93 @@
+package flight
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// headTransform keeps the first three rows of a batch
+func headTransform(ctx context.Context, batch arrow.Record) (arrow.Record, error) {
+	return batch.NewSlice(0, min(3, batch.NumRows())), nil
+}
+
+// idsTransform keeps only the id column of a batch
+func idsTransform(ctx context.Context, batch arrow.Record) (arrow.Record, error) {
+	schema := arrow.NewSchema([]arrow.Field{batch.Schema().Field(0)}, nil)
+	return array.NewRecord(schema, []arrow.Array{batch.Column(0)}, batch.NumRows()), nil
+}
+
+// failTransform always fails
+func failTransform(ctx context.Context, batch arrow.Record) (arrow.Record, error) {
+	return nil, errors.New("transform exploded")
+}
+
+// TestGetBatchWithPipeline tests that a chain of server transforms is applied during the download
+func TestGetBatchWithPipeline(t *testing.T) {
+	server, err := NewFlightServer(FlightServerConfig{
+		ChunkRows:  2,
+		Transforms: map[string]RecordTransform{"head": headTransform, "ids": idsTransform, "fail": failTransform},
+	})
+	require.NoError(t, err)
+	defer server.Stop()
+	addr := startBareServer(t, server)
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+	batchID := server.StoreBatch(batch)
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	reader, err := client.GetBatchWithPipeline(ctx, batchID, []string{"head", "ids"})
+	require.NoError(t, err, "Failed to start pipeline")
+	defer reader.Release()
+
+	require.Equal(t, 1, reader.Schema().NumFields())
+	assert.Equal(t, "id", reader.Schema().Field(0).Name)
+	var ids []int32
+	for reader.Next() {
+		ids = append(ids, reader.Record().Column(0).(*array.Int32).Int32Values()...)
+	}
+	require.NoError(t, reader.Err())
+	assert.Equal(t, []int32{1, 2, 3}, ids, "The transforms should run in order")
+
+	// Names the server does not advertise are rejected before the download
+	_, err = client.GetBatchWithPipeline(ctx, batchID, []string{"head", "missing"})
+	assert.ErrorIs(t, err, ErrNotSupported)
+	assert.ErrorContains(t, err, "missing")
+
+	// Transform failures reach the reader
+	reader, err = client.GetBatchWithPipeline(ctx, batchID, []string{"ids", "fail"})
+	if err == nil {
+		for reader.Next() {
+		}
+		err = reader.Err()
+		reader.Release()
+	}
+	assert.ErrorContains(t, err, "transform exploded")
+}
+
+// TestGetBatchWithPipelineNotSupported tests that servers without transforms are detected
+func TestGetBatchWithPipelineNotSupported(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	_, err = client.GetBatchWithPipeline(context.Background(), "any", []string{"head"})
+	assert.ErrorIs(t, err, ErrNotSupported)
+}
@@ -0,0 +1,117 @@
+package flight
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/stats"
+)
+
+// ConnPool shares gRPC connections between the FlightClients it creates.
+// Clients with the same address and gRPC settings use a single connection,
+// which is closed once no client holds it, so fan-out callers such as
+// MultiClient don't dial the same target repeatedly.
+type ConnPool struct {
+	mu    sync.Mutex
+	conns map[connKey]*pooledConn
+}
+
+// connKey identifies the client settings that determine a connection, so
+// only clients that would dial identical connections share one
+type connKey struct {
+	addr          string
+	serviceConfig string
+	tcpKeepAlive  time.Duration
+	statsHandler  stats.Handler
+	root          context.Context
+	deadlineHint  bool
+	maxMsgSize    int
+	windowSize    int32
+	connWindow    int32
+}
+
+// poolable reports whether values can be part of a connKey. Map keys holding
+// uncomparable values, such as a stats handler that is a struct with a slice
+// field, would panic on lookup.
+func poolable(values ...any) bool {
+	for _, v := range values {
+		if v != nil && !reflect.ValueOf(v).Comparable() {
+			return false
+		}
+	}
+	return true
+}
+
+// pooledConn is a shared connection and the number of clients holding it
+type pooledConn struct {
+	conn *grpc.ClientConn
+	refs int
+}
+
+// NewConnPool creates an empty connection pool
+func NewConnPool() *ConnPool {
+	return &ConnPool{conns: make(map[connKey]*pooledConn)}
+}
+
+// NewClient creates a FlightClient whose connection is shared through the pool
+func (p *ConnPool) NewClient(config FlightClientConfig) (*FlightClient, error) {
+	return newFlightClient(config, p)
+}
+
+// acquire returns a Flight client over the shared connection for key,
+// dialing it first if no client holds one. Closing the returned client
+// releases the connection rather than closing it.
+func (p *ConnPool) acquire(key connKey, addr string, opts []grpc.DialOption) (flight.Client, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pc, ok := p.conns[key]
+	if !ok {
+		conn, err := grpc.NewClient(addr, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Flight client: %w", err)
+		}
+		pc = &pooledConn{conn: conn}
+		p.conns[key] = pc
+	}
+	pc.refs++
+
+	client := &pooledClient{Client: flight.NewClientFromConn(pc.conn, nil)}
+	client.release = func() { p.release(key) }
+	return client, nil
+}
+
+// release drops a reference to the connection for key, closing it once the
+// last client is done with it
+func (p *ConnPool) release(key connKey) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pc, ok := p.conns[key]
+	if !ok {
+		return
+	}
+	pc.refs--
+	if pc.refs == 0 {
+		pc.conn.Close()
+		delete(p.conns, key)
+	}
+}
+
+// pooledClient is a Flight client over a pooled connection
+type pooledClient struct {
+	flight.Client
+	once    sync.Once
+	release func()
+}
+
+// Close returns the connection to the pool
+func (c *pooledClient) Close() error {
+	c.once.Do(c.release)
+	return nil
+}
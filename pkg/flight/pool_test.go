@@ -0,0 +1,120 @@
+package flight
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/stats"
+)
+
+// poolRefs returns the number of clients holding each pooled connection
+func poolRefs(p *ConnPool) []int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var refs []int
+	for _, pc := range p.conns {
+		refs = append(refs, pc.refs)
+	}
+	return refs
+}
+
+// TestConnPoolSharesConnections tests that clients of the same address share one connection
+func TestConnPoolSharesConnections(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	pool := NewConnPool()
+	writer, err := pool.NewClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	reader, err := pool.NewClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	assert.Equal(t, []int{2}, poolRefs(pool), "Both clients should share one connection")
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batchID, err := writer.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to put batch")
+	retrieved, err := reader.GetBatch(ctx, batchID)
+	require.NoError(t, err, "Failed to get batch")
+	retrieved.Release()
+
+	// The connection outlives the first client and closes with the last
+	require.NoError(t, writer.Close())
+	assert.Equal(t, []int{1}, poolRefs(pool))
+	_, err = reader.ListBatches(ctx)
+	require.NoError(t, err, "Remaining client should keep working")
+
+	require.NoError(t, reader.Close())
+	assert.Empty(t, poolRefs(pool), "Connection should close with its last client")
+}
+
+// TestConnPoolSeparatesTargets tests that different addresses get their own connections
+func TestConnPoolSeparatesTargets(t *testing.T) {
+	pool := NewConnPool()
+
+	first, err := pool.NewClient(FlightClientConfig{Addr: "localhost:1"})
+	require.NoError(t, err)
+	defer first.Close()
+	second, err := pool.NewClient(FlightClientConfig{Addr: "localhost:2"})
+	require.NoError(t, err)
+	defer second.Close()
+
+	assert.ElementsMatch(t, []int{1, 1}, poolRefs(pool))
+}
+
+// tagStatsHandler is a gRPC stats handler whose type cannot be compared
+type tagStatsHandler []string
+
+// TagRPC implements stats.Handler
+func (h tagStatsHandler) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+// HandleRPC implements stats.Handler
+func (h tagStatsHandler) HandleRPC(context.Context, stats.RPCStats) {}
+
+// TagConn implements stats.Handler
+func (h tagStatsHandler) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+// HandleConn implements stats.Handler
+func (h tagStatsHandler) HandleConn(context.Context, stats.ConnStats) {}
+
+// TestConnPoolStatsHandlers tests that clients share a connection only with
+// the same stats handler, and that handlers that cannot be told apart are
+// rejected rather than panicking
+func TestConnPoolStatsHandlers(t *testing.T) {
+	pool := NewConnPool()
+	handler := &countingStatsHandler{}
+
+	first, err := pool.NewClient(FlightClientConfig{Addr: "localhost:1", StatsHandler: handler})
+	require.NoError(t, err)
+	defer first.Close()
+	second, err := pool.NewClient(FlightClientConfig{Addr: "localhost:1", StatsHandler: handler})
+	require.NoError(t, err)
+	defer second.Close()
+	other, err := pool.NewClient(FlightClientConfig{Addr: "localhost:1", StatsHandler: &countingStatsHandler{}})
+	require.NoError(t, err)
+	defer other.Close()
+	assert.ElementsMatch(t, []int{2, 1}, poolRefs(pool))
+
+	assert.NotPanics(t, func() {
+		_, err = pool.NewClient(FlightClientConfig{Addr: "localhost:1", StatsHandler: tagStatsHandler{"a"}})
+	})
+	assert.Error(t, err)
+
+	// Without a pool any handler works
+	client, err := NewFlightClient(FlightClientConfig{Addr: "localhost:1", StatsHandler: tagStatsHandler{"a"}})
+	require.NoError(t, err)
+	client.Close()
+}
@@ -0,0 +1,203 @@
+package flight
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// PriorityHeader is the gRPC metadata key carrying a call's priority, so
+// servers can prioritize requests too. Its value is the Priority's String.
+const PriorityHeader = "flight-priority"
+
+// Priority is the scheduling tier of a client call. When the client's
+// MaxConcurrentCalls limit is reached, waiting calls are admitted by a
+// weighted scheduler that favours higher tiers without starving lower ones.
+type Priority int
+
+// Priorities, from most to least favoured
+const (
+	PriorityHigh   Priority = 1
+	PriorityNormal Priority = 0
+	PriorityLow    Priority = -1
+)
+
+// String returns the name sent in PriorityHeader
+func (p Priority) String() string {
+	switch p {
+	case PriorityHigh:
+		return "high"
+	case PriorityLow:
+		return "low"
+	default:
+		return "normal"
+	}
+}
+
+// priorityKey is the context key of the call priority
+type priorityKey struct{}
+
+// WithPriority returns a context whose calls run with priority p. Calls made
+// with a context carrying no priority run at PriorityNormal. The priority is
+// also sent to the server in the PriorityHeader metadata.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	ctx = context.WithValue(ctx, priorityKey{}, p)
+	return metadata.AppendToOutgoingContext(ctx, PriorityHeader, p.String())
+}
+
+// priorityFromContext returns the priority set with WithPriority
+func priorityFromContext(ctx context.Context) Priority {
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok {
+		return p
+	}
+	return PriorityNormal
+}
+
+// priorityTiers lists the tiers in scheduling order with the share of
+// admissions each gets while several tiers are waiting
+var priorityTiers = []struct {
+	priority Priority
+	weight   int
+}{
+	{PriorityHigh, 4},
+	{PriorityNormal, 2},
+	{PriorityLow, 1},
+}
+
+// callScheduler limits the number of concurrent calls, admitting waiting calls
+// by weighted priority: among the tiers with waiting calls, the highest tier
+// with credit left goes first, and credits are refilled once every waiting
+// tier has used its share
+type callScheduler struct {
+	mu      sync.Mutex
+	limit   int
+	active  int
+	queues  [3][]*callWaiter // Indexed like priorityTiers
+	credits [3]int
+}
+
+// callWaiter is a call waiting for a slot
+type callWaiter struct {
+	ready   chan struct{} // Closed when the call is granted a slot
+	granted bool
+}
+
+// newCallScheduler creates a scheduler admitting at most limit calls at once
+func newCallScheduler(limit int) *callScheduler {
+	s := &callScheduler{limit: limit}
+	s.refill()
+	return s
+}
+
+// admit waits for a call slot, returning the function that gives it back. A
+// nil scheduler admits every call immediately.
+func (s *callScheduler) admit(ctx context.Context) (func(), error) {
+	if s == nil {
+		return func() {}, nil
+	}
+
+	s.mu.Lock()
+	if s.active < s.limit && s.waiting() == 0 {
+		s.active++
+		s.mu.Unlock()
+		return s.done, nil
+	}
+
+	tier := tierIndex(priorityFromContext(ctx))
+	w := &callWaiter{ready: make(chan struct{})}
+	s.queues[tier] = append(s.queues[tier], w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return s.done, nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if w.granted {
+			// The slot was handed over as the context ended; pass it on
+			s.handOff()
+		} else {
+			s.remove(tier, w)
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// done gives back a call slot, handing it to the next waiting call
+func (s *callScheduler) done() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handOff()
+}
+
+// handOff passes a held slot to the next waiting call, or frees it if no
+// call is waiting. Must be called with mu held.
+func (s *callScheduler) handOff() {
+	w := s.next()
+	if w == nil {
+		s.active--
+		return
+	}
+	w.granted = true
+	close(w.ready)
+}
+
+// next dequeues the waiting call to admit next. Must be called with mu held.
+func (s *callScheduler) next() *callWaiter {
+	if s.waiting() == 0 {
+		return nil
+	}
+	for {
+		for i := range priorityTiers {
+			if len(s.queues[i]) > 0 && s.credits[i] > 0 {
+				s.credits[i]--
+				w := s.queues[i][0]
+				s.queues[i] = s.queues[i][1:]
+				return w
+			}
+		}
+		// Every waiting tier has used its share
+		s.refill()
+	}
+}
+
+// waiting returns the number of queued calls. Must be called with mu held.
+func (s *callScheduler) waiting() int {
+	n := 0
+	for _, queue := range s.queues {
+		n += len(queue)
+	}
+	return n
+}
+
+// remove drops a waiter that gave up. Must be called with mu held.
+func (s *callScheduler) remove(tier int, w *callWaiter) {
+	queue := s.queues[tier]
+	for i, queued := range queue {
+		if queued == w {
+			s.queues[tier] = append(queue[:i], queue[i+1:]...)
+			return
+		}
+	}
+}
+
+// refill restores every tier's share of admissions
+func (s *callScheduler) refill() {
+	for i, tier := range priorityTiers {
+		s.credits[i] = tier.weight
+	}
+}
+
+// tierIndex returns the index of a priority in priorityTiers
+func tierIndex(p Priority) int {
+	switch {
+	case p > PriorityNormal:
+		return 0
+	case p < PriorityNormal:
+		return 2
+	default:
+		return 1
+	}
+}
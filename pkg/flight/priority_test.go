@@ -0,0 +1,189 @@
+package flight
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+// queuedCalls returns the number of calls waiting in a scheduler
+func queuedCalls(s *callScheduler) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.waiting()
+}
+
+// TestPriorityAdmission tests that queued high-priority calls are admitted before queued low-priority ones
+func TestPriorityAdmission(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr, MaxConcurrentCalls: 1})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Hold the only slot while the other calls queue up
+	_, release, err := client.acquire(ctx)
+	require.NoError(t, err, "Failed to acquire a slot")
+
+	var mu sync.Mutex
+	var admitted []Priority
+	var wg sync.WaitGroup
+	enqueue := func(p Priority) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, release, err := client.acquire(WithPriority(ctx, p))
+			if !assert.NoError(t, err, "Queued call should be admitted") {
+				return
+			}
+			mu.Lock()
+			admitted = append(admitted, p)
+			mu.Unlock()
+			release()
+		}()
+	}
+
+	queued := 0
+	for _, p := range []Priority{PriorityLow, PriorityLow, PriorityLow, PriorityHigh, PriorityHigh} {
+		enqueue(p)
+		queued++
+		require.Eventually(t, func() bool { return queuedCalls(client.scheduler) == queued },
+			time.Second, time.Millisecond, "Call should be queued")
+	}
+
+	release()
+	wg.Wait()
+	assert.Equal(t, []Priority{PriorityHigh, PriorityHigh, PriorityLow, PriorityLow, PriorityLow}, admitted)
+
+	// The client works normally once the queue has drained
+	_, err = client.ListBatches(WithPriority(ctx, PriorityLow))
+	assert.NoError(t, err)
+}
+
+// TestPriorityWeights tests that low-priority calls are not starved by a stream of high-priority ones
+func TestPriorityWeights(t *testing.T) {
+	s := newCallScheduler(1)
+	for i := 0; i < 8; i++ {
+		s.queues[tierIndex(PriorityHigh)] = append(s.queues[tierIndex(PriorityHigh)], &callWaiter{})
+	}
+	low := &callWaiter{}
+	s.queues[tierIndex(PriorityLow)] = append(s.queues[tierIndex(PriorityLow)], low)
+
+	position := 0
+	for i := 0; i < 9; i++ {
+		if s.next() == low {
+			position = i
+		}
+	}
+	assert.Equal(t, 4, position, "The low-priority call should follow the high tier's share")
+}
+
+// TestPriorityCancel tests that a queued call gives up when its context ends
+func TestPriorityCancel(t *testing.T) {
+	s := newCallScheduler(1)
+	done, err := s.admit(context.Background())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = s.admit(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 0, queuedCalls(s), "The abandoned call should leave the queue")
+
+	done()
+	done, err = s.admit(context.Background())
+	require.NoError(t, err, "The slot should be free again")
+	done()
+}
+
+// TestPriorityMetadata tests that the priority is sent as gRPC metadata
+func TestPriorityMetadata(t *testing.T) {
+	md, ok := metadata.FromOutgoingContext(WithPriority(context.Background(), PriorityHigh))
+	require.True(t, ok)
+	assert.Equal(t, []string{"high"}, md.Get(PriorityHeader))
+	assert.Equal(t, PriorityNormal, priorityFromContext(context.Background()))
+}
+
+// TestConcurrencyLimitAcrossOperations tests that different operations share the call limit
+func TestConcurrencyLimitAcrossOperations(t *testing.T) {
+	server := &blockingGetServer{started: make(chan struct{})}
+	addr := startBareServer(t, server)
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr, MaxConcurrentCalls: 1})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	// An open download holds the only slot
+	getCtx, cancelGet := context.WithCancel(context.Background())
+	defer cancelGet()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		client.GetBatch(getCtx, "any")
+	}()
+	<-server.started
+	assert.Equal(t, 1, client.ActiveCalls())
+
+	// A listing waits for it, giving up with its context
+	listCtx, cancelList := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancelList()
+	listed := make(chan error, 1)
+	go func() {
+		_, err := client.ListBatches(listCtx)
+		listed <- err
+	}()
+	require.Eventually(t, func() bool { return client.QueuedCalls() == 1 }, time.Second, time.Millisecond,
+		"The listing should wait for a slot")
+	assert.ErrorIs(t, <-listed, context.DeadlineExceeded)
+	assert.Zero(t, client.QueuedCalls())
+
+	// Ending the download frees the slot
+	cancelGet()
+	<-done
+	assert.Zero(t, client.ActiveCalls())
+	_, err = client.ListBatches(context.Background())
+	assert.NotErrorIs(t, err, context.DeadlineExceeded, "The listing should reach the server")
+}
+
+// TestStreamLimit tests that MaxConcurrentStreams makes operations wait for an
+// open stream to close
+func TestStreamLimit(t *testing.T) {
+	server := &blockingGetServer{started: make(chan struct{})}
+	addr := startBareServer(t, server)
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr, MaxConcurrentStreams: 1})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	// An open download holds the only stream
+	getCtx, cancelGet := context.WithCancel(context.Background())
+	defer cancelGet()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		client.GetBatch(getCtx, "any")
+	}()
+	<-server.started
+
+	// A listing blocks until its context ends
+	listCtx, cancelList := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancelList()
+	_, err = client.ListBatches(listCtx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, client.ActiveCalls(), "The listing should not have started")
+
+	// Closing the download frees the stream
+	cancelGet()
+	<-done
+	_, err = client.ListBatches(context.Background())
+	assert.NotErrorIs(t, err, context.DeadlineExceeded, "The listing should reach the server")
+}
@@ -0,0 +1,20 @@
+package flight
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/apache/arrow-go/v18/arrow"
+)
+
+// GetBatchProjected retrieves only the named columns of a batch, in the given
+// order. The server is asked to send just those columns, and the result is
+// downselected to exactly them whatever the server sends, so consumers get
+// the same schema from servers that ignore the projection or return a wider
+// record. Naming a column the batch lacks fails the download.
+func (c *FlightClient) GetBatchProjected(ctx context.Context, batchID string, columns []string) (arrow.Record, error) {
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("projection must name at least one column")
+	}
+	return c.GetBatchWithOptions(ctx, batchID, GetOptions{Columns: columns})
+}
@@ -0,0 +1,101 @@
+package flight
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPutBatchColumns tests that only the selected columns are transmitted
+func TestPutBatchColumns(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := client.PutBatchWithOptions(ctx, batch, PutOptions{Columns: []string{"value", "id"}})
+	require.NoError(t, err, "Failed to put projected batch")
+
+	// The server only holds the selected columns, in the requested order
+	stored, err := server.RetrieveBatch(result.BatchID)
+	require.NoError(t, err, "Failed to retrieve stored batch")
+	defer stored.Release()
+	require.Equal(t, int64(2), stored.NumCols())
+	assert.Equal(t, "value", stored.ColumnName(0))
+	assert.Equal(t, "id", stored.ColumnName(1))
+	assert.Equal(t, 3.3, stored.Column(0).(*array.Float64).Value(2))
+
+	// The caller's record is left intact
+	assert.Equal(t, int64(3), batch.NumCols())
+
+	_, err = client.PutBatchWithOptions(ctx, batch, PutOptions{Columns: []string{"missing"}})
+	assert.Error(t, err, "Unknown columns should be rejected")
+}
+
+// wideGetServer serves a FlightServer's batches ignoring the requested columns
+type wideGetServer struct {
+	flight.BaseFlightServer
+	server *FlightServer
+}
+
+// DoGet sends every column of the batch
+func (s *wideGetServer) DoGet(request *flight.Ticket, stream flight.FlightService_DoGetServer) error {
+	t, err := decodeTicket(request.Ticket)
+	if err != nil {
+		return err
+	}
+	t.Columns = nil
+	raw, err := t.encode()
+	if err != nil {
+		return err
+	}
+	return s.server.DoGet(&flight.Ticket{Ticket: raw}, stream)
+}
+
+// TestGetBatchProjected tests that downloads hold exactly the requested columns
+func TestGetBatchProjected(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+	wideAddr := startBareServer(t, &wideGetServer{server: server})
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+	batchID := server.StoreBatch(batch)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for name, addr := range map[string]string{"projecting": addr, "wide": wideAddr} {
+		t.Run(name, func(t *testing.T) {
+			client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+			require.NoError(t, err, "Failed to create Flight client")
+			defer client.Close()
+
+			retrieved, err := client.GetBatchProjected(ctx, batchID, []string{"value", "id"})
+			require.NoError(t, err, "Failed to get projected batch")
+			defer retrieved.Release()
+			require.Equal(t, int64(2), retrieved.NumCols())
+			assert.Equal(t, "value", retrieved.ColumnName(0))
+			assert.Equal(t, "id", retrieved.ColumnName(1))
+			assert.Equal(t, 3.3, retrieved.Column(0).(*array.Float64).Value(2))
+			assert.Equal(t, batch.NumRows(), retrieved.NumRows())
+
+			_, err = client.GetBatchProjected(ctx, batchID, []string{"id", "missing"})
+			assert.ErrorContains(t, err, "missing", "Unknown columns should be rejected")
+		})
+	}
+}
@@ -0,0 +1,319 @@
+package flight
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	arrow_utils "github.com/TFMV/temporal/pkg/arrow"
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/util"
+)
+
+// PutStreamOptions configures PutStream
+type PutStreamOptions struct {
+	// Schema, if set, is the schema of the upload. Every record is aligned to
+	// it as it arrives: columns are matched by name and reordered, and missing
+	// nullable columns are filled with nulls.
+	Schema *arrow.Schema
+	// UnifySchemas uploads records whose schemas differ but are compatible
+	// by aligning them all to the union of their schemas (see
+	// arrow_utils.UnifySchemas). The union is known only once the channel is
+	// closed, so records are buffered until then; set Schema instead to
+	// stream without buffering. Ignored if Schema is set.
+	UnifySchemas bool
+	// ByteChunkSize, if positive, slices records so that each IPC message
+	// body stays under this many bytes, for servers with strict frame limits.
+	// Rows per slice are estimated from each record's average row width, with
+	// room for buffer padding, so a slice holding rows far wider than the
+	// average can still exceed the target. A single row is never split.
+	ByteChunkSize int64
+}
+
+// PutStream uploads the records received from a channel as one batch on a
+// single DoPut, completing when the channel is closed. PutStream takes
+// ownership of the records it receives. Without options every record must
+// have the schema of the first. Cancelling ctx aborts the upload.
+func (c *FlightClient) PutStream(ctx context.Context, records <-chan arrow.Record, options PutStreamOptions) (string, error) {
+	recv := func() (arrow.Record, error) {
+		select {
+		case rec, ok := <-records:
+			if !ok {
+				return nil, io.EOF
+			}
+			return rec, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	// Records received before the upload schema was known
+	var pending []arrow.Record
+	defer func() { releaseRecords(pending) }()
+
+	schema := options.Schema
+	switch {
+	case schema == nil && options.UnifySchemas:
+		var schemas []*arrow.Schema
+		for {
+			rec, err := recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return "", err
+			}
+			pending = append(pending, rec)
+			schemas = append(schemas, rec.Schema())
+		}
+		if len(schemas) == 0 {
+			return "", fmt.Errorf("no records to upload")
+		}
+
+		var err error
+		if schema, err = arrow_utils.UnifySchemas(schemas...); err != nil {
+			return "", fmt.Errorf("failed to unify record schemas: %w", err)
+		}
+	case schema == nil:
+		rec, err := recv()
+		if err == io.EOF {
+			return "", fmt.Errorf("no records to upload")
+		}
+		if err != nil {
+			return "", err
+		}
+		pending = append(pending, rec)
+		schema = rec.Schema()
+	}
+
+	align := options.Schema != nil || options.UnifySchemas
+	next := func() (arrow.Record, error) {
+		var rec arrow.Record
+		if len(pending) > 0 {
+			rec, pending = pending[0], pending[1:]
+		} else {
+			var err error
+			if rec, err = recv(); err != nil {
+				return nil, err
+			}
+		}
+
+		if rec.Schema().Equal(schema) {
+			return rec, nil
+		}
+		defer rec.Release()
+		if !align {
+			return nil, fmt.Errorf("record schema %s differs from the stream schema %s", rec.Schema(), schema)
+		}
+		aligned, err := arrow_utils.AlignRecord(rec, schema, c.allocator)
+		if err != nil {
+			return nil, fmt.Errorf("failed to align record: %w", err)
+		}
+		return aligned, nil
+	}
+
+	if options.ByteChunkSize > 0 {
+		chunker := &byteChunker{next: next, maxBytes: options.ByteChunkSize}
+		defer chunker.release()
+		next = chunker.nextChunk
+	}
+
+	return c.putStream(ctx, schema, next)
+}
+
+// ipcBufferOverhead is the most an IPC message body grows per buffer beyond
+// the buffer's data: padding to 8-byte alignment plus a compressed length
+const ipcBufferOverhead = 16
+
+// byteChunker slices the records returned by next into chunks whose IPC
+// message bodies are estimated to stay under maxBytes
+type byteChunker struct {
+	next     func() (arrow.Record, error)
+	maxBytes int64
+
+	rec    arrow.Record // Record being sliced
+	offset int64        // First row of rec not yet returned
+	rows   int64        // Rows per chunk of rec
+}
+
+// nextChunk returns the next slice of the current record, advancing to the
+// next record once it is used up. Records without rows pass through whole.
+func (b *byteChunker) nextChunk() (arrow.Record, error) {
+	for b.rec == nil || b.offset == b.rec.NumRows() {
+		b.release()
+		rec, err := b.next()
+		if err != nil {
+			return nil, err
+		}
+		if rec.NumRows() == 0 {
+			return rec, nil
+		}
+		b.rec, b.offset, b.rows = rec, 0, chunkRows(rec, b.maxBytes)
+	}
+
+	end := min(b.offset+b.rows, b.rec.NumRows())
+	chunk := b.rec.NewSlice(b.offset, end)
+	b.offset = end
+	return chunk, nil
+}
+
+// release releases the record being sliced
+func (b *byteChunker) release() {
+	if b.rec != nil {
+		b.rec.Release()
+		b.rec = nil
+	}
+}
+
+// chunkRows estimates how many rows of rec fit in an IPC message body of
+// maxBytes, and is at least 1
+func chunkRows(rec arrow.Record, maxBytes int64) int64 {
+	var buffers int64
+	for _, col := range rec.Columns() {
+		buffers += countBuffers(col.Data())
+	}
+	budget := maxBytes - buffers*ipcBufferOverhead
+	rowBytes := (util.TotalRecordSize(rec) + rec.NumRows() - 1) / rec.NumRows()
+	if rowBytes == 0 {
+		return rec.NumRows()
+	}
+	return max(1, budget/rowBytes)
+}
+
+// countBuffers returns the number of buffers in data and its children
+func countBuffers(data arrow.ArrayData) int64 {
+	n := int64(len(data.Buffers()))
+	for _, child := range data.Children() {
+		n += countBuffers(child)
+	}
+	return n
+}
+
+// putStream uploads the records returned by next on one DoPut until next
+// returns io.EOF, and reports the call to the metrics hook
+func (c *FlightClient) putStream(ctx context.Context, schema *arrow.Schema, next func() (arrow.Record, error)) (string, error) {
+	start := time.Now()
+	stats := CallStats{Method: MethodPutBatch, Compression: c.EffectiveCompression(), Label: CallLabel(ctx)}
+
+	batchID, err := c.doPutStream(ctx, schema, next, &stats)
+	err = labelError(ctx, err)
+
+	stats.BatchID = batchID
+	stats.Duration = time.Since(start)
+	stats.Err = err
+	c.observe(stats)
+
+	return batchID, err
+}
+
+// doPutStream implements putStream, recording the rows and bytes sent in stats
+func (c *FlightClient) doPutStream(ctx context.Context, schema *arrow.Schema, next func() (arrow.Record, error), stats *CallStats) (string, error) {
+	if err := c.requireFeature(ctx, "streamed uploads", func(s ServerCapabilities) bool { return s.StreamedUploads }); err != nil {
+		return "", err
+	}
+	schemaID, err := c.registerSchema(ctx, schema)
+	if err != nil {
+		return "", err
+	}
+
+	client, release, err := c.acquire(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	// Cancelling on return aborts the call if the upload stops part way
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := client.DoPut(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to start DoPut stream: %w", err)
+	}
+
+	appMetadata, err := json.Marshal(putMetadata{Streamed: true, SchemaID: schemaID})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode put metadata: %w", err)
+	}
+	descriptor := &flight.FlightDescriptor{Type: flight.DescriptorCMD, Cmd: []byte("put")}
+	if !c.ipc.DescriptorWithSchema {
+		if err := stream.Send(&flight.FlightData{
+			FlightDescriptor: descriptor,
+			AppMetadata:      appMetadata,
+		}); err != nil {
+			return "", fmt.Errorf("failed to send descriptor: %w", err)
+		}
+		appMetadata = nil
+	}
+
+	counter := &countingStream{DataStreamWriter: stream}
+	writer := flight.NewRecordWriter(counter, c.writerOptions(schema, stats.Compression)...)
+	if c.ipc.DescriptorWithSchema {
+		// Sent with the schema, ahead of the first record
+		writer.SetFlightDescriptor(descriptor)
+	}
+	var writeTime time.Duration
+
+	for {
+		rec, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			writer.Close()
+			return "", err
+		}
+
+		if err := c.checkConstraints(ctx, rec, nil); err != nil {
+			rec.Release()
+			writer.Close()
+			return "", err
+		}
+
+		stats.Rows += rec.NumRows()
+		stats.UncompressedBytes += util.TotalRecordSize(rec)
+		writeStart := time.Now()
+		err = writer.WriteWithAppMetadata(rec, appMetadata)
+		appMetadata = nil
+		writeTime += time.Since(writeStart)
+		rec.Release()
+		if errors.Is(err, io.EOF) {
+			// The server has already ended the call; its reply says why
+			break
+		}
+		if err != nil {
+			writer.Close()
+			return "", fmt.Errorf("failed to write batch to stream: %w", err)
+		}
+	}
+	stats.Bytes = counter.bodyBytes
+	c.observeEncoding(ctx, stats.Compression, stats.UncompressedBytes, writeTime-counter.sendTime)
+
+	// Half-close to mark the end of the upload
+	if err := writer.Close(); err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to close writer: %w", err)
+	}
+	if err := stream.CloseSend(); err != nil {
+		return "", fmt.Errorf("failed to close send direction: %w", err)
+	}
+
+	result, err := stream.Recv()
+	if err != nil {
+		return "", fmt.Errorf("failed to receive result: %w", err)
+	}
+	decoded, err := decodePutResult(result.AppMetadata)
+	if err != nil {
+		return "", err
+	}
+
+	// Servers that don't know streamed uploads store only the first record
+	// batch and reply with the bare ID
+	if !decoded.Streamed {
+		return "", fmt.Errorf("%w: streamed uploads", ErrNotSupported)
+	}
+	return decoded.BatchID, nil
+}
@@ -0,0 +1,281 @@
+package flight
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createRecord builds a record from named columns of int32 or float64 values
+func createRecord(t *testing.T, columns []string, values map[string]any) arrow.Record {
+	mem := memory.NewGoAllocator()
+	var fields []arrow.Field
+	var arrays []arrow.Array
+	numRows := int64(0)
+	for _, name := range columns {
+		switch v := values[name].(type) {
+		case []int32:
+			b := array.NewInt32Builder(mem)
+			b.AppendValues(v, nil)
+			arrays = append(arrays, b.NewArray())
+			fields = append(fields, arrow.Field{Name: name, Type: arrow.PrimitiveTypes.Int32})
+			numRows = int64(len(v))
+			b.Release()
+		case []int64:
+			b := array.NewInt64Builder(mem)
+			b.AppendValues(v, nil)
+			arrays = append(arrays, b.NewArray())
+			fields = append(fields, arrow.Field{Name: name, Type: arrow.PrimitiveTypes.Int64})
+			numRows = int64(len(v))
+			b.Release()
+		case []float64:
+			b := array.NewFloat64Builder(mem)
+			b.AppendValues(v, nil)
+			arrays = append(arrays, b.NewArray())
+			fields = append(fields, arrow.Field{Name: name, Type: arrow.PrimitiveTypes.Float64, Nullable: true})
+			numRows = int64(len(v))
+			b.Release()
+		default:
+			t.Fatalf("unsupported column type %T", v)
+		}
+	}
+
+	rec := array.NewRecord(arrow.NewSchema(fields, nil), arrays, numRows)
+	for _, arr := range arrays {
+		arr.Release()
+	}
+	return rec
+}
+
+// sendRecords returns a closed channel holding the records
+func sendRecords(records ...arrow.Record) <-chan arrow.Record {
+	ch := make(chan arrow.Record, len(records))
+	for _, rec := range records {
+		ch <- rec
+	}
+	close(ch)
+	return ch
+}
+
+// TestPutStreamUnifySchemas tests that records with compatible schemas are uploaded as one batch
+func TestPutStreamUnifySchemas(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	records := sendRecords(
+		createRecord(t, []string{"id"}, map[string]any{"id": []int32{1, 2}}),
+		createRecord(t, []string{"id", "score"}, map[string]any{"id": []int32{3}, "score": []float64{0.5}}),
+		createRecord(t, []string{"score", "id"}, map[string]any{"id": []int32{4, 5}, "score": []float64{1.5, 2.5}}),
+	)
+
+	batchID, err := client.PutStream(ctx, records, PutStreamOptions{UnifySchemas: true})
+	require.NoError(t, err, "Failed to stream records")
+
+	retrieved, err := client.GetBatch(ctx, batchID)
+	require.NoError(t, err, "Failed to get batch")
+	defer retrieved.Release()
+
+	require.Equal(t, int64(5), retrieved.NumRows())
+	schema := retrieved.Schema()
+	require.Equal(t, 2, len(schema.Fields()))
+	assert.Equal(t, "id", schema.Field(0).Name)
+	assert.Equal(t, "score", schema.Field(1).Name)
+	assert.True(t, schema.Field(1).Nullable, "A column missing from some records should be nullable")
+
+	ids := retrieved.Column(0).(*array.Int32)
+	assert.Equal(t, []int32{1, 2, 3, 4, 5}, ids.Int32Values())
+
+	scores := retrieved.Column(1).(*array.Float64)
+	assert.Equal(t, 2, scores.NullN(), "Rows without a score should be null")
+	assert.True(t, scores.IsNull(0))
+	assert.Equal(t, 0.5, scores.Value(2))
+	assert.Equal(t, 2.5, scores.Value(4))
+}
+
+// TestPutStreamIncompatibleSchemas tests that schemas which cannot be unified are rejected
+func TestPutStreamIncompatibleSchemas(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	records := sendRecords(
+		createRecord(t, []string{"id"}, map[string]any{"id": []int32{1}}),
+		createRecord(t, []string{"id"}, map[string]any{"id": []int64{2}}),
+	)
+
+	_, err = client.PutStream(ctx, records, PutStreamOptions{UnifySchemas: true})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "incompatible")
+}
+
+// TestPutStreamSchemaMismatch tests that differing schemas are rejected without unification
+func TestPutStreamSchemaMismatch(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	records := sendRecords(
+		createRecord(t, []string{"id"}, map[string]any{"id": []int32{1}}),
+		createRecord(t, []string{"id", "score"}, map[string]any{"id": []int32{2}, "score": []float64{0.5}}),
+	)
+
+	_, err = client.PutStream(ctx, records, PutStreamOptions{})
+	assert.Error(t, err)
+}
+
+// bodySizeServer is a Flight server that records the body size of every
+// message uploaded to it
+type bodySizeServer struct {
+	*FlightServer
+	mu    sync.Mutex
+	sizes []int
+}
+
+// DoPut records the uploaded body sizes and stores the upload
+func (s *bodySizeServer) DoPut(stream flight.FlightService_DoPutServer) error {
+	return s.FlightServer.DoPut(&bodySizeStream{FlightService_DoPutServer: stream, server: s})
+}
+
+// bodySizeStream records the body size of each message it receives
+type bodySizeStream struct {
+	flight.FlightService_DoPutServer
+	server *bodySizeServer
+}
+
+// Recv records the body size of the received message
+func (s *bodySizeStream) Recv() (*flight.FlightData, error) {
+	data, err := s.FlightService_DoPutServer.Recv()
+	if err == nil && len(data.DataBody) > 0 {
+		s.server.mu.Lock()
+		s.server.sizes = append(s.server.sizes, len(data.DataBody))
+		s.server.mu.Unlock()
+	}
+	return data, err
+}
+
+// TestPutStreamByteChunkSize tests that records are sliced so that every
+// message body stays under the target size
+func TestPutStreamByteChunkSize(t *testing.T) {
+	server, err := NewFlightServer(FlightServerConfig{})
+	require.NoError(t, err, "Failed to create Flight server")
+	defer server.Stop()
+	recorder := &bodySizeServer{FlightServer: server}
+	addr := startBareServer(t, recorder)
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	mem := memory.NewGoAllocator()
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+	}, nil)
+	builder := array.NewRecordBuilder(mem, schema)
+	defer builder.Release()
+	for i := range 1000 {
+		builder.Field(0).(*array.Int64Builder).Append(int64(i))
+		if i%7 == 0 {
+			builder.Field(1).AppendNull()
+		} else {
+			builder.Field(1).(*array.StringBuilder).Append(fmt.Sprintf("name-%d", i))
+		}
+	}
+	rec := builder.NewRecord()
+	defer rec.Release()
+
+	const target = 2048
+	rec.Retain()
+	batchID, err := client.PutStream(ctx, sendRecords(rec), PutStreamOptions{ByteChunkSize: target})
+	require.NoError(t, err, "Failed to stream records")
+
+	recorder.mu.Lock()
+	sizes := recorder.sizes
+	recorder.mu.Unlock()
+	assert.Greater(t, len(sizes), 1, "The record should be sent in several messages")
+	for _, size := range sizes {
+		assert.LessOrEqual(t, size, target, "Every message body should stay under the target")
+	}
+
+	retrieved, err := client.GetBatch(ctx, batchID)
+	require.NoError(t, err, "Failed to get batch")
+	defer retrieved.Release()
+	assert.True(t, array.RecordEqual(rec, retrieved), "The slices should reassemble into the record")
+}
+
+// TestPutWriter tests that written records are uploaded as one batch when the
+// writer is closed, and that failures surface from Write and Close
+func TestPutWriter(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	w := client.NewPutWriter(ctx, PutStreamOptions{})
+	for i := range int32(3) {
+		rec := createRecord(t, []string{"id"}, map[string]any{"id": []int32{2 * i, 2*i + 1}})
+		require.NoError(t, w.Write(rec), "Failed to write record")
+		rec.Release()
+	}
+	batchID, err := w.Close()
+	require.NoError(t, err, "Failed to complete upload")
+
+	retrieved, err := client.GetBatch(ctx, batchID)
+	require.NoError(t, err, "Failed to get batch")
+	defer retrieved.Release()
+	assert.Equal(t, []int32{0, 1, 2, 3, 4, 5}, retrieved.Column(0).(*array.Int32).Int32Values())
+
+	again, err := w.Close()
+	assert.NoError(t, err, "Closing again should return the same result")
+	assert.Equal(t, batchID, again)
+	rec := createRecord(t, []string{"id"}, map[string]any{"id": []int32{6}})
+	defer rec.Release()
+	assert.ErrorIs(t, w.Write(rec), ErrWriterClosed)
+
+	// A record the upload rejects fails the writes after it and Close
+	failing := client.NewPutWriter(ctx, PutStreamOptions{})
+	require.NoError(t, failing.Write(rec))
+	other := createRecord(t, []string{"score"}, map[string]any{"score": []float64{0.5}})
+	defer other.Release()
+	_ = failing.Write(other)
+	err = failing.Write(rec)
+	assert.ErrorContains(t, err, "differs from the stream schema")
+	_, err = failing.Close()
+	assert.ErrorContains(t, err, "differs from the stream schema")
+}
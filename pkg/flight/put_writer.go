@@ -0,0 +1,78 @@
+package flight
+
+import (
+	"context"
+	"sync"
+
+	"github.com/apache/arrow-go/v18/arrow"
+)
+
+// PutWriter uploads the records written to it as one batch on a single DoPut,
+// the push counterpart of PutStream for code that produces records in a loop:
+//
+//	w := client.NewPutWriter(ctx, PutStreamOptions{})
+//	for rec := range records {
+//		if err := w.Write(rec); err != nil {
+//			break
+//		}
+//	}
+//	batchID, err := w.Close()
+//
+// Close must be called to complete the upload and free the stream; the batch
+// is stored only once it returns without error. A PutWriter is not safe for
+// concurrent Writes.
+type PutWriter struct {
+	records chan arrow.Record
+	done    chan struct{} // Closed when the upload has ended
+	batchID string
+	err     error
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewPutWriter starts an upload fed by the returned writer. The options are
+// those of PutStream; without a Schema, the first record written sets it.
+// Cancelling ctx aborts the upload.
+func (c *FlightClient) NewPutWriter(ctx context.Context, options PutStreamOptions) *PutWriter {
+	w := &PutWriter{records: make(chan arrow.Record), done: make(chan struct{})}
+	go func() {
+		defer close(w.done)
+		w.batchID, w.err = c.PutStream(ctx, w.records, options)
+	}()
+	return w
+}
+
+// Write sends a record to the upload, waiting until the stream takes it. The
+// writer retains the record for as long as it needs it, so the caller keeps
+// its reference. If the upload has already failed, Write returns its error.
+func (w *PutWriter) Write(rec arrow.Record) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return ErrWriterClosed
+	}
+
+	rec.Retain()
+	select {
+	case w.records <- rec:
+		return nil
+	case <-w.done:
+		rec.Release()
+		return w.err
+	}
+}
+
+// Close ends the upload and returns the ID of the stored batch, or the error
+// the upload failed with. Later calls return the same result.
+func (w *PutWriter) Close() (string, error) {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.records)
+	}
+	w.mu.Unlock()
+
+	<-w.done
+	return w.batchID, w.err
+}
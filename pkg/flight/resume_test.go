@@ -0,0 +1,214 @@
+package flight
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// interruptingServer streams a batch one row per record and drops the first
+// download after a few rows
+type interruptingServer struct {
+	flight.BaseFlightServer
+	batch     arrow.Record
+	failAfter int64
+	// failEvery drops every download, not just the first, after failAfter rows
+	failEvery bool
+	// corrupt sends an undecodable message instead of dropping the download
+	corrupt bool
+
+	mu      sync.Mutex
+	offsets []int64
+}
+
+// DoGet serves the batch from the ticket's offset, failing the first attempt part way
+func (s *interruptingServer) DoGet(request *flight.Ticket, stream flight.FlightService_DoGetServer) error {
+	t, err := decodeTicket(request.Ticket)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.offsets = append(s.offsets, t.Offset)
+	first := len(s.offsets) == 1
+	s.mu.Unlock()
+
+	writer := flight.NewRecordWriter(stream, ipc.WithSchema(s.batch.Schema()))
+	defer writer.Close()
+
+	for row := t.Offset; row < s.batch.NumRows(); row++ {
+		if (first || s.failEvery) && row == t.Offset+s.failAfter {
+			if s.corrupt {
+				return stream.Send(&flight.FlightData{DataHeader: []byte("not an IPC message")})
+			}
+			return status.Error(codes.Unavailable, "connection lost")
+		}
+		chunk := s.batch.NewSlice(row, row+1)
+		err := writer.Write(chunk)
+		chunk.Release()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TestGetBatchResume tests that an interrupted download resumes from the rows already received
+func TestGetBatchResume(t *testing.T) {
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	srv := &interruptingServer{batch: batch, failAfter: 2}
+	addr := startBareServer(t, srv)
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr, ResumeAttempts: 1})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	retrieved, err := client.GetBatch(ctx, "any")
+	require.NoError(t, err, "Download should resume")
+	defer retrieved.Release()
+
+	assert.Equal(t, batch.NumRows(), retrieved.NumRows())
+	names := retrieved.Column(1).(*array.String)
+	assert.Equal(t, []string{"one", "two", "three", "four", "five"}, []string{
+		names.Value(0), names.Value(1), names.Value(2), names.Value(3), names.Value(4),
+	})
+	assert.Equal(t, []int64{0, 2}, srv.offsets, "The second attempt should start after the received rows")
+}
+
+// TestGetBatchNoResume tests that interrupted downloads fail without ResumeAttempts
+func TestGetBatchNoResume(t *testing.T) {
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	addr := startBareServer(t, &interruptingServer{batch: batch, failAfter: 2})
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = client.GetBatch(ctx, "any")
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+// TestGetBatchResumePerBatch tests that the resume budget applies to each record batch and covers decode failures
+func TestGetBatchResumePerBatch(t *testing.T) {
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for name, tc := range map[string]struct {
+		server  *interruptingServer
+		offsets []int64
+	}{
+		"repeated interruptions": {server: &interruptingServer{batch: batch, failAfter: 2, failEvery: true}, offsets: []int64{0, 2, 4}},
+		"corrupt message":        {server: &interruptingServer{batch: batch, failAfter: 3, corrupt: true}, offsets: []int64{0, 3}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			addr := startBareServer(t, tc.server)
+
+			client, err := NewFlightClient(FlightClientConfig{Addr: addr, ResumeAttempts: 1, ResumePerBatch: true})
+			require.NoError(t, err, "Failed to create Flight client")
+			defer client.Close()
+
+			retrieved, err := client.GetBatch(ctx, "any")
+			require.NoError(t, err, "Download should resume at the failed batch")
+			defer retrieved.Release()
+			assert.Equal(t, batch.NumRows(), retrieved.NumRows())
+			assert.Equal(t, int32(5), retrieved.Column(0).(*array.Int32).Value(4))
+			assert.Equal(t, tc.offsets, tc.server.offsets)
+		})
+	}
+
+	// Without ResumePerBatch the attempts are shared by the whole download,
+	// and undecodable messages are not retried
+	for name, srv := range map[string]*interruptingServer{
+		"repeated interruptions": {batch: batch, failAfter: 2, failEvery: true},
+		"corrupt message":        {batch: batch, failAfter: 3, corrupt: true},
+	} {
+		t.Run(name+" shared budget", func(t *testing.T) {
+			client, err := NewFlightClient(FlightClientConfig{Addr: startBareServer(t, srv), ResumeAttempts: 1})
+			require.NoError(t, err, "Failed to create Flight client")
+			defer client.Close()
+
+			_, err = client.GetBatch(ctx, "any")
+			assert.Error(t, err)
+		})
+	}
+}
+
+// TestServerChunkedOffsetGet tests chunked DoGet responses starting from a row offset
+func TestServerChunkedOffsetGet(t *testing.T) {
+	server, err := NewFlightServer(FlightServerConfig{ChunkRows: 2})
+	require.NoError(t, err)
+	defer server.Stop()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+	batchID := server.StoreBatch(batch)
+
+	raw, err := ticket{BatchID: batchID, Offset: 1}.encode()
+	require.NoError(t, err)
+
+	stream := &recordingGetStream{}
+	require.NoError(t, server.DoGet(&flight.Ticket{Ticket: raw}, stream))
+
+	reader, err := flight.NewRecordReader(stream)
+	require.NoError(t, err)
+	defer reader.Release()
+
+	var sizes []int64
+	for reader.Next() {
+		sizes = append(sizes, reader.Record().NumRows())
+	}
+	assert.Equal(t, []int64{2, 2}, sizes, "Rows 1-4 should arrive in chunks of two")
+
+	raw, err = ticket{BatchID: batchID, Offset: 6}.encode()
+	require.NoError(t, err)
+	err = server.DoGet(&flight.Ticket{Ticket: raw}, &recordingGetStream{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+// recordingGetStream captures the messages a server sends on a DoGet stream
+type recordingGetStream struct {
+	flight.FlightService_DoGetServer
+	messages []*flight.FlightData
+}
+
+// Send records a copy of the message, which the writer may reuse
+func (s *recordingGetStream) Send(data *flight.FlightData) error {
+	s.messages = append(s.messages, proto.Clone(data).(*flight.FlightData))
+	return nil
+}
+
+// Recv replays the recorded messages
+func (s *recordingGetStream) Recv() (*flight.FlightData, error) {
+	if len(s.messages) == 0 {
+		return nil, io.EOF
+	}
+	data := s.messages[0]
+	s.messages = s.messages[1:]
+	return data, nil
+}
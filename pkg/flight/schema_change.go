@@ -0,0 +1,74 @@
+package flight
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+)
+
+// schemaSplitter divides a DoGet stream at the schema messages that start
+// each IPC stream after the first, so a record reader ends cleanly where a
+// server changes the schema part way instead of failing on an unexpected
+// message
+type schemaSplitter struct {
+	flight.DataStreamReader
+	started bool               // A schema message has been returned
+	pending *flight.FlightData // The schema message that ended the last reader
+}
+
+// Recv returns the next message of the current IPC stream, or io.EOF at a
+// schema change
+func (r *schemaSplitter) Recv() (*flight.FlightData, error) {
+	if pending := r.pending; pending != nil {
+		r.pending = nil
+		return pending, nil
+	}
+
+	data, err := r.DataStreamReader.Recv()
+	if err != nil || !isSchemaMessage(data) {
+		return data, err
+	}
+	if !r.started {
+		r.started = true
+		return data, nil
+	}
+	r.pending = data
+	return nil, io.EOF
+}
+
+// changed reports whether the last reader ended at a schema change
+func (r *schemaSplitter) changed() bool {
+	return r.pending != nil
+}
+
+// isSchemaMessage reports whether data carries an IPC schema message
+func isSchemaMessage(data *flight.FlightData) bool {
+	if len(data.DataHeader) == 0 {
+		return false
+	}
+	msg := ipc.NewMessage(memory.NewBufferBytes(data.DataHeader), memory.NewBufferBytes(data.DataBody))
+	defer msg.Release()
+	return msg.Type() == ipc.MessageSchema
+}
+
+// changeSchema switches the stream to the IPC stream that follows a schema
+// change, failing with ErrSchemaChanged unless GetOptions.AllowSchemaChange is
+// set
+func (s *BatchStream) changeSchema() error {
+	reader, err := flight.NewRecordReader(s.split, s.client.readerOptions(s.allocator)...)
+	if err != nil {
+		return fmt.Errorf("failed to read changed schema: %w", err)
+	}
+	previous := s.reader.Schema()
+	s.reader.Release()
+	s.reader = reader
+
+	if !s.options.AllowSchemaChange {
+		return fmt.Errorf("%w after %d rows: from %s to %s", ErrSchemaChanged, s.rows, previous, reader.Schema())
+	}
+	_, err = s.deriveSchema(reader.Schema())
+	return err
+}
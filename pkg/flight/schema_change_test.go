@@ -0,0 +1,108 @@
+package flight
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// evolvingServer is a Flight server whose DoGet sends its records as
+// consecutive IPC streams, starting a new one whenever the schema changes
+type evolvingServer struct {
+	flight.BaseFlightServer
+	records []arrow.Record
+}
+
+// DoGet sends every record, starting a new IPC stream at each schema change
+func (s *evolvingServer) DoGet(ticket *flight.Ticket, stream flight.FlightService_DoGetServer) error {
+	var writer *flight.Writer
+	var schema *arrow.Schema
+	for _, rec := range s.records {
+		if writer == nil || !schema.Equal(rec.Schema()) {
+			if writer != nil {
+				writer.Close()
+			}
+			schema = rec.Schema()
+			writer = flight.NewRecordWriter(stream, ipc.WithSchema(schema))
+		}
+		if err := writer.Write(rec); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// TestGetBatchStreamSchemaChange tests downloads whose schema changes part way
+func TestGetBatchStreamSchemaChange(t *testing.T) {
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	id := batch.Column(0)
+	widened := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int32},
+		{Name: "extra", Type: arrow.BinaryTypes.String, Nullable: true},
+	}, nil)
+	extra := array.MakeArrayOfNull(memory.NewGoAllocator(), arrow.BinaryTypes.String, id.Len())
+	defer extra.Release()
+	changed := array.NewRecord(widened, []arrow.Array{id, extra}, batch.NumRows())
+	defer changed.Release()
+
+	addr := startBareServer(t, &evolvingServer{records: []arrow.Record{batch, batch, changed}})
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("rejected by default", func(t *testing.T) {
+		stream, err := client.GetBatchStream(ctx, "evolving")
+		require.NoError(t, err, "Failed to open stream")
+		defer stream.Close()
+
+		for i := 0; i < 2; i++ {
+			rec, err := stream.Next()
+			require.NoError(t, err, "Records before the change should be read")
+			rec.Release()
+		}
+		_, err = stream.Next()
+		assert.ErrorIs(t, err, ErrSchemaChanged)
+		assert.ErrorContains(t, err, "after 10 rows")
+	})
+
+	t.Run("allowed", func(t *testing.T) {
+		stream, err := client.GetBatchStreamWithOptions(ctx, "evolving", GetOptions{AllowSchemaChange: true})
+		require.NoError(t, err, "Failed to open stream")
+		defer stream.Close()
+		assert.True(t, batch.Schema().Equal(stream.Schema()))
+
+		var schemas []*arrow.Schema
+		for {
+			rec, err := stream.Next()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err, "Failed to read record")
+			schemas = append(schemas, rec.Schema())
+			rec.Release()
+		}
+		require.Len(t, schemas, 3)
+		assert.True(t, batch.Schema().Equal(schemas[1]))
+		assert.True(t, widened.Equal(schemas[2]), "The last record should have the new schema")
+	})
+
+	t.Run("combined download", func(t *testing.T) {
+		_, err := client.GetBatchWithOptions(ctx, "evolving", GetOptions{AllowSchemaChange: true})
+		assert.ErrorIs(t, err, ErrSchemaChanged)
+	})
+}
@@ -0,0 +1,147 @@
+package flight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ActionGetSchemaID is the DoAction type used to fetch the registry schema ID
+// recorded for a batch. The action body is the batch ID and the result body
+// is the schema ID, empty if none was recorded.
+const ActionGetSchemaID = "schemaid"
+
+// ErrSchemaRejected is returned when a SchemaRegistry refuses the schema of an
+// upload. Nothing is sent to the server.
+var ErrSchemaRejected = errors.New("schema rejected by registry")
+
+// SchemaRegistry validates and registers the schemas of uploads against a
+// central registry (FlightClientConfig.SchemaRegistry). Adapters for
+// registries such as Confluent or Apicurio implement it; MemorySchemaRegistry
+// is an in-process implementation. Implementations must be safe for
+// concurrent use.
+type SchemaRegistry interface {
+	// Validate returns an error if the registry does not accept schema
+	Validate(ctx context.Context, schema *arrow.Schema) error
+	// Register records schema and returns its ID. Registering an equal
+	// schema again returns the same ID.
+	Register(ctx context.Context, schema *arrow.Schema) (string, error)
+}
+
+// MemorySchemaRegistry is an in-memory SchemaRegistry assigning sequential
+// IDs. Schemas are compared by field names, types and nullability; metadata
+// is ignored.
+type MemorySchemaRegistry struct {
+	// AutoRegister makes Validate accept schemas not yet registered, which
+	// the upload then registers. Without it only schemas passed to Register
+	// ahead of time are accepted.
+	AutoRegister bool
+
+	mu      sync.Mutex
+	ids     map[string]string // Schema key to ID
+	schemas map[string]*arrow.Schema
+}
+
+// Validate implements SchemaRegistry
+func (r *MemorySchemaRegistry) Validate(ctx context.Context, schema *arrow.Schema) error {
+	if r.AutoRegister {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.ids[schemaKey(schema)]; !ok {
+		return fmt.Errorf("schema %s is not registered", schema)
+	}
+	return nil
+}
+
+// Register implements SchemaRegistry
+func (r *MemorySchemaRegistry) Register(ctx context.Context, schema *arrow.Schema) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := schemaKey(schema)
+	if id, ok := r.ids[key]; ok {
+		return id, nil
+	}
+	if r.ids == nil {
+		r.ids = make(map[string]string)
+		r.schemas = make(map[string]*arrow.Schema)
+	}
+	id := strconv.Itoa(len(r.ids) + 1)
+	r.ids[key] = id
+	r.schemas[id] = schema
+	return id, nil
+}
+
+// schemaKey identifies a schema by its fields, ignoring metadata
+func schemaKey(schema *arrow.Schema) string {
+	if key := schema.Fingerprint(); key != "" {
+		return key
+	}
+	// Arrow cannot fingerprint every type
+	return arrow.NewSchema(schema.Fields(), nil).String()
+}
+
+// Lookup returns the schema registered under id
+func (r *MemorySchemaRegistry) Lookup(id string) (*arrow.Schema, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	schema, ok := r.schemas[id]
+	return schema, ok
+}
+
+// registerSchema validates and registers the schema of an upload with the
+// client's registry, returning its ID, or "" without a registry
+func (c *FlightClient) registerSchema(ctx context.Context, schema *arrow.Schema) (string, error) {
+	if c.schemaRegistry == nil {
+		return "", nil
+	}
+	if err := c.schemaRegistry.Validate(ctx, schema); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrSchemaRejected, err)
+	}
+	id, err := c.schemaRegistry.Register(ctx, schema)
+	if err != nil {
+		return "", fmt.Errorf("failed to register schema: %w", err)
+	}
+	return id, nil
+}
+
+// getSchemaID returns the registry schema ID recorded for a batch
+func (s *FlightServer) getSchemaID(batchID string, stream flight.FlightService_DoActionServer) error {
+	if err := s.authorize(stream.Context(), batchID); err != nil {
+		return err
+	}
+
+	s.batchesMu.RLock()
+	if target, ok := s.names[batchID]; ok {
+		batchID = target
+	}
+	_, ok := s.batches[batchID]
+	schemaID := s.schemaIDs[batchID]
+	s.batchesMu.RUnlock()
+
+	if !ok {
+		return status.Errorf(codes.NotFound, "batch with ID %s not found", batchID)
+	}
+	return stream.Send(&flight.Result{Body: []byte(schemaID)})
+}
+
+// GetSchemaID returns the registry schema ID recorded when a batch was
+// uploaded by a client with a SchemaRegistry, so consumers can resolve the
+// schema in the registry. It returns "" if none was recorded.
+func (c *FlightClient) GetSchemaID(ctx context.Context, batchID string) (string, error) {
+	body, err := c.doAction(ctx, ActionGetSchemaID, []byte(batchID))
+	if err != nil {
+		return "", fmt.Errorf("failed to get schema ID for batch %s: %w", batchID, err)
+	}
+	return string(body), nil
+}
@@ -0,0 +1,89 @@
+package flight
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSchemaRegistryValidation tests that uploads with unregistered schemas are refused before sending
+func TestSchemaRegistryValidation(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	registry := &MemorySchemaRegistry{}
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr, SchemaRegistry: registry})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	// Validation fails
+	_, err = client.PutBatch(ctx, batch)
+	assert.ErrorIs(t, err, ErrSchemaRejected)
+	batchIDs, err := client.ListBatches(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, batchIDs, "Rejected uploads should not be sent")
+
+	// Validation passes once the schema is registered
+	schemaID, err := registry.Register(ctx, batch.Schema())
+	require.NoError(t, err)
+	batchID, err := client.PutBatch(ctx, batch)
+	require.NoError(t, err, "Registered schemas should be accepted")
+
+	recorded, err := client.GetSchemaID(ctx, batchID)
+	require.NoError(t, err, "Failed to get schema ID")
+	assert.Equal(t, schemaID, recorded)
+}
+
+// TestSchemaRegistryRegistration tests that uploads register their schemas and record the IDs
+func TestSchemaRegistryRegistration(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	registry := &MemorySchemaRegistry{AutoRegister: true}
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr, SchemaRegistry: registry})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+	other := createCompressibleBatch(t, 10)
+	defer other.Release()
+
+	var ids []string
+	for _, rec := range []arrow.Record{batch, batch, other} {
+		batchID, err := client.PutBatch(ctx, rec)
+		require.NoError(t, err, "Failed to put batch")
+		schemaID, err := client.GetSchemaID(ctx, batchID)
+		require.NoError(t, err, "Failed to get schema ID")
+		ids = append(ids, schemaID)
+	}
+	assert.Equal(t, []string{"1", "1", "2"}, ids, "Equal schemas should share an ID")
+
+	schema, ok := registry.Lookup("2")
+	require.True(t, ok, "Consumers should resolve recorded IDs")
+	assert.True(t, schema.Equal(other.Schema()))
+
+	// Batches uploaded without a registry have no schema ID
+	plain, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer plain.Close()
+	batchID, err := plain.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to put batch")
+	schemaID, err := plain.GetSchemaID(ctx, batchID)
+	require.NoError(t, err)
+	assert.Empty(t, schemaID)
+}
@@ -0,0 +1,72 @@
+package flight
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSchemaMetadataRoundTrip tests that field and schema metadata survive PutBatch, GetBatch and GetSchema
+func TestSchemaMetadataRoundTrip(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	schemaMeta := arrow.NewMetadata([]string{"source", "version"}, []string{"sensors", "3"})
+	schema := arrow.NewSchema(
+		[]arrow.Field{
+			{
+				Name:     "temperature",
+				Type:     arrow.PrimitiveTypes.Float64,
+				Nullable: true,
+				Metadata: arrow.NewMetadata([]string{"unit", "description"}, []string{"celsius", "Ambient temperature"}),
+			},
+			{Name: "sensor", Type: arrow.BinaryTypes.String},
+		},
+		&schemaMeta,
+	)
+
+	builder := array.NewRecordBuilder(memory.NewGoAllocator(), schema)
+	defer builder.Release()
+	builder.Field(0).(*array.Float64Builder).AppendValues([]float64{21.5, 22.0}, nil)
+	builder.Field(1).(*array.StringBuilder).AppendValues([]string{"a", "b"}, nil)
+	batch := builder.NewRecord()
+	defer batch.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batchID, err := client.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to put batch")
+
+	retrieved, err := client.GetBatch(ctx, batchID)
+	require.NoError(t, err, "Failed to get batch")
+	defer retrieved.Release()
+	assertSchemaMetadata(t, schema, retrieved.Schema())
+
+	fetched, err := client.GetSchema(ctx, batchID)
+	require.NoError(t, err, "Failed to get schema")
+	assertSchemaMetadata(t, schema, fetched)
+
+	_, err = client.GetSchema(ctx, "missing")
+	assert.Error(t, err, "Unknown batches should fail")
+}
+
+// assertSchemaMetadata checks that a schema matches the expected one including all metadata
+func assertSchemaMetadata(t *testing.T, expected, actual *arrow.Schema) {
+	t.Helper()
+	assert.True(t, expected.Equal(actual), "Schemas should match")
+	assert.Equal(t, expected.Metadata().ToMap(), actual.Metadata().ToMap(), "Schema metadata should match")
+	for i, field := range expected.Fields() {
+		assert.Equal(t, field.Metadata.ToMap(), actual.Field(i).Metadata.ToMap(), "Metadata of field %s should match", field.Name)
+	}
+}
@@ -2,31 +2,54 @@ package flight
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/apache/arrow-go/v18/arrow"
 	"github.com/apache/arrow-go/v18/arrow/flight"
 	"github.com/apache/arrow-go/v18/arrow/ipc"
 	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/arrow/util"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	arrow_utils "github.com/TFMV/temporal/pkg/arrow"
 )
 
 // FlightServer implements a simple Arrow Flight server for sharing Arrow RecordBatches
 // between Temporal activities with minimal serialization.
 type FlightServer struct {
 	flight.BaseFlightServer
-	server      *grpc.Server
-	listener    net.Listener
-	addr        string
-	batches     map[string]arrow.Record
-	batchesMu   sync.RWMutex
-	allocator   memory.Allocator
-	expirations map[string]time.Time
-	ttl         time.Duration
-	cancel      context.CancelFunc // Cancel function for cleanup goroutine
+	server       *grpc.Server
+	listener     net.Listener
+	addr         string
+	batches      map[string]arrow.Record
+	batchesMu    sync.RWMutex
+	allocator    memory.Allocator
+	expirations  map[string]time.Time
+	lineage      map[string][]string // Parent batch IDs recorded for derived batches
+	names        map[string]string   // Stable names pointing at batch IDs
+	policies     map[string]*AccessPolicy
+	schemaIDs    map[string]string // Registry schema IDs recorded by clients
+	contents     map[string]string // Batch IDs by content hash, for deduplication
+	transactions map[string]*transaction
+	ttl          time.Duration
+	chunkRows    int64
+	cancel       context.CancelFunc // Cancel function for cleanup goroutine
+	done         <-chan struct{}    // Closed when the server is stopping
+	watchers     map[*batchWatcher]struct{}
+	watchersMu   sync.Mutex
+
+	sessionCommands  map[string]SessionCommand
+	transforms       map[string]RecordTransform
+	resolvePrincipal PrincipalResolver
+	ticketSigner     TicketSigner
 }
 
 // FlightServerConfig contains configuration options for the Flight server
@@ -37,6 +60,38 @@ type FlightServerConfig struct {
 	Allocator memory.Allocator
 	// TTL for stored batches (default: 1 hour)
 	TTL time.Duration
+	// MaxMessageSize is the largest gRPC message the server sends or receives
+	// (default: 64 MiB), which bounds the size of a record batch
+	MaxMessageSize int
+	// InitialWindowSize and InitialConnWindowSize fix the HTTP/2 flow
+	// control windows of each stream and of each connection, in bytes,
+	// instead of letting gRPC size them dynamically (see the
+	// FlightClientConfig fields of the same names)
+	InitialWindowSize     int32
+	InitialConnWindowSize int32
+	// ChunkRows splits DoGet responses into record batches of at most this many
+	// rows, so interrupted downloads can be resumed part way (default: 0, one
+	// record batch per stored batch)
+	ChunkRows int64
+	// SessionCommands registers the commands clients can run over a Session,
+	// keyed by name. The built-in "get" command can be overridden.
+	SessionCommands map[string]SessionCommand
+	// ResolvePrincipal maps the access token sent with a request to the
+	// principal checked against the AccessPolicy of restricted batches. The
+	// default treats the token itself as the principal.
+	ResolvePrincipal PrincipalResolver
+	// Transforms registers the functions clients can apply to a batch while
+	// downloading it (GetBatchWithPipeline), keyed by name
+	Transforms map[string]RecordTransform
+	// TicketSigner, if set, makes DoGet refuse tickets that fail its
+	// verification with codes.Unauthenticated, and signs the tickets of the
+	// endpoints the server lists. The other reads of a batch's data or
+	// schema, GetFlightInfo, GetSchema, the null count and fingerprint
+	// actions and the session get command, likewise require the batch ID
+	// signed. Clients must sign with the same signer
+	// (FlightClientConfig.TicketSigner). Requests that only manage batches,
+	// such as name updates or drops, are governed by access policies instead.
+	TicketSigner TicketSigner
 }
 
 // NewFlightServer creates a new Arrow Flight server
@@ -50,21 +105,51 @@ func NewFlightServer(config FlightServerConfig) (*FlightServer, error) {
 	if config.TTL == 0 {
 		config.TTL = 1 * time.Hour
 	}
+	if config.ResolvePrincipal == nil {
+		config.ResolvePrincipal = tokenPrincipal
+	}
+	if config.MaxMessageSize == 0 {
+		config.MaxMessageSize = defaultMaxMessageSize
+	}
 
 	// Create the server without starting the listener yet
 	server := &FlightServer{
-		addr:        config.Addr,
-		batches:     make(map[string]arrow.Record),
-		expirations: make(map[string]time.Time),
-		allocator:   config.Allocator,
-		ttl:         config.TTL,
+		addr:         config.Addr,
+		batches:      make(map[string]arrow.Record),
+		expirations:  make(map[string]time.Time),
+		lineage:      make(map[string][]string),
+		names:        make(map[string]string),
+		policies:     make(map[string]*AccessPolicy),
+		schemaIDs:    make(map[string]string),
+		contents:     make(map[string]string),
+		transactions: make(map[string]*transaction),
+		allocator:    config.Allocator,
+		ttl:          config.TTL,
+		chunkRows:    config.ChunkRows,
+		watchers:     make(map[*batchWatcher]struct{}),
+
+		transforms:       config.Transforms,
+		resolvePrincipal: config.ResolvePrincipal,
+		ticketSigner:     config.TicketSigner,
+	}
+
+	server.sessionCommands = map[string]SessionCommand{SessionCommandGet: server.sessionGet}
+	for name, command := range config.SessionCommands {
+		server.sessionCommands[name] = command
 	}
 
 	// Create a gRPC server with appropriate options
-	server.server = grpc.NewServer(
-		grpc.MaxRecvMsgSize(64*1024*1024), // 64MB max message size
-		grpc.MaxSendMsgSize(64*1024*1024), // 64MB max message size
-	)
+	opts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(config.MaxMessageSize),
+		grpc.MaxSendMsgSize(config.MaxMessageSize),
+	}
+	if config.InitialWindowSize > 0 {
+		opts = append(opts, grpc.InitialWindowSize(config.InitialWindowSize))
+	}
+	if config.InitialConnWindowSize > 0 {
+		opts = append(opts, grpc.InitialConnWindowSize(config.InitialConnWindowSize))
+	}
+	server.server = grpc.NewServer(opts...)
 
 	// Register the Flight service
 	flight.RegisterFlightServiceServer(server.server, server)
@@ -72,6 +157,7 @@ func NewFlightServer(config FlightServerConfig) (*FlightServer, error) {
 	// Start a goroutine to clean up expired batches
 	ctx, cancel := context.WithCancel(context.Background())
 	server.cancel = cancel
+	server.done = ctx.Done()
 	go server.cleanupExpiredBatches(ctx)
 
 	return server, nil
@@ -105,10 +191,11 @@ func (s *FlightServer) Stop() {
 
 	// Clear all batches to release memory
 	s.batchesMu.Lock()
-	for id, batch := range s.batches {
-		batch.Release()
-		delete(s.batches, id)
-		delete(s.expirations, id)
+	for id := range s.batches {
+		s.removeBatchLocked(id)
+	}
+	for id := range s.transactions {
+		s.rollbackTxLocked(id)
 	}
 	s.batchesMu.Unlock()
 
@@ -127,18 +214,31 @@ func (s *FlightServer) Stop() {
 
 // GetFlightInfo implements the Flight GetFlightInfo method
 func (s *FlightServer) GetFlightInfo(ctx context.Context, request *flight.FlightDescriptor) (*flight.FlightInfo, error) {
-	cmd := string(request.Cmd)
-
-	s.batchesMu.RLock()
-	batch, ok := s.batches[cmd]
-	s.batchesMu.RUnlock()
+	cmd, err := s.commandBatchID(request.Cmd)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, cmd); err != nil {
+		return nil, err
+	}
 
+	batch, ok := s.acquireBatch(cmd)
 	if !ok {
-		return nil, fmt.Errorf("batch with ID %s not found", cmd)
+		return nil, status.Errorf(codes.NotFound, "batch with ID %s not found", cmd)
 	}
+	defer batch.Release()
 
+	return s.flightInfo(cmd, batch, request), nil
+}
+
+// flightInfo describes a stored batch
+func (s *FlightServer) flightInfo(batchID string, batch arrow.Record, descriptor *flight.FlightDescriptor) *flight.FlightInfo {
+	raw := []byte(batchID)
+	if s.ticketSigner != nil {
+		raw = s.ticketSigner.Sign(raw)
+	}
 	endpoint := &flight.FlightEndpoint{
-		Ticket: &flight.Ticket{Ticket: []byte(cmd)},
+		Ticket: &flight.Ticket{Ticket: raw},
 		Location: []*flight.Location{
 			{Uri: fmt.Sprintf("grpc://%s", s.addr)},
 		},
@@ -146,33 +246,97 @@ func (s *FlightServer) GetFlightInfo(ctx context.Context, request *flight.Flight
 
 	return &flight.FlightInfo{
 		Schema:           flight.SerializeSchema(batch.Schema(), s.allocator),
-		FlightDescriptor: request,
+		FlightDescriptor: descriptor,
 		Endpoint:         []*flight.FlightEndpoint{endpoint},
 		TotalRecords:     batch.NumRows(),
-		TotalBytes:       -1, // Unknown size
+		TotalBytes:       util.TotalRecordSize(batch),
+	}
+}
+
+// GetSchema implements the Flight GetSchema method
+func (s *FlightServer) GetSchema(ctx context.Context, request *flight.FlightDescriptor) (*flight.SchemaResult, error) {
+	cmd, err := s.commandBatchID(request.Cmd)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, cmd); err != nil {
+		return nil, err
+	}
+
+	batch, ok := s.acquireBatch(cmd)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "batch with ID %s not found", cmd)
+	}
+	defer batch.Release()
+
+	return &flight.SchemaResult{
+		Schema: flight.SerializeSchema(batch.Schema(), s.allocator),
 	}, nil
 }
 
 // DoGet implements the Flight DoGet method
 func (s *FlightServer) DoGet(request *flight.Ticket, stream flight.FlightService_DoGetServer) error {
-	batchID := string(request.Ticket)
-
-	s.batchesMu.RLock()
-	batch, ok := s.batches[batchID]
-	s.batchesMu.RUnlock()
+	raw := request.Ticket
+	if s.ticketSigner != nil {
+		var err error
+		if raw, err = s.ticketSigner.Verify(raw); err != nil {
+			return status.Errorf(codes.Unauthenticated, "invalid ticket: %v", err)
+		}
+	}
+	t, err := decodeTicket(raw)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if id, policy := s.accessPolicy(t.BatchID); policy != nil {
+		if err := s.checkAccess(stream.Context(), id, policy); err != nil {
+			return err
+		}
+	}
 
+	batch, ok := s.acquireBatch(t.BatchID)
 	if !ok {
-		return fmt.Errorf("batch with ID %s not found", batchID)
+		return status.Errorf(codes.NotFound, "batch with ID %s not found", t.BatchID)
+	}
+	defer batch.Release()
+
+	if len(t.Pipeline) > 0 {
+		transformed, err := s.runPipeline(stream.Context(), batch, t.Pipeline)
+		if err != nil {
+			return err
+		}
+		defer transformed.Release()
+		batch = transformed
+	}
+	if len(t.Columns) > 0 {
+		projected, err := arrow_utils.SelectColumns(batch, t.Columns)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		defer projected.Release()
+		batch = projected
+	}
+
+	if t.Offset > batch.NumRows() {
+		return status.Errorf(codes.InvalidArgument, "offset %d is beyond the %d rows of batch %s", t.Offset, batch.NumRows(), t.BatchID)
 	}
 
 	// Create a writer for the stream
 	writer := flight.NewRecordWriter(stream, ipc.WithSchema(batch.Schema()))
 
-	// Write the batch to the stream and handle errors
-	if err := writer.Write(batch); err != nil {
-		// Make sure to close the writer even if writing fails
-		writer.Close()
-		return fmt.Errorf("failed to write batch to stream: %w", err)
+	// Write the rows from the offset onwards, in chunks if configured
+	chunkRows := s.chunkRows
+	if chunkRows <= 0 {
+		chunkRows = max(batch.NumRows()-t.Offset, 1)
+	}
+	for offset := t.Offset; offset < batch.NumRows(); offset += chunkRows {
+		chunk := batch.NewSlice(offset, min(offset+chunkRows, batch.NumRows()))
+		err := writer.Write(chunk)
+		chunk.Release()
+		if err != nil {
+			// Make sure to close the writer even if writing fails
+			writer.Close()
+			return fmt.Errorf("failed to write batch to stream: %w", err)
+		}
 	}
 
 	// Close the writer to signal the end of the stream
@@ -196,24 +360,56 @@ func (s *FlightServer) DoPut(stream flight.FlightService_DoPutServer) error {
 		return fmt.Errorf("missing flight descriptor in first message")
 	}
 
-	// Create a reader for the stream
-	reader, err := flight.NewRecordReader(stream)
+	// Decode any structured metadata sent along with the descriptor
+	var meta putMetadata
+	if len(firstMsg.AppMetadata) > 0 {
+		if err := json.Unmarshal(firstMsg.AppMetadata, &meta); err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid put metadata: %v", err)
+		}
+	}
+
+	// Create a reader for the stream. Uploads that send the descriptor with
+	// the schema, as the reference implementations do, have their first
+	// message read again as the start of the data.
+	var data flight.DataStreamReader = stream
+	if len(firstMsg.DataHeader) > 0 {
+		data = &replayReader{DataStreamReader: stream, first: firstMsg}
+	}
+	reader, err := flight.NewRecordReader(data)
 	if err != nil {
 		return fmt.Errorf("failed to create record reader: %w", err)
 	}
 	defer reader.Release()
 
-	// Read the first record
-	if !reader.Next() {
+	var batch arrow.Record
+	if meta.Streamed {
+		// Streamed uploads end when the client half-closes
+		var batches []arrow.Record
+		for reader.Next() {
+			rec := reader.Record()
+			rec.Retain()
+			batches = append(batches, rec)
+		}
 		if err := reader.Err(); err != nil {
+			releaseRecords(batches)
 			return fmt.Errorf("error reading record: %w", err)
 		}
-		return fmt.Errorf("no record received")
-	}
+		if batch, err = combineRecords(s.allocator, reader.Schema(), batches); err != nil {
+			return fmt.Errorf("failed to combine streamed records: %w", err)
+		}
+	} else {
+		// Read the first record
+		if !reader.Next() {
+			if err := reader.Err(); err != nil {
+				return fmt.Errorf("error reading record: %w", err)
+			}
+			return fmt.Errorf("no record received")
+		}
 
-	// Get the record and retain it
-	batch := reader.Record()
-	batch.Retain() // Retain the batch so it's not released when the reader is released
+		// Get the record and retain it
+		batch = reader.Record()
+		batch.Retain() // Retain the batch so it's not released when the reader is released
+	}
 	defer func() {
 		// If we exit with an error, make sure to release the batch
 		if batch != nil {
@@ -221,68 +417,225 @@ func (s *FlightServer) DoPut(stream flight.FlightService_DoPutServer) error {
 		}
 	}()
 
-	// Generate a unique ID for the batch
-	batchID := generateBatchID()
+	// Apply a delta upload to its base batch
+	if meta.Delta != nil {
+		// A delta reads its base, so it needs the base's access
+		if id, policy := s.accessPolicy(meta.Delta.BaseID); policy != nil {
+			if err := s.checkAccess(stream.Context(), id, policy); err != nil {
+				return err
+			}
+		}
+		merged, err := s.applyDelta(meta.Delta, batch)
+		if err != nil {
+			return err
+		}
+		batch.Release()
+		batch = merged
+	}
+
+	// Only deduplicate uploads whose claimed hash matches their contents
+	dedupe := meta.ContentHash != "" && meta.Delta == nil && meta.Access == nil && meta.TxID == ""
+	if dedupe {
+		fingerprint, err := RecordFingerprint(batch)
+		if err != nil {
+			return err
+		}
+		if fingerprint != meta.ContentHash {
+			return status.Errorf(codes.InvalidArgument, "content hash %s does not match the uploaded batch", meta.ContentHash)
+		}
+	}
+
+	// Store the batch, or stage it until its transaction commits. An upload
+	// reusing the ID the client named a stored batch with is a retry: the
+	// stored batch is kept and its lifetime extended, provided the retry asks
+	// for the same access policy. So is an unrestricted batch with the same
+	// contents as a deduplicated upload, unless the client named the upload:
+	// it must then be readable under that name. Other uploads get an ID no
+	// batch has, so they are never mistaken for a retry.
+	// Staged uploads must come from the transaction's principal
+	var principal string
+	if meta.TxID != "" {
+		if principal, err = s.requestPrincipal(stream.Context()); err != nil {
+			return err
+		}
+	}
 
-	// Store the batch
+	batchID := meta.BatchID
 	s.batchesMu.Lock()
-	s.batches[batchID] = batch
-	s.expirations[batchID] = time.Now().Add(s.ttl)
+	deduped := false
+	if dedupe && meta.BatchID == "" {
+		if id, ok := s.contents[meta.ContentHash]; ok && s.policies[id] == nil {
+			batchID, deduped = id, true
+		}
+	}
+	if batchID == "" {
+		batchID = s.newBatchIDLocked(meta.TxID)
+	}
+	var retried bool
+	if meta.TxID != "" {
+		if retried, err = s.stageBatchLocked(meta.TxID, principal, batchID, batch, meta); err != nil {
+			s.batchesMu.Unlock()
+			return err
+		}
+	} else {
+		_, retried = s.batches[batchID]
+		if retried {
+			if err := checkRetryPolicy(batchID, s.policies[batchID], meta.Access); err != nil {
+				s.batchesMu.Unlock()
+				return err
+			}
+		} else {
+			s.storeBatchLocked(batchID, batch, meta)
+			if dedupe {
+				s.contents[meta.ContentHash] = batchID
+			}
+		}
+		s.expirations[batchID] = time.Now().Add(s.ttl)
+	}
 	s.batchesMu.Unlock()
 
-	// We've successfully stored the batch, so don't release it on exit
-	batch = nil
+	if !retried {
+		// We've successfully stored the batch, so don't release it on exit
+		batch = nil
+		if meta.TxID == "" {
+			s.notifyWatchers(batchID)
+		}
+	}
 
-	// Send the batch ID back to the client
+	// Send the batch ID back to the client, acknowledging deltas, streamed
+	// uploads, access policies, deduplication and retries explicitly. IDs
+	// that look like JSON are always wrapped so the client can tell them from
+	// an acknowledgement.
+	result := []byte(batchID)
+	if meta.Delta != nil || meta.Streamed || meta.Access != nil || retried || strings.HasPrefix(batchID, "{") {
+		ack := putResult{BatchID: batchID, Delta: meta.Delta != nil, Streamed: meta.Streamed, Access: meta.Access != nil, Deduped: deduped, Existing: retried}
+		if result, err = json.Marshal(ack); err != nil {
+			return fmt.Errorf("failed to encode put result: %w", err)
+		}
+	}
 	err = stream.Send(&flight.PutResult{
-		AppMetadata: []byte(batchID),
+		AppMetadata: result,
 	})
 	if err != nil {
-		// If we fail to send the result, remove the batch from storage
-		s.batchesMu.Lock()
-		if storedBatch, ok := s.batches[batchID]; ok {
-			storedBatch.Release()
-			delete(s.batches, batchID)
-			delete(s.expirations, batchID)
+		// If we fail to send the result, remove the batch from storage unless
+		// an earlier upload stored it
+		if !retried {
+			s.batchesMu.Lock()
+			if meta.TxID != "" {
+				s.unstageBatchLocked(meta.TxID, batchID)
+			} else {
+				s.removeBatchLocked(batchID)
+			}
+			s.batchesMu.Unlock()
 		}
-		s.batchesMu.Unlock()
 		return fmt.Errorf("failed to send result: %w", err)
 	}
 
 	return nil
 }
 
-// ListFlights implements the Flight ListFlights method
+// ListFlights implements the Flight ListFlights method. A JSON criteria
+// expression lists the batches passing its ListFilter, and with a page size
+// lists one page of them (see ListBatchesPage). Restricted batches are only
+// listed to requests allowed to read them.
 func (s *FlightServer) ListFlights(request *flight.Criteria, stream flight.FlightService_ListFlightsServer) error {
+	criteria, err := decodeListCriteria(request.GetExpression())
+	if err != nil {
+		return err
+	}
+	if criteria.PageSize > 0 {
+		return s.listPage(criteria, stream)
+	}
+
 	s.batchesMu.RLock()
 	defer s.batchesMu.RUnlock()
 
 	for batchID, batch := range s.batches {
+		if !criteria.matches(batchID, batch) || !s.canRead(stream.Context(), batchID) {
+			continue
+		}
 		descriptor := &flight.FlightDescriptor{
 			Type: flight.DescriptorCMD,
 			Cmd:  []byte(batchID),
 		}
 
-		endpoint := &flight.FlightEndpoint{
-			Ticket: &flight.Ticket{Ticket: []byte(batchID)},
-			Location: []*flight.Location{
-				{Uri: fmt.Sprintf("grpc://%s", s.addr)},
-			},
+		if err := stream.Send(s.flightInfo(batchID, batch, descriptor)); err != nil {
+			return err
 		}
+	}
 
-		info := &flight.FlightInfo{
-			Schema:           flight.SerializeSchema(batch.Schema(), s.allocator),
-			FlightDescriptor: descriptor,
-			Endpoint:         []*flight.FlightEndpoint{endpoint},
-			TotalRecords:     batch.NumRows(),
-			TotalBytes:       -1, // Unknown size
-		}
+	return nil
+}
+
+// serverActions lists the custom actions supported by DoAction
+var serverActions = []*flight.ActionType{
+	{Type: ActionWatchBatches, Description: "Stream the IDs of newly stored batches matching a prefix"},
+	{Type: ActionGetLineage, Description: "Return the parent batch IDs recorded for a batch"},
+	{Type: ActionSwapName, Description: "Atomically point a name at a batch, discarding the previous one"},
+	{Type: ActionPublishName, Description: "Atomically point a name at a batch, keeping the previous one"},
+	{Type: ActionResolveName, Description: "Return the batch ID a name points at"},
+	{Type: ActionDropBatch, Description: "Release a stored batch"},
+	{Type: ActionNullCounts, Description: "Return the null count of each column of a batch"},
+	{Type: ActionFingerprint, Description: "Return a fingerprint of a batch's contents"},
+	{Type: ActionGetSchemaID, Description: "Return the registry schema ID recorded for a batch"},
+	{Type: ActionVersion, Description: "Return the server's protocol and Arrow versions"},
+	{Type: ActionCapabilities, Description: "Return the optional features the server supports"},
+	{Type: ActionBeginTx, Description: "Open a transaction staging uploads until it commits"},
+	{Type: ActionCommitTx, Description: "Make the batches staged by a transaction visible"},
+	{Type: ActionRollbackTx, Description: "Discard the batches staged by a transaction"},
+}
 
-		if err := stream.Send(info); err != nil {
+// DoAction implements the Flight DoAction method
+func (s *FlightServer) DoAction(action *flight.Action, stream flight.FlightService_DoActionServer) error {
+	switch action.Type {
+	case ActionWatchBatches:
+		return s.watchBatches(string(action.Body), stream)
+	case ActionGetLineage:
+		return s.getLineage(string(action.Body), stream)
+	case ActionSwapName:
+		return s.pointName(stream.Context(), action.Body, false)
+	case ActionPublishName:
+		return s.pointName(stream.Context(), action.Body, true)
+	case ActionResolveName:
+		return s.resolveName(string(action.Body), stream)
+	case ActionDropBatch:
+		return s.dropBatch(stream.Context(), string(action.Body))
+	case ActionNullCounts:
+		batchID, err := s.commandBatchID(action.Body)
+		if err != nil {
+			return err
+		}
+		return s.nullCounts(batchID, stream)
+	case ActionFingerprint:
+		batchID, err := s.commandBatchID(action.Body)
+		if err != nil {
 			return err
 		}
+		return s.fingerprint(batchID, stream)
+	case ActionGetSchemaID:
+		return s.getSchemaID(string(action.Body), stream)
+	case ActionVersion:
+		return s.serverVersion(stream)
+	case ActionCapabilities:
+		return s.serverCapabilities(stream)
+	case ActionBeginTx:
+		return s.beginTx(stream)
+	case ActionCommitTx:
+		return s.commitTx(stream.Context(), string(action.Body))
+	case ActionRollbackTx:
+		return s.rollbackTx(stream.Context(), string(action.Body))
+	default:
+		return status.Errorf(codes.Unimplemented, "unknown action %q", action.Type)
 	}
+}
 
+// ListActions implements the Flight ListActions method
+func (s *FlightServer) ListActions(request *flight.Empty, stream flight.FlightService_ListActionsServer) error {
+	for _, action := range serverActions {
+		if err := stream.Send(action); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -307,42 +660,55 @@ func (s *FlightServer) performCleanup() {
 	now := time.Now()
 	var expiredIDs []string
 
-	// Find expired batches
+	var abandonedTxIDs []string
+
+	// Find expired batches, and transactions left open as long as a batch lives
 	s.batchesMu.RLock()
 	for batchID, expiration := range s.expirations {
 		if now.After(expiration) {
 			expiredIDs = append(expiredIDs, batchID)
 		}
 	}
+	for txID, tx := range s.transactions {
+		if now.After(tx.started.Add(s.ttl)) {
+			abandonedTxIDs = append(abandonedTxIDs, txID)
+		}
+	}
 	s.batchesMu.RUnlock()
 
 	// Remove expired batches
 	if len(expiredIDs) > 0 {
 		s.batchesMu.Lock()
 		for _, batchID := range expiredIDs {
-			if batch, ok := s.batches[batchID]; ok {
-				batch.Release()
-				delete(s.batches, batchID)
-				delete(s.expirations, batchID)
-			}
+			s.removeBatchLocked(batchID)
 		}
 		s.batchesMu.Unlock()
 		fmt.Printf("Cleaned up %d expired batches\n", len(expiredIDs))
 	}
+
+	// Roll back abandoned transactions
+	if len(abandonedTxIDs) > 0 {
+		s.batchesMu.Lock()
+		for _, txID := range abandonedTxIDs {
+			s.rollbackTxLocked(txID)
+		}
+		s.batchesMu.Unlock()
+		fmt.Printf("Rolled back %d abandoned transactions\n", len(abandonedTxIDs))
+	}
 }
 
 // StoreBatch stores a batch in the server and returns a unique ID
 func (s *FlightServer) StoreBatch(batch arrow.Record) string {
-	batchID := generateBatchID()
-
 	// Retain the batch so it's not released when the caller releases it
 	batch.Retain()
 
 	s.batchesMu.Lock()
+	batchID := s.newBatchIDLocked("")
 	s.batches[batchID] = batch
 	s.expirations[batchID] = time.Now().Add(s.ttl)
 	s.batchesMu.Unlock()
 
+	s.notifyWatchers(batchID)
 	return batchID
 }
 
@@ -353,7 +719,7 @@ func (s *FlightServer) RetrieveBatch(batchID string) (arrow.Record, error) {
 	s.batchesMu.RUnlock()
 
 	if !ok {
-		return nil, fmt.Errorf("batch with ID %s not found", batchID)
+		return nil, status.Errorf(codes.NotFound, "batch with ID %s not found", batchID)
 	}
 
 	// Update the expiration time
@@ -371,16 +737,105 @@ func (s *FlightServer) ReleaseBatch(batchID string) {
 	s.batchesMu.Lock()
 	defer s.batchesMu.Unlock()
 
-	if batch, ok := s.batches[batchID]; ok {
-		batch.Release()
-		delete(s.batches, batchID)
-		delete(s.expirations, batchID)
+	s.removeBatchLocked(batchID)
+}
+
+// acquireBatch looks up a batch by ID or name and retains it so a concurrent
+// replace or release cannot free it while in use. The caller must Release it.
+func (s *FlightServer) acquireBatch(batchID string) (arrow.Record, bool) {
+	s.batchesMu.RLock()
+	defer s.batchesMu.RUnlock()
+
+	if target, ok := s.names[batchID]; ok {
+		batchID = target
+	}
+	batch, ok := s.batches[batchID]
+	if !ok {
+		return nil, false
+	}
+	batch.Retain()
+	return batch, true
+}
+
+// storeBatchLocked stores an uploaded batch along with what its metadata
+// records about it. Must be called with batchesMu held.
+func (s *FlightServer) storeBatchLocked(batchID string, batch arrow.Record, meta putMetadata) {
+	s.batches[batchID] = batch
+	if len(meta.Lineage) > 0 {
+		s.lineage[batchID] = meta.Lineage
+	}
+	if meta.Access != nil {
+		s.policies[batchID] = meta.Access
+	}
+	if meta.SchemaID != "" {
+		s.schemaIDs[batchID] = meta.SchemaID
 	}
 }
 
-// generateBatchID generates a unique batch ID
+// removeBatchLocked releases a stored batch and drops everything recorded about
+// it. Must be called with batchesMu held.
+func (s *FlightServer) removeBatchLocked(batchID string) {
+	batch, ok := s.batches[batchID]
+	if !ok {
+		return
+	}
+	batch.Release()
+	delete(s.batches, batchID)
+	delete(s.expirations, batchID)
+	delete(s.lineage, batchID)
+	delete(s.policies, batchID)
+	delete(s.schemaIDs, batchID)
+	for name, target := range s.names {
+		if target == batchID {
+			delete(s.names, name)
+		}
+	}
+	for hash, target := range s.contents {
+		if target == batchID {
+			delete(s.contents, hash)
+		}
+	}
+}
+
+// replayReader returns a message already received before the rest of a stream
+type replayReader struct {
+	flight.DataStreamReader
+	first *flight.FlightData
+}
+
+// Recv returns the replayed message first
+func (r *replayReader) Recv() (*flight.FlightData, error) {
+	if first := r.first; first != nil {
+		r.first = nil
+		return first, nil
+	}
+	return r.DataStreamReader.Recv()
+}
+
+// batchSeq numbers the generated batch IDs, so that IDs generated in the same
+// nanosecond differ
+var batchSeq atomic.Uint64
+
+// generateBatchID generates a batch ID unique among the generated ones
 func generateBatchID() string {
-	return fmt.Sprintf("batch-%d", time.Now().UnixNano())
+	return fmt.Sprintf("batch-%d-%d", time.Now().UnixNano(), batchSeq.Add(1))
+}
+
+// newBatchIDLocked generates a batch ID that neither a stored batch nor one
+// staged in transaction txID, if set, has taken, since clients may name
+// batches too. Must be called with batchesMu held.
+func (s *FlightServer) newBatchIDLocked(txID string) string {
+	for {
+		batchID := generateBatchID()
+		_, stored := s.batches[batchID]
+		staged := false
+		if tx, ok := s.transactions[txID]; ok {
+			_, staged = tx.batches[batchID]
+		}
+		if !stored && !staged {
+			return batchID
+		}
+	}
 }
 
 // Serve starts the Flight server
@@ -0,0 +1,83 @@
+package flight
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// flakyListServer fails ListFlights with Unavailable a number of times before succeeding
+type flakyListServer struct {
+	flight.BaseFlightServer
+	failures int32
+	calls    atomic.Int32
+}
+
+// ListFlights fails until the configured number of failures has been reached
+func (s *flakyListServer) ListFlights(request *flight.Criteria, stream flight.FlightService_ListFlightsServer) error {
+	if s.calls.Add(1) <= s.failures {
+		return status.Error(codes.Unavailable, "try again")
+	}
+	return stream.Send(&flight.FlightInfo{
+		FlightDescriptor: &flight.FlightDescriptor{Type: flight.DescriptorCMD, Cmd: []byte("batch-1")},
+	})
+}
+
+// TestServiceConfigRetryPolicy tests that a method-level retry policy from the service config takes effect
+func TestServiceConfigRetryPolicy(t *testing.T) {
+	const serviceConfig = `{
+		"methodConfig": [{
+			"name": [{"service": "arrow.flight.protocol.FlightService", "method": "ListFlights"}],
+			"retryPolicy": {
+				"maxAttempts": 3,
+				"initialBackoff": "0.01s",
+				"maxBackoff": "0.1s",
+				"backoffMultiplier": 2,
+				"retryableStatusCodes": ["UNAVAILABLE"]
+			}
+		}]
+	}`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Without the service config the first failure is returned
+	plain := &flakyListServer{failures: 2}
+	client, err := NewFlightClient(FlightClientConfig{Addr: startBareServer(t, plain)})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	_, err = client.ListBatches(ctx)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, int32(1), plain.calls.Load())
+
+	// With it, gRPC retries transparently until the call succeeds
+	flaky := &flakyListServer{failures: 2}
+	client, err = NewFlightClient(FlightClientConfig{
+		Addr:          startBareServer(t, flaky),
+		ServiceConfig: serviceConfig,
+	})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ids, err := client.ListBatches(ctx)
+	require.NoError(t, err, "Retry policy should hide transient failures")
+	assert.Equal(t, []string{"batch-1"}, ids)
+	assert.Equal(t, int32(3), flaky.calls.Load())
+}
+
+// TestInvalidServiceConfig tests that malformed service configs are rejected at construction
+func TestInvalidServiceConfig(t *testing.T) {
+	_, err := NewFlightClient(FlightClientConfig{ServiceConfig: "{not json"})
+	assert.Error(t, err)
+
+	_, err = NewFlightClient(FlightClientConfig{ServiceConfig: `{"loadBalancingConfig": "wrong"}`})
+	assert.Error(t, err)
+}
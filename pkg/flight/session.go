@@ -0,0 +1,439 @@
+package flight
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// SessionCommandGet is the built-in session command returning a stored
+	// batch. Its params are {"batchId": "..."}.
+	SessionCommandGet = "get"
+
+	// sessionResultBuffer is the number of messages queued per unread result
+	sessionResultBuffer = 16
+)
+
+// SessionCommand handles one request received over a session. It returns the
+// records to send back, which are released once they have been sent.
+type SessionCommand func(ctx context.Context, params json.RawMessage) (array.RecordReader, error)
+
+// sessionRequest is the AppMetadata of a request sent over a session
+type sessionRequest struct {
+	ID      uint64          `json:"id"`
+	Command string          `json:"command"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// sessionResponse is the AppMetadata of every message sent back over a
+// session. The last message of a result has Done set and carries no data.
+type sessionResponse struct {
+	ID    uint64 `json:"id"`
+	Done  bool   `json:"done,omitempty"`
+	Code  uint32 `json:"code,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// sessionGetParams are the params of the get command
+type sessionGetParams struct {
+	BatchID string `json:"batchId"`
+	// Signed is the signed batch ID, required by servers with a TicketSigner
+	Signed []byte `json:"signed,omitempty"`
+}
+
+// DoExchange implements the Flight DoExchange method as a session: every
+// message received is a request whose result is streamed back tagged with the
+// request's ID. Requests run concurrently, so their results may interleave.
+func (s *FlightServer) DoExchange(stream flight.FlightService_DoExchangeServer) error {
+	var sendMu sync.Mutex
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		data, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var request sessionRequest
+		if err := json.Unmarshal(data.AppMetadata, &request); err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid session request: %v", err)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sender := &sessionSender{stream: stream, mu: &sendMu, id: request.ID}
+			sender.finish(s.runSessionCommand(stream.Context(), request, sender))
+		}()
+	}
+}
+
+// runSessionCommand executes a session request and writes its result
+func (s *FlightServer) runSessionCommand(ctx context.Context, request sessionRequest, sender *sessionSender) error {
+	command, ok := s.sessionCommands[request.Command]
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "unknown session command %q", request.Command)
+	}
+
+	reader, err := command(ctx, request.Params)
+	if err != nil {
+		return err
+	}
+	defer reader.Release()
+
+	writer := flight.NewRecordWriter(sender, ipc.WithSchema(reader.Schema()))
+	for reader.Next() {
+		if err := writer.Write(reader.Record()); err != nil {
+			writer.Close()
+			return fmt.Errorf("failed to write session result: %w", err)
+		}
+	}
+	if err := reader.Err(); err != nil {
+		writer.Close()
+		return err
+	}
+	return writer.Close()
+}
+
+// sessionGet implements the built-in get command
+func (s *FlightServer) sessionGet(ctx context.Context, params json.RawMessage) (array.RecordReader, error) {
+	var p sessionGetParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid get params: %v", err)
+	}
+	if s.ticketSigner != nil {
+		batchID, err := s.commandBatchID(p.Signed)
+		if err != nil {
+			return nil, err
+		}
+		p.BatchID = batchID
+	}
+	if err := s.authorize(ctx, p.BatchID); err != nil {
+		return nil, err
+	}
+
+	batch, ok := s.acquireBatch(p.BatchID)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "batch with ID %s not found", p.BatchID)
+	}
+	defer batch.Release()
+
+	return array.NewRecordReader(batch.Schema(), []arrow.Record{batch})
+}
+
+// sessionSender writes the messages of one result onto the shared session
+// stream, tagging each with the request ID
+type sessionSender struct {
+	stream flight.FlightService_DoExchangeServer
+	mu     *sync.Mutex
+	id     uint64
+}
+
+// Send implements flight.DataStreamWriter
+func (w *sessionSender) Send(data *flight.FlightData) error {
+	meta, err := json.Marshal(sessionResponse{ID: w.id})
+	if err != nil {
+		return err
+	}
+	data.AppMetadata = meta
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.stream.Send(data)
+}
+
+// finish sends the final message of the result, carrying err if the command failed
+func (w *sessionSender) finish(err error) {
+	response := sessionResponse{ID: w.id, Done: true}
+	if err != nil {
+		st := status.Convert(err)
+		response.Code = uint32(st.Code())
+		response.Error = st.Message()
+	}
+
+	meta, err := json.Marshal(response)
+	if err != nil {
+		return
+	}
+
+	// A failed send means the session is gone, so there is nobody to tell
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.stream.Send(&flight.FlightData{AppMetadata: meta})
+}
+
+// Session is a persistent DoExchange stream over which commands are sent and
+// their results received without setting up a new call per request. Requests
+// are multiplexed by a correlation ID, so several results can be read at once.
+type Session struct {
+	client  *FlightClient
+	stream  flight.FlightService_DoExchangeClient
+	cancel  context.CancelFunc
+	release func()
+	sendMu  sync.Mutex
+	done    chan struct{} // Closed when the receive loop exits
+
+	mu      sync.Mutex
+	nextID  uint64
+	pending map[uint64]*SessionResult
+	err     error // Why the session stopped; set before done is closed
+}
+
+// OpenSession opens a session on the server. The session holds the connection
+// until Close is called.
+func (c *FlightClient) OpenSession(ctx context.Context) (*Session, error) {
+	conn, release, err := c.acquire(ctx)
+	if err != nil {
+		return nil, labelError(ctx, err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	stream, err := conn.DoExchange(ctx)
+	if err != nil {
+		cancel()
+		release()
+		return nil, labelError(ctx, fmt.Errorf("failed to open session: %w", err))
+	}
+
+	s := &Session{
+		client:  c,
+		stream:  stream,
+		cancel:  cancel,
+		release: release,
+		done:    make(chan struct{}),
+		pending: make(map[uint64]*SessionResult),
+	}
+	go s.receive()
+	return s, nil
+}
+
+// Do sends a command with JSON-encoded params (nil for none) and returns its
+// result stream once the result's schema has arrived. ctx bounds reading the
+// whole result, and its label tags the errors of both. Results must be
+// Closed; an unread result stalls the others once its buffer fills.
+func (s *Session) Do(ctx context.Context, command string, params any) (*SessionResult, error) {
+	result, err := s.do(ctx, command, params)
+	return result, labelError(ctx, err)
+}
+
+// do implements Do
+func (s *Session) do(ctx context.Context, command string, params any) (*SessionResult, error) {
+	var raw json.RawMessage
+	if params != nil {
+		var err error
+		if raw, err = json.Marshal(params); err != nil {
+			return nil, fmt.Errorf("failed to encode session params: %w", err)
+		}
+	}
+
+	s.mu.Lock()
+	if s.err != nil {
+		s.mu.Unlock()
+		return nil, s.err
+	}
+	s.nextID++
+	result := &SessionResult{
+		session:  s,
+		ctx:      ctx,
+		id:       s.nextID,
+		messages: make(chan sessionMessage, sessionResultBuffer),
+		closed:   make(chan struct{}),
+	}
+	s.pending[result.id] = result
+	s.mu.Unlock()
+
+	meta, err := json.Marshal(sessionRequest{ID: result.id, Command: command, Params: raw})
+	if err != nil {
+		result.Close()
+		return nil, fmt.Errorf("failed to encode session request: %w", err)
+	}
+
+	s.sendMu.Lock()
+	err = s.stream.Send(&flight.FlightData{AppMetadata: meta})
+	s.sendMu.Unlock()
+	if err != nil {
+		result.Close()
+		return nil, fmt.Errorf("failed to send session request: %w", err)
+	}
+
+	reader, err := flight.NewRecordReader(&sessionResultReader{result: result},
+		s.client.readerOptions(s.client.allocator)...)
+	if err != nil {
+		result.Close()
+		return nil, fmt.Errorf("session command %s failed: %w", command, err)
+	}
+	result.reader = reader
+	return result, nil
+}
+
+// GetBatch retrieves a stored batch over the session with the built-in get command
+func (s *Session) GetBatch(ctx context.Context, batchID string) (*SessionResult, error) {
+	params := sessionGetParams{BatchID: batchID}
+	if s.client.ticketSigner != nil {
+		params.Signed = s.client.batchCommand(batchID)
+	}
+	return s.Do(ctx, SessionCommandGet, params)
+}
+
+// Close ends the session, abandoning any results not yet read
+func (s *Session) Close() error {
+	s.mu.Lock()
+	if s.err == nil {
+		s.err = ErrSessionClosed
+	}
+	s.mu.Unlock()
+
+	s.sendMu.Lock()
+	s.stream.CloseSend()
+	s.sendMu.Unlock()
+
+	s.cancel()
+	<-s.done
+	s.release()
+	return nil
+}
+
+// receive routes the messages of the session stream to their results until
+// the stream ends
+func (s *Session) receive() {
+	defer close(s.done)
+
+	for {
+		data, err := s.stream.Recv()
+		if err != nil {
+			s.fail(err)
+			return
+		}
+
+		var response sessionResponse
+		if err := json.Unmarshal(data.AppMetadata, &response); err != nil {
+			s.fail(fmt.Errorf("invalid session response: %w", err))
+			return
+		}
+
+		s.mu.Lock()
+		result := s.pending[response.ID]
+		if response.Done {
+			delete(s.pending, response.ID)
+		}
+		s.mu.Unlock()
+
+		// Messages of results closed early are dropped
+		if result == nil {
+			continue
+		}
+		select {
+		case result.messages <- sessionMessage{data: data, response: response}:
+		case <-result.closed:
+		}
+	}
+}
+
+// fail stops the session, ending every pending result with err
+func (s *Session) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err == nil {
+		if err == io.EOF {
+			err = ErrSessionClosed
+		}
+		s.err = fmt.Errorf("session stopped: %w", err)
+	}
+	for id, result := range s.pending {
+		close(result.messages)
+		delete(s.pending, id)
+	}
+}
+
+// sessionMessage is a message routed to a result
+type sessionMessage struct {
+	data     *flight.FlightData
+	response sessionResponse
+}
+
+// SessionResult reads the record batches returned for one session request
+type SessionResult struct {
+	session   *Session
+	ctx       context.Context // The context of the request
+	id        uint64
+	messages  chan sessionMessage
+	closed    chan struct{}
+	closeOnce sync.Once
+	reader    *flight.Reader
+}
+
+// Schema returns the schema of the result
+func (r *SessionResult) Schema() *arrow.Schema {
+	return r.reader.Schema()
+}
+
+// Next returns the next record batch of the result, or io.EOF once the result
+// is complete. The caller must Release the returned record.
+func (r *SessionResult) Next() (arrow.Record, error) {
+	if r.reader.Next() {
+		batch := r.reader.Record()
+		batch.Retain()
+		return batch, nil
+	}
+	if err := r.reader.Err(); err != nil {
+		return nil, labelError(r.ctx, fmt.Errorf("error reading session result: %w", err))
+	}
+	return nil, io.EOF
+}
+
+// Close releases the result, discarding any part not yet read
+func (r *SessionResult) Close() {
+	r.closeOnce.Do(func() {
+		close(r.closed)
+		if r.reader != nil {
+			r.reader.Release()
+		}
+
+		r.session.mu.Lock()
+		delete(r.session.pending, r.id)
+		r.session.mu.Unlock()
+	})
+}
+
+// sessionResultReader feeds the messages of one result to a flight.Reader
+type sessionResultReader struct {
+	result *SessionResult
+}
+
+// Recv implements flight.DataStreamReader
+func (r *sessionResultReader) Recv() (*flight.FlightData, error) {
+	select {
+	case msg, ok := <-r.result.messages:
+		if !ok {
+			r.result.session.mu.Lock()
+			defer r.result.session.mu.Unlock()
+			return nil, r.result.session.err
+		}
+		if !msg.response.Done {
+			return msg.data, nil
+		}
+		if msg.response.Error != "" {
+			return nil, serverError(status.Error(codes.Code(msg.response.Code), msg.response.Error))
+		}
+		return nil, io.EOF
+	case <-r.result.closed:
+		return nil, io.EOF
+	case <-r.result.ctx.Done():
+		return nil, r.result.ctx.Err()
+	}
+}
@@ -0,0 +1,124 @@
+package flight
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+// rangeCommand is a session command returning the integers [0, n) in record
+// batches of at most 2 rows
+func rangeCommand(ctx context.Context, params json.RawMessage) (array.RecordReader, error) {
+	var p struct {
+		N int64 `json:"n"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	schema := arrow.NewSchema([]arrow.Field{{Name: "n", Type: arrow.PrimitiveTypes.Int64}}, nil)
+	builder := array.NewInt64Builder(memory.NewGoAllocator())
+	defer builder.Release()
+
+	var records []arrow.Record
+	for start := int64(0); start < p.N; start += 2 {
+		for i := start; i < min(start+2, p.N); i++ {
+			builder.Append(i)
+		}
+		col := builder.NewArray()
+		records = append(records, array.NewRecord(schema, []arrow.Array{col}, int64(col.Len())))
+		col.Release()
+	}
+	defer func() {
+		for _, rec := range records {
+			rec.Release()
+		}
+	}()
+	return array.NewRecordReader(schema, records)
+}
+
+// readSessionRows drains a session result and returns its row count
+func readSessionRows(t *testing.T, result *SessionResult) int64 {
+	defer result.Close()
+
+	var rows int64
+	for {
+		rec, err := result.Next()
+		if err == io.EOF {
+			return rows
+		}
+		require.NoError(t, err, "Failed to read session result")
+		rows += rec.NumRows()
+		rec.Release()
+	}
+}
+
+// TestSession tests that several requests share one DoExchange session
+func TestSession(t *testing.T) {
+	server, err := NewFlightServer(FlightServerConfig{
+		SessionCommands: map[string]SessionCommand{"range": rangeCommand},
+	})
+	require.NoError(t, err)
+	defer server.Stop()
+	addr := startBareServer(t, server)
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+	batchID := server.StoreBatch(batch)
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	session, err := client.OpenSession(ctx)
+	require.NoError(t, err, "Failed to open session")
+
+	// Results of concurrent requests are routed by correlation ID
+	first, err := session.Do(ctx, "range", map[string]int64{"n": 7})
+	require.NoError(t, err)
+	second, err := session.GetBatch(ctx, batchID)
+	require.NoError(t, err)
+	assert.True(t, second.Schema().Equal(batch.Schema()))
+
+	assert.Equal(t, batch.NumRows(), readSessionRows(t, second))
+	assert.Equal(t, int64(7), readSessionRows(t, first))
+
+	// A failed request leaves the session usable
+	_, err = session.Do(ctx, "missing", nil)
+	var serverErr *FlightServerError
+	require.True(t, errors.As(err, &serverErr), "Error should wrap a FlightServerError")
+	assert.Equal(t, codes.Unimplemented, serverErr.Code)
+
+	_, err = session.GetBatch(ctx, "unknown")
+	assert.ErrorContains(t, err, "not found")
+
+	again, err := session.Do(ctx, "range", map[string]int64{"n": 3})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), readSessionRows(t, again))
+
+	// A result closed before it is read does not block the session
+	abandoned, err := session.Do(ctx, "range", map[string]int64{"n": 1000})
+	require.NoError(t, err)
+	abandoned.Close()
+
+	last, err := session.GetBatch(ctx, batchID)
+	require.NoError(t, err)
+	assert.Equal(t, batch.NumRows(), readSessionRows(t, last))
+
+	require.NoError(t, session.Close())
+	_, err = session.GetBatch(ctx, batchID)
+	assert.ErrorIs(t, err, ErrSessionClosed)
+}
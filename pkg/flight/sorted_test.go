@@ -0,0 +1,128 @@
+package flight
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	arrow_utils "github.com/TFMV/temporal/pkg/arrow"
+)
+
+// createKeyedBatch creates a batch with a string key, an int64 key and optional nulls in the int64 key
+func createKeyedBatch(t *testing.T, regions []string, seqs []int64, valid []bool) arrow.Record {
+	schema := arrow.NewSchema(
+		[]arrow.Field{
+			{Name: "region", Type: arrow.BinaryTypes.String},
+			{Name: "seq", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		},
+		nil,
+	)
+
+	builder := array.NewRecordBuilder(memory.NewGoAllocator(), schema)
+	defer builder.Release()
+	builder.Field(0).(*array.StringBuilder).AppendValues(regions, nil)
+	builder.Field(1).(*array.Int64Builder).AppendValues(seqs, valid)
+
+	return builder.NewRecord()
+}
+
+// TestPutBatchRequireSorted tests the sort order guardrail on upload
+func TestPutBatchRequireSorted(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("Sorted", func(t *testing.T) {
+		batch := createKeyedBatch(t, []string{"a", "b", "c"}, []int64{3, 1, 2}, nil)
+		defer batch.Release()
+
+		_, err := client.PutBatchWithOptions(ctx, batch, PutOptions{RequireSorted: []string{"region"}})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Unsorted", func(t *testing.T) {
+		batch := createKeyedBatch(t, []string{"a", "b", "c"}, []int64{1, 3, 2}, nil)
+		defer batch.Release()
+
+		_, err := client.PutBatchWithOptions(ctx, batch, PutOptions{RequireSorted: []string{"seq"}})
+		assert.ErrorIs(t, err, ErrNotSorted)
+		assert.Contains(t, err.Error(), "row 2")
+	})
+
+	t.Run("MultiKey", func(t *testing.T) {
+		batch := createKeyedBatch(t, []string{"a", "a", "b", "b"}, []int64{1, 2, 1, 5}, nil)
+		defer batch.Release()
+
+		_, err := client.PutBatchWithOptions(ctx, batch, PutOptions{RequireSorted: []string{"region", "seq"}})
+		assert.NoError(t, err, "Batch is sorted by region then seq")
+
+		_, err = client.PutBatchWithOptions(ctx, batch, PutOptions{RequireSorted: []string{"seq", "region"}})
+		assert.ErrorIs(t, err, ErrNotSorted, "Batch is not sorted by seq then region")
+		assert.Contains(t, err.Error(), "row 2")
+	})
+
+	t.Run("Nulls", func(t *testing.T) {
+		batch := createKeyedBatch(t, []string{"a", "b", "c"}, []int64{0, 1, 2}, []bool{false, true, true})
+		defer batch.Release()
+
+		_, err := client.PutBatchWithOptions(ctx, batch, PutOptions{RequireSorted: []string{"seq"}})
+		assert.NoError(t, err, "Nulls sort first by default")
+
+		_, err = client.PutBatchWithOptions(ctx, batch, PutOptions{RequireSorted: []string{"seq"}, NullsLast: true})
+		assert.ErrorIs(t, err, ErrNotSorted)
+		assert.Contains(t, err.Error(), "row 1")
+	})
+
+	t.Run("UnknownColumn", func(t *testing.T) {
+		batch := createKeyedBatch(t, []string{"a"}, []int64{1}, nil)
+		defer batch.Release()
+
+		_, err := client.PutBatchWithOptions(ctx, batch, PutOptions{RequireSorted: []string{"missing"}})
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrNotSorted)
+	})
+}
+
+// TestSortRecordSatisfiesRequireSorted tests that SortRecord output passes the RequireSorted guardrail
+func TestSortRecordSatisfiesRequireSorted(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batch := createKeyedBatch(t,
+		[]string{"b", "a", "b", "a", "c"},
+		[]int64{2, 9, 0, 1, 4},
+		[]bool{true, true, false, true, true},
+	)
+	defer batch.Release()
+
+	sorted, err := arrow_utils.SortRecord(ctx, batch, []arrow_utils.SortKey{
+		{Column: "region"},
+		{Column: "seq", Order: arrow_utils.Descending, NullsLast: true},
+	}, nil)
+	require.NoError(t, err, "Failed to sort batch")
+	defer sorted.Release()
+
+	_, err = client.PutBatchWithOptions(ctx, sorted, PutOptions{RequireSorted: []string{"region"}})
+	assert.NoError(t, err)
+	_, err = client.PutBatchWithOptions(ctx, batch, PutOptions{RequireSorted: []string{"region"}})
+	assert.ErrorIs(t, err, ErrNotSorted, "The unsorted input should fail the guardrail")
+}
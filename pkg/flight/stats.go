@@ -0,0 +1,67 @@
+package flight
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ActionNullCounts is the DoAction type used to compute per-column null counts
+// on the server. The action body is the batch ID and the result body is a JSON
+// object mapping column names to null counts.
+const ActionNullCounts = "nullcounts"
+
+// NullCounts returns the number of nulls in each top-level column of a record,
+// read from the arrays' validity bitmaps without scanning values. Nulls inside
+// nested columns (struct fields, list elements) are not counted; only the
+// top-level array's own nulls are. With duplicate column names the first
+// column wins.
+func NullCounts(rec arrow.Record) map[string]int64 {
+	counts := make(map[string]int64, rec.NumCols())
+	for i, field := range rec.Schema().Fields() {
+		if _, ok := counts[field.Name]; ok {
+			continue
+		}
+		counts[field.Name] = int64(rec.Column(i).NullN())
+	}
+	return counts
+}
+
+// nullCounts computes the null counts of a stored batch
+func (s *FlightServer) nullCounts(batchID string, stream flight.FlightService_DoActionServer) error {
+	if err := s.authorize(stream.Context(), batchID); err != nil {
+		return err
+	}
+
+	batch, ok := s.acquireBatch(batchID)
+	if !ok {
+		return status.Errorf(codes.NotFound, "batch with ID %s not found", batchID)
+	}
+	defer batch.Release()
+
+	body, err := json.Marshal(NullCounts(batch))
+	if err != nil {
+		return fmt.Errorf("failed to encode null counts: %w", err)
+	}
+	return stream.Send(&flight.Result{Body: body})
+}
+
+// GetNullCounts asks the server for the null counts of a stored batch, as
+// computed by NullCounts, without transferring the batch
+func (c *FlightClient) GetNullCounts(ctx context.Context, batchID string) (map[string]int64, error) {
+	body, err := c.doAction(ctx, ActionNullCounts, c.batchCommand(batchID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get null counts for batch %s: %w", batchID, err)
+	}
+
+	counts := make(map[string]int64)
+	if err := json.Unmarshal(body, &counts); err != nil {
+		return nil, fmt.Errorf("failed to decode null counts: %w", err)
+	}
+	return counts, nil
+}
@@ -0,0 +1,76 @@
+package flight
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createNullableBatch creates a batch with nulls in a primitive and a nested column
+func createNullableBatch(t *testing.T) arrow.Record {
+	schema := arrow.NewSchema(
+		[]arrow.Field{
+			{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+			{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+			{Name: "tags", Type: arrow.ListOf(arrow.BinaryTypes.String), Nullable: true},
+		},
+		nil,
+	)
+
+	builder := array.NewRecordBuilder(memory.NewGoAllocator(), schema)
+	defer builder.Release()
+	builder.Field(0).(*array.Int64Builder).AppendValues([]int64{1, 2, 3, 4}, nil)
+	builder.Field(1).(*array.StringBuilder).AppendValues([]string{"a", "", "", "d"}, []bool{true, false, false, true})
+
+	// One null list and one list holding a null element, which is not counted
+	tags := builder.Field(2).(*array.ListBuilder)
+	values := tags.ValueBuilder().(*array.StringBuilder)
+	tags.Append(true)
+	values.Append("x")
+	tags.AppendNull()
+	tags.Append(true)
+	values.AppendNull()
+	tags.Append(true)
+
+	return builder.NewRecord()
+}
+
+// TestNullCounts tests that local and server-side null counts agree
+func TestNullCounts(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	batch := createNullableBatch(t)
+	defer batch.Release()
+
+	expected := map[string]int64{"id": 0, "name": 2, "tags": 1}
+	assert.Equal(t, expected, NullCounts(batch))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batchID, err := client.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to put batch")
+
+	retrieved, err := client.GetBatch(ctx, batchID)
+	require.NoError(t, err, "Failed to get batch")
+	defer retrieved.Release()
+
+	pushdown, err := client.GetNullCounts(ctx, batchID)
+	require.NoError(t, err, "Failed to get null counts")
+	assert.Equal(t, NullCounts(retrieved), pushdown)
+	assert.Equal(t, expected, pushdown)
+
+	_, err = client.GetNullCounts(ctx, "missing")
+	assert.Error(t, err)
+}
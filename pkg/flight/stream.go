@@ -0,0 +1,478 @@
+package flight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/arrow/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	arrow_utils "github.com/TFMV/temporal/pkg/arrow"
+)
+
+// Backoff bounds between attempts to resume an interrupted download
+const (
+	resumeInitialBackoff = 100 * time.Millisecond
+	resumeMaxBackoff     = 5 * time.Second
+)
+
+// BatchStream reads the record batches of a stored batch one at a time, so
+// large batches can be processed without materializing them in memory. If the
+// client has ResumeAttempts configured, a download interrupted by a retryable
+// error is re-requested from the first row not yet received.
+type BatchStream struct {
+	client    *FlightClient
+	conn      flight.Client
+	ctx       context.Context
+	batchID   string
+	release   func()
+	stop      context.CancelFunc // Ends the adaptive deadline, if any
+	options   GetOptions
+	allocator memory.Allocator
+
+	// The current DoGet attempt
+	reader  *flight.Reader
+	counter *countingReader
+	split   *schemaSplitter
+	cancel  context.CancelFunc
+	schema  *arrow.Schema
+	resumes int
+	batches int
+
+	// Records received ahead of Next, when GetOptions.ReadAhead is set. The
+	// reader goroutine owns the fields above until ahead is closed.
+	ahead    chan readResult
+	stopRead context.CancelFunc
+	aheadErr error // The error last returned by Next
+
+	// Call statistics reported to the client's metrics hook on Close
+	start      time.Time
+	rows       int64
+	bytes      int64 // Body bytes received by earlier attempts
+	arrowBytes int64 // Size of the Arrow buffers received
+	err        error
+}
+
+// GetBatchStream opens a DoGet stream for a batch. The caller must Close the
+// stream, which also aborts the download if it has not been fully read.
+func (c *FlightClient) GetBatchStream(ctx context.Context, batchID string) (*BatchStream, error) {
+	return c.GetBatchStreamWithOptions(ctx, batchID, GetOptions{})
+}
+
+// GetBatchStreamWithOptions opens a DoGet stream for a batch with per-call
+// options. Next fails with ErrLimitExceeded once a limit is exceeded.
+func (c *FlightClient) GetBatchStreamWithOptions(ctx context.Context, batchID string, options GetOptions) (*BatchStream, error) {
+	if options.AccessToken != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, AccessTokenHeader, options.AccessToken)
+	}
+
+	stream, err := c.openBatchStream(ctx, batchID, options)
+	if options.AlignSchema == nil || !errors.Is(err, ErrSchemaMismatch) {
+		return stream, labelError(ctx, err)
+	}
+	options, err = c.alignExpectedSchema(ctx, batchID, options)
+	if err != nil {
+		return nil, labelError(ctx, err)
+	}
+	stream, err = c.openBatchStream(ctx, batchID, options)
+	return stream, labelError(ctx, err)
+}
+
+// openBatchStream starts one download of GetBatchStreamWithOptions
+func (c *FlightClient) openBatchStream(ctx context.Context, batchID string, options GetOptions) (*BatchStream, error) {
+	start := time.Now()
+	stop := context.CancelFunc(func() {})
+	if c.adaptiveTimeout != nil {
+		if size, ok := c.sizeHint(ctx, batchID); ok {
+			ctx, stop = c.withTransferTimeout(ctx, size)
+		}
+	}
+
+	conn, release, err := c.acquire(ctx)
+	if err != nil {
+		stop()
+		c.observe(CallStats{Method: MethodGetBatch, BatchID: batchID, Duration: time.Since(start), Label: CallLabel(ctx), Err: err})
+		return nil, err
+	}
+
+	s := &BatchStream{
+		client:  c,
+		conn:    conn,
+		ctx:     ctx,
+		batchID: batchID,
+		release: release,
+		stop:    stop,
+		options: options,
+		start:   start,
+	}
+	s.allocator = options.Allocator
+	if s.allocator == nil {
+		s.allocator = c.allocator
+	}
+	if options.ReadAhead > 0 {
+		// Every attempt runs under this context, so Close can abort the
+		// download the reader goroutine is receiving
+		s.ctx, s.stopRead = context.WithCancel(ctx)
+		stop = joinCancel(s.stopRead, stop)
+		s.stop = stop
+	}
+	if err := s.open(0); err != nil {
+		release()
+		stop()
+		c.observe(CallStats{Method: MethodGetBatch, BatchID: batchID, Duration: time.Since(start), Label: CallLabel(ctx), Err: err})
+		return nil, err
+	}
+	if s.schema, err = s.deriveSchema(s.reader.Schema()); err != nil {
+		s.closeAttempt()
+		release()
+		stop()
+		c.observe(CallStats{Method: MethodGetBatch, BatchID: batchID, Duration: time.Since(start), Label: CallLabel(ctx), Err: err})
+		return nil, err
+	}
+
+	if options.ReadAhead > 0 {
+		// The goroutine holds one more record while it waits to hand it over
+		s.ahead = make(chan readResult, options.ReadAhead-1)
+		go s.readAhead()
+	}
+	return s, nil
+}
+
+// joinCancel returns a cancel function calling both a and b
+func joinCancel(a, b context.CancelFunc) context.CancelFunc {
+	return func() {
+		a()
+		b()
+	}
+}
+
+// deriveSchema returns the schema of the records Next returns for an IPC
+// stream of the given schema, after Columns, Coerce, NormalizeTimezone and
+// Mask, checking it against ExpectedSchema
+func (s *BatchStream) deriveSchema(schema *arrow.Schema) (*arrow.Schema, error) {
+	var err error
+	if len(s.options.Columns) > 0 {
+		if schema, err = arrow_utils.SelectSchema(schema, s.options.Columns); err != nil {
+			return nil, fmt.Errorf("failed to project columns of batch %s: %w", s.batchID, err)
+		}
+	}
+	if len(s.options.Coerce) > 0 {
+		if schema, err = arrow_utils.CoerceSchema(schema, s.options.Coerce); err != nil {
+			return nil, fmt.Errorf("failed to coerce columns: %w", err)
+		}
+	}
+	if s.options.NormalizeTimezone != "" {
+		if schema, err = arrow_utils.NormalizeTimezoneSchema(schema, s.options.NormalizeTimezone, s.options.AssumeTimezone); err != nil {
+			return nil, fmt.Errorf("failed to normalize time zones: %w", err)
+		}
+	}
+	if s.options.ExpectedSchema != nil && !sameFields(s.options.ExpectedSchema, schema) {
+		return nil, fmt.Errorf("%w: expected %s, got %s", ErrSchemaMismatch, s.options.ExpectedSchema, schema)
+	}
+	if len(s.options.Mask) > 0 {
+		if schema, err = arrow_utils.MaskSchema(schema, s.options.Mask); err != nil {
+			return nil, fmt.Errorf("failed to mask columns: %w", err)
+		}
+	}
+	return schema, nil
+}
+
+// readResult is a record or error received by the read-ahead goroutine
+type readResult struct {
+	record arrow.Record
+	err    error
+}
+
+// readAhead receives the stream's records into the read-ahead buffer until
+// the stream ends or fails, blocking while the buffer is full
+func (s *BatchStream) readAhead() {
+	defer close(s.ahead)
+
+	for {
+		record, err := s.read()
+		s.ahead <- readResult{record: record, err: err}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// open starts a DoGet attempt that skips the first offset rows
+func (s *BatchStream) open(offset int64) error {
+	raw, err := s.client.encodeTicket(ticket{
+		BatchID:  s.batchID,
+		Offset:   offset,
+		Pipeline: s.options.pipeline,
+		Columns:  s.options.Columns,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Cancelling the context aborts the stream if we stop reading early
+	ctx, cancel := context.WithCancel(s.ctx)
+
+	stream, err := s.conn.DoGet(ctx, &flight.Ticket{Ticket: raw})
+	if err != nil {
+		cancel()
+		return fmt.Errorf("failed to start DoGet stream: %w", err)
+	}
+
+	counter := &countingReader{DataStreamReader: stream}
+	split := &schemaSplitter{DataStreamReader: counter}
+	reader, err := flight.NewRecordReader(split, s.client.readerOptions(s.allocator)...)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("failed to create record reader: %w", accessError(err))
+	}
+
+	s.reader, s.counter, s.split, s.cancel = reader, counter, split, cancel
+	return nil
+}
+
+// closeAttempt releases the current DoGet attempt
+func (s *BatchStream) closeAttempt() {
+	s.reader.Release()
+	s.cancel()
+	s.bytes += s.counter.bodyBytes
+	s.reader = nil
+}
+
+// Schema returns the schema of the records in the stream. When the server
+// changes schema part way (GetOptions.AllowSchemaChange), it is the schema of
+// the first records; each record carries its own.
+func (s *BatchStream) Schema() *arrow.Schema {
+	return s.schema
+}
+
+// Next returns the next record batch, or io.EOF once the stream is exhausted.
+// The caller owns the only reference to the returned record and must release
+// it once; the stream keeps none.
+func (s *BatchStream) Next() (arrow.Record, error) {
+	if s.ahead == nil {
+		return s.read()
+	}
+
+	result, ok := <-s.ahead
+	if !ok {
+		// The stream ended with the error already returned
+		return nil, s.aheadErr
+	}
+	s.aheadErr = result.err
+	return result.record, result.err
+}
+
+// Batches returns an iterator over the record batches of a batch, streamed
+// from one DoGet as the loop advances:
+//
+//	for rec, err := range client.Batches(ctx, batchID) {
+//		if err != nil {
+//			return err
+//		}
+//		// use rec
+//	}
+//
+// Each record is released when the loop moves on, so it is only valid within
+// its iteration; retain it to keep it longer. A failure to open or read the
+// stream is yielded once with a nil record and ends the iteration. Breaking
+// out of the loop closes the stream and aborts the download.
+func (c *FlightClient) Batches(ctx context.Context, batchID string) iter.Seq2[arrow.Record, error] {
+	return c.BatchesWithOptions(ctx, batchID, GetOptions{})
+}
+
+// BatchesWithOptions returns an iterator like Batches with per-call options
+func (c *FlightClient) BatchesWithOptions(ctx context.Context, batchID string, options GetOptions) iter.Seq2[arrow.Record, error] {
+	return func(yield func(arrow.Record, error) bool) {
+		stream, err := c.GetBatchStreamWithOptions(ctx, batchID, options)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer stream.Close()
+
+		for {
+			rec, err := stream.Next()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			more := yield(rec, nil)
+			rec.Release()
+			if !more {
+				return
+			}
+		}
+	}
+}
+
+// read receives the next record batch from the server, resuming the download
+// if it is interrupted
+func (s *BatchStream) read() (arrow.Record, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	for {
+		if s.reader != nil && s.reader.Next() {
+			batch := s.reader.Record()
+			s.rows += batch.NumRows()
+			s.arrowBytes += util.TotalRecordSize(batch)
+			s.batches++
+			if s.client.resumePerBatch {
+				s.resumes = 0
+			}
+
+			// Enforce the configured limits
+			if s.options.MaxBatches > 0 && s.batches > s.options.MaxBatches {
+				return nil, s.fail(fmt.Errorf("%w: more than %d record batches", ErrLimitExceeded, s.options.MaxBatches))
+			}
+			if s.options.MaxRows > 0 && s.rows > s.options.MaxRows {
+				return nil, s.fail(fmt.Errorf("%w: more than %d rows", ErrLimitExceeded, s.options.MaxRows))
+			}
+
+			batch.Retain()
+			if len(s.options.Columns) > 0 {
+				projected, err := arrow_utils.SelectColumns(batch, s.options.Columns)
+				batch.Release()
+				if err != nil {
+					return nil, s.fail(fmt.Errorf("failed to project columns of batch %s: %w", s.batchID, err))
+				}
+				batch = projected
+			}
+			if len(s.options.Coerce) > 0 {
+				coerced, err := arrow_utils.CoerceRecord(s.ctx, batch, s.options.Coerce, s.options.AllowLossy, s.allocator)
+				batch.Release()
+				if err != nil {
+					return nil, s.fail(fmt.Errorf("failed to coerce columns: %w", err))
+				}
+				batch = coerced
+			}
+			if s.options.NormalizeTimezone != "" {
+				normalized, err := arrow_utils.NormalizeTimezoneRecord(batch, s.options.NormalizeTimezone, s.options.AssumeTimezone, s.allocator)
+				batch.Release()
+				if err != nil {
+					return nil, s.fail(fmt.Errorf("failed to normalize time zones: %w", err))
+				}
+				batch = normalized
+			}
+			if len(s.options.Mask) > 0 {
+				masked, err := arrow_utils.MaskRecord(s.ctx, batch, s.options.Mask, s.allocator)
+				batch.Release()
+				if err != nil {
+					return nil, s.fail(fmt.Errorf("failed to mask columns: %w", err))
+				}
+				batch = masked
+			}
+			return batch, nil
+		}
+
+		var err error
+		if s.reader != nil {
+			if err = s.reader.Err(); err == nil {
+				if !s.split.changed() {
+					return nil, io.EOF
+				}
+				if err := s.changeSchema(); err != nil {
+					return nil, s.fail(err)
+				}
+				continue
+			}
+			s.closeAttempt()
+		}
+
+		if !s.resume(err) {
+			return nil, s.fail(fmt.Errorf("error reading batch: %w", err))
+		}
+	}
+}
+
+// fail ends the stream with err, tagged with the call's label
+func (s *BatchStream) fail(err error) error {
+	s.err = labelError(s.ctx, err)
+	return s.err
+}
+
+// resume re-requests the rest of the batch after err, reporting false when the
+// error is not retryable or the resume attempts are exhausted
+func (s *BatchStream) resume(err error) bool {
+	for {
+		if !s.client.isResumable(err) || s.resumes >= s.client.resumeAttempts {
+			return false
+		}
+		s.resumes++
+
+		backoff := min(resumeInitialBackoff<<(s.resumes-1), resumeMaxBackoff)
+		select {
+		case <-time.After(backoff):
+		case <-s.ctx.Done():
+			return false
+		}
+
+		if err = s.open(s.rows); err == nil {
+			return true
+		}
+	}
+}
+
+// isResumable reports whether a download failure is worth resuming
+func (c *FlightClient) isResumable(err error) bool {
+	if _, ok := status.FromError(err); !ok && c.resumePerBatch {
+		// The server sent a message the reader could not decode
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.Aborted:
+		return true
+	default:
+		return false
+	}
+}
+
+// Close releases the stream and its connection
+func (s *BatchStream) Close() {
+	err := s.err
+	if s.ahead != nil {
+		// Stop the reader goroutine and discard the records it buffered. The
+		// call failed only if Next returned an error; stopping the reader
+		// early is not a failure.
+		s.stopRead()
+		for result := range s.ahead {
+			if result.record != nil {
+				result.record.Release()
+			}
+		}
+		err = s.aheadErr
+		if err == io.EOF {
+			err = nil
+		}
+	}
+
+	if s.reader != nil {
+		s.closeAttempt()
+	}
+	s.release()
+	s.stop()
+
+	s.client.observe(CallStats{
+		Method:            MethodGetBatch,
+		BatchID:           s.batchID,
+		Duration:          time.Since(s.start),
+		Rows:              s.rows,
+		Bytes:             s.bytes,
+		UncompressedBytes: s.arrowBytes,
+		Label:             CallLabel(s.ctx),
+		Err:               err,
+	})
+}
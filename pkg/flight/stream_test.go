@@ -0,0 +1,228 @@
+package flight
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetBatchStream tests reading a batch record by record
+func TestGetBatchStream(t *testing.T) {
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	addr := startBareServer(t, &multiBatchServer{batch: batch, count: 3})
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.GetBatchStream(ctx, "any")
+	require.NoError(t, err, "Failed to open batch stream")
+	defer stream.Close()
+	assert.True(t, batch.Schema().Equal(stream.Schema()))
+
+	var numRecords int
+	for {
+		rec, err := stream.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err, "Failed to read record")
+		assert.Equal(t, batch.NumRows(), rec.NumRows())
+		rec.Release()
+		numRecords++
+	}
+	assert.Equal(t, 3, numRecords)
+}
+
+// TestGetBatchStreamReadAhead tests that a read-ahead stream delivers every record and stops cleanly when closed early
+func TestGetBatchStreamReadAhead(t *testing.T) {
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	addr := startBareServer(t, &multiBatchServer{batch: batch, count: 10})
+
+	metrics := &recordingMetrics{}
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr, Metrics: metrics})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.GetBatchStreamWithOptions(ctx, "any", GetOptions{ReadAhead: 3})
+	require.NoError(t, err, "Failed to open stream")
+
+	var records, rows int64
+	for {
+		rec, err := stream.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err, "Failed to read record")
+		assert.True(t, array.RecordEqual(batch, rec), "Records should arrive intact")
+		records++
+		rows += rec.NumRows()
+		rec.Release()
+	}
+	_, err = stream.Next()
+	assert.Equal(t, io.EOF, err, "The stream should stay at EOF")
+	stream.Close()
+	assert.Equal(t, int64(10), records)
+	assert.Equal(t, 10*batch.NumRows(), rows)
+
+	// Closing after the first record stops the reader goroutine
+	stream, err = client.GetBatchStreamWithOptions(ctx, "any", GetOptions{ReadAhead: 1})
+	require.NoError(t, err, "Failed to open stream")
+	rec, err := stream.Next()
+	require.NoError(t, err, "Failed to read record")
+	rec.Release()
+	stream.Close()
+
+	require.Len(t, metrics.calls, 2)
+	assert.NoError(t, metrics.calls[1].Err, "Closing early is not a failure")
+
+	// Limits still apply to records read ahead
+	stream, err = client.GetBatchStreamWithOptions(ctx, "any", GetOptions{ReadAhead: 2, MaxBatches: 2})
+	require.NoError(t, err, "Failed to open stream")
+	defer stream.Close()
+	for {
+		rec, err := stream.Next()
+		if err != nil {
+			assert.ErrorIs(t, err, ErrLimitExceeded)
+			break
+		}
+		rec.Release()
+	}
+}
+
+// TestBatches tests ranging over the record batches of a batch, stopping
+// early, and receiving errors from the iterator
+func TestBatches(t *testing.T) {
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	addr := startBareServer(t, &multiBatchServer{batch: batch, count: 3})
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var records int
+	var kept arrow.Record
+	for rec, err := range client.Batches(ctx, "any") {
+		require.NoError(t, err, "Failed to read batch")
+		assert.True(t, array.RecordEqual(batch, rec), "Each record should hold the batch")
+		if kept == nil {
+			// Retained records outlive their iteration
+			rec.Retain()
+			kept = rec
+		}
+		records++
+	}
+	assert.Equal(t, 3, records)
+	require.NotNil(t, kept)
+	assert.True(t, array.RecordEqual(batch, kept), "A retained record should stay valid")
+	kept.Release()
+
+	// Breaking out of the loop ends the download
+	records = 0
+	for _, err := range client.Batches(ctx, "any") {
+		require.NoError(t, err, "Failed to read batch")
+		records++
+		break
+	}
+	assert.Equal(t, 1, records)
+
+	// Errors end the iteration
+	var errs []error
+	for rec, err := range client.BatchesWithOptions(ctx, "any", GetOptions{MaxBatches: 1}) {
+		if err != nil {
+			assert.Nil(t, rec)
+			errs = append(errs, err)
+		}
+	}
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], ErrLimitExceeded)
+}
+
+// trickleServer writes a record, then the rest one every delay, until the
+// client goes away
+type trickleServer struct {
+	flight.BaseFlightServer
+	batch arrow.Record
+	count int
+	delay time.Duration
+}
+
+// DoGet writes the record count times, pausing after the first
+func (s *trickleServer) DoGet(request *flight.Ticket, stream flight.FlightService_DoGetServer) error {
+	writer := flight.NewRecordWriter(stream, ipc.WithSchema(s.batch.Schema()))
+	defer writer.Close()
+
+	for i := 0; i < s.count; i++ {
+		if i > 0 {
+			select {
+			case <-time.After(s.delay):
+			case <-stream.Context().Done():
+				return stream.Context().Err()
+			}
+		}
+		if err := writer.Write(s.batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TestGetBatchStreamCloseEarly tests that closing a stream part way aborts
+// the download rather than receiving the rest, with and without read-ahead
+func TestGetBatchStreamCloseEarly(t *testing.T) {
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	addr := startBareServer(t, &trickleServer{batch: batch, count: 20, delay: 100 * time.Millisecond})
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for _, readAhead := range []int{0, 1, 4} {
+		stream, err := client.GetBatchStreamWithOptions(ctx, "any", GetOptions{ReadAhead: readAhead})
+		require.NoError(t, err, "Failed to open stream")
+		rec, err := stream.Next()
+		require.NoError(t, err, "Failed to read record")
+		rec.Release()
+
+		start := time.Now()
+		stream.Close()
+		assert.Less(t, time.Since(start), 500*time.Millisecond, "Close with read-ahead %d should not drain the stream", readAhead)
+	}
+
+	// Breaking out of a loop over the batches aborts the download too
+	start := time.Now()
+	for _, err := range client.BatchesWithOptions(ctx, "any", GetOptions{ReadAhead: 2}) {
+		require.NoError(t, err, "Failed to read batch")
+		break
+	}
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
@@ -0,0 +1,110 @@
+package flight
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	arrow_utils "github.com/TFMV/temporal/pkg/arrow"
+	"github.com/apache/arrow-go/v18/arrow"
+)
+
+// PutStructStream uploads the rows received from a channel as one batch,
+// converting them with arrow_utils.NewStructRecordBuilder. Rows are grouped
+// into record batches of batchSize and streamed on a single DoPut as they
+// fill; the final partial batch is sent once the channel is closed, which
+// completes the upload. Cancelling ctx aborts the upload.
+func PutStructStream[T any](ctx context.Context, c *FlightClient, rows <-chan T, batchSize int) (string, error) {
+	if batchSize <= 0 {
+		return "", fmt.Errorf("batch size must be positive, got %d", batchSize)
+	}
+
+	builder, err := arrow_utils.NewStructRecordBuilder[T](c.allocator)
+	if err != nil {
+		return "", fmt.Errorf("failed to create struct builder: %w", err)
+	}
+	defer builder.Release()
+
+	next := func() (arrow.Record, error) {
+		for {
+			select {
+			case row, ok := <-rows:
+				if !ok {
+					if builder.Len() == 0 {
+						return nil, io.EOF
+					}
+					return builder.NewRecord(), nil
+				}
+				builder.Append(row)
+				if builder.Len() >= batchSize {
+					return builder.NewRecord(), nil
+				}
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+	}
+
+	return c.putStream(ctx, builder.Schema(), next)
+}
+
+// GetStructStream streams a stored batch and decodes each row into T with
+// arrow_utils.NewStructDecoder. Rows are delivered on the first channel, which
+// is closed when the batch has been read or reading fails. A failure (opening
+// the stream, a schema that doesn't match T, or a row that can't be decoded)
+// is sent on the second channel, which is closed after the rows channel.
+// Cancelling ctx stops the stream.
+func GetStructStream[T any](ctx context.Context, c *FlightClient, batchID string) (<-chan T, <-chan error) {
+	rows := make(chan T)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(rows)
+
+		if err := decodeStructStream(ctx, c, batchID, rows); err != nil {
+			errs <- err
+		}
+	}()
+
+	return rows, errs
+}
+
+// decodeStructStream implements GetStructStream
+func decodeStructStream[T any](ctx context.Context, c *FlightClient, batchID string, rows chan<- T) error {
+	stream, err := c.GetBatchStream(ctx, batchID)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	decoder, err := arrow_utils.NewStructDecoder[T](stream.Schema())
+	if err != nil {
+		return fmt.Errorf("failed to decode batch %s: %w", batchID, err)
+	}
+
+	for {
+		rec, err := stream.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		for i := 0; i < int(rec.NumRows()); i++ {
+			row, err := decoder.Decode(rec, i)
+			if err != nil {
+				rec.Release()
+				return fmt.Errorf("failed to decode batch %s: %w", batchID, err)
+			}
+			select {
+			case rows <- row:
+			case <-ctx.Done():
+				rec.Release()
+				return ctx.Err()
+			}
+		}
+		rec.Release()
+	}
+}
@@ -0,0 +1,212 @@
+package flight
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// reading is a sensor reading produced by a pipeline
+type reading struct {
+	Sensor   string    `arrow:"sensor"`
+	Value    float64   `arrow:"value"`
+	Offset   *int32    `arrow:"offset"`
+	Taken    time.Time `arrow:"taken"`
+	internal string
+	Ignored  bool `arrow:"-"`
+}
+
+// recordingMetrics collects the stats of every observed call
+type recordingMetrics struct {
+	mu    sync.Mutex
+	calls []CallStats
+}
+
+// ObserveCall implements Metrics
+func (m *recordingMetrics) ObserveCall(stats CallStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, stats)
+}
+
+// TestPutStructStream tests that rows from a channel are uploaded in batches as one batch
+func TestPutStructStream(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	metrics := &recordingMetrics{}
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr, Metrics: metrics})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	taken := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	rows := make(chan reading)
+	go func() {
+		defer close(rows)
+		for i := 0; i < 25; i++ {
+			row := reading{Sensor: "s1", Value: float64(i), Taken: taken.Add(time.Duration(i) * time.Second)}
+			if i%2 == 0 {
+				offset := int32(i)
+				row.Offset = &offset
+			}
+			rows <- row
+		}
+	}()
+
+	batchID, err := PutStructStream(ctx, client, rows, 10)
+	require.NoError(t, err, "Failed to stream rows")
+	require.Len(t, metrics.calls, 1)
+	assert.Equal(t, int64(25), metrics.calls[0].Rows)
+	assert.Equal(t, batchID, metrics.calls[0].BatchID)
+
+	retrieved, err := client.GetBatch(ctx, batchID)
+	require.NoError(t, err, "Failed to get batch")
+	defer retrieved.Release()
+
+	schema := retrieved.Schema()
+	require.Equal(t, 4, len(schema.Fields()), "Unexported and skipped fields should not be columns")
+	assert.Equal(t, []string{"sensor", "value", "offset", "taken"},
+		[]string{schema.Field(0).Name, schema.Field(1).Name, schema.Field(2).Name, schema.Field(3).Name})
+	assert.True(t, schema.Field(2).Nullable, "Pointer fields should be nullable")
+
+	require.Equal(t, int64(25), retrieved.NumRows(), "Every row should be stored, including the final partial batch")
+	values := retrieved.Column(1).(*array.Float64)
+	offsets := retrieved.Column(2).(*array.Int32)
+	times := retrieved.Column(3).(*array.Timestamp)
+	for i := 0; i < 25; i++ {
+		assert.Equal(t, float64(i), values.Value(i))
+		assert.Equal(t, i%2 != 0, offsets.IsNull(i), "Row %d nullness", i)
+		assert.Equal(t, arrow.Timestamp(taken.Add(time.Duration(i)*time.Second).UnixMicro()), times.Value(i))
+	}
+}
+
+// TestPutStructStreamCancel tests that cancelling the context aborts the upload
+func TestPutStructStreamCancel(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rows := make(chan reading, 1)
+	rows <- reading{Sensor: "s1"}
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	_, err = PutStructStream(ctx, client, rows, 10)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	batches, err := client.ListBatches(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, batches, "Aborted uploads should not be stored")
+}
+
+// TestGetStructStream tests that a stored batch decodes back into the structs it was built from
+func TestGetStructStream(t *testing.T) {
+	server, err := NewFlightServer(FlightServerConfig{ChunkRows: 4})
+	require.NoError(t, err)
+	defer server.Stop()
+	addr := startBareServer(t, server)
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	taken := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	var sent []reading
+	rows := make(chan reading, 10)
+	for i := 0; i < 10; i++ {
+		row := reading{Sensor: fmt.Sprintf("s%d", i), Value: float64(i) / 2, Taken: taken.Add(time.Duration(i) * time.Minute)}
+		if i%3 == 0 {
+			offset := int32(-i)
+			row.Offset = &offset
+		}
+		sent = append(sent, row)
+		rows <- row
+	}
+	close(rows)
+
+	batchID, err := PutStructStream(ctx, client, rows, 3)
+	require.NoError(t, err, "Failed to stream rows")
+
+	// The server sends the batch in several record batches
+	decoded, errs := GetStructStream[reading](ctx, client, batchID)
+	var received []reading
+	for row := range decoded {
+		received = append(received, row)
+	}
+	require.NoError(t, <-errs)
+
+	require.Len(t, received, len(sent))
+	for i := range sent {
+		assert.Equal(t, sent[i].Sensor, received[i].Sensor)
+		assert.Equal(t, sent[i].Value, received[i].Value)
+		assert.Equal(t, sent[i].Offset, received[i].Offset, "Row %d offset", i)
+		assert.True(t, sent[i].Taken.Equal(received[i].Taken), "Row %d time", i)
+	}
+}
+
+// TestGetStructStreamMismatch tests that a schema not matching the struct is reported on the error channel
+func TestGetStructStreamMismatch(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batchID, err := client.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to put batch")
+
+	// The test batch has no sensor column
+	decoded, errs := GetStructStream[reading](ctx, client, batchID)
+	for range decoded {
+		t.Fatal("No rows should be decoded")
+	}
+	assert.ErrorContains(t, <-errs, `column "sensor"`)
+
+	// Matching a subset of the columns works, with types checked
+	type named struct {
+		ID   int32  `arrow:"id"`
+		Name string `arrow:"name"`
+	}
+	names, errs := GetStructStream[named](ctx, client, batchID)
+	var got []string
+	for row := range names {
+		got = append(got, row.Name)
+	}
+	require.NoError(t, <-errs)
+	assert.Equal(t, []string{"one", "two", "three", "four", "five"}, got)
+
+	type wrongType struct {
+		ID string `arrow:"id"`
+	}
+	wrong, errs := GetStructStream[wrongType](ctx, client, batchID)
+	for range wrong {
+	}
+	assert.ErrorContains(t, <-errs, "cannot decode int32")
+}
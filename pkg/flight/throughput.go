@@ -0,0 +1,69 @@
+package flight
+
+import (
+	"sync"
+	"time"
+)
+
+// throughputSmoothing is the weight of the latest transfer in the rolling
+// throughput averages
+const throughputSmoothing = 0.2
+
+// rateAverage is an exponentially weighted moving average of transfer rates
+type rateAverage struct {
+	rate    float64 // Bytes per second
+	samples int
+	mu      sync.Mutex
+}
+
+// add folds the rate of a transfer into the average. The first transfer sets
+// it outright.
+func (a *rateAverage) add(bytes int64, elapsed time.Duration) {
+	rate := float64(bytes) / elapsed.Seconds()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.samples == 0 {
+		a.rate = rate
+	} else {
+		a.rate = throughputSmoothing*rate + (1-throughputSmoothing)*a.rate
+	}
+	a.samples++
+}
+
+// get returns the average and the number of transfers it is based on
+func (a *rateAverage) get() (float64, int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.rate, a.samples
+}
+
+// throughputTracker keeps the rolling upload and download rates of a client
+type throughputTracker struct {
+	upload   rateAverage
+	download rateAverage
+}
+
+// observe records a successful upload or download
+func (t *throughputTracker) observe(stats CallStats) {
+	if stats.Err != nil || stats.UncompressedBytes <= 0 || stats.Duration <= 0 {
+		return
+	}
+	switch stats.Method {
+	case MethodPutBatch:
+		t.upload.add(stats.UncompressedBytes, stats.Duration)
+	case MethodGetBatch:
+		t.download.add(stats.UncompressedBytes, stats.Duration)
+	}
+}
+
+// Throughput returns exponentially weighted moving averages of the client's
+// recent upload and download rates, in uncompressed Arrow bytes per second.
+// Each successful PutBatch or GetBatch call (including streams) updates its
+// average, giving the latest transfer a weight of 0.2. A rate is 0 until the
+// first transfer in that direction completes.
+func (c *FlightClient) Throughput() (uploadBps, downloadBps float64) {
+	uploadBps, _ = c.throughput.upload.get()
+	downloadBps, _ = c.throughput.download.get()
+	return uploadBps, downloadBps
+}
@@ -0,0 +1,68 @@
+package flight
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestThroughput tests that uploads and downloads update their own rolling rate
+func TestThroughput(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	upload, download := client.Throughput()
+	assert.Zero(t, upload)
+	assert.Zero(t, download)
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+	batchID, err := client.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to put batch")
+
+	upload, download = client.Throughput()
+	assert.Positive(t, upload)
+	assert.Zero(t, download, "Uploads should not count as downloads")
+
+	retrieved, err := client.GetBatch(ctx, batchID)
+	require.NoError(t, err, "Failed to get batch")
+	retrieved.Release()
+
+	_, download = client.Throughput()
+	assert.Positive(t, download)
+}
+
+// TestRateAverage tests the exponentially weighted average of transfer rates
+func TestRateAverage(t *testing.T) {
+	var tracker throughputTracker
+	tracker.observe(CallStats{Method: MethodGetBatch, UncompressedBytes: 1000, Duration: time.Second})
+	rate, samples := tracker.download.get()
+	assert.Equal(t, 1, samples)
+	assert.InDelta(t, 1000, rate, 1e-9, "The first transfer sets the rate")
+
+	tracker.observe(CallStats{Method: MethodGetBatch, UncompressedBytes: 6000, Duration: time.Second})
+	rate, _ = tracker.download.get()
+	assert.InDelta(t, 2000, rate, 1e-9)
+
+	// Failed and empty calls are ignored
+	tracker.observe(CallStats{Method: MethodGetBatch, UncompressedBytes: 1, Duration: time.Hour, Err: assert.AnError})
+	tracker.observe(CallStats{Method: MethodGetBatch, Duration: time.Second})
+	rate, samples = tracker.download.get()
+	assert.Equal(t, 2, samples)
+	assert.InDelta(t, 2000, rate, 1e-9)
+
+	rate, samples = tracker.upload.get()
+	assert.Zero(t, samples)
+	assert.Zero(t, rate)
+}
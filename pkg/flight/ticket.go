@@ -0,0 +1,85 @@
+package flight
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ticket is the structured form of a DoGet ticket. Tickets without options are
+// sent as the bare batch ID, which is what older servers expect; a JSON object
+// is only used when there are options to pass, or when the ID itself looks
+// like one.
+type ticket struct {
+	BatchID string `json:"batchId"`
+	// Offset is the number of leading rows to skip
+	Offset int64 `json:"offset,omitempty"`
+	// Pipeline names the server transforms applied to the batch, in order.
+	// Offset counts rows of the transformed output.
+	Pipeline []string `json:"pipeline,omitempty"`
+	// Columns selects the columns sent, in order, after the pipeline has run
+	Columns []string `json:"columns,omitempty"`
+}
+
+// encode returns the wire form of the ticket
+func (t ticket) encode() ([]byte, error) {
+	if t.Offset == 0 && len(t.Pipeline) == 0 && len(t.Columns) == 0 && !strings.HasPrefix(t.BatchID, "{") {
+		return []byte(t.BatchID), nil
+	}
+	return json.Marshal(t)
+}
+
+// encodeTicket returns the wire form of a ticket, signed if the client has a
+// TicketSigner
+func (c *FlightClient) encodeTicket(t ticket) ([]byte, error) {
+	raw, err := t.encode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode ticket: %w", err)
+	}
+	if c.ticketSigner != nil {
+		raw = c.ticketSigner.Sign(raw)
+	}
+	return raw, nil
+}
+
+// decodeTicket parses a DoGet ticket in either form
+func decodeTicket(raw []byte) (ticket, error) {
+	if len(raw) == 0 || raw[0] != '{' {
+		return ticket{BatchID: string(raw)}, nil
+	}
+	var t ticket
+	if err := json.Unmarshal(raw, &t); err != nil {
+		return ticket{}, fmt.Errorf("invalid ticket: %w", err)
+	}
+	if t.Offset < 0 {
+		return ticket{}, fmt.Errorf("invalid ticket: negative offset %d", t.Offset)
+	}
+	return t, nil
+}
+
+// batchCommand returns the descriptor command or action body naming a batch
+// for a read outside DoGet, signed like tickets if the client has a
+// TicketSigner
+func (c *FlightClient) batchCommand(batchID string) []byte {
+	if c.ticketSigner != nil {
+		return c.ticketSigner.Sign([]byte(batchID))
+	}
+	return []byte(batchID)
+}
+
+// commandBatchID returns the batch ID named by the descriptor command or
+// action body of a read outside DoGet, verifying its signature if the server
+// has a TicketSigner
+func (s *FlightServer) commandBatchID(cmd []byte) (string, error) {
+	if s.ticketSigner == nil {
+		return string(cmd), nil
+	}
+	batchID, err := s.ticketSigner.Verify(cmd)
+	if err != nil {
+		return "", status.Errorf(codes.Unauthenticated, "invalid signed batch ID: %v", err)
+	}
+	return string(batchID), nil
+}
@@ -0,0 +1,150 @@
+package flight
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TestHMACTicketSigner tests that signed tickets verify and altered ones do not
+func TestHMACTicketSigner(t *testing.T) {
+	signer := HMACTicketSigner{Key: []byte("secret")}
+
+	signed := signer.Sign([]byte("batch-1"))
+	ticket, err := signer.Verify(signed)
+	require.NoError(t, err, "A signed ticket should verify")
+	assert.Equal(t, []byte("batch-1"), ticket)
+
+	forged := append([]byte{}, signed...)
+	forged[len(forged)-1] = '2'
+	_, err = signer.Verify(forged)
+	assert.Error(t, err, "An altered ticket should not verify")
+
+	_, err = HMACTicketSigner{Key: []byte("other")}.Verify(signed)
+	assert.Error(t, err, "A ticket signed under another key should not verify")
+
+	_, err = signer.Verify([]byte("batch-1"))
+	assert.Error(t, err, "An unsigned ticket should not verify")
+}
+
+// TestTicketSigner tests that a server with a signer serves signed tickets
+// and refuses unsigned or forged ones
+func TestTicketSigner(t *testing.T) {
+	signer := HMACTicketSigner{Key: []byte("secret")}
+	server, err := NewFlightServer(FlightServerConfig{TicketSigner: signer})
+	require.NoError(t, err, "Failed to create Flight server")
+	defer server.Stop()
+	addr := startBareServer(t, server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+	batchID := server.StoreBatch(batch)
+
+	signing, err := NewFlightClient(FlightClientConfig{Addr: addr, TicketSigner: signer})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer signing.Close()
+
+	retrieved, err := signing.GetBatch(ctx, batchID)
+	require.NoError(t, err, "Signed tickets should be accepted")
+	retrieved.Release()
+	projected, err := signing.GetBatchProjected(ctx, batchID, []string{"name"})
+	require.NoError(t, err, "Signed structured tickets should be accepted")
+	projected.Release()
+
+	// The server signs the tickets it lists
+	entries, err := signing.ListCatalog(ctx, nil)
+	require.NoError(t, err, "Failed to list catalog")
+	require.Len(t, entries, 1)
+	listed, err := signer.Verify(entries[0].Endpoints[0].Ticket)
+	require.NoError(t, err, "Listed tickets should be signed")
+	assert.Equal(t, batchID, string(listed))
+
+	for name, config := range map[string]FlightClientConfig{
+		"unsigned":  {Addr: addr},
+		"wrong key": {Addr: addr, TicketSigner: HMACTicketSigner{Key: []byte("guess")}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			client, err := NewFlightClient(config)
+			require.NoError(t, err, "Failed to create Flight client")
+			defer client.Close()
+
+			_, err = client.GetBatch(ctx, batchID)
+			assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		})
+	}
+}
+
+// TestTicketSignerOtherReads tests that reads of a batch outside DoGet also
+// require a signed batch ID when the server has a signer
+func TestTicketSignerOtherReads(t *testing.T) {
+	signer := HMACTicketSigner{Key: []byte("secret")}
+	server, err := NewFlightServer(FlightServerConfig{TicketSigner: signer})
+	require.NoError(t, err, "Failed to create Flight server")
+	defer server.Stop()
+	addr := startBareServer(t, server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+	batchID := server.StoreBatch(batch)
+
+	reads := []struct {
+		name string
+		read func(client *FlightClient) error
+	}{
+		{"schema", func(client *FlightClient) error { _, err := client.GetSchema(ctx, batchID); return err }},
+		{"flight info", func(client *FlightClient) error { _, err := client.EstimateTransfer(ctx, batchID); return err }},
+		{"null counts", func(client *FlightClient) error { _, err := client.GetNullCounts(ctx, batchID); return err }},
+		{"fingerprint", func(client *FlightClient) error { _, err := client.Fingerprint(ctx, batchID); return err }},
+		{"session get", func(client *FlightClient) error {
+			session, err := client.OpenSession(ctx)
+			if err != nil {
+				return err
+			}
+			defer session.Close()
+			result, err := session.GetBatch(ctx, batchID)
+			if err != nil {
+				return err
+			}
+			defer result.Close()
+			rec, err := result.Next()
+			if err != nil {
+				return err
+			}
+			rec.Release()
+			return nil
+		}},
+	}
+
+	for name, config := range map[string]FlightClientConfig{
+		"signed":    {Addr: addr, TicketSigner: signer},
+		"unsigned":  {Addr: addr},
+		"wrong key": {Addr: addr, TicketSigner: HMACTicketSigner{Key: []byte("guess")}},
+	} {
+		client, err := NewFlightClient(config)
+		require.NoError(t, err, "Failed to create Flight client")
+		defer client.Close()
+
+		for _, read := range reads {
+			t.Run(name+"/"+read.name, func(t *testing.T) {
+				err := read.read(client)
+				if name == "signed" {
+					assert.NoError(t, err, "Signed batch IDs should be accepted")
+				} else {
+					assert.Equal(t, codes.Unauthenticated, status.Code(err))
+				}
+			})
+		}
+	}
+}
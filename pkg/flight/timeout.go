@@ -0,0 +1,71 @@
+package flight
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/flight"
+)
+
+// AdaptiveTimeout scales the deadline of each transfer with the size of the
+// batch, so large transfers get the time they need while small ones fail
+// fast. The deadline of a transfer of n bytes is Base + n/Throughput, capped
+// at Max. A shorter deadline on the caller's context still applies.
+type AdaptiveTimeout struct {
+	// Base is the allowance for a transfer of no data, covering connection
+	// setup and server-side processing
+	Base time.Duration
+	// Throughput is the slowest transfer rate expected, in bytes per second
+	Throughput int64
+	// Max, if positive, caps the deadline
+	Max time.Duration
+}
+
+// validate checks the settings
+func (t *AdaptiveTimeout) validate() error {
+	if t.Base <= 0 {
+		return fmt.Errorf("base timeout must be positive, got %s", t.Base)
+	}
+	if t.Throughput <= 0 {
+		return fmt.Errorf("throughput must be positive, got %d", t.Throughput)
+	}
+	return nil
+}
+
+// timeout returns the deadline allowed for a transfer of the given size
+func (t *AdaptiveTimeout) timeout(bytes int64) time.Duration {
+	timeout := t.Base + time.Duration(float64(bytes)/float64(t.Throughput)*float64(time.Second))
+	if t.Max > 0 && timeout > t.Max {
+		timeout = t.Max
+	}
+	return timeout
+}
+
+// withTransferTimeout bounds ctx by the adaptive deadline of a transfer of
+// the given size. Without an adaptive timeout configured ctx is returned as is.
+func (c *FlightClient) withTransferTimeout(ctx context.Context, bytes int64) (context.Context, context.CancelFunc) {
+	if c.adaptiveTimeout == nil {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, c.adaptiveTimeout.timeout(bytes))
+}
+
+// sizeHint asks the server for the size of a batch through GetFlightInfo,
+// within the base timeout. It reports false if the server cannot tell.
+func (c *FlightClient) sizeHint(ctx context.Context, batchID string) (int64, bool) {
+	ctx, cancel := context.WithTimeout(ctx, c.adaptiveTimeout.Base)
+	defer cancel()
+
+	client, release, err := c.acquire(ctx)
+	if err != nil {
+		return 0, false
+	}
+	defer release()
+
+	info, err := client.GetFlightInfo(ctx, &flight.FlightDescriptor{Type: flight.DescriptorCMD, Cmd: c.batchCommand(batchID)})
+	if err != nil || info.TotalBytes < 0 {
+		return 0, false
+	}
+	return info.TotalBytes, true
+}
@@ -0,0 +1,82 @@
+package flight
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAdaptiveTimeoutScaling tests that the deadline grows with the transfer size up to the cap
+func TestAdaptiveTimeoutScaling(t *testing.T) {
+	timeout := &AdaptiveTimeout{Base: time.Second, Throughput: 1 << 20, Max: time.Minute}
+	assert.Equal(t, time.Second, timeout.timeout(0))
+	assert.Equal(t, 11*time.Second, timeout.timeout(10<<20))
+	assert.Equal(t, time.Minute, timeout.timeout(1<<40), "The cap should apply")
+
+	_, err := NewFlightClient(FlightClientConfig{AdaptiveTimeout: &AdaptiveTimeout{Base: time.Second}})
+	assert.Error(t, err, "A throughput is required")
+}
+
+// slowGetServer is a Flight server that reports a batch size and delays its downloads
+type slowGetServer struct {
+	flight.BaseFlightServer
+	batch      arrow.Record
+	totalBytes int64
+	delay      time.Duration
+}
+
+// GetFlightInfo reports the configured size
+func (s *slowGetServer) GetFlightInfo(ctx context.Context, request *flight.FlightDescriptor) (*flight.FlightInfo, error) {
+	return &flight.FlightInfo{FlightDescriptor: request, TotalRecords: -1, TotalBytes: s.totalBytes}, nil
+}
+
+// DoGet waits before writing the record
+func (s *slowGetServer) DoGet(request *flight.Ticket, stream flight.FlightService_DoGetServer) error {
+	select {
+	case <-time.After(s.delay):
+	case <-stream.Context().Done():
+		return stream.Context().Err()
+	}
+	writer := flight.NewRecordWriter(stream, ipc.WithSchema(s.batch.Schema()))
+	defer writer.Close()
+	return writer.Write(s.batch)
+}
+
+// TestAdaptiveTimeoutGet tests that download deadlines follow the size the server reports
+func TestAdaptiveTimeoutGet(t *testing.T) {
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	adaptive := &AdaptiveTimeout{Base: 100 * time.Millisecond, Throughput: 1 << 20}
+	for name, tc := range map[string]struct {
+		totalBytes int64
+		ok         bool
+	}{
+		"small batch times out":       {totalBytes: 1024, ok: false},
+		"large batch has time":        {totalBytes: 10 << 20, ok: true},
+		"unknown size is not bounded": {totalBytes: -1, ok: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			addr := startBareServer(t, &slowGetServer{batch: batch, totalBytes: tc.totalBytes, delay: 500 * time.Millisecond})
+
+			client, err := NewFlightClient(FlightClientConfig{Addr: addr, AdaptiveTimeout: adaptive})
+			require.NoError(t, err, "Failed to create Flight client")
+			defer client.Close()
+
+			retrieved, err := client.GetBatch(context.Background(), "any")
+			if !tc.ok {
+				assert.Error(t, err, "The deadline should expire")
+				return
+			}
+			require.NoError(t, err, "The download should complete")
+			retrieved.Release()
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package flight
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createTimezoneBatch creates a batch holding the same instant in a New York
+// millisecond column and a Tokyo second column, and its UTC wall time in a
+// timezone-naive column
+func createTimezoneBatch(t *testing.T, instant time.Time) arrow.Record {
+	schema := arrow.NewSchema(
+		[]arrow.Field{
+			{Name: "new_york", Type: &arrow.TimestampType{Unit: arrow.Millisecond, TimeZone: "America/New_York"}},
+			{Name: "tokyo", Type: &arrow.TimestampType{Unit: arrow.Second, TimeZone: "Asia/Tokyo"}},
+			{Name: "naive", Type: &arrow.TimestampType{Unit: arrow.Millisecond}},
+		},
+		nil,
+	)
+
+	builder := array.NewRecordBuilder(memory.NewGoAllocator(), schema)
+	defer builder.Release()
+	builder.Field(0).(*array.TimestampBuilder).AppendValues([]arrow.Timestamp{arrow.Timestamp(instant.UnixMilli()), 0}, []bool{true, false})
+	builder.Field(1).(*array.TimestampBuilder).AppendValues([]arrow.Timestamp{arrow.Timestamp(instant.Unix()), 0}, []bool{true, false})
+	builder.Field(2).(*array.TimestampBuilder).AppendValues([]arrow.Timestamp{arrow.Timestamp(instant.UnixMilli()), 0}, []bool{true, false})
+	return builder.NewRecord()
+}
+
+// TestGetBatchNormalizeTimezone tests that downloads present timestamps of
+// any source zone in the requested zone
+func TestGetBatchNormalizeTimezone(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	instant := time.Date(2024, time.January, 15, 12, 0, 0, 0, time.UTC)
+	batch := createTimezoneBatch(t, instant)
+	defer batch.Release()
+	batchID, err := client.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to put batch")
+
+	t.Run("aware", func(t *testing.T) {
+		retrieved, err := client.GetBatchWithOptions(ctx, batchID, GetOptions{NormalizeTimezone: "UTC"})
+		require.NoError(t, err, "Failed to get batch")
+		defer retrieved.Release()
+
+		expected := arrow.NewSchema(
+			[]arrow.Field{
+				{Name: "new_york", Type: &arrow.TimestampType{Unit: arrow.Millisecond, TimeZone: "UTC"}},
+				{Name: "tokyo", Type: &arrow.TimestampType{Unit: arrow.Second, TimeZone: "UTC"}},
+				{Name: "naive", Type: &arrow.TimestampType{Unit: arrow.Millisecond}},
+			},
+			nil,
+		)
+		assert.True(t, expected.Equal(retrieved.Schema()), "Units should be kept and naive columns untouched, got %s", retrieved.Schema())
+		for i := 0; i < 3; i++ {
+			col := retrieved.Column(i).(*array.Timestamp)
+			unit := col.DataType().(*arrow.TimestampType).Unit
+			assert.Equal(t, instant, col.Value(0).ToTime(unit).UTC(), "Column %d should keep its instant", i)
+			assert.Equal(t, 1, col.NullN(), "Column %d should keep its null", i)
+		}
+	})
+
+	t.Run("assumed", func(t *testing.T) {
+		retrieved, err := client.GetBatchWithOptions(ctx, batchID, GetOptions{
+			NormalizeTimezone: "UTC",
+			AssumeTimezone:    "America/New_York",
+		})
+		require.NoError(t, err, "Failed to get batch")
+		defer retrieved.Release()
+
+		naive := retrieved.Column(2).(*array.Timestamp)
+		assert.Equal(t, "UTC", naive.DataType().(*arrow.TimestampType).TimeZone)
+		assert.Equal(t, instant.Add(5*time.Hour), naive.Value(0).ToTime(arrow.Millisecond).UTC(), "Noon in New York is 17:00 UTC in January")
+		assert.True(t, naive.IsNull(1))
+	})
+
+	t.Run("invalid zone", func(t *testing.T) {
+		_, err := client.GetBatchWithOptions(ctx, batchID, GetOptions{NormalizeTimezone: "Mars/Olympus"})
+		assert.ErrorContains(t, err, "invalid time zone")
+	})
+}
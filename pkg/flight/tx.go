@@ -0,0 +1,310 @@
+package flight
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// ActionBeginTx is the DoAction type used to open a transaction. The
+	// result body is the transaction ID.
+	ActionBeginTx = "tx-begin"
+	// ActionCommitTx is the DoAction type used to make the batches staged by a
+	// transaction visible. The action body is the transaction ID.
+	ActionCommitTx = "tx-commit"
+	// ActionRollbackTx is the DoAction type used to discard the batches staged
+	// by a transaction. The action body is the transaction ID.
+	ActionRollbackTx = "tx-rollback"
+)
+
+// transaction holds the uploads staged by an open transaction
+type transaction struct {
+	batches   map[string]stagedBatch
+	started   time.Time
+	principal string // Principal that began the transaction; "" if it sent no token
+}
+
+// stagedBatch is an upload waiting for its transaction to commit
+type stagedBatch struct {
+	batch arrow.Record
+	meta  putMetadata
+}
+
+// beginTx opens a transaction owned by the request's principal and returns
+// its ID
+func (s *FlightServer) beginTx(stream flight.FlightService_DoActionServer) error {
+	principal, err := s.requestPrincipal(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	s.batchesMu.Lock()
+	txID, err := s.newTxIDLocked()
+	if err != nil {
+		s.batchesMu.Unlock()
+		return err
+	}
+	s.transactions[txID] = &transaction{batches: make(map[string]stagedBatch), started: time.Now(), principal: principal}
+	s.batchesMu.Unlock()
+
+	return stream.Send(&flight.Result{Body: []byte(txID)})
+}
+
+// newTxIDLocked generates a random transaction ID no open transaction has, so
+// that IDs cannot be guessed. Must be called with batchesMu held.
+func (s *FlightServer) newTxIDLocked() (string, error) {
+	for {
+		var id [16]byte
+		if _, err := rand.Read(id[:]); err != nil {
+			return "", fmt.Errorf("failed to read random bytes: %w", err)
+		}
+		txID := "tx-" + hex.EncodeToString(id[:])
+		if _, ok := s.transactions[txID]; !ok {
+			return txID, nil
+		}
+	}
+}
+
+// txLocked returns an open transaction, refusing requests from another
+// principal than the one that began it. Must be called with batchesMu held.
+func (s *FlightServer) txLocked(txID, principal string) (*transaction, error) {
+	tx, ok := s.transactions[txID]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "transaction %s not found", txID)
+	}
+	if tx.principal != principal {
+		return nil, status.Errorf(codes.PermissionDenied, "transaction %s belongs to another principal", txID)
+	}
+	return tx, nil
+}
+
+// stageBatchLocked adds an upload from principal to a transaction, reporting
+// whether a batch with the same ID is already staged or stored. A retry
+// asking for another access policy than that batch's is refused. Must be
+// called with batchesMu held.
+func (s *FlightServer) stageBatchLocked(txID, principal, batchID string, batch arrow.Record, meta putMetadata) (bool, error) {
+	tx, err := s.txLocked(txID, principal)
+	if err != nil {
+		return false, err
+	}
+	if staged, ok := tx.batches[batchID]; ok {
+		return true, checkRetryPolicy(batchID, staged.meta.Access, meta.Access)
+	}
+	if _, ok := s.batches[batchID]; ok {
+		return true, checkRetryPolicy(batchID, s.policies[batchID], meta.Access)
+	}
+	tx.batches[batchID] = stagedBatch{batch: batch, meta: meta}
+	return false, nil
+}
+
+// unstageBatchLocked releases a staged upload. Must be called with batchesMu
+// held.
+func (s *FlightServer) unstageBatchLocked(txID, batchID string) {
+	tx, ok := s.transactions[txID]
+	if !ok {
+		return
+	}
+	if staged, ok := tx.batches[batchID]; ok {
+		staged.batch.Release()
+		delete(tx.batches, batchID)
+	}
+}
+
+// commitTx stores every batch staged by a transaction at once and closes it,
+// on behalf of the principal that began it. If another batch has been stored
+// under the ID of a staged one, nothing is stored and the commit fails with
+// codes.AlreadyExists.
+func (s *FlightServer) commitTx(ctx context.Context, txID string) error {
+	principal, err := s.requestPrincipal(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.batchesMu.Lock()
+	tx, err := s.txLocked(txID, principal)
+	if err != nil {
+		s.batchesMu.Unlock()
+		return err
+	}
+
+	// A batch stored under a staged ID since it was staged is not the
+	// transaction's to replace, so the whole transaction is discarded
+	var conflicts []string
+	for batchID := range tx.batches {
+		if _, ok := s.batches[batchID]; ok {
+			conflicts = append(conflicts, batchID)
+		}
+	}
+	if len(conflicts) > 0 {
+		s.rollbackTxLocked(txID)
+		s.batchesMu.Unlock()
+		slices.Sort(conflicts)
+		return status.Errorf(codes.AlreadyExists, "transaction %s rolled back: batches %s were stored since they were staged",
+			txID, strings.Join(conflicts, ", "))
+	}
+	delete(s.transactions, txID)
+
+	committed := make([]string, 0, len(tx.batches))
+	expiration := time.Now().Add(s.ttl)
+	for batchID, staged := range tx.batches {
+		s.storeBatchLocked(batchID, staged.batch, staged.meta)
+		s.expirations[batchID] = expiration
+		committed = append(committed, batchID)
+	}
+	s.batchesMu.Unlock()
+
+	for _, batchID := range committed {
+		s.notifyWatchers(batchID)
+	}
+	return nil
+}
+
+// rollbackTx discards every batch staged by a transaction and closes it, on
+// behalf of the principal that began it. Unknown transactions, such as ones
+// already rolled back, are ignored.
+func (s *FlightServer) rollbackTx(ctx context.Context, txID string) error {
+	principal, err := s.requestPrincipal(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.batchesMu.Lock()
+	defer s.batchesMu.Unlock()
+
+	if _, err := s.txLocked(txID, principal); status.Code(err) == codes.PermissionDenied {
+		return err
+	}
+	s.rollbackTxLocked(txID)
+	return nil
+}
+
+// rollbackTxLocked implements rollbackTx. Must be called with batchesMu held.
+func (s *FlightServer) rollbackTxLocked(txID string) {
+	tx, ok := s.transactions[txID]
+	if !ok {
+		return
+	}
+	for _, staged := range tx.batches {
+		staged.batch.Release()
+	}
+	delete(s.transactions, txID)
+}
+
+// Tx is a transaction grouping several uploads that become visible together
+// on Commit, or are all discarded on Rollback. Until then the staged batches
+// are held by the server but cannot be read, listed or watched. A Tx is safe
+// for concurrent use; uploads still in flight when it is committed fail.
+type Tx struct {
+	client *FlightClient
+	id     string
+	stop   func() bool // Stops the rollback on cancellation of BeginTx's context
+	mu     sync.Mutex
+	done   bool
+}
+
+// BeginTx opens a transaction on the server. The transaction is rolled back
+// when ctx ends unless it was committed first, so ctx should cover the whole
+// transaction rather than only this call. A transaction left open is also
+// discarded by the server once its batches would have expired. The server
+// ties the transaction to the principal of the access token sent with ctx
+// (WithAccessToken), if any, and refuses uploads, commits and rollbacks that
+// do not send the same principal's token. ErrNotSupported is returned if the
+// server lacks transactions.
+func (c *FlightClient) BeginTx(ctx context.Context) (*Tx, error) {
+	if err := c.requireFeature(ctx, "transactions", func(s ServerCapabilities) bool { return s.Transactions }); err != nil {
+		return nil, err
+	}
+
+	body, err := c.doAction(ctx, ActionBeginTx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	tx := &Tx{client: c, id: string(body)}
+	tx.mu.Lock()
+	tx.stop = context.AfterFunc(ctx, func() {
+		// Keep ctx's access token, which the rollback needs
+		tx.Rollback(context.WithoutCancel(ctx))
+	})
+	tx.mu.Unlock()
+	return tx, nil
+}
+
+// ID returns the server's ID of the transaction
+func (tx *Tx) ID() string {
+	return tx.id
+}
+
+// PutBatch stages a batch in the transaction and returns its batch ID
+func (tx *Tx) PutBatch(ctx context.Context, batch arrow.Record) (string, error) {
+	result, err := tx.PutBatchWithOptions(ctx, batch, PutOptions{})
+	if err != nil {
+		return "", err
+	}
+	return result.BatchID, nil
+}
+
+// PutBatchWithOptions stages a batch in the transaction, as
+// FlightClient.PutBatchWithOptions does. DedupeByContent is ignored, since a
+// staged batch cannot stand in for another upload.
+func (tx *Tx) PutBatchWithOptions(ctx context.Context, batch arrow.Record, options PutOptions) (*PutBatchResult, error) {
+	tx.mu.Lock()
+	done := tx.done
+	tx.mu.Unlock()
+	if done {
+		return nil, fmt.Errorf("%w: %s", ErrTxDone, tx.id)
+	}
+
+	options.txID = tx.id
+	return tx.client.PutBatchWithOptions(ctx, batch, options)
+}
+
+// Commit makes every batch staged in the transaction visible at once. If a
+// batch was stored outside the transaction under the ID of a staged one since
+// it was staged, nothing is committed and the server's codes.AlreadyExists
+// error is returned. The transaction is over afterwards, even if Commit fails.
+func (tx *Tx) Commit(ctx context.Context) error {
+	if !tx.finish() {
+		return fmt.Errorf("%w: %s", ErrTxDone, tx.id)
+	}
+	if _, err := tx.client.doAction(ctx, ActionCommitTx, []byte(tx.id)); err != nil {
+		return fmt.Errorf("failed to commit transaction %s: %w", tx.id, err)
+	}
+	return nil
+}
+
+// Rollback discards every batch staged in the transaction. Rolling back a
+// transaction that is already over does nothing.
+func (tx *Tx) Rollback(ctx context.Context) error {
+	if !tx.finish() {
+		return nil
+	}
+	if _, err := tx.client.doAction(ctx, ActionRollbackTx, []byte(tx.id)); err != nil {
+		return fmt.Errorf("failed to roll back transaction %s: %w", tx.id, err)
+	}
+	return nil
+}
+
+// finish marks the transaction over, reporting false if it already was
+func (tx *Tx) finish() bool {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.done {
+		return false
+	}
+	tx.done = true
+	tx.stop()
+	return true
+}
@@ -0,0 +1,204 @@
+package flight
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TestTxCommit tests that staged batches become visible together on commit
+func TestTxCommit(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	tx, err := client.BeginTx(ctx)
+	require.NoError(t, err, "Failed to begin transaction")
+
+	firstID, err := tx.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to stage first batch")
+	secondID, err := tx.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to stage second batch")
+
+	// Staged batches can be neither read nor listed
+	_, err = client.GetBatch(ctx, firstID)
+	assert.Error(t, err, "Staged batches should not be readable")
+	ids, err := client.ListBatches(ctx)
+	require.NoError(t, err, "Failed to list batches")
+	assert.Empty(t, ids, "Staged batches should not be listed")
+
+	require.NoError(t, tx.Commit(ctx), "Failed to commit transaction")
+
+	ids, err = client.ListBatches(ctx)
+	require.NoError(t, err, "Failed to list batches")
+	assert.ElementsMatch(t, []string{firstID, secondID}, ids)
+	retrieved, err := client.GetBatch(ctx, secondID)
+	require.NoError(t, err, "Committed batches should be readable")
+	defer retrieved.Release()
+	assert.Equal(t, batch.NumRows(), retrieved.NumRows())
+
+	// The transaction is over
+	_, err = tx.PutBatch(ctx, batch)
+	assert.ErrorIs(t, err, ErrTxDone)
+	assert.ErrorIs(t, tx.Commit(ctx), ErrTxDone)
+	assert.NoError(t, tx.Rollback(ctx), "Rolling back a finished transaction does nothing")
+}
+
+// TestTxRollback tests that rolling back, explicitly or by cancelling the
+// transaction's context, discards the staged batches
+func TestTxRollback(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	tx, err := client.BeginTx(ctx)
+	require.NoError(t, err, "Failed to begin transaction")
+	_, err = tx.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to stage batch")
+	require.NoError(t, tx.Rollback(ctx), "Failed to roll back transaction")
+	assert.ErrorIs(t, tx.Commit(ctx), ErrTxDone)
+
+	txCtx, txCancel := context.WithCancel(ctx)
+	cancelled, err := client.BeginTx(txCtx)
+	require.NoError(t, err, "Failed to begin transaction")
+	_, err = cancelled.PutBatch(txCtx, batch)
+	require.NoError(t, err, "Failed to stage batch")
+	txCancel()
+
+	assert.Eventually(t, func() bool {
+		server.batchesMu.RLock()
+		defer server.batchesMu.RUnlock()
+		return len(server.transactions) == 0
+	}, 2*time.Second, 10*time.Millisecond, "Cancelling the context should roll back the transaction")
+	assert.ErrorIs(t, cancelled.Commit(ctx), ErrTxDone)
+
+	ids, err := client.ListBatches(ctx)
+	require.NoError(t, err, "Failed to list batches")
+	assert.Empty(t, ids, "Rolled back batches should be discarded")
+}
+
+// TestTxPrincipal tests that a transaction can only be used by the principal
+// that began it, and that transaction IDs are not predictable
+func TestTxPrincipal(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	alice := WithAccessToken(ctx, "alice")
+	bob := WithAccessToken(ctx, "bob")
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	tx, err := client.BeginTx(alice)
+	require.NoError(t, err, "Failed to begin transaction")
+	other, err := client.BeginTx(bob)
+	require.NoError(t, err, "Failed to begin transaction")
+	defer other.Rollback(bob)
+	assert.NotEqual(t, tx.ID(), other.ID())
+	assert.Len(t, tx.ID(), len("tx-")+32, "Transaction IDs should be random")
+
+	batchID, err := tx.PutBatch(alice, batch)
+	require.NoError(t, err, "Failed to stage batch")
+
+	// Other principals, or requests without a token, may not use it
+	for name, ctx := range map[string]context.Context{"other principal": bob, "no token": ctx} {
+		t.Run(name, func(t *testing.T) {
+			stolen := &Tx{client: client, id: tx.ID(), stop: func() bool { return true }}
+			_, err := stolen.PutBatch(ctx, batch)
+			assert.Equal(t, codes.PermissionDenied, status.Code(err), "Staging should be denied")
+
+			stolen = &Tx{client: client, id: tx.ID(), stop: func() bool { return true }}
+			assert.Equal(t, codes.PermissionDenied, status.Code(stolen.Commit(ctx)), "Committing should be denied")
+			stolen = &Tx{client: client, id: tx.ID(), stop: func() bool { return true }}
+			assert.Equal(t, codes.PermissionDenied, status.Code(stolen.Rollback(ctx)), "Rolling back should be denied")
+		})
+	}
+
+	require.NoError(t, tx.Commit(alice), "The owner should commit the transaction")
+	retrieved, err := client.GetBatch(ctx, batchID)
+	require.NoError(t, err, "The staged batch should be committed")
+	retrieved.Release()
+}
+
+// TestTxCommitConflict tests that a commit fails, storing nothing, when a
+// batch was stored under the ID of a staged one after it was staged
+func TestTxCommitConflict(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	var next atomic.Int32
+	staging, err := NewFlightClient(FlightClientConfig{Addr: addr, IDGenerator: IDGeneratorFunc(func(arrow.Record) (string, error) {
+		return fmt.Sprintf("staged-%d", next.Add(1)), nil
+	})})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer staging.Close()
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr, IDGenerator: IDGeneratorFunc(func(arrow.Record) (string, error) {
+		return "staged-2", nil
+	})})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	tx, err := staging.BeginTx(ctx)
+	require.NoError(t, err, "Failed to begin transaction")
+	for i := 0; i < 2; i++ {
+		_, err = tx.PutBatch(ctx, batch)
+		require.NoError(t, err, "Failed to stage batch")
+	}
+
+	_, err = client.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to store batch")
+	server.batchesMu.RLock()
+	expiration := server.expirations["staged-2"]
+	server.batchesMu.RUnlock()
+
+	err = tx.Commit(ctx)
+	assert.Equal(t, codes.AlreadyExists, status.Code(err), "The commit should fail")
+	assert.ErrorContains(t, err, "staged-2")
+
+	ids, err := client.ListBatches(ctx)
+	require.NoError(t, err, "Failed to list batches")
+	assert.Equal(t, []string{"staged-2"}, ids, "Nothing should be committed")
+	server.batchesMu.RLock()
+	assert.Equal(t, expiration, server.expirations["staged-2"], "The stored batch's lifetime should be kept")
+	assert.Empty(t, server.transactions, "The transaction should be over")
+	server.batchesMu.RUnlock()
+}
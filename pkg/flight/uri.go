@@ -0,0 +1,73 @@
+package flight
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/apache/arrow-go/v18/arrow"
+)
+
+// BatchURIScheme is the URI scheme of batch references produced by BatchURI
+const BatchURIScheme = "flight"
+
+// batchURIPrefix precedes the batch ID in the path of a batch URI
+const batchURIPrefix = "/batch/"
+
+// BatchURI returns a shareable reference to a batch stored on the client's
+// server, of the form flight://host:port/batch/<id>
+func (c *FlightClient) BatchURI(batchID string) string {
+	u := url.URL{
+		Scheme:  BatchURIScheme,
+		Host:    c.ActiveAddr(),
+		Path:    batchURIPrefix + batchID,
+		RawPath: batchURIPrefix + url.PathEscape(batchID),
+	}
+	return u.String()
+}
+
+// ParseBatchURI splits a URI produced by BatchURI into the server address and
+// the batch ID
+func ParseBatchURI(uri string) (addr, batchID string, err error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid batch URI: %w", err)
+	}
+	if u.Scheme != BatchURIScheme {
+		return "", "", fmt.Errorf("invalid batch URI %q: scheme must be %q", uri, BatchURIScheme)
+	}
+	if u.Host == "" {
+		return "", "", fmt.Errorf("invalid batch URI %q: missing server address", uri)
+	}
+
+	batchID, ok := strings.CutPrefix(u.Path, batchURIPrefix)
+	if !ok || batchID == "" {
+		return "", "", fmt.Errorf("invalid batch URI %q: path must be %s<id>", uri, batchURIPrefix)
+	}
+	return u.Host, batchID, nil
+}
+
+// GetBatchFromURI retrieves the batch referenced by a batch URI. Batches on
+// other servers are fetched by a temporary client with this client's
+// configuration and connection pool, without its failover addresses.
+func (c *FlightClient) GetBatchFromURI(ctx context.Context, uri string) (arrow.Record, error) {
+	addr, batchID, err := ParseBatchURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	if addr == c.ActiveAddr() {
+		return c.GetBatch(ctx, batchID)
+	}
+
+	config := c.config
+	config.Addr = addr
+	config.FailoverAddrs = nil
+	remote, err := newFlightClient(config, c.pool)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client for %s: %w", addr, err)
+	}
+	defer remote.Close()
+
+	return remote.GetBatch(ctx, batchID)
+}
@@ -0,0 +1,101 @@
+package flight
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBatchURIRoundTrip tests that batch URIs survive parsing, including IDs that need escaping
+func TestBatchURIRoundTrip(t *testing.T) {
+	client, err := NewFlightClient(FlightClientConfig{Addr: "flight.example.com:8815"})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	uri := client.BatchURI("batch-1")
+	assert.Equal(t, "flight://flight.example.com:8815/batch/batch-1", uri)
+
+	for _, batchID := range []string{"batch-1", "sales/2024 q1", "a%b"} {
+		addr, parsed, err := ParseBatchURI(client.BatchURI(batchID))
+		require.NoError(t, err, "Failed to parse URI for %q", batchID)
+		assert.Equal(t, "flight.example.com:8815", addr)
+		assert.Equal(t, batchID, parsed)
+	}
+
+	for _, uri := range []string{
+		"grpc://host:1/batch/x",
+		"flight:///batch/x",
+		"flight://host:1/other/x",
+		"flight://host:1/batch/",
+	} {
+		_, _, err := ParseBatchURI(uri)
+		assert.Error(t, err, "URI %q should be rejected", uri)
+	}
+}
+
+// TestGetBatchFromURI tests fetching a batch referenced on another server
+func TestGetBatchFromURI(t *testing.T) {
+	localServer, localAddr := startTestServer(t)
+	defer localServer.Stop()
+	remoteServer, remoteAddr := startTestServer(t)
+	defer remoteServer.Stop()
+
+	local, err := NewFlightClient(FlightClientConfig{Addr: localAddr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer local.Close()
+	remote, err := NewFlightClient(FlightClientConfig{Addr: remoteAddr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer remote.Close()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batchID, err := remote.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to put batch")
+
+	for name, client := range map[string]*FlightClient{"same server": remote, "other server": local} {
+		retrieved, err := client.GetBatchFromURI(ctx, remote.BatchURI(batchID))
+		require.NoError(t, err, "Failed to get batch from URI via %s", name)
+		assert.True(t, array.RecordEqual(batch, retrieved), "Retrieved record should match via %s", name)
+		retrieved.Release()
+	}
+}
+
+// TestGetBatchFromURIConfig tests that batches on other servers are fetched
+// with the client's full configuration, such as its ticket signer
+func TestGetBatchFromURIConfig(t *testing.T) {
+	signer := HMACTicketSigner{Key: []byte("secret")}
+	signedServer, err := NewFlightServer(FlightServerConfig{TicketSigner: signer})
+	require.NoError(t, err, "Failed to create Flight server")
+	defer signedServer.Stop()
+	signedAddr := startBareServer(t, signedServer)
+
+	localServer, localAddr := startTestServer(t)
+	defer localServer.Stop()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+	batchID := signedServer.StoreBatch(batch)
+
+	local, err := NewFlightClient(FlightClientConfig{Addr: localAddr, TicketSigner: signer})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer local.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	uri := (&url.URL{Scheme: BatchURIScheme, Host: signedAddr, Path: "/batch/" + batchID}).String()
+	retrieved, err := local.GetBatchFromURI(ctx, uri)
+	require.NoError(t, err, "The remote fetch should sign its ticket")
+	defer retrieved.Release()
+	assert.True(t, array.RecordEqual(batch, retrieved))
+}
@@ -0,0 +1,101 @@
+package flight
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+)
+
+const (
+	// ActionVersion is the DoAction type used to exchange version information.
+	// The result body is a JSON-encoded VersionInfo.
+	ActionVersion = "version"
+
+	// ProtocolVersion is the version of this package's wire conventions
+	// (tickets, put metadata and custom actions) on top of Arrow Flight
+	ProtocolVersion = 1
+
+	// compatibilityCheckTimeout bounds the check run by NewFlightClient
+	compatibilityCheckTimeout = 10 * time.Second
+)
+
+// ErrIncompatibleServer is returned when the client and server report
+// versions that cannot interoperate
+var ErrIncompatibleServer = errors.New("incompatible Flight server")
+
+// VersionInfo describes the versions a client or server was built with
+type VersionInfo struct {
+	// ProtocolVersion is the server's ProtocolVersion
+	ProtocolVersion int `json:"protocolVersion"`
+	// ArrowVersion is the arrow-go release the server was built with
+	ArrowVersion string `json:"arrowVersion"`
+}
+
+// LocalVersion returns the versions this binary was built with
+func LocalVersion() VersionInfo {
+	return VersionInfo{ProtocolVersion: ProtocolVersion, ArrowVersion: arrow.PkgVersion}
+}
+
+// compatibleWith returns an ErrIncompatibleServer error naming the mismatch
+// if a server reporting remote cannot serve this client
+func (v VersionInfo) compatibleWith(remote VersionInfo) error {
+	if remote.ProtocolVersion != v.ProtocolVersion {
+		return fmt.Errorf("%w: server speaks protocol version %d, client speaks %d",
+			ErrIncompatibleServer, remote.ProtocolVersion, v.ProtocolVersion)
+	}
+	if majorVersion(remote.ArrowVersion) != majorVersion(v.ArrowVersion) {
+		return fmt.Errorf("%w: server uses Arrow %s, client uses Arrow %s",
+			ErrIncompatibleServer, remote.ArrowVersion, v.ArrowVersion)
+	}
+	return nil
+}
+
+// majorVersion returns the major component of a semantic version
+func majorVersion(version string) string {
+	major, _, _ := strings.Cut(strings.TrimPrefix(version, "v"), ".")
+	return major
+}
+
+// serverVersion reports the server's versions
+func (s *FlightServer) serverVersion(stream flight.FlightService_DoActionServer) error {
+	body, err := json.Marshal(LocalVersion())
+	if err != nil {
+		return fmt.Errorf("failed to encode version: %w", err)
+	}
+	return stream.Send(&flight.Result{Body: body})
+}
+
+// ServerVersion asks the server for the versions it was built with.
+// ErrNotSupported is returned if the server predates the version action.
+func (c *FlightClient) ServerVersion(ctx context.Context) (VersionInfo, error) {
+	body, err := c.doAction(ctx, ActionVersion, nil)
+	if err != nil {
+		return VersionInfo{}, fmt.Errorf("failed to get server version: %w", err)
+	}
+
+	var version VersionInfo
+	if err := json.Unmarshal(body, &version); err != nil {
+		return VersionInfo{}, fmt.Errorf("failed to decode server version: %w", err)
+	}
+	return version, nil
+}
+
+// CheckCompatibility compares the server's versions with the client's and
+// returns an ErrIncompatibleServer error naming the mismatch. Servers that
+// predate the version action cannot be checked and are assumed compatible.
+func (c *FlightClient) CheckCompatibility(ctx context.Context) error {
+	remote, err := c.ServerVersion(ctx)
+	if errors.Is(err, ErrNotSupported) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return LocalVersion().compatibleWith(remote)
+}
@@ -0,0 +1,66 @@
+package flight
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// versionServer is a Flight server that reports a fixed version
+type versionServer struct {
+	flight.BaseFlightServer
+	version string
+}
+
+// DoAction answers the version action with the configured body
+func (s *versionServer) DoAction(action *flight.Action, stream flight.FlightService_DoActionServer) error {
+	return stream.Send(&flight.Result{Body: []byte(s.version)})
+}
+
+// TestServerVersion tests that the server reports the versions it was built with
+func TestServerVersion(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr, VerifyCompatibility: true})
+	require.NoError(t, err, "Matching versions should be compatible")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	version, err := client.ServerVersion(ctx)
+	require.NoError(t, err, "Failed to get server version")
+	assert.Equal(t, LocalVersion(), version)
+}
+
+// TestVerifyCompatibility tests that mismatched servers are rejected with an error naming the mismatch
+func TestVerifyCompatibility(t *testing.T) {
+	tests := map[string]struct {
+		version string
+		message string
+	}{
+		"arrow":    {`{"protocolVersion":1,"arrowVersion":"17.0.0"}`, "server uses Arrow 17.0.0"},
+		"protocol": {`{"protocolVersion":2,"arrowVersion":"18.2.0"}`, "server speaks protocol version 2"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			addr := startBareServer(t, &versionServer{version: tc.version})
+
+			_, err := NewFlightClient(FlightClientConfig{Addr: addr, VerifyCompatibility: true})
+			assert.ErrorIs(t, err, ErrIncompatibleServer)
+			assert.ErrorContains(t, err, tc.message)
+		})
+	}
+
+	// Servers without the version action cannot be checked
+	addr := startBareServer(t, &flight.BaseFlightServer{})
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr, VerifyCompatibility: true})
+	require.NoError(t, err, "Servers without the version action should be assumed compatible")
+	client.Close()
+}
@@ -0,0 +1,77 @@
+package flight
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Poll intervals of WaitForBatch
+const (
+	waitDefaultInterval = 100 * time.Millisecond
+	waitMaxInterval     = 5 * time.Second
+)
+
+// WaitForBatch waits until a batch is stored on the server, then retrieves
+// it, for consumers that may ask for a batch before its producer has
+// uploaded it. Availability is polled with GetFlightInfo, right away and
+// then after pollInterval (default: 100ms), doubled after every miss up to
+// 5s (or pollInterval, if longer). Each poll is a round trip to the server,
+// so prefer SubscribeBatches when many batches are awaited at once; the
+// backoff bounds the cost of a long wait to about one call every 5s. Polling
+// stops when ctx is done. A NotFound status is a miss, as is an Unknown one
+// whose message says the batch was not found, which servers that do not set
+// status codes return; other errors end the wait.
+func (c *FlightClient) WaitForBatch(ctx context.Context, batchID string, pollInterval time.Duration) (arrow.Record, error) {
+	if pollInterval <= 0 {
+		pollInterval = waitDefaultInterval
+	}
+
+	for {
+		found, err := c.batchStored(ctx, batchID)
+		if err != nil && ctx.Err() != nil {
+			return nil, fmt.Errorf("batch %s did not become available: %w", batchID, ctx.Err())
+		}
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			return c.GetBatch(ctx, batchID)
+		}
+
+		select {
+		case <-time.After(pollInterval):
+		case <-ctx.Done():
+			return nil, fmt.Errorf("batch %s did not become available: %w", batchID, ctx.Err())
+		}
+		pollInterval = min(pollInterval*2, max(waitMaxInterval, pollInterval))
+	}
+}
+
+// batchStored asks the server whether it holds a batch
+func (c *FlightClient) batchStored(ctx context.Context, batchID string) (bool, error) {
+	client, release, err := c.acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer release()
+
+	_, err = client.GetFlightInfo(ctx, &flight.FlightDescriptor{Type: flight.DescriptorCMD, Cmd: c.batchCommand(batchID)})
+	switch status.Code(err) {
+	case codes.OK:
+		return true, nil
+	case codes.NotFound:
+		return false, nil
+	case codes.Unknown:
+		if strings.Contains(status.Convert(err).Message(), "not found") {
+			return false, nil
+		}
+	}
+	return false, fmt.Errorf("failed to get flight info for batch %s: %w", batchID, err)
+}
@@ -0,0 +1,106 @@
+package flight
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TestWaitForBatch tests that a consumer waiting for a batch receives it once it is uploaded
+func TestWaitForBatch(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	// The producer publishes the batch under a name after a delay
+	produced := make(chan error, 1)
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		_, err := client.PutBatchAtomic(ctx, "daily-report", batch)
+		produced <- err
+	}()
+
+	start := time.Now()
+	retrieved, err := client.WaitForBatch(ctx, "daily-report", 20*time.Millisecond)
+	require.NoError(t, err, "Failed to wait for batch")
+	defer retrieved.Release()
+	require.NoError(t, <-produced)
+
+	assert.GreaterOrEqual(t, time.Since(start), 300*time.Millisecond, "The batch should not exist before it is uploaded")
+	assert.Equal(t, batch.NumRows(), retrieved.NumRows())
+
+	// The wait gives up with the context
+	short, cancelShort := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancelShort()
+	_, err = client.WaitForBatch(short, "never-uploaded", 0)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+// uncodedServer is a FlightServer whose GetFlightInfo errors carry no status
+// code, like those of servers that return plain errors
+type uncodedServer struct {
+	*FlightServer
+}
+
+// GetFlightInfo strips the status code from errors
+func (s *uncodedServer) GetFlightInfo(ctx context.Context, desc *flight.FlightDescriptor) (*flight.FlightInfo, error) {
+	info, err := s.FlightServer.GetFlightInfo(ctx, desc)
+	if err != nil {
+		return nil, errors.New(status.Convert(err).Message())
+	}
+	return info, nil
+}
+
+// TestWaitForBatchUncoded tests that a not found error without a status code
+// is a miss, and that the server reports missing batches as NotFound
+func TestWaitForBatchUncoded(t *testing.T) {
+	server, err := NewFlightServer(FlightServerConfig{})
+	require.NoError(t, err, "Failed to create Flight server")
+	defer server.Stop()
+	addr := startBareServer(t, &uncodedServer{FlightServer: server})
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	produced := make(chan error, 1)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_, err := client.PutBatchAtomic(ctx, "daily-report", batch)
+		produced <- err
+	}()
+
+	retrieved, err := client.WaitForBatch(ctx, "daily-report", 20*time.Millisecond)
+	require.NoError(t, err, "Failed to wait for batch")
+	defer retrieved.Release()
+	require.NoError(t, <-produced)
+	assert.Equal(t, batch.NumRows(), retrieved.NumRows())
+
+	_, err = client.GetBatch(ctx, "never-uploaded")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = server.RetrieveBatch("never-uploaded")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -0,0 +1,204 @@
+package flight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// ActionWatchBatches is the DoAction type used to subscribe to new batches.
+	// The action body is the batch ID prefix to match (empty matches everything).
+	ActionWatchBatches = "watch"
+
+	// watcherBufferSize is the number of pending notifications kept per subscriber
+	watcherBufferSize = 256
+
+	// Reconnect backoff bounds for SubscribeBatches
+	subscribeInitialBackoff = 100 * time.Millisecond
+	subscribeMaxBackoff     = 5 * time.Second
+)
+
+// batchWatcher is a single subscriber registered through the watch action
+type batchWatcher struct {
+	prefix string
+	ch     chan string
+}
+
+// notifyWatchers sends a newly stored batch ID to every matching subscriber.
+// Slow subscribers that have filled their buffer miss the notification rather
+// than blocking the write path.
+func (s *FlightServer) notifyWatchers(batchID string) {
+	s.watchersMu.Lock()
+	defer s.watchersMu.Unlock()
+
+	for w := range s.watchers {
+		if !strings.HasPrefix(batchID, w.prefix) {
+			continue
+		}
+		select {
+		case w.ch <- batchID:
+		default:
+			fmt.Printf("Dropping batch notification %s for slow subscriber\n", batchID)
+		}
+	}
+}
+
+// watchBatches streams the IDs of newly stored batches the client may read
+// until the client goes away or the server stops. An empty result is sent first to acknowledge the
+// subscription so clients can detect support without waiting for a batch.
+func (s *FlightServer) watchBatches(prefix string, stream flight.FlightService_DoActionServer) error {
+	w := &batchWatcher{prefix: prefix, ch: make(chan string, watcherBufferSize)}
+
+	s.watchersMu.Lock()
+	s.watchers[w] = struct{}{}
+	s.watchersMu.Unlock()
+
+	defer func() {
+		s.watchersMu.Lock()
+		delete(s.watchers, w)
+		s.watchersMu.Unlock()
+	}()
+
+	if err := stream.Send(&flight.Result{}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case batchID := <-w.ch:
+			if s.authorize(stream.Context(), batchID) != nil {
+				continue
+			}
+			if err := stream.Send(&flight.Result{Body: []byte(batchID)}); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-s.done:
+			return nil
+		}
+	}
+}
+
+// SubscribeBatches returns a channel that yields the IDs of batches stored on the
+// server whose ID starts with prefix. The subscription is a long-lived DoAction
+// stream which is transparently re-established if it drops. Delivery is at
+// most once: batches stored while the subscription is re-established, or while
+// the consumer lags so far behind that the server's buffer fills up, are not
+// reported, so consumers that must see every batch should reconcile with
+// ListBatches. The channel is closed when ctx is cancelled, when the client is
+// closed, or when the subscription cannot be re-established because the
+// server no longer supports it or refuses the request's credentials.
+// ErrNotSupported is returned if the server does not implement the watch
+// action.
+func (c *FlightClient) SubscribeBatches(ctx context.Context, prefix string) (<-chan string, error) {
+	// The subscription holds the connection open for its whole lifetime
+	client, release, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := openWatch(ctx, client, prefix)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	ids := make(chan string)
+	go func() {
+		defer close(ids)
+		defer func() { release() }()
+
+		backoff := subscribeInitialBackoff
+		for {
+			// Forward notifications until the stream breaks
+			for {
+				result, err := stream.Recv()
+				if err != nil {
+					break
+				}
+				backoff = subscribeInitialBackoff
+				if len(result.Body) == 0 {
+					continue
+				}
+				select {
+				case ids <- string(result.Body):
+				case <-ctx.Done():
+					return
+				case <-c.done:
+					return
+				}
+			}
+
+			// Reconnect with exponential backoff until the context is
+			// cancelled, the client is closed or the server refuses for good.
+			// Each attempt acquires the connection anew, since the client may
+			// have failed over in the meantime.
+			for {
+				select {
+				case <-time.After(backoff):
+				case <-ctx.Done():
+					return
+				case <-c.done:
+					return
+				}
+				backoff = min(backoff*2, subscribeMaxBackoff)
+
+				release()
+				client, release, err = c.acquire(ctx)
+				if err == nil {
+					if stream, err = openWatch(ctx, client, prefix); err == nil {
+						break
+					}
+					release()
+				}
+				release = func() {}
+				if watchEnded(err) {
+					return
+				}
+			}
+		}
+	}()
+
+	return ids, nil
+}
+
+// watchEnded reports whether a failure to re-establish a subscription is
+// final, so retrying cannot succeed
+func watchEnded(err error) bool {
+	if errors.Is(err, ErrClientClosed) || errors.Is(err, ErrNotSupported) {
+		return true
+	}
+	switch status.Code(err) {
+	case codes.Unauthenticated, codes.PermissionDenied:
+		return true
+	}
+	return false
+}
+
+// openWatch starts a watch action and waits for the server's acknowledgement
+func openWatch(ctx context.Context, client flight.Client, prefix string) (flight.FlightService_DoActionClient, error) {
+	stream, err := client.DoAction(ctx, &flight.Action{
+		Type: ActionWatchBatches,
+		Body: []byte(prefix),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start watch action: %w", err)
+	}
+
+	if _, err := stream.Recv(); err != nil {
+		if status.Code(err) == codes.Unimplemented {
+			return nil, fmt.Errorf("%w: batch subscriptions", ErrNotSupported)
+		}
+		return nil, fmt.Errorf("failed to subscribe to batches: %w", err)
+	}
+
+	return stream, nil
+}
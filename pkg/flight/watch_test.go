@@ -0,0 +1,138 @@
+package flight
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// startBareServer starts a Flight server that implements none of the optional RPCs
+func startBareServer(t *testing.T, srv flight.FlightServer) string {
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err, "Failed to listen")
+
+	server := grpc.NewServer()
+	flight.RegisterFlightServiceServer(server, srv)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	return listener.Addr().String()
+}
+
+// TestSubscribeBatches tests that new batch IDs are delivered to subscribers
+func TestSubscribeBatches(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ids, err := client.SubscribeBatches(ctx, "batch-")
+	require.NoError(t, err, "Failed to subscribe")
+
+	batch := createTestBatch(t, memory.NewGoAllocator())
+	defer batch.Release()
+
+	batchID, err := client.PutBatch(ctx, batch)
+	require.NoError(t, err, "Failed to put batch")
+
+	select {
+	case id := <-ids:
+		assert.Equal(t, batchID, id, "Subscriber should see the new batch")
+	case <-ctx.Done():
+		t.Fatal("Timed out waiting for batch notification")
+	}
+
+	// Cancelling the context closes the channel
+	cancel()
+	for range ids {
+	}
+}
+
+// TestSubscribeBatchesUnsupported tests the error returned by servers without the watch action
+func TestSubscribeBatchesUnsupported(t *testing.T) {
+	addr := startBareServer(t, &flight.BaseFlightServer{})
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = client.SubscribeBatches(ctx, "")
+	assert.ErrorIs(t, err, ErrNotSupported)
+}
+
+// TestSubscribeBatchesClientClosed tests that closing the client closes the
+// subscription's channel
+func TestSubscribeBatchesClientClosed(t *testing.T) {
+	server, addr := startTestServer(t)
+	defer server.Stop()
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+
+	ids, err := client.SubscribeBatches(context.Background(), "")
+	require.NoError(t, err, "Failed to subscribe")
+
+	client.Close()
+	select {
+	case _, ok := <-ids:
+		assert.False(t, ok, "No batch was stored")
+	case <-time.After(5 * time.Second):
+		t.Fatal("The channel should close with the client")
+	}
+}
+
+// refusingWatchServer acknowledges the first watch action and ends it, then
+// refuses to watch again
+type refusingWatchServer struct {
+	flight.BaseFlightServer
+	watches atomic.Int32
+}
+
+// DoAction implements flight.FlightServer
+func (s *refusingWatchServer) DoAction(action *flight.Action, stream flight.FlightService_DoActionServer) error {
+	if s.watches.Add(1) > 1 {
+		return status.Error(codes.PermissionDenied, "subscription revoked")
+	}
+	return stream.Send(&flight.Result{})
+}
+
+// TestSubscribeBatchesRefused tests that the channel is closed when the server
+// refuses to re-establish the subscription instead of retrying forever
+func TestSubscribeBatchesRefused(t *testing.T) {
+	server := &refusingWatchServer{}
+	addr := startBareServer(t, server)
+
+	client, err := NewFlightClient(FlightClientConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight client")
+	defer client.Close()
+
+	ids, err := client.SubscribeBatches(context.Background(), "")
+	require.NoError(t, err, "Failed to subscribe")
+
+	select {
+	case _, ok := <-ids:
+		assert.False(t, ok, "No batch was stored")
+	case <-time.After(5 * time.Second):
+		t.Fatal("The channel should close once the server refuses")
+	}
+	assert.Equal(t, int32(2), server.watches.Load(), "The subscription should not be retried")
+	assert.Zero(t, client.ActiveCalls(), "The subscription should release the connection")
+}
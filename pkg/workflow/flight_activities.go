@@ -3,6 +3,7 @@ package workflow
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"time"
 
@@ -125,6 +126,38 @@ func CloseFlightContext(ctx *FlightContext) error {
 	return serverErr
 }
 
+// FlightCallContext derives the context for Flight calls made inside an
+// activity, so that a call fails with context.DeadlineExceeded before the
+// activity itself times out, leaving time to record a heartbeat or return a
+// clean error. When the activity has a deadline (the earlier of its
+// StartToClose and ScheduleToClose timeouts), the returned context expires
+// margin before it, capped at a tenth of the remaining time so short
+// activities keep most of it. A margin of zero or less uses the default of
+// 5 seconds. Outside an activity, or without a deadline, ctx is only made
+// cancellable. The caller must call the returned cancel function.
+func FlightCallContext(ctx context.Context, margin time.Duration) (context.Context, context.CancelFunc) {
+	if !activity.IsActivity(ctx) {
+		return context.WithCancel(ctx)
+	}
+
+	deadline := activity.GetInfo(ctx).Deadline
+	if deadline.IsZero() {
+		return context.WithCancel(ctx)
+	}
+
+	if margin <= 0 {
+		margin = flightDeadlineSafetyMargin
+	}
+	margin = min(margin, time.Until(deadline)/10)
+	return context.WithDeadline(ctx, deadline.Add(-margin))
+}
+
+// flightCallContext derives the context used for Flight calls by the built-in
+// activities, with the default safety margin
+func flightCallContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return FlightCallContext(ctx, flightDeadlineSafetyMargin)
+}
+
 // FlightGenerateBatchActivity generates a batch and stores it in the Flight server
 func FlightGenerateBatchActivity(ctx context.Context, batchSize int, flightConfig FlightConfig) (string, error) {
 	// Get activity info for logging
@@ -161,7 +194,9 @@ func FlightGenerateBatchActivity(ctx context.Context, batchSize int, flightConfi
 	defer batch.Release()
 
 	// Store the batch in the Flight server
-	batchID, err := flightCtx.Client.PutBatch(ctx, batch)
+	callCtx, cancel := flightCallContext(ctx)
+	defer cancel()
+	batchID, err := flightCtx.Client.PutBatch(callCtx, batch)
 	if err != nil {
 		return "", fmt.Errorf("failed to store batch in Flight server: %w", err)
 	}
@@ -205,7 +240,9 @@ func FlightProcessBatchActivity(ctx context.Context, batchID string, threshold f
 	}()
 
 	// Retrieve the batch from the Flight server
-	batch, err := flightCtx.Client.GetBatch(ctx, batchID)
+	callCtx, cancel := flightCallContext(ctx)
+	defer cancel()
+	batch, err := flightCtx.Client.GetBatch(callCtx, batchID)
 	if err != nil {
 		return "", fmt.Errorf("failed to retrieve batch from Flight server: %w", err)
 	}
@@ -219,7 +256,7 @@ func FlightProcessBatchActivity(ctx context.Context, batchID string, threshold f
 	defer processedBatch.Release()
 
 	// Store the processed batch in the Flight server
-	processedBatchID, err := flightCtx.Client.PutBatch(ctx, processedBatch)
+	processedBatchID, err := flightCtx.Client.PutBatch(callCtx, processedBatch)
 	if err != nil {
 		return "", fmt.Errorf("failed to store processed batch in Flight server: %w", err)
 	}
@@ -263,7 +300,9 @@ func FlightStoreBatchActivity(ctx context.Context, batchID string, flightConfig
 	}()
 
 	// Retrieve the batch from the Flight server
-	batch, err := flightCtx.Client.GetBatch(ctx, batchID)
+	callCtx, cancel := flightCallContext(ctx)
+	defer cancel()
+	batch, err := flightCtx.Client.GetBatch(callCtx, batchID)
 	if err != nil {
 		return 0, fmt.Errorf("failed to retrieve batch from Flight server: %w", err)
 	}
@@ -276,9 +315,243 @@ func FlightStoreBatchActivity(ctx context.Context, batchID string, flightConfig
 	return numRows, nil
 }
 
+// FlightJoinBatchesActivity hash joins two batches on the given key columns and
+// stores the result as a new batch. The smaller batch, by the row counts the
+// server reports, is loaded into the hash table and the other one is streamed
+// through it; the left batch is streamed when either count is unknown.
+func FlightJoinBatchesActivity(ctx context.Context, leftID, rightID string, keyColumns []string, joinType arrow_utils.JoinType, flightConfig FlightConfig) (string, error) {
+	// Get activity info for logging
+	info := activity.GetInfo(ctx)
+	logger := activity.GetLogger(ctx)
+	logger.Info("Starting FlightJoinBatchesActivity", "ActivityID", info.ActivityID, "LeftID", leftID, "RightID", rightID)
+
+	// Get Flight context
+	flightCtx, err := GetFlightContext(ctx, flightConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to get Flight context: %w", err)
+	}
+	defer func() {
+		if err := CloseFlightContext(flightCtx); err != nil {
+			logger.Error("Failed to close flight context", "error", err)
+		}
+	}()
+
+	callCtx, cancel := flightCallContext(ctx)
+	defer cancel()
+
+	// Build on the left batch only if it is known to be the smaller one
+	buildLeft := false
+	leftSize, leftErr := flightCtx.Client.EstimateTransfer(callCtx, leftID)
+	rightSize, rightErr := flightCtx.Client.EstimateTransfer(callCtx, rightID)
+	if leftErr == nil && rightErr == nil {
+		buildLeft = leftSize.Rows < rightSize.Rows
+	}
+	probeID, buildID, probeSide, buildSide := leftID, rightID, "left", "right"
+	if buildLeft {
+		probeID, buildID, probeSide, buildSide = rightID, leftID, "right", "left"
+	}
+
+	// Open the probe side first so a missing batch fails before the build
+	probe, err := flightCtx.Client.GetBatchStream(callCtx, probeID)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s batch: %w", probeSide, err)
+	}
+	defer probe.Close()
+
+	// Build phase: load the smaller batch into the hash table
+	activity.RecordHeartbeat(ctx, "Building join hash table")
+	build, err := flightCtx.Client.GetBatch(callCtx, buildID)
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve %s batch: %w", buildSide, err)
+	}
+	defer build.Release()
+
+	var join *arrow_utils.HashJoin
+	if buildLeft {
+		join, err = arrow_utils.NewHashJoinBuildLeft(build, probe.Schema(), keyColumns, joinType, flightConfig.Allocator)
+	} else {
+		join, err = arrow_utils.NewHashJoin(probe.Schema(), build, keyColumns, joinType, flightConfig.Allocator)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to build join: %w", err)
+	}
+	defer join.Release()
+
+	// Probe phase: stream the other batch through the hash table
+	var joined []arrow.Record
+	defer func() {
+		for _, rec := range joined {
+			rec.Release()
+		}
+	}()
+
+	var probedRows int64
+	for {
+		rec, err := probe.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s batch: %w", probeSide, err)
+		}
+
+		out, err := join.Probe(callCtx, rec)
+		probedRows += rec.NumRows()
+		rec.Release()
+		if err != nil {
+			return "", fmt.Errorf("failed to probe join: %w", err)
+		}
+		joined = append(joined, out)
+		activity.RecordHeartbeat(ctx, probedRows)
+	}
+
+	// Add the rows only known once every probe row has been seen
+	rest, err := join.Finish(callCtx)
+	if err != nil {
+		return "", fmt.Errorf("failed to finish join: %w", err)
+	}
+	joined = append(joined, rest)
+
+	result, err := arrow_utils.ConcatRecords(flightConfig.Allocator, join.Schema(), joined)
+	if err != nil {
+		return "", fmt.Errorf("failed to combine join output: %w", err)
+	}
+	defer result.Release()
+
+	// Store the joined batch, recording both inputs as its lineage
+	putResult, err := flightCtx.Client.PutBatchWithOptions(callCtx, result, flight.PutOptions{
+		Lineage: []string{leftID, rightID},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to store joined batch in Flight server: %w", err)
+	}
+
+	logger.Info("Joined batches", "LeftID", leftID, "RightID", rightID, "JoinedBatchID", putResult.BatchID, "NumRows", result.NumRows())
+	return putResult.BatchID, nil
+}
+
+// FlightSortBatchActivity sorts a batch by the given keys and stores the result
+// as a new batch. The batch is sorted in memory, so it must fit in the worker's
+// memory roughly twice over.
+func FlightSortBatchActivity(ctx context.Context, batchID string, keys []arrow_utils.SortKey, flightConfig FlightConfig) (string, error) {
+	// Get activity info for logging
+	info := activity.GetInfo(ctx)
+	logger := activity.GetLogger(ctx)
+	logger.Info("Starting FlightSortBatchActivity", "ActivityID", info.ActivityID, "BatchID", batchID)
+
+	// Record heartbeats
+	heartbeat := time.NewTicker(5 * time.Second)
+	defer heartbeat.Stop()
+
+	go func() {
+		for range heartbeat.C {
+			activity.RecordHeartbeat(ctx, "Sorting batch")
+		}
+	}()
+
+	// Get Flight context
+	flightCtx, err := GetFlightContext(ctx, flightConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to get Flight context: %w", err)
+	}
+	defer func() {
+		if err := CloseFlightContext(flightCtx); err != nil {
+			logger.Error("Failed to close flight context", "error", err)
+		}
+	}()
+
+	// Retrieve the batch from the Flight server
+	callCtx, cancel := flightCallContext(ctx)
+	defer cancel()
+	batch, err := flightCtx.Client.GetBatch(callCtx, batchID)
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve batch from Flight server: %w", err)
+	}
+	defer batch.Release()
+
+	// Sort the batch
+	sorted, err := arrow_utils.SortRecord(callCtx, batch, keys, flightConfig.Allocator)
+	if err != nil {
+		return "", fmt.Errorf("failed to sort batch: %w", err)
+	}
+	defer sorted.Release()
+
+	// Store the sorted batch in the Flight server
+	result, err := flightCtx.Client.PutBatchWithOptions(callCtx, sorted, flight.PutOptions{
+		Lineage: []string{batchID},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to store sorted batch in Flight server: %w", err)
+	}
+
+	logger.Info("Sorted batch", "BatchID", batchID, "SortedBatchID", result.BatchID, "NumRows", sorted.NumRows())
+	return result.BatchID, nil
+}
+
+// FlightAggregateBatchActivity groups a batch by the given columns, applies the
+// aggregations and stores the result as a new batch. The batch is aggregated
+// in memory.
+func FlightAggregateBatchActivity(ctx context.Context, batchID string, groupBy []string, aggs []arrow_utils.Aggregation, flightConfig FlightConfig) (string, error) {
+	// Get activity info for logging
+	info := activity.GetInfo(ctx)
+	logger := activity.GetLogger(ctx)
+	logger.Info("Starting FlightAggregateBatchActivity", "ActivityID", info.ActivityID, "BatchID", batchID)
+
+	// Record heartbeats
+	heartbeat := time.NewTicker(5 * time.Second)
+	defer heartbeat.Stop()
+
+	go func() {
+		for range heartbeat.C {
+			activity.RecordHeartbeat(ctx, "Aggregating batch")
+		}
+	}()
+
+	// Get Flight context
+	flightCtx, err := GetFlightContext(ctx, flightConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to get Flight context: %w", err)
+	}
+	defer func() {
+		if err := CloseFlightContext(flightCtx); err != nil {
+			logger.Error("Failed to close flight context", "error", err)
+		}
+	}()
+
+	// Retrieve the batch from the Flight server
+	callCtx, cancel := flightCallContext(ctx)
+	defer cancel()
+	batch, err := flightCtx.Client.GetBatch(callCtx, batchID)
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve batch from Flight server: %w", err)
+	}
+	defer batch.Release()
+
+	// Aggregate the batch
+	aggregated, err := arrow_utils.AggregateRecord(callCtx, batch, groupBy, aggs, flightConfig.Allocator)
+	if err != nil {
+		return "", fmt.Errorf("failed to aggregate batch: %w", err)
+	}
+	defer aggregated.Release()
+
+	// Store the aggregated batch in the Flight server
+	result, err := flightCtx.Client.PutBatchWithOptions(callCtx, aggregated, flight.PutOptions{
+		Lineage: []string{batchID},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to store aggregated batch in Flight server: %w", err)
+	}
+
+	logger.Info("Aggregated batch", "BatchID", batchID, "AggregatedBatchID", result.BatchID, "NumGroups", aggregated.NumRows())
+	return result.BatchID, nil
+}
+
 // RegisterFlightActivities registers the Flight activities with the worker
 func RegisterFlightActivities(w worker.Worker) {
 	w.RegisterActivity(FlightGenerateBatchActivity)
 	w.RegisterActivity(FlightProcessBatchActivity)
 	w.RegisterActivity(FlightStoreBatchActivity)
+	w.RegisterActivity(FlightJoinBatchesActivity)
+	w.RegisterActivity(FlightSortBatchActivity)
+	w.RegisterActivity(FlightAggregateBatchActivity)
 }
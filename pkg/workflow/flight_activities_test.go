@@ -0,0 +1,348 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/testsuite"
+
+	arrow_utils "github.com/TFMV/temporal/pkg/arrow"
+	"github.com/TFMV/temporal/pkg/flight"
+)
+
+// startFlightServer starts a Flight server for activity tests and returns it
+// with a config pointing at it
+func startFlightServer(t *testing.T) (*flight.FlightServer, FlightConfig) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err, "Failed to find available port")
+	addr := listener.Addr().String()
+	listener.Close()
+
+	server, err := flight.NewFlightServer(flight.FlightServerConfig{Addr: addr})
+	require.NoError(t, err, "Failed to create Flight server")
+	go server.Start()
+	t.Cleanup(server.Stop)
+
+	// Wait for the server to start
+	time.Sleep(100 * time.Millisecond)
+
+	return server, FlightConfig{ServerAddr: addr}
+}
+
+// createSalesBatch creates a small batch of sales by region
+func createSalesBatch(t *testing.T) arrow.Record {
+	schema := arrow.NewSchema(
+		[]arrow.Field{
+			{Name: "region", Type: arrow.BinaryTypes.String},
+			{Name: "units", Type: arrow.PrimitiveTypes.Int64},
+			{Name: "price", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+		},
+		nil,
+	)
+
+	builder := array.NewRecordBuilder(memory.NewGoAllocator(), schema)
+	defer builder.Release()
+	builder.Field(0).(*array.StringBuilder).AppendValues([]string{"east", "west", "east", "west", "north"}, nil)
+	builder.Field(1).(*array.Int64Builder).AppendValues([]int64{1, 2, 3, 4, 5}, nil)
+	builder.Field(2).(*array.Float64Builder).AppendValues([]float64{10, 20, 30, 0, 50}, []bool{true, true, true, false, true})
+
+	return builder.NewRecord()
+}
+
+// TestFlightAggregateBatchActivity tests grouped aggregation of a stored batch
+func TestFlightAggregateBatchActivity(t *testing.T) {
+	server, config := startFlightServer(t)
+
+	batch := createSalesBatch(t)
+	defer batch.Release()
+	batchID := server.StoreBatch(batch)
+
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestActivityEnvironment()
+	env.RegisterActivity(FlightAggregateBatchActivity)
+
+	aggs := []arrow_utils.Aggregation{
+		{Func: arrow_utils.AggregateCount},
+		{Func: arrow_utils.AggregateSum, Column: "units"},
+		{Func: arrow_utils.AggregateAvg, Column: "price"},
+		{Func: arrow_utils.AggregateMax, Column: "price", Alias: "top_price"},
+	}
+	value, err := env.ExecuteActivity(FlightAggregateBatchActivity, batchID, []string{"region"}, aggs, config)
+	require.NoError(t, err, "Aggregation activity failed")
+
+	var resultID string
+	require.NoError(t, value.Get(&resultID))
+	result, err := server.RetrieveBatch(resultID)
+	require.NoError(t, err, "Aggregated batch should be stored")
+	defer result.Release()
+
+	require.Equal(t, int64(3), result.NumRows(), "One row per region")
+	names := make([]string, result.NumCols())
+	for i, field := range result.Schema().Fields() {
+		names[i] = field.Name
+	}
+	assert.Equal(t, []string{"region", "count", "sum_units", "avg_price", "top_price"}, names)
+
+	regions := result.Column(0).(*array.String)
+	counts := result.Column(1).(*array.Int64)
+	sums := result.Column(2).(*array.Int64)
+	avgs := result.Column(3).(*array.Float64)
+	maxes := result.Column(4).(*array.Float64)
+
+	// Groups appear in order of first appearance: east, west, north
+	assert.Equal(t, "east", regions.Value(0))
+	assert.Equal(t, int64(2), counts.Value(0))
+	assert.Equal(t, int64(4), sums.Value(0))
+	assert.InDelta(t, 20.0, avgs.Value(0), 1e-9)
+	assert.InDelta(t, 30.0, maxes.Value(0), 1e-9)
+
+	// Nulls are skipped by avg and max
+	assert.Equal(t, "west", regions.Value(1))
+	assert.Equal(t, int64(6), sums.Value(1))
+	assert.InDelta(t, 20.0, avgs.Value(1), 1e-9)
+	assert.InDelta(t, 20.0, maxes.Value(1), 1e-9)
+}
+
+// TestFlightJoinBatchesActivity tests left joins whose hash table is built on
+// either input, whichever has fewer rows
+func TestFlightJoinBatchesActivity(t *testing.T) {
+	server, config := startFlightServer(t)
+
+	sales := createSalesBatch(t)
+	defer sales.Release()
+	salesID := server.StoreBatch(sales)
+
+	builder := array.NewRecordBuilder(memory.NewGoAllocator(), arrow.NewSchema(
+		[]arrow.Field{
+			{Name: "region", Type: arrow.BinaryTypes.String},
+			{Name: "manager", Type: arrow.BinaryTypes.String},
+		},
+		nil,
+	))
+	defer builder.Release()
+	builder.Field(0).(*array.StringBuilder).AppendValues([]string{"east", "south"}, nil)
+	builder.Field(1).(*array.StringBuilder).AppendValues([]string{"ann", "bob"}, nil)
+	regions := builder.NewRecord()
+	defer regions.Release()
+	regionsID := server.StoreBatch(regions)
+
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestActivityEnvironment()
+	env.RegisterActivity(FlightJoinBatchesActivity)
+
+	join := func(leftID, rightID string) arrow.Record {
+		value, err := env.ExecuteActivity(FlightJoinBatchesActivity, leftID, rightID, []string{"region"}, arrow_utils.LeftJoin, config)
+		require.NoError(t, err, "Join activity failed")
+		var resultID string
+		require.NoError(t, value.Get(&resultID))
+		result, err := server.RetrieveBatch(resultID)
+		require.NoError(t, err, "Joined batch should be stored")
+		return result
+	}
+
+	// The regions are smaller, so they are built on as the right input...
+	result := join(salesID, regionsID)
+	require.Equal(t, int64(5), result.NumRows(), "Every sale is kept")
+	assert.Equal(t, "manager", result.Schema().Field(3).Name)
+	managers := map[string]int{}
+	for i := 0; i < int(result.NumRows()); i++ {
+		managers[result.Column(3).ValueStr(i)]++
+	}
+	assert.Equal(t, map[string]int{"ann": 2, "(null)": 3}, managers)
+	result.Release()
+
+	// ...and as the left input, including the region without sales
+	result = join(regionsID, salesID)
+	defer result.Release()
+	require.Equal(t, int64(3), result.NumRows(), "Two east sales and the unmatched south region")
+	assert.Equal(t, []string{"region", "manager", "units", "price"}, []string{
+		result.Schema().Field(0).Name, result.Schema().Field(1).Name,
+		result.Schema().Field(2).Name, result.Schema().Field(3).Name,
+	})
+	units := map[string]string{}
+	for i := 0; i < int(result.NumRows()); i++ {
+		units[result.Column(2).ValueStr(i)] = result.Column(0).ValueStr(i)
+	}
+	assert.Equal(t, map[string]string{"1": "east", "3": "east", "(null)": "south"}, units)
+}
+
+// TestFlightSortBatchActivity tests that a stored batch is sorted into a new
+// batch and that unknown sort columns fail the activity
+func TestFlightSortBatchActivity(t *testing.T) {
+	server, config := startFlightServer(t)
+
+	batch := createSalesBatch(t)
+	defer batch.Release()
+	batchID := server.StoreBatch(batch)
+
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestActivityEnvironment()
+	env.RegisterActivity(FlightSortBatchActivity)
+
+	keys := []arrow_utils.SortKey{{Column: "region"}, {Column: "units", Order: arrow_utils.Descending}}
+	value, err := env.ExecuteActivity(FlightSortBatchActivity, batchID, keys, config)
+	require.NoError(t, err, "Sort activity failed")
+
+	var resultID string
+	require.NoError(t, value.Get(&resultID))
+	assert.NotEqual(t, batchID, resultID, "The sorted batch should be stored separately")
+	result, err := server.RetrieveBatch(resultID)
+	require.NoError(t, err, "Sorted batch should be stored")
+	defer result.Release()
+
+	regions := result.Column(0).(*array.String)
+	units := result.Column(1).(*array.Int64)
+	var got []string
+	for i := 0; i < int(result.NumRows()); i++ {
+		got = append(got, regions.Value(i))
+	}
+	assert.Equal(t, []string{"east", "east", "north", "west", "west"}, got)
+	assert.Equal(t, []int64{3, 1, 5, 4, 2}, units.Int64Values())
+
+	_, err = env.ExecuteActivity(FlightSortBatchActivity, batchID, []arrow_utils.SortKey{{Column: "missing"}}, config)
+	assert.ErrorContains(t, err, "failed to sort batch")
+}
+
+// failingReader stops with an error after yielding limit records
+type failingReader struct {
+	array.RecordReader
+	limit int
+	err   error
+}
+
+// Next fails once the limit is reached
+func (r *failingReader) Next() bool {
+	if r.limit == 0 {
+		r.err = errors.New("source connection lost")
+		return false
+	}
+	r.limit--
+	return r.RecordReader.Next()
+}
+
+// Err returns the injected error
+func (r *failingReader) Err() error {
+	return r.err
+}
+
+// TestPutStreamActivityResume tests that a restarted upload resumes from its last heartbeat
+func TestPutStreamActivityResume(t *testing.T) {
+	server, config := startFlightServer(t)
+
+	batch := createSalesBatch(t)
+	defer batch.Release()
+
+	// The source yields one record per row, failing partway on the first attempt
+	var offsets []int64
+	attempt := 0
+	source := func(ctx context.Context, offset int64) (array.RecordReader, error) {
+		offsets = append(offsets, offset)
+		attempt++
+		var recs []arrow.Record
+		for row := offset; row < batch.NumRows(); row++ {
+			rec := batch.NewSlice(row, row+1)
+			defer rec.Release()
+			recs = append(recs, rec)
+		}
+		reader, err := array.NewRecordReader(batch.Schema(), recs)
+		if err != nil || attempt > 1 {
+			return reader, err
+		}
+		return &failingReader{RecordReader: reader, limit: 3}, nil
+	}
+	putStream := &PutStreamActivity{Source: source, FlightConfig: config}
+
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestActivityEnvironment()
+	env.RegisterActivity(putStream)
+	var checkpoint ProgressDetails
+	env.SetOnActivityHeartbeatListener(func(info *activity.Info, details converter.EncodedValues) {
+		require.NoError(t, details.Get(&checkpoint))
+	})
+
+	_, err := env.ExecuteActivity(putStream.PutStream)
+	require.ErrorContains(t, err, "source connection lost")
+	assert.Equal(t, int64(3), checkpoint.Offset)
+	assert.Equal(t, 3, checkpoint.Batches)
+	assert.Positive(t, checkpoint.Bytes)
+
+	// The retry starts from the recorded checkpoint
+	env = suite.NewTestActivityEnvironment()
+	env.RegisterActivity(putStream)
+	env.SetHeartbeatDetails(checkpoint)
+	value, err := env.ExecuteActivity(putStream.PutStream)
+	require.NoError(t, err, "Resumed upload failed")
+
+	var progress ProgressDetails
+	require.NoError(t, value.Get(&progress))
+	assert.Equal(t, []int64{0, 3}, offsets)
+	assert.Equal(t, batch.NumRows(), progress.Offset)
+	assert.Equal(t, int(batch.NumRows()), progress.Batches)
+	require.Len(t, progress.BatchIDs, int(batch.NumRows()))
+	assert.Equal(t, checkpoint.BatchIDs, progress.BatchIDs[:3], "Uploaded batches should not be sent again")
+
+	regions := make([]string, 0, batch.NumRows())
+	for _, batchID := range progress.BatchIDs {
+		stored, err := server.RetrieveBatch(batchID)
+		require.NoError(t, err, "Uploaded batch should be stored")
+		regions = append(regions, stored.Column(0).(*array.String).Value(0))
+		stored.Release()
+	}
+	assert.Equal(t, []string{"east", "west", "east", "west", "north"}, regions)
+}
+
+// TestFlightCallContext tests that calls made in an activity expire the safety
+// margin before the activity's deadline, and that the margin is capped
+func TestFlightCallContext(t *testing.T) {
+	type deadlines struct {
+		Activity time.Time
+		Call     time.Time
+	}
+	callDeadline := func(ctx context.Context, margin time.Duration) (deadlines, error) {
+		callCtx, cancel := FlightCallContext(ctx, margin)
+		defer cancel()
+		deadline, ok := callCtx.Deadline()
+		if !ok {
+			return deadlines{}, errors.New("call context has no deadline")
+		}
+		return deadlines{Activity: activity.GetInfo(ctx).Deadline, Call: deadline}, nil
+	}
+
+	var suite testsuite.WorkflowTestSuite
+	for name, test := range map[string]struct {
+		margin, want time.Duration
+	}{
+		"margin":  {margin: 30 * time.Second, want: 30 * time.Second},
+		"default": {margin: 0, want: flightDeadlineSafetyMargin},
+		// The test environment gives activities 10 minutes
+		"capped": {margin: time.Hour, want: time.Minute},
+	} {
+		t.Run(name, func(t *testing.T) {
+			env := suite.NewTestActivityEnvironment()
+			env.RegisterActivity(callDeadline)
+			value, err := env.ExecuteActivity(callDeadline, test.margin)
+			require.NoError(t, err, "Activity failed")
+
+			var got deadlines
+			require.NoError(t, value.Get(&got))
+			require.False(t, got.Activity.IsZero(), "The activity should have a deadline")
+			assert.WithinDuration(t, got.Activity.Add(-test.want), got.Call, time.Second)
+		})
+	}
+
+	// Outside an activity no deadline is added
+	ctx, cancel := FlightCallContext(context.Background(), time.Second)
+	defer cancel()
+	_, ok := ctx.Deadline()
+	assert.False(t, ok, "A context outside an activity should keep its deadline")
+}
@@ -0,0 +1,100 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"go.temporal.io/sdk/activity"
+
+	"github.com/TFMV/temporal/pkg/flight"
+)
+
+// RecordSource opens the input of a PutStreamActivity, skipping the first
+// offset rows so that a retried activity resumes where the last one stopped.
+// The activity releases the reader.
+type RecordSource func(ctx context.Context, offset int64) (array.RecordReader, error)
+
+// ProgressDetails is the progress of a PutStreamActivity, recorded as the
+// activity's heartbeat details after each uploaded batch and returned when
+// it completes
+type ProgressDetails struct {
+	// Batches is the number of record batches uploaded
+	Batches int `json:"batches"`
+	// Bytes is the number of IPC body bytes sent
+	Bytes int64 `json:"bytes"`
+	// Offset is the number of source rows uploaded, where a retry resumes
+	Offset int64 `json:"offset"`
+	// BatchIDs lists the stored batches in upload order
+	BatchIDs []string `json:"batchIds"`
+}
+
+// PutStreamActivity uploads a large load to the Flight server one record
+// batch at a time, storing each as its own batch, so that a retried attempt
+// re-sends at most the batch that was in flight when the last one failed.
+// Since the activity needs its Source it is registered as a struct:
+//
+//	w.RegisterActivity(&workflow.PutStreamActivity{Source: source, FlightConfig: config})
+//
+// Workflows should set a HeartbeatTimeout so that a stalled upload is retried.
+type PutStreamActivity struct {
+	// Source opens the records to upload
+	Source RecordSource
+	// FlightConfig locates the Flight server
+	FlightConfig FlightConfig
+}
+
+// PutStream uploads the records of the source, resuming from the progress
+// recorded in the heartbeat details of a previous attempt
+func (a *PutStreamActivity) PutStream(ctx context.Context) (ProgressDetails, error) {
+	info := activity.GetInfo(ctx)
+	logger := activity.GetLogger(ctx)
+
+	var progress ProgressDetails
+	if activity.HasHeartbeatDetails(ctx) {
+		if err := activity.GetHeartbeatDetails(ctx, &progress); err != nil {
+			return progress, fmt.Errorf("failed to read heartbeat details: %w", err)
+		}
+	}
+	logger.Info("Starting PutStream", "ActivityID", info.ActivityID, "Attempt", info.Attempt, "Offset", progress.Offset)
+
+	// Get Flight context
+	flightCtx, err := GetFlightContext(ctx, a.FlightConfig)
+	if err != nil {
+		return progress, fmt.Errorf("failed to get Flight context: %w", err)
+	}
+	defer func() {
+		if err := CloseFlightContext(flightCtx); err != nil {
+			logger.Error("Failed to close flight context", "error", err)
+		}
+	}()
+
+	callCtx, cancel := flightCallContext(ctx)
+	defer cancel()
+
+	reader, err := a.Source(callCtx, progress.Offset)
+	if err != nil {
+		return progress, fmt.Errorf("failed to open source at offset %d: %w", progress.Offset, err)
+	}
+	defer reader.Release()
+
+	for reader.Next() {
+		rec := reader.Record()
+		result, err := flightCtx.Client.PutBatchWithOptions(callCtx, rec, flight.PutOptions{})
+		if err != nil {
+			return progress, fmt.Errorf("failed to store batch at offset %d: %w", progress.Offset, err)
+		}
+
+		progress.Batches++
+		progress.Bytes += result.CompressedBytes
+		progress.Offset += rec.NumRows()
+		progress.BatchIDs = append(progress.BatchIDs, result.BatchID)
+		activity.RecordHeartbeat(ctx, progress)
+	}
+	if err := reader.Err(); err != nil {
+		return progress, fmt.Errorf("failed to read source at offset %d: %w", progress.Offset, err)
+	}
+
+	logger.Info("Uploaded stream", "Batches", progress.Batches, "Rows", progress.Offset, "Bytes", progress.Bytes)
+	return progress, nil
+}
@@ -15,6 +15,10 @@ const (
 	flightScheduleToStartTimeout = 1 * time.Minute
 	flightHeartbeatTimeout       = 30 * time.Second
 
+	// Flight calls made by activities are cut off this long before the activity
+	// deadline so they fail cleanly instead of outliving the activity
+	flightDeadlineSafetyMargin = 5 * time.Second
+
 	// Default retry policy for Flight workflow
 	flightMaxAttempts        = 3
 	flightInitialInterval    = 1 * time.Second